@@ -0,0 +1,143 @@
+// oidc-stub: identity provider tiruan untuk mencoba login SSO secara lokal.
+// Tidak ada halaman login: /authorize langsung menyetujui dengan email dari
+// ?login_hint= (atau STUB_EMAIL). JANGAN dipakai di production.
+//
+//	go run ./cmd/oidc-stub   (default :9999, issuer http://localhost:9999)
+//	OIDC_ISSUER=http://localhost:9999 OIDC_CLIENT_ID=be-ambis \
+//	OIDC_REDIRECT_URL=http://localhost:8080/api/auth/oidc/callback go run ./cmd/server
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type grant struct {
+	clientID, redirectURI, challenge, nonce, email string
+}
+
+var (
+	mu     sync.Mutex
+	grants = map[string]grant{}
+)
+
+func main() {
+	addr := env("STUB_ADDR", ":9999")
+	issuer := env("STUB_ISSUER", "http://localhost:9999")
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	const kid = "stub-1"
+
+	http.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"jwks_uri":                              issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"id_token_signing_alg_values_supported": []string{"EdDSA"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	})
+	http.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "OKP", "crv": "Ed25519", "use": "sig", "alg": "EdDSA", "kid": kid,
+			"x": base64.RawURLEncoding.EncodeToString(pub),
+		}}})
+	})
+	http.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+			http.Error(w, "stub requires response_type=code and PKCE S256", 400)
+			return
+		}
+		email := q.Get("login_hint")
+		if email == "" {
+			email = env("STUB_EMAIL", "sso.user@example.com")
+		}
+		code := randomString()
+		mu.Lock()
+		grants[code] = grant{q.Get("client_id"), q.Get("redirect_uri"), q.Get("code_challenge"), q.Get("nonce"), email}
+		mu.Unlock()
+		back, err := url.Parse(q.Get("redirect_uri"))
+		if err != nil {
+			http.Error(w, "bad redirect_uri", 400)
+			return
+		}
+		bq := back.Query()
+		bq.Set("code", code)
+		bq.Set("state", q.Get("state"))
+		back.RawQuery = bq.Encode()
+		http.Redirect(w, r, back.String(), http.StatusFound)
+	})
+	http.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		code := r.PostForm.Get("code")
+		mu.Lock()
+		g, ok := grants[code]
+		delete(grants, code)
+		mu.Unlock()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		switch {
+		case !ok:
+			writeJSON(w, 400, map[string]string{"error": "invalid_grant"})
+			return
+		case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+			writeJSON(w, 400, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+			return
+		case r.PostForm.Get("redirect_uri") != g.redirectURI:
+			writeJSON(w, 400, map[string]string{"error": "invalid_grant", "error_description": "redirect_uri mismatch"})
+			return
+		}
+		now := time.Now()
+		t := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"iss": issuer, "aud": g.clientID, "sub": "stub|" + g.email,
+			"iat": now.Unix(), "exp": now.Add(5 * time.Minute).Unix(),
+			"nonce": g.nonce, "email": g.email, "email_verified": true, "name": "SSO " + g.email,
+		})
+		t.Header["kid"] = kid
+		idToken, err := t.SignedString(priv)
+		if err != nil {
+			writeJSON(w, 500, map[string]string{"error": "server_error"})
+			return
+		}
+		writeJSON(w, 200, map[string]interface{}{
+			"access_token": randomString(), "token_type": "Bearer", "expires_in": 300, "id_token": idToken,
+		})
+	})
+
+	log.Printf("[oidc-stub] issuer=%s listening on %s", issuer, addr)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func env(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -3,112 +3,230 @@ package main
 import (
 	"context"
 	"log"
-	"net/http"
 	"time"
 
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/handlers"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/mailer"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/oidc"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/routes"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 
-	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	socketio "github.com/googollee/go-socket.io"
-	engineio "github.com/googollee/go-socket.io/engineio"
-	"github.com/googollee/go-socket.io/engineio/transport"
-	"github.com/googollee/go-socket.io/engineio/transport/polling"
-	"github.com/googollee/go-socket.io/engineio/transport/websocket"
 )
 
-var SocketServer *socketio.Server
-
 func main() {
 	config.Load()
+	if err := utils.LoadJWTKeys(); err != nil {
+		log.Fatalf("[jwt] %v", err)
+	}
 
-	SocketServer = socketio.NewServer(&engineio.Options{
-		Transports: []transport.Transport{
-			&polling.Transport{},
-			&websocket.Transport{
-				CheckOrigin: func(r *http.Request) bool { return true },
-			},
-		},
-	})
-	SocketServer.OnConnect("/", func(s socketio.Conn) error {
-		log.Println("socket connected:", s.ID())
-		return nil
-	})
-	SocketServer.OnEvent("/", "join_board", func(s socketio.Conn, boardId string) {
-		s.Join(boardId)
-		log.Println("[SOCKET] join:", s.ID(), "->", boardId)
-	})
+	repos := openStorage()
+	authz.Init(repos)
+	ensureAdmins(services.NewUserService(repos.Users, config.Cfg.AdminEmails))
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	bp := newBackplane()
+
+	app, closeApp := newServer(ctx, repos, bp)
+	defer closeApp()
+
+	// instance kedua (DEV_MODE): storage & backplane sama, room realtime terpisah
+	if config.Cfg.DevMode && config.Cfg.DevSecondPort != "" {
+		app2, closeApp2 := newServer(ctx, repos, bp)
+		defer closeApp2()
+		go func() {
+			log.Fatal(app2.Listen(":" + config.Cfg.DevSecondPort))
+		}()
+		log.Printf("[dev] second instance on :%s", config.Cfg.DevSecondPort)
+	}
 
-	SocketServer.OnEvent("/", "leave_board", func(s socketio.Conn, boardID string) {
-		log.Printf("socket %s leave board %s", s.ID(), boardID)
-		s.Leave(boardID)
-		s.Emit("left_board", boardID)
-	})
-	SocketServer.OnError("/", func(s socketio.Conn, e error) {
-		log.Println("socket error:", e)
-	})
-	SocketServer.OnDisconnect("/", func(s socketio.Conn, reason string) {
-		log.Println("socket disconnected:", reason)
-	})
-	go func() {
-		if err := SocketServer.Serve(); err != nil {
-			log.Fatalf("socketio listen error: %s\n", err)
+	log.Fatal(app.Listen(":" + config.Cfg.Port))
+}
+
+// openStorage: Mongo, atau in-memory untuk dev tanpa database
+func openStorage() *repository.Repos {
+	if config.Cfg.Storage == "memory" {
+		log.Println("[storage] in-memory backend, data hilang saat restart")
+		return repository.NewMemory()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := config.ConnectMongo(ctx); err != nil {
+		log.Fatal(err)
+	}
+	return repository.NewMongo(config.MongoDB)
+}
+
+// newBackplane sesuai config REALTIME_BACKPLANE
+func newBackplane() realtime.Backplane {
+	if config.Cfg.RealtimeBackplane == "mongo" {
+		if config.MongoDB == nil {
+			log.Fatal("[realtime] REALTIME_BACKPLANE=mongo requires STORAGE=mongo")
 		}
-	}()
-	defer SocketServer.Close()
+		return realtime.NewMongoBackplane(config.MongoDB)
+	}
+	return realtime.NewMemoryBackplane()
+}
 
-	app := fiber.New(fiber.Config{AppName: "Be-Ambis-Solving"})
+// newServer merakit service, handler, route dan server realtime satu instance.
+// Fungsi close menutup server socket.io.
+func newServer(ctx context.Context, repos *repository.Repos, bp realtime.Backplane) (*fiber.App, func()) {
+	app := fiber.New(fiber.Config{
+		AppName: "Be-Ambis-Solving",
+		// di belakang proxy: IP klien (untuk batas login per IP) dari header ini
+		ProxyHeader: config.Cfg.ProxyHeader,
+	})
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
-		ExposeHeaders:    "Content-Length",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, If-Match, If-None-Match",
+		ExposeHeaders:    "Content-Length, ETag",
 		AllowCredentials: false,
 	}))
-	app.All("/socket.io/*", adaptor.HTTPHandler(SocketServer))
 	app.Use(recover.New())
 	app.Use(logger.New())
 
 	// Healthcheck
 	app.Get("/health", func(c *fiber.Ctx) error { return c.SendString("ok") })
 
-	// DB connect
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := config.ConnectMongo(ctx); err != nil {
-		log.Fatal(err)
+	userSvc := services.NewUserService(repos.Users, config.Cfg.AdminEmails)
+	sessionSvc := services.NewSessionService(repos.Sessions, config.Cfg.AccessTokenTTL, config.Cfg.RefreshTokenTTL)
+	// cache IsActive per instance: pencabutan dikabarkan ke instance lain
+	if err := realtime.ShareRevocations(ctx, sessionSvc, bp); err != nil {
+		log.Fatalf("[realtime] backplane: %v", err)
+	}
+	auditSvc := services.NewAuditService(repos.Audit)
+	guard := services.NewLoginGuard(repos.Throttles, repos.Users, auditSvc, services.LoginGuardOptions{
+		MaxFailures:   config.Cfg.LoginMaxFailures,
+		IPMaxFailures: config.Cfg.LoginIPMaxFailures,
+		Lockout:       config.Cfg.LoginLockout,
+	})
+	mfaSvc := services.NewMFAService(repos.Users, repos.MFA, sessionSvc, guard, config.Cfg.MFAIssuer)
+	authSvc := services.NewAuthService(userSvc, sessionSvc, guard, mfaSvc, config.Cfg.RequireEmailVerification)
+	tokenSvc := services.NewAccessTokenService(repos.Tokens, repos.Users)
+	mail := newMailer()
+	accountSvc := services.NewAccountService(repos.Users, repos.EmailTokens, sessionSvc, tokenSvc, mail, services.AccountOptions{
+		AppURL:    config.Cfg.AppURL,
+		ResetTTL:  config.Cfg.ResetTokenTTL,
+		VerifyTTL: config.Cfg.VerifyTokenTTL,
+	})
+	historySvc := services.NewBoardEventService(repos.BoardEvents)
+	presenceSvc := services.NewPresenceService(repos.Presence)
+	presence := realtime.NewPresence(presenceSvc)
+	activitySvc := services.NewActivityService(repos.Activities)
+	editor := realtime.NewTaskEditor(services.NewTaskDocService(repos.TaskDocs, repos.Tasks, repos.Tx, activitySvc))
+	// socket.io: handshake wajib token, join_board hanya untuk member board
+	socketOpts := realtime.SocketOptions{
+		Sessions:        sessionSvc,
+		Tokens:          tokenSvc,
+		Events:          historySvc,
+		Presence:        presence,
+		Editor:          editor,
+		AllowedOrigins:  config.Cfg.SocketAllowedOrigins,
+		AllowAllOrigins: config.Cfg.DevMode,
 	}
+	socketServer := realtime.New(socketOpts)
+	go func() {
+		if err := socketServer.Serve(); err != nil {
+			log.Fatalf("socketio listen error: %s\n", err)
+		}
+	}()
+	// satu event bus untuk socket.io dan WebSocket biasa (/ws/boards/:boardId),
+	// diteruskan ke instance lain lewat backplane; seq diberikan sekali di sini
+	hub := realtime.NewHub()
+	distributed, err := realtime.Distributed(ctx, realtime.Multi(realtime.NewSocketIOPublisher(socketServer, presence), hub), bp)
+	if err != nil {
+		log.Fatalf("[realtime] backplane: %v", err)
+	}
+	events := realtime.Sequenced(historySvc, distributed)
+	// presence_update tidak diberi seq / disimpan di log replay
+	presence.Attach(distributed)
+	go presence.Run(ctx)
+	// task_op juga tanpa seq; snapshot deskripsi diumumkan lewat events
+	editor.Attach(distributed, events)
+	go editor.Run(ctx)
+
+	boardSvc := services.NewBoardService(repos.Boards, activitySvc)
+	inviteSvc := services.NewInvitationService(repos.Invitations, repos.Users, boardSvc, mail, services.InvitationOptions{
+		AppURL: config.Cfg.AppURL,
+		TTL:    config.Cfg.InviteTTL,
+	})
+	authH := handlers.NewAuthHandler(authSvc, userSvc, inviteSvc, sessionSvc, accountSvc)
+	oidcSvc := services.NewOIDCService(services.OIDCOptions{
+		Provider: oidc.Config{
+			Issuer:       config.Cfg.OIDCIssuer,
+			ClientID:     config.Cfg.OIDCClientID,
+			ClientSecret: config.Cfg.OIDCClientSecret,
+			RedirectURL:  config.Cfg.OIDCRedirectURL,
+			Scopes:       config.Cfg.OIDCScopes,
+		},
+		AutoCreate:       config.Cfg.OIDCAutoCreate,
+		AllowedRedirects: config.Cfg.OIDCAllowedRedirects,
+	}, repos.LoginStates, userSvc, sessionSvc, mfaSvc)
+	oidcH := handlers.NewOIDCHandler(oidcSvc)
+	mfaH := handlers.NewMFAHandler(mfaSvc)
+	userH := handlers.NewUserHandler(userSvc, accountSvc)
 
-	userSvc := services.NewUserService()
-	authSvc := services.NewAuthService(userSvc)
-	authH := handlers.NewAuthHandler(authSvc, userSvc)
+	taskSvc := services.NewTaskService(repos.Tasks, repos.Notes, repos.Boards, repos.Tx, activitySvc, services.OrderingMode(config.Cfg.TaskOrdering))
+	noteSvc := services.NewNoteService(repos.Notes, repos.Tasks, repos.Boards, activitySvc)
 
-	boardSvc := services.NewBoardService()
-	taskSvc := services.NewTaskService()
-	noteSvc := services.NewNoteService()
+	trashSvc := services.NewTrashService(repos.Boards, repos.Tasks, repos.Notes, config.Cfg.TrashRetention)
+	go trashSvc.Run(ctx)
 
-	boardH := handlers.NewBoardHandler(boardSvc, SocketServer)
-	taskH := handlers.NewTaskHandler(taskSvc, SocketServer)
+	boardH := handlers.NewBoardHandler(boardSvc, events, historySvc, presenceSvc, activitySvc, trashSvc)
+	taskH := handlers.NewTaskHandler(taskSvc, events, editor, activitySvc)
+	inviteH := handlers.NewInvitationHandler(inviteSvc, boardSvc, events)
 
 	noteH := handlers.NewNoteHandler(noteSvc)
-	timelineH := handlers.NewTimelineHandler()
+	timelineH := handlers.NewTimelineHandler(taskSvc, noteSvc)
 
-	devH := handlers.NewDevHandler(boardSvc, taskSvc)
+	adminSvc := services.NewAdminService(repos.Users, repos.Boards, repos.Tasks, repos.Notes, boardSvc, accountSvc, auditSvc)
+	adminH := handlers.NewAdminHandler(adminSvc, boardSvc, taskSvc, noteSvc, guard)
+	tokenH := handlers.NewAccessTokenHandler(tokenSvc)
 
-	routes.Register(app, authH, boardH, taskH, noteH, timelineH, devH)
+	var devH *handlers.DevHandler
+	if config.Cfg.DevMode {
+		devH = handlers.NewDevHandler(boardSvc, taskSvc)
+	}
 
-	app.Use("/socket.io/*", func(c *fiber.Ctx) error {
-		log.Printf("[SOCKETIO] HIT %s", c.OriginalURL())
-		return c.Next()
-	})
+	routes.Register(app, authH, boardH, taskH, noteH, timelineH, inviteH, oidcH, mfaH, userH, adminH, tokenH, devH)
 
-	app.All("/socket.io/*", adaptor.HTTPHandler(SocketServer))
+	realtime.Mount(app, socketServer, hub, socketOpts)
 
-	log.Fatal(app.Listen(":" + config.Cfg.Port))
+	return app, func() { _ = socketServer.Close() }
+}
+
+// ensureAdmins: promosikan user di ADMIN_EMAILS; gagal tidak menghentikan server
+func ensureAdmins(users services.UserService) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := users.EnsureAdmins(ctx); err != nil {
+		log.Printf("[admin] ADMIN_EMAILS: %v", err)
+	}
+}
+
+// newMailer sesuai config MAILER
+func newMailer() mailer.Mailer {
+	if config.Cfg.Mailer == "smtp" {
+		if config.Cfg.SMTPHost == "" {
+			log.Fatal("[mail] MAILER=smtp requires SMTP_HOST")
+		}
+		return &mailer.SMTP{
+			Host:     config.Cfg.SMTPHost,
+			Port:     config.Cfg.SMTPPort,
+			Username: config.Cfg.SMTPUsername,
+			Password: config.Cfg.SMTPPassword,
+			From:     config.Cfg.MailFrom,
+		}
+	}
+	return &mailer.Log{Path: config.Cfg.MailLogFile}
 }
@@ -2,33 +2,207 @@ package authz
 
 import (
 	"context"
+	"errors"
 	"time"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func IsMemberOrOwner(ctx context.Context, boardID, userID primitive.ObjectID) (bool, error) {
-	var doc struct {
-		OwnerID primitive.ObjectID `bson:"ownerId"`
+var (
+	boards repository.BoardRepo
+	tasks  repository.TaskRepo
+	notes  repository.NoteRepo
+	users  repository.UserRepo
+)
+
+// Init memasang repository yang dipakai pengecekan akses; panggil sekali saat startup
+func Init(r *repository.Repos) {
+	boards = r.Boards
+	tasks = r.Tasks
+	notes = r.Notes
+	users = r.Users
+}
+
+// IsAdmin: role global user adalah admin (user nonaktif tidak dihitung)
+func IsAdmin(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	return HasRole(ctx, userID, models.RoleAdmin)
+}
+
+// HasRole: role global user salah satu dari roles (user nonaktif selalu false)
+func HasRole(ctx context.Context, userID primitive.ObjectID, roles ...models.UserRole) (bool, error) {
+	u, err := users.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !u.IsActive {
+		return false, nil
+	}
+	for _, r := range roles {
+		if u.Role == r {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ErrMFARequired: user member board, tapi board mewajibkan 2FA dan user belum
+// mengaktifkannya
+var ErrMFARequired = errors.New("this board requires two-factor authentication")
+
+// RoleOf: role user di board; false bila bukan owner/member
+func RoleOf(ctx context.Context, userID, boardID primitive.ObjectID) (models.BoardRole, bool, error) {
+	b, err := boards.FindByID(ctx, boardID)
+	if err != nil {
+		return "", false, err
+	}
+	return roleIn(ctx, b, userID)
+}
+
+// RoleOfDeleted: seperti RoleOf, untuk board yang ada di trash
+func RoleOfDeleted(ctx context.Context, userID, boardID primitive.ObjectID) (models.BoardRole, bool, error) {
+	b, err := boards.FindDeleted(ctx, boardID)
+	if err != nil {
+		return "", false, err
+	}
+	return roleIn(ctx, b, userID)
+}
+
+// RoleOfAny: board aktif maupun di trash (restore isi board; service yang
+// menolak bila board-nya belum di-restore)
+func RoleOfAny(ctx context.Context, userID, boardID primitive.ObjectID) (models.BoardRole, bool, error) {
+	role, ok, err := RoleOf(ctx, userID, boardID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return RoleOfDeleted(ctx, userID, boardID)
+	}
+	return role, ok, err
+}
+
+func roleIn(ctx context.Context, b *models.Board, userID primitive.ObjectID) (models.BoardRole, bool, error) {
+	role, ok := b.RoleOf(userID)
+	if ok && b.RequireMFA {
+		has, err := HasMFA(ctx, userID)
+		if err != nil {
+			return "", false, err
+		}
+		if !has {
+			return "", false, ErrMFARequired
+		}
+	}
+	return role, ok, nil
+}
+
+// HasMFA: user sudah mengaktifkan 2FA
+func HasMFA(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	u, err := users.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
-	if err := config.MongoDB.Collection("boards").FindOne(ctx, bson.M{"_id": boardID}).Decode(&doc); err != nil {
+	return u.MFAEnabled(), nil
+}
+
+// Can: apakah user boleh melakukan action di board
+func Can(ctx context.Context, userID, boardID primitive.ObjectID, action Action) (bool, error) {
+	role, ok, err := RoleOf(ctx, userID, boardID)
+	if err != nil || !ok {
 		return false, err
 	}
-	if doc.OwnerID == userID {
-		return true, nil
+	return RoleAllows(role, action), nil
+}
+
+// Boards: board aktif (termasuk yang diarsipkan) tempat user boleh melakukan
+// action; board wajib 2FA dilewati bila user belum mengaktifkannya
+func Boards(ctx context.Context, userID primitive.ObjectID, action Action) ([]primitive.ObjectID, error) {
+	list, err := boards.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var out []primitive.ObjectID
+	for i := range list {
+		role, ok, err := roleIn(ctx, &list[i], userID)
+		if errors.Is(err, ErrMFARequired) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ok && RoleAllows(role, action) {
+			out = append(out, list[i].ID)
+		}
 	}
-	n, err := config.MongoDB.Collection("boards").CountDocuments(ctx, bson.M{"_id": boardID, "members": userID})
-	return n > 0, err
+	return out, nil
+}
+
+func IsMemberOrOwner(ctx context.Context, boardID, userID primitive.ObjectID) (bool, error) {
+	_, ok, err := RoleOf(ctx, userID, boardID)
+	return ok, err
+}
+
+// IsArchived: board diarsipkan (task & note-nya read-only)
+func IsArchived(ctx context.Context, boardID primitive.ObjectID) (bool, error) {
+	b, err := boards.FindByID(ctx, boardID)
+	if err != nil {
+		return false, err
+	}
+	return b.IsArchived, nil
 }
 
 func BoardIDFromTask(ctx context.Context, taskID primitive.ObjectID) (primitive.ObjectID, error) {
-	var t struct {
-		BoardID primitive.ObjectID `bson:"boardId"`
+	t, err := tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return t.BoardID, nil
+}
+
+// BoardIDFromDeletedTask: seperti BoardIDFromTask, untuk task di trash
+func BoardIDFromDeletedTask(ctx context.Context, taskID primitive.ObjectID) (primitive.ObjectID, error) {
+	t, err := tasks.FindDeleted(ctx, taskID)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return t.BoardID, nil
+}
+
+// CanEditNote: author butuh note:write, orang lain butuh note:moderate.
+// Note tanpa board (pribadi) hanya bisa diubah author-nya.
+func CanEditNote(ctx context.Context, userID, noteID primitive.ObjectID) (bool, error) {
+	n, err := notes.FindByID(ctx, noteID)
+	if err != nil {
+		return false, err
+	}
+	return canEditNote(ctx, userID, n, RoleOf)
+}
+
+// CanRestoreNote: aturan CanEditNote untuk note di trash
+func CanRestoreNote(ctx context.Context, userID, noteID primitive.ObjectID) (bool, error) {
+	n, err := notes.FindDeleted(ctx, noteID)
+	if err != nil {
+		return false, err
+	}
+	return canEditNote(ctx, userID, n, RoleOfAny)
+}
+
+func canEditNote(ctx context.Context, userID primitive.ObjectID, n *models.Note, roleOf func(context.Context, primitive.ObjectID, primitive.ObjectID) (models.BoardRole, bool, error)) (bool, error) {
+	if n.BoardID == nil {
+		return n.AuthorID == userID, nil
+	}
+	action := NoteModerate
+	if n.AuthorID == userID {
+		action = NoteWrite
+	}
+	role, ok, err := roleOf(ctx, userID, *n.BoardID)
+	if err != nil || !ok {
+		return false, err
 	}
-	err := config.MongoDB.Collection("tasks").FindOne(ctx, bson.M{"_id": taskID}).Decode(&t)
-	return t.BoardID, err
+	return RoleAllows(role, action), nil
 }
 
 func WithTimeout(parent context.Context) (context.Context, context.CancelFunc) {
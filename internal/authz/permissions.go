@@ -0,0 +1,62 @@
+package authz
+
+import "github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+
+// Action: izin yang dicek terhadap role user di sebuah board
+type Action string
+
+const (
+	BoardRead    Action = "board:read"
+	BoardUpdate  Action = "board:update"  // nama, deskripsi, kolom
+	BoardMembers Action = "board:members" // tambah/hapus member & ubah role
+	BoardDelete  Action = "board:delete"
+	TaskRead     Action = "task:read"
+	TaskWrite    Action = "task:write"
+	NoteRead     Action = "note:read"
+	NoteWrite    Action = "note:write"    // tulis note sendiri
+	NoteModerate Action = "note:moderate" // ubah/hapus note orang lain
+	// BoardCreate bukan izin role (board belum ada); hanya dibatasi scope token
+	BoardCreate Action = "board:create"
+)
+
+var rolePerms = map[models.BoardRole]map[Action]bool{
+	models.BoardRoleOwner: set(BoardRead, BoardUpdate, BoardMembers, BoardDelete,
+		TaskRead, TaskWrite, NoteRead, NoteWrite, NoteModerate),
+	models.BoardRoleAdmin: set(BoardRead, BoardUpdate, BoardMembers,
+		TaskRead, TaskWrite, NoteRead, NoteWrite, NoteModerate),
+	models.BoardRoleEditor:    set(BoardRead, TaskRead, TaskWrite, NoteRead, NoteWrite, NoteModerate),
+	models.BoardRoleCommenter: set(BoardRead, TaskRead, NoteRead, NoteWrite),
+	models.BoardRoleViewer:    set(BoardRead, TaskRead, NoteRead),
+}
+
+func set(actions ...Action) map[Action]bool {
+	m := make(map[Action]bool, len(actions))
+	for _, a := range actions {
+		m[a] = true
+	}
+	return m
+}
+
+// RoleAllows: apakah role boleh melakukan action
+func RoleAllows(role models.BoardRole, action Action) bool {
+	return rolePerms[role][action]
+}
+
+// scopePerms: batas personal access token. Tetap dicek bersama role user di
+// board; scope tidak pernah menambah izin.
+var scopePerms = map[models.TokenScope]map[Action]bool{
+	models.ScopeRead:       set(BoardRead, TaskRead, NoteRead),
+	models.ScopeTasksWrite: set(BoardRead, TaskRead, TaskWrite, NoteRead),
+	models.ScopeBoardsAdmin: set(BoardRead, BoardUpdate, BoardMembers, BoardDelete, BoardCreate,
+		TaskRead, TaskWrite, NoteRead, NoteWrite, NoteModerate),
+}
+
+// ScopeAllows: salah satu scope token mengizinkan action
+func ScopeAllows(scopes []models.TokenScope, action Action) bool {
+	for _, s := range scopes {
+		if scopePerms[s][action] {
+			return true
+		}
+	}
+	return false
+}
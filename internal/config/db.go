@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"log"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -23,30 +24,66 @@ func ConnectMongo(ctx context.Context) error {
 	MongoClient = cl
 	MongoDB = cl.Database(Cfg.DBName)
 	log.Println("[mongo] connected")
-	return ensureIndexes(ctx)
+	if err := ensureIndexes(ctx); err != nil {
+		return err
+	}
+	return lowercaseEmails(ctx)
+}
+
+// lowercaseEmails: email user disimpan lowercase (lookup & uniq_email exact);
+// data lama yang masih berhuruf besar diubah sekali saat startup. Bentrok
+// dengan akun lain (beda huruf saja) hanya di-log, perlu dibereskan manual.
+func lowercaseEmails(ctx context.Context) error {
+	users := MongoDB.Collection("users")
+	cur, err := users.Find(ctx, bson.M{"email": bson.M{"$regex": "[A-Z]"}},
+		options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return err
+	}
+	var rows []struct {
+		ID    interface{} `bson:"_id"`
+		Email string      `bson:"email"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		lower := strings.ToLower(strings.TrimSpace(r.Email))
+		_, err := users.UpdateByID(ctx, r.ID, bson.M{"$set": bson.M{"email": lower}})
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("[mongo] email %q not lowercased: %q already exists", r.Email, lower)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func ensureIndexes(ctx context.Context) error {
-	// users: email unique
+	// users: email unique, createdAt (daftar admin)
 	users := MongoDB.Collection("users")
-	_, err := users.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{Key: "email", Value: 1}},
-		Options: options.Index().SetUnique(true).SetName("uniq_email"),
+	_, err := users.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_email")},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("ix_createdAt")},
 	})
 	if err != nil {
 		return err
 	}
 
-	// boards: ownerId, members
+	// boards: ownerId, members, createdAt (daftar admin), deletedAt (purge trash)
 	boards := MongoDB.Collection("boards")
 	if _, err = boards.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "ownerId", Value: 1}}, Options: options.Index().SetName("ix_ownerId")},
 		{Keys: bson.D{{Key: "members", Value: 1}}, Options: options.Index().SetName("ix_members")},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}, Options: options.Index().SetName("ix_createdAt")},
+		{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetName("ix_deletedAt").SetSparse(true)},
 	}); err != nil {
 		return err
 	}
 
-	// tasks: boardId, status, assignees, dueDate, columnId+order
+	// tasks: boardId, status, assignees, dueDate, columnId+order, columnId+rank, deletedAt
 	tasks := MongoDB.Collection("tasks")
 	if _, err = tasks.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "boardId", Value: 1}}, Options: options.Index().SetName("ix_boardId")},
@@ -55,16 +92,149 @@ func ensureIndexes(ctx context.Context) error {
 		{Keys: bson.D{{Key: "dueDate", Value: 1}}, Options: options.Index().SetName("ix_dueDate")},
 		{Keys: bson.D{{Key: "boardId", Value: 1}, {Key: "columnId", Value: 1}, {Key: "order", Value: 1}},
 			Options: options.Index().SetName("ix_board_column_order")},
+		{Keys: bson.D{{Key: "boardId", Value: 1}, {Key: "columnId", Value: 1}, {Key: "rank", Value: 1}},
+			Options: options.Index().SetName("ix_board_column_rank")},
+		{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetName("ix_deletedAt").SetSparse(true)},
 	}); err != nil {
 		return err
 	}
 
-	// notes: taskId, boardId, onTimelineAt
+	// notes: taskId, boardId, onTimelineAt, deletedAt
 	notes := MongoDB.Collection("notes")
 	if _, err = notes.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{Keys: bson.D{{Key: "taskId", Value: 1}}, Options: options.Index().SetName("ix_taskId")},
 		{Keys: bson.D{{Key: "boardId", Value: 1}}, Options: options.Index().SetName("ix_boardId")},
 		{Keys: bson.D{{Key: "onTimelineAt", Value: 1}}, Options: options.Index().SetName("ix_onTimelineAt")},
+		{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetName("ix_deletedAt").SetSparse(true)},
+	}); err != nil {
+		return err
+	}
+
+	// invitations: satu undangan pending per board+email, token, inbox per email
+	invitations := MongoDB.Collection("invitations")
+	if _, err = invitations.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "boardId", Value: 1}, {Key: "email", Value: 1}},
+			Options: options.Index().SetName("uniq_pending_board_email").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": "pending"})},
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetName("uniq_tokenHash").SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "status", Value: 1}}, Options: options.Index().SetName("ix_email_status")},
+	}); err != nil {
+		return err
+	}
+
+	// sessions: per user, lookup refresh token, hapus otomatis setelah expiresAt
+	sessions := MongoDB.Collection("sessions")
+	if _, err = sessions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetName("ix_userId")},
+		{Keys: bson.D{{Key: "refreshHash", Value: 1}}, Options: options.Index().SetName("uniq_refreshHash").SetUnique(true)},
+		{Keys: bson.D{{Key: "prevRefreshHash", Value: 1}}, Options: options.Index().SetName("ix_prevRefreshHash").SetSparse(true)},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("ttl_expiresAt").SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	// login_states (OIDC): lookup state, hapus otomatis setelah expiresAt
+	loginStates := MongoDB.Collection("login_states")
+	if _, err = loginStates.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "state", Value: 1}}, Options: options.Index().SetName("uniq_state").SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("ttl_expiresAt").SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	// email_tokens (reset password / verifikasi email): hapus otomatis setelah expiresAt
+	emailTokens := MongoDB.Collection("email_tokens")
+	if _, err = emailTokens.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetName("uniq_tokenHash").SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "purpose", Value: 1}}, Options: options.Index().SetName("ix_user_purpose")},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("ttl_expiresAt").SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	// login_throttles: satu dokumen per key, hapus otomatis setelah expiresAt
+	throttles := MongoDB.Collection("login_throttles")
+	if _, err = throttles.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetName("uniq_key").SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("ttl_expiresAt").SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	// audit_events: per user & per jenis, terbaru dulu
+	audit := MongoDB.Collection("audit_events")
+	if _, err = audit.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subjectId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("ix_subject_createdAt")},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("ix_type_createdAt")},
+	}); err != nil {
+		return err
+	}
+
+	// mfa_challenges: login yang menunggu kode 2FA
+	mfa := MongoDB.Collection("mfa_challenges")
+	if _, err = mfa.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetName("uniq_tokenHash").SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("ttl_expiresAt").SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	// access_tokens: lookup token, daftar per user
+	tokens := MongoDB.Collection("access_tokens")
+	if _, err = tokens.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetName("uniq_tokenHash").SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}, Options: options.Index().SetName("ix_userId_createdAt")},
+	}); err != nil {
+		return err
+	}
+
+	// realtime_events: pesan backplane, cukup disimpan sebentar
+	realtimeEvents := MongoDB.Collection("realtime_events")
+	if _, err = realtimeEvents.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetName("ttl_createdAt").SetExpireAfterSeconds(3600),
+	}); err != nil {
+		return err
+	}
+
+	// board_events: log replay per board, urut seq
+	boardEvents := MongoDB.Collection("board_events")
+	if _, err = boardEvents.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "boardId", Value: 1}, {Key: "seq", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_board_seq"),
+	}); err != nil {
+		return err
+	}
+
+	// presence: satu entri per board+koneksi; TTL hanya cadangan bila tidak
+	// ada instance yang menyapu (sweeper biasanya menghapus lebih dulu)
+	presence := MongoDB.Collection("presence")
+	if _, err = presence.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "boardId", Value: 1}, {Key: "connectionId", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_board_connection")},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetName("ttl_expiresAt").SetExpireAfterSeconds(300)},
+	}); err != nil {
+		return err
+	}
+
+	// task_docs: sesi edit deskripsi bersama; disapu bila idle (updatedAt)
+	taskDocs := MongoDB.Collection("task_docs")
+	if _, err = taskDocs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "updatedAt", Value: 1}}, Options: options.Index().SetName("ix_updatedAt"),
+	}); err != nil {
+		return err
+	}
+
+	// task_doc_ops: satu op per rev dokumen
+	taskDocOps := MongoDB.Collection("task_doc_ops")
+	if _, err = taskDocOps.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "taskId", Value: 1}, {Key: "rev", Value: 1}}, Options: options.Index().SetUnique(true).SetName("uniq_task_rev"),
+	}); err != nil {
+		return err
+	}
+
+	// activities: riwayat per board & per task, terbaru dulu (cursor _id)
+	activities := MongoDB.Collection("activities")
+	if _, err = activities.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "boardId", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("ix_board_id")},
+		{Keys: bson.D{{Key: "taskId", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetName("ix_task_id")},
 	}); err != nil {
 		return err
 	}
@@ -3,16 +3,87 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type AppConfig struct {
-	Port           string
-	MongoURI       string
-	DBName         string
-	JWTSecret      string
+	Port      string
+	MongoURI  string
+	DBName    string
+	JWTSecret string
+	// JWTAlg: HS256 (JWT_SECRET) atau RS256/EdDSA (key dari file PEM)
+	JWTAlg            string
+	JWTSigningKeyFile string
+	// JWTVerifyKeyFiles: public key lama yang masih diterima (pisah koma)
+	JWTVerifyKeyFiles string
+	// DevMode: izinkan default yang tidak aman (mis. JWT_SECRET=devsecret)
+	DevMode        bool
 	EnableRegister bool
+	// Storage: "mongo" (default) atau "memory" (dev tanpa database)
+	Storage string
+	// TaskOrdering: "index" (order 1..N, default) atau "rank" (rank leksikografis)
+	TaskOrdering string
+	// InviteTTL: masa berlaku undangan board (INVITE_TTL_HOURS, default 7 hari)
+	InviteTTL time.Duration
+	// AccessTokenTTL (ACCESS_TOKEN_TTL_MINUTES, default 15) & RefreshTokenTTL
+	// (REFRESH_TOKEN_TTL_HOURS, default 30 hari, diperpanjang tiap refresh)
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// OIDC (SSO); aktif bila OIDC_ISSUER diisi
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string   // URL callback API ini: .../api/auth/oidc/callback
+	OIDCScopes       []string // default: openid email profile
+	OIDCAutoCreate   bool     // buat user baru untuk email yang belum terdaftar
+	// OIDCAllowedRedirects: URL frontend yang boleh dipakai ?redirect= (pisah koma)
+	OIDCAllowedRedirects []string
+	// Mailer: "log" (default, tulis ke MAIL_LOG_FILE / log server) atau "smtp"
+	Mailer       string
+	MailFrom     string
+	MailLogFile  string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// AppURL: URL frontend untuk link di email (/reset-password, /verify-email,
+	// /invitations/accept)
+	AppURL string
+	// RequireEmailVerification: login ditolak sampai email dikonfirmasi
+	RequireEmailVerification bool
+	// ResetTokenTTL (RESET_TOKEN_TTL_MINUTES, default 60) & VerifyTokenTTL
+	// (VERIFY_TOKEN_TTL_HOURS, default 48)
+	ResetTokenTTL  time.Duration
+	VerifyTokenTTL time.Duration
+	// Login brute-force: akun dikunci setelah LOGIN_MAX_FAILURES (default 10),
+	// IP setelah LOGIN_IP_MAX_FAILURES (default 50), selama LOGIN_LOCKOUT_MINUTES (15)
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginLockout       time.Duration
+	// TrashRetention: task/note/board di trash dihapus permanen setelah
+	// TRASH_RETENTION_DAYS (default 30)
+	TrashRetention time.Duration
+	// ProxyHeader: header IP klien asli di belakang reverse proxy (mis. X-Forwarded-For)
+	ProxyHeader string
+	// MFAIssuer: nama akun di aplikasi authenticator (MFA_ISSUER)
+	MFAIssuer string
+	// AdminEmails: user terverifikasi dengan email ini dijadikan admin saat
+	// startup (ADMIN_EMAILS, pisah koma)
+	AdminEmails []string
+	// SocketAllowedOrigins: origin browser yang boleh membuka socket.io
+	// (SOCKET_ALLOWED_ORIGINS, pisah koma). Same-origin selalu boleh; kosong +
+	// DEV_MODE = semua origin.
+	SocketAllowedOrigins []string
+	// RealtimeBackplane: "memory" (satu instance) atau "mongo" (change stream,
+	// wajib bila API dijalankan lebih dari satu replica)
+	RealtimeBackplane string
+	// DevSecondPort: DEV_MODE saja; instance kedua di proses yang sama (storage
+	// & backplane dipakai bersama) untuk mencoba realtime lintas instance
+	DevSecondPort string
 }
 
 var Cfg AppConfig
@@ -20,13 +91,68 @@ var Cfg AppConfig
 func Load() {
 	_ = godotenv.Load()
 	Cfg = AppConfig{
-		Port:           getEnv("PORT", "8080"),
-		MongoURI:       getEnv("MONGO_URI", "mongodb://localhost:27017"),
-		DBName:         getEnv("MONGO_DB", "task_manager"),
-		JWTSecret:      getEnv("JWT_SECRET", "devsecret"),
-		EnableRegister: getEnv("ENABLE_REGISTER", "false") == "true",
+		Port:                     getEnv("PORT", "8080"),
+		MongoURI:                 getEnv("MONGO_URI", "mongodb://localhost:27017"),
+		DBName:                   getEnv("MONGO_DB", "task_manager"),
+		JWTSecret:                getEnv("JWT_SECRET", "devsecret"),
+		JWTAlg:                   getEnv("JWT_ALG", "HS256"),
+		JWTSigningKeyFile:        os.Getenv("JWT_SIGNING_KEY_FILE"),
+		JWTVerifyKeyFiles:        os.Getenv("JWT_VERIFY_KEY_FILES"),
+		DevMode:                  getEnv("DEV_MODE", "false") == "true",
+		EnableRegister:           getEnv("ENABLE_REGISTER", "false") == "true",
+		Storage:                  getEnv("STORAGE", "mongo"),
+		TaskOrdering:             getEnv("TASK_ORDERING", "index"),
+		InviteTTL:                time.Duration(getEnvInt("INVITE_TTL_HOURS", 168)) * time.Hour,
+		AccessTokenTTL:           time.Duration(getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL:          time.Duration(getEnvInt("REFRESH_TOKEN_TTL_HOURS", 720)) * time.Hour,
+		OIDCIssuer:               os.Getenv("OIDC_ISSUER"),
+		OIDCClientID:             os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:         os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:          os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCScopes:               getEnvList("OIDC_SCOPES", " "),
+		OIDCAutoCreate:           getEnv("OIDC_AUTO_CREATE", "false") == "true",
+		OIDCAllowedRedirects:     getEnvList("OIDC_ALLOWED_REDIRECTS", ","),
+		Mailer:                   getEnv("MAILER", "log"),
+		MailFrom:                 getEnv("MAIL_FROM", "no-reply@localhost"),
+		MailLogFile:              os.Getenv("MAIL_LOG_FILE"),
+		SMTPHost:                 os.Getenv("SMTP_HOST"),
+		SMTPPort:                 getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:             os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:             os.Getenv("SMTP_PASSWORD"),
+		AppURL:                   strings.TrimRight(getEnv("APP_URL", "http://localhost:3000"), "/"),
+		RequireEmailVerification: getEnv("REQUIRE_EMAIL_VERIFICATION", "false") == "true",
+		ResetTokenTTL:            time.Duration(getEnvInt("RESET_TOKEN_TTL_MINUTES", 60)) * time.Minute,
+		VerifyTokenTTL:           time.Duration(getEnvInt("VERIFY_TOKEN_TTL_HOURS", 48)) * time.Hour,
+		LoginMaxFailures:         getEnvInt("LOGIN_MAX_FAILURES", 10),
+		LoginIPMaxFailures:       getEnvInt("LOGIN_IP_MAX_FAILURES", 50),
+		LoginLockout:             time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		TrashRetention:           time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		ProxyHeader:              os.Getenv("PROXY_HEADER"),
+		MFAIssuer:                getEnv("MFA_ISSUER", "Be-Ambis-Solving"),
+		AdminEmails:              getEnvList("ADMIN_EMAILS", ","),
+		SocketAllowedOrigins:     getEnvList("SOCKET_ALLOWED_ORIGINS", ","),
+		RealtimeBackplane:        getEnv("REALTIME_BACKPLANE", "memory"),
+		DevSecondPort:            os.Getenv("DEV_SECOND_PORT"),
 	}
-	log.Printf("[config] loaded. DB=%s Port=%s", Cfg.DBName, Cfg.Port)
+	log.Printf("[config] loaded. DB=%s Port=%s Storage=%s", Cfg.DBName, Cfg.Port, Cfg.Storage)
+}
+
+// getEnvList: nilai env dipisah sep, item kosong dibuang
+func getEnvList(key, sep string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getEnvInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
 }
 
 func getEnv(key, def string) string {
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccessTokenHandler struct {
+	Svc services.AccessTokenService
+}
+
+func NewAccessTokenHandler(s services.AccessTokenService) *AccessTokenHandler {
+	return &AccessTokenHandler{Svc: s}
+}
+
+// maxTokenDays: batas expiresInDays
+const maxTokenDays = 365
+
+type tokenCreateReq struct {
+	Name   string              `json:"name"`
+	Scopes []models.TokenScope `json:"scopes"`
+	// ExpiresInDays: 1-365; kosong = tidak kedaluwarsa
+	ExpiresInDays *int `json:"expiresInDays"`
+}
+
+// POST /me/tokens
+func (h *AccessTokenHandler) Create(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req tokenCreateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	var expires *time.Time
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays < 1 || *req.ExpiresInDays > maxTokenDays {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "expiresInDays must be 1-365"})
+		}
+		t := time.Now().UTC().AddDate(0, 0, *req.ExpiresInDays)
+		expires = &t
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	t, plain, err := h.Svc.Create(ctx, uid, req.Name, req.Scopes, expires)
+	switch {
+	case errors.Is(err, services.ErrInvalidTokenName), errors.Is(err, services.ErrInvalidTokenScope),
+		errors.Is(err, services.ErrTokenExpiry):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyTokens):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	// token plain hanya ditampilkan sekali ini
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"token": plain, "accessToken": t})
+}
+
+// GET /me/tokens
+func (h *AccessTokenHandler) List(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	list, err := h.Svc.List(ctx, uid)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []models.AccessToken{}
+	}
+	return c.JSON(list)
+}
+
+// DELETE /me/tokens/:id
+func (h *AccessTokenHandler) Revoke(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Svc.Revoke(ctx, uid, id); err != nil {
+		if errors.Is(err, services.ErrAccessTokenUnknown) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
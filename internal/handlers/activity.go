@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type activityLister func(ctx context.Context, id primitive.ObjectID, cursor *primitive.ObjectID, limit int) (*services.ActivityPage, error)
+
+// listActivity: ?cursor= (nextCursor halaman sebelumnya) & ?limit= (default 50, maks 200)
+func listActivity(c *fiber.Ctx, param string, list activityLister) error {
+	id, err := utils.MustObjectID(c.Params(param))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var cursor *primitive.ObjectID
+	if v := c.Query("cursor"); v != "" {
+		cur, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid cursor"})
+		}
+		cursor = &cur
+	}
+	limit := services.ActivityDefaultLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > services.ActivityMaxLimit {
+			return c.Status(400).JSON(fiber.Map{"error": "limit must be 1-" + strconv.Itoa(services.ActivityMaxLimit)})
+		}
+		limit = n
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	page, err := list(ctx, id, cursor, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(page)
+}
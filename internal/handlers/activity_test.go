@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestListActivityQuery(t *testing.T) {
+	var gotCursor *primitive.ObjectID
+	var gotLimit int
+	app := fiber.New()
+	app.Get("/x/:id/activity", func(c *fiber.Ctx) error {
+		return listActivity(c, "id", func(_ context.Context, _ primitive.ObjectID, cursor *primitive.ObjectID, limit int) (*services.ActivityPage, error) {
+			gotCursor, gotLimit = cursor, limit
+			return &services.ActivityPage{NextCursor: "next"}, nil
+		})
+	})
+	id, cur := primitive.NewObjectID().Hex(), primitive.NewObjectID()
+
+	for path, want := range map[string]int{
+		"/x/nope/activity":                  400,
+		"/x/" + id + "/activity?cursor=zz":  400,
+		"/x/" + id + "/activity?limit=0":    400,
+		"/x/" + id + "/activity?limit=201":  400,
+		"/x/" + id + "/activity?limit=many": 400,
+		"/x/" + id + "/activity?limit=200":  200,
+	} {
+		if res := call(t, app, "GET", path, ""); res.status != want {
+			t.Errorf("GET %s = %d, want %d", path, res.status, want)
+		}
+	}
+
+	res := call(t, app, "GET", "/x/"+id+"/activity?cursor="+cur.Hex(), "")
+	if res.status != 200 || res.body["nextCursor"] != "next" {
+		t.Fatalf("GET = %d %v", res.status, res.body)
+	}
+	if gotCursor == nil || *gotCursor != cur || gotLimit != services.ActivityDefaultLimit {
+		t.Errorf("lister got cursor %v, limit %d", gotCursor, gotLimit)
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler: /api/admin/*, semua route sudah dijaga RequireRole(admin)
+type AdminHandler struct {
+	Admin  services.AdminService
+	Boards services.BoardService
+	Tasks  services.TaskService
+	Notes  services.NoteService
+	Guard  services.LoginGuard
+}
+
+func NewAdminHandler(a services.AdminService, b services.BoardService, t services.TaskService, n services.NoteService, g services.LoginGuard) *AdminHandler {
+	return &AdminHandler{Admin: a, Boards: b, Tasks: t, Notes: n, Guard: g}
+}
+
+const (
+	adminDefaultLimit = 50
+	adminMaxLimit     = 200
+)
+
+func adminStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrInvalidUserRole), errors.Is(err, services.ErrAdminSelf):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrInactiveOwner), errors.Is(err, services.ErrAlreadyOwner),
+		errors.Is(err, services.ErrUnverifiedAdmin):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+// adminUser: seperti /me, ditambah status yang hanya relevan untuk admin
+func adminUser(u *models.User) fiber.Map {
+	return fiber.Map{
+		"id":                    u.ID.Hex(),
+		"email":                 u.Email,
+		"name":                  u.Name,
+		"avatarUrl":             u.AvatarURL,
+		"role":                  u.Role,
+		"isActive":              u.IsActive,
+		"emailVerifiedAt":       u.EmailVerifiedAt,
+		"mfaEnabled":            u.MFAEnabled(),
+		"passwordResetRequired": u.PasswordResetRequired,
+		"createdAt":             u.CreatedAt,
+		"updatedAt":             u.UpdatedAt,
+	}
+}
+
+// pageParams: ?offset= & ?limit= (default 50, maks 200)
+func pageParams(c *fiber.Ctx) (int, int, error) {
+	offset, limit := 0, adminDefaultLimit
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("invalid offset")
+		}
+		offset = n
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > adminMaxLimit {
+			return 0, 0, errors.New("limit must be 1-" + strconv.Itoa(adminMaxLimit))
+		}
+		limit = n
+	}
+	return offset, limit, nil
+}
+
+// boolQuery: "" = nil (tidak difilter)
+func boolQuery(c *fiber.Ctx, key string) (*bool, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, errors.New("invalid " + key)
+	}
+	return &b, nil
+}
+
+// GET /admin/stats
+func (h *AdminHandler) Stats(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	st, err := h.Admin.Stats(ctx)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(st)
+}
+
+// GET /admin/users?q=&role=&active=&offset=&limit=
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	offset, limit, err := pageParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	active, err := boolQuery(c, "active")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	f := repository.UserFilter{Query: c.Query("q"), Role: models.UserRole(c.Query("role")), Active: active}
+	if f.Role != "" && !models.ValidUserRole(f.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": services.ErrInvalidUserRole.Error()})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	list, total, err := h.Admin.ListUsers(ctx, f, offset, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	items := make([]fiber.Map, 0, len(list))
+	for i := range list {
+		items = append(items, adminUser(&list[i]))
+	}
+	return c.JSON(fiber.Map{"items": items, "total": total, "offset": offset, "limit": limit})
+}
+
+// GET /admin/users/:id
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Admin.GetUser(ctx, id)
+	if err != nil {
+		return c.Status(adminStatus(err)).JSON(fiber.Map{"error": "user not found"})
+	}
+	return c.JSON(adminUser(u))
+}
+
+type adminRoleReq struct {
+	Role models.UserRole `json:"role"`
+}
+
+// PUT /admin/users/:id/role
+func (h *AdminHandler) SetRole(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	target, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req adminRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Admin.SetRole(ctx, uid, target, req.Role)
+	if err != nil {
+		return c.Status(adminStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(adminUser(u))
+}
+
+// POST /admin/users/:id/deactivate
+func (h *AdminHandler) Deactivate(c *fiber.Ctx) error { return h.setActive(c, false) }
+
+// POST /admin/users/:id/reactivate
+func (h *AdminHandler) Reactivate(c *fiber.Ctx) error { return h.setActive(c, true) }
+
+func (h *AdminHandler) setActive(c *fiber.Ctx, active bool) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	target, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Admin.SetActive(ctx, uid, target, active)
+	if err != nil {
+		return c.Status(adminStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(adminUser(u))
+}
+
+// POST /admin/users/:id/force-reset
+func (h *AdminHandler) ForcePasswordReset(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	target, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Admin.ForcePasswordReset(ctx, uid, target); err != nil {
+		return c.Status(adminStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /admin/users/:id/unlock
+func (h *AdminHandler) Unlock(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	target, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Guard.Unlock(ctx, target, uid); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /admin/boards?q=&ownerId=&archived=&offset=&limit=
+func (h *AdminHandler) ListBoards(c *fiber.Ctx) error {
+	offset, limit, err := pageParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	archived, err := boolQuery(c, "archived")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	f := repository.BoardFilter{Query: c.Query("q"), Archived: archived}
+	if v := c.Query("ownerId"); v != "" {
+		owner, err := utils.MustObjectID(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid ownerId"})
+		}
+		f.OwnerID = &owner
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	list, total, err := h.Admin.ListBoards(ctx, f, offset, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []models.Board{}
+	}
+	return c.JSON(fiber.Map{"items": list, "total": total, "offset": offset, "limit": limit})
+}
+
+// GET /admin/boards/:id → board beserta semua task & note-nya (baca saja)
+func (h *AdminHandler) GetBoard(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	b, err := h.Boards.Get(ctx, id)
+	if err != nil {
+		return c.Status(adminStatus(err)).JSON(fiber.Map{"error": "board not found"})
+	}
+	tasks, err := h.Tasks.ListByBoard(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	notes, err := h.Notes.ListByBoard(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	if notes == nil {
+		notes = []models.Note{}
+	}
+	return c.JSON(fiber.Map{"board": b, "tasks": tasks, "notes": notes})
+}
+
+type transferReq struct {
+	OwnerID string `json:"ownerId"`
+}
+
+// POST /admin/boards/:id/transfer
+func (h *AdminHandler) TransferBoard(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req transferReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	owner, err := utils.MustObjectID(req.OwnerID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid ownerId"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	b, err := h.Admin.TransferBoard(ctx, uid, id, owner)
+	if err != nil {
+		return c.Status(adminStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(b)
+}
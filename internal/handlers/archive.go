@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// boardArchived: 409 dengan code tetap supaya klien bisa membedakannya dari
+// konflik version/urutan dan menampilkan board sebagai read-only
+func boardArchived(c *fiber.Ctx) error {
+	return c.Status(409).JSON(fiber.Map{"error": services.ErrBoardArchived.Error(), "code": "board_archived"})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArchivedBoardRejectsTaskWrites(t *testing.T) {
+	f := newTaskFixture(t)
+	if _, err := f.boards.SetArchived(context.Background(), f.board.ID, primitive.NilObjectID, true); err != nil {
+		t.Fatal(err)
+	}
+	path := "/tasks/" + f.tasks["a"].ID.Hex()
+
+	for _, req := range []struct{ method, path, body string }{
+		{"PATCH", path, `{"title":"x"}`},
+		{"POST", path + "/move", `{"toColumnId":"doing","toPosition":1}`},
+	} {
+		res := call(t, f.app, req.method, req.path, req.body)
+		if res.status != 409 || res.body["code"] != "board_archived" {
+			t.Errorf("%s %s = %d %v", req.method, req.path, res.status, res.body)
+		}
+	}
+	if len(f.events.sent) != 0 {
+		t.Errorf("events for rejected writes: %v", f.events.types())
+	}
+	if res := call(t, f.app, "GET", path, ""); res.status != 200 || res.body["title"] != "a" {
+		t.Errorf("GET = %d %v", res.status, res.body)
+	}
+}
+
+func TestArchiveAnnouncesOnlyChanges(t *testing.T) {
+	f := newBoardFixture(t)
+	path := "/boards/" + f.board.ID.Hex()
+	var changes []string
+	for _, step := range []struct {
+		action   string
+		archived bool
+	}{{"archive", true}, {"archive", true}, {"unarchive", false}} {
+		res := call(t, f.app, "POST", path+"/"+step.action, "")
+		if res.status != 200 || res.body["isArchived"] != step.archived || res.header["Etag"] == "" {
+			t.Fatalf("%s = %d %v", step.action, res.status, res.body)
+		}
+		for _, evt := range f.events.sent {
+			changes = append(changes, string(evt.Data.(realtime.BoardUpdate).Change))
+		}
+		f.events.sent = nil
+	}
+	// archive kedua tidak mengubah apa-apa → tidak ada event
+	if want := []string{"archived", "unarchived"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %v, want %v", changes, want)
+	}
+	if res := call(t, f.app, "POST", "/boards/nope/archive", ""); res.status != 400 {
+		t.Errorf("invalid id = %d", res.status)
+	}
+}
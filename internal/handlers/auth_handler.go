@@ -2,20 +2,31 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
 type AuthHandler struct {
-	Auth  services.AuthService
-	Users services.UserService
+	Auth        services.AuthService
+	Users       services.UserService
+	Invitations services.InvitationService
+	Sessions    services.SessionService
+	Accounts    services.AccountService
 }
 
-func NewAuthHandler(a services.AuthService, u services.UserService) *AuthHandler {
-	return &AuthHandler{Auth: a, Users: u}
+func NewAuthHandler(a services.AuthService, u services.UserService, inv services.InvitationService, ss services.SessionService, acc services.AccountService) *AuthHandler {
+	return &AuthHandler{Auth: a, Users: u, Invitations: inv, Sessions: ss, Accounts: acc}
+}
+
+func sessionMeta(c *fiber.Ctx) services.SessionMeta {
+	return services.SessionMeta{UserAgent: c.Get(fiber.HeaderUserAgent), IP: c.IP()}
 }
 
 type loginReq struct {
@@ -30,17 +41,132 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	token, uid, err := h.Auth.Login(ctx, req.Email, req.Password)
+	res, err := h.Auth.Login(ctx, req.Email, req.Password, sessionMeta(c))
+	var locked *services.LoginLockedError
+	if errors.As(err, &locked) {
+		return loginLocked(c, locked)
+	}
+	if errors.Is(err, services.ErrAccountDisabled) || errors.Is(err, services.ErrEmailNotVerified) ||
+		errors.Is(err, services.ErrPasswordResetRequired) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid email or password"})
 	}
-	return c.JSON(fiber.Map{"token": token, "userId": uid})
+	return c.JSON(loginResponse(res))
+}
+
+// loginLocked: 429 beserta Retry-After (detik)
+func loginLocked(c *fiber.Ctx, locked *services.LoginLockedError) error {
+	secs := int64(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(secs, 10))
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": locked.Error(), "retryAfter": secs})
+}
+
+// loginResponse: pasangan token, atau {mfaRequired, mfaToken} bila user memakai 2FA
+func loginResponse(res *services.LoginResult) interface{} {
+	if res.MFAToken != "" {
+		return fiber.Map{"mfaRequired": true, "mfaToken": res.MFAToken, "mfaExpiresIn": res.MFAExpiresIn}
+	}
+	return res.Tokens
+}
+
+type refreshReq struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// POST /auth/refresh: tukar refresh token dengan pasangan token baru
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req refreshReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	pair, err := h.Sessions.Refresh(ctx, req.RefreshToken, sessionMeta(c))
+	if errors.Is(err, services.ErrInvalidRefresh) || errors.Is(err, services.ErrRefreshReused) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(pair)
+}
+
+// POST /auth/logout: cabut session token ini (?all=true → semua session user)
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if c.QueryBool("all") {
+		err = h.Sessions.RevokeAll(ctx, uid)
+	} else {
+		err = h.Sessions.Revoke(ctx, uid, utils.SessionIDFromCtx(c))
+	}
+	if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /me/sessions
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	list, err := h.Sessions.ListActive(ctx, uid)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	current := utils.SessionIDFromCtx(c)
+	out := make([]fiber.Map, 0, len(list))
+	for _, s := range list {
+		out = append(out, fiber.Map{
+			"id":         s.ID,
+			"userAgent":  s.UserAgent,
+			"ip":         s.IP,
+			"createdAt":  s.CreatedAt,
+			"lastUsedAt": s.LastUsedAt,
+			"expiresAt":  s.ExpiresAt,
+			"current":    s.ID == current,
+		})
+	}
+	return c.JSON(out)
+}
+
+// DELETE /me/sessions/:id
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Sessions.Revoke(ctx, uid, id); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
 }
 
 type registerReq struct {
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// InviteToken (opsional): token dari email undangan board, langsung diterima
+	InviteToken string `json:"inviteToken"`
 }
 
 // (Dev convenience) enable only if ENABLE_REGISTER=true
@@ -58,9 +184,110 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"id":    u.ID.Hex(),
-		"email": u.Email,
-		"name":  u.Name,
-	})
+	resp := fiber.Map{
+		"id":            u.ID.Hex(),
+		"email":         u.Email,
+		"name":          u.Name,
+		"emailVerified": false,
+	}
+	if err := h.Accounts.SendVerification(ctx, u); err != nil {
+		resp["verificationError"] = err.Error()
+	}
+	// akun sudah jadi; gagal klaim undangan tidak membatalkan registrasi
+	if req.InviteToken != "" {
+		if inv, err := h.Invitations.AcceptToken(ctx, req.InviteToken, u.ID); err != nil {
+			resp["invitationError"] = err.Error()
+		} else {
+			resp["joinedBoardId"] = inv.BoardID.Hex()
+		}
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+type emailReq struct {
+	Email string `json:"email"`
+}
+
+// POST /auth/forgot: kirim link reset; respons sama untuk email terdaftar/tidak
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req emailReq
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email is required"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Accounts.ForgotPassword(ctx, req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+type resetReq struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// POST /auth/reset: password baru dari token di email; semua session dicabut
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req resetReq
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token is required"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	err := h.Accounts.ResetPassword(ctx, req.Token, req.Password)
+	if errors.Is(err, services.ErrInvalidEmailToken) || errors.Is(err, services.ErrWeakPassword) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type verifyReq struct {
+	Token string `json:"token"`
+}
+
+// POST /auth/verify: konfirmasi email dari token di email
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req verifyReq
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "token is required"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	err := h.Accounts.VerifyEmail(ctx, req.Token)
+	if errors.Is(err, services.ErrInvalidEmailToken) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /auth/verify/resend: kirim ulang link verifikasi (public, karena user
+// yang belum terverifikasi tidak bisa login)
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	var req emailReq
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "email is required"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Accounts.ResendVerification(ctx, req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// GET /.well-known/jwks.json
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	set, err := utils.JWKS()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(set)
 }
@@ -4,34 +4,112 @@ package handlers
 
 import (
 	"context"
-	"log" // Impor log untuk debugging
+	"errors"
+	"strconv"
 	"time"
 
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"github.com/gofiber/fiber/v2"
-	socketio "github.com/googollee/go-socket.io"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// 1. Tambahkan SocketServer ke dalam struct
 type BoardHandler struct {
-	Svc          services.BoardService
-	SocketServer *socketio.Server
+	Svc      services.BoardService
+	Events   realtime.Publisher
+	History  services.BoardEventService // log replay event realtime
+	Presence services.PresenceService
+	Activity services.ActivityService
+	Trash    services.TrashService
 }
 
-// 2. Modifikasi constructor untuk menerima SocketServer
-func NewBoardHandler(s services.BoardService, so *socketio.Server) *BoardHandler {
-	return &BoardHandler{Svc: s, SocketServer: so}
+func NewBoardHandler(s services.BoardService, events realtime.Publisher, history services.BoardEventService, presence services.PresenceService, activity services.ActivityService, trash services.TrashService) *BoardHandler {
+	return &BoardHandler{Svc: s, Events: events, History: history, Presence: presence, Activity: activity, Trash: trash}
+}
+
+// publishBoard mengirim board_updated ke owner & member b (plus extra, mis.
+// user yang baru dikeluarkan)
+func publishBoard(events realtime.Publisher, c *fiber.Ctx, b *models.Board, extra []primitive.ObjectID, changes ...realtime.BoardChange) {
+	actor, _ := utils.UserIDFromCtx(c)
+	for _, ch := range changes {
+		events.Publish(realtime.NewBoardUpdated(b, ch, actor, extra...))
+	}
 }
 
-// (Tipe boardCreateReq tidak berubah)
 type boardCreateReq struct {
 	Name        string               `json:"name"`
 	Description *string              `json:"description"`
 	Columns     []models.BoardColumn `json:"columns"`
-	Members     []string             `json:"members"`
+	Members     []string             `json:"members"`     // member lama → editor
+	MemberRoles []memberReq          `json:"memberRoles"` // {userId, role}
+}
+
+type memberReq struct {
+	UserID string           `json:"userId"`
+	Role   models.BoardRole `json:"role"`
+}
+
+// parseMembers menggabungkan members (editor) dan memberRoles; memberRoles menang
+func parseMembers(legacy []string, roles []memberReq) ([]models.BoardMember, error) {
+	var out []models.BoardMember
+	for _, m := range legacy {
+		if oid, err := primitive.ObjectIDFromHex(m); err == nil {
+			out = append(out, models.BoardMember{UserID: oid, Role: models.BoardRoleEditor})
+		}
+	}
+	for _, m := range roles {
+		oid, err := primitive.ObjectIDFromHex(m.UserID)
+		if err != nil {
+			return nil, errors.New("invalid member userId")
+		}
+		out = append(out, models.BoardMember{UserID: oid, Role: m.Role})
+	}
+	return out, nil
+}
+
+// isOwner: role pemanggil dari guard BoardAccessBy*
+func isOwner(c *fiber.Ctx) bool {
+	role, _ := c.Locals("boardRole").(models.BoardRole)
+	return role == models.BoardRoleOwner
+}
+
+// removedMembers: user yang kehilangan akses bila member board diganti after
+func removedMembers(b *models.Board, after []models.BoardMember) []primitive.ObjectID {
+	keep := map[primitive.ObjectID]bool{b.OwnerID: true}
+	for _, m := range after {
+		keep[m.UserID] = true
+	}
+	var out []primitive.ObjectID
+	for _, id := range b.Members {
+		if !keep[id] {
+			keep[id] = true
+			out = append(out, id)
+		}
+	}
+	for _, m := range b.MemberRoles {
+		if !keep[m.UserID] {
+			keep[m.UserID] = true
+			out = append(out, m.UserID)
+		}
+	}
+	return out
+}
+
+// boardWriteError: status untuk error Update/SetMember/RemoveMember
+func boardWriteError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, services.ErrNotAMember):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrAdminOwnerOnly):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrBoardBusy):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 }
 
 func (h *BoardHandler) Create(c *fiber.Ctx) error {
@@ -44,36 +122,30 @@ func (h *BoardHandler) Create(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
 	}
-	var memberOIDs []primitive.ObjectID
-	for _, m := range req.Members {
-		if oid, err := primitive.ObjectIDFromHex(m); err == nil {
-			memberOIDs = append(memberOIDs, oid)
-		}
+	members, err := parseMembers(req.Members, req.MemberRoles)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	b, err := h.Svc.Create(ctx, uid, req.Name, req.Description, req.Columns, memberOIDs)
+	b, err := h.Svc.Create(ctx, uid, req.Name, req.Description, req.Columns, members)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// 3. Broadcast event setelah berhasil
-	h.SocketServer.BroadcastToNamespace("/", "board_updated", nil)
-	log.Println("Broadcast [board_updated] setelah Create Board")
-
+	publishBoard(h.Events, c, b, nil, realtime.BoardCreated)
+	setETag(c, b.Version)
 	return c.Status(201).JSON(b)
 }
 
-// (Handler List dan Get tidak perlu broadcast karena tidak mengubah data)
 func (h *BoardHandler) List(c *fiber.Ctx) error {
-	// ... kode tidak berubah ...
 	uid, err := utils.UserIDFromCtx(c)
 	if err != nil {
 		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
 	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	out, err := h.Svc.ListForUser(ctx, uid)
+	out, err := h.Svc.ListForUser(ctx, uid, c.QueryBool("includeArchived"))
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -81,7 +153,6 @@ func (h *BoardHandler) List(c *fiber.Ctx) error {
 }
 
 func (h *BoardHandler) Get(c *fiber.Ctx) error {
-	// ... kode tidak berubah ...
 	id, err := utils.MustObjectID(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
@@ -92,15 +163,60 @@ func (h *BoardHandler) Get(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "not found"})
 	}
+	setETag(c, b.Version)
+	if notModified(c, b.Version) {
+		return c.SendStatus(304)
+	}
 	return c.JSON(b)
 }
 
-// (Tipe boardUpdateReq tidak berubah)
+// EventsSince: GET /boards/:id/events?since=<seq>, event yang terlewat klien
+// setelah putus koneksi. resync=true bila log sudah tidak lengkap.
+func (h *BoardHandler) EventsSince(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	since, err := strconv.ParseInt(c.Query("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "since must be a non-negative integer"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	r, err := h.History.Since(ctx, id, since)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(realtime.NewReplay(r))
+}
+
+// ListActivity: GET /boards/:id/activity, riwayat board beserta task & note-nya
+func (h *BoardHandler) ListActivity(c *fiber.Ctx) error {
+	return listActivity(c, "id", h.Activity.ListByBoard)
+}
+
+// ListPresence: GET /boards/:id/presence, state awal sebelum presence_update
+func (h *BoardHandler) ListPresence(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	out, err := h.Presence.List(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(out)
+}
+
 type boardUpdateReq struct {
 	Name        *string               `json:"name"`
 	Description *string               `json:"description"`
 	Columns     *[]models.BoardColumn `json:"columns"`
 	Members     *[]string             `json:"members"`
+	MemberRoles *[]memberReq          `json:"memberRoles"`
+	RequireMFA  *bool                 `json:"requireMfa"` // owner saja
 }
 
 func (h *BoardHandler) Update(c *fiber.Ctx) error {
@@ -112,27 +228,154 @@ func (h *BoardHandler) Update(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
 	}
+	expect, err := ifMatch(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
 
-	var memberOIDs *[]primitive.ObjectID
-	if req.Members != nil {
-		tmp := make([]primitive.ObjectID, 0, len(*req.Members))
-		for _, m := range *req.Members {
-			if oid, err := primitive.ObjectIDFromHex(m); err == nil {
-				tmp = append(tmp, oid)
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+
+	// ubah member butuh board:members (guard route hanya board:update)
+	var members *[]models.BoardMember
+	if req.Members != nil || req.MemberRoles != nil {
+		if ok, err := authz.Can(ctx, uid, id, authz.BoardMembers); err != nil || !ok {
+			return c.Status(403).JSON(fiber.Map{"error": "forbidden", "required": authz.BoardMembers})
+		}
+		var legacy []string
+		var roles []memberReq
+		if req.Members != nil {
+			legacy = *req.Members
+		}
+		if req.MemberRoles != nil {
+			roles = *req.MemberRoles
+		}
+		tmp, err := parseMembers(legacy, roles)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		members = &tmp
+	}
+	if req.RequireMFA != nil {
+		if !isOwner(c) {
+			return c.Status(403).JSON(fiber.Map{"error": "only the owner can change the 2FA requirement"})
+		}
+		// owner sendiri harus sudah 2FA supaya tidak terkunci dari board-nya
+		if *req.RequireMFA {
+			has, err := authz.HasMFA(ctx, uid)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			}
+			if !has {
+				return c.Status(409).JSON(fiber.Map{"error": "enable two-factor authentication on your account first"})
 			}
 		}
-		memberOIDs = &tmp
+	}
+	patch := services.BoardPatch{
+		Name:        req.Name,
+		Description: req.Description,
+		Columns:     req.Columns,
+		Members:     members,
+		RequireMFA:  req.RequireMFA,
+	}
+	before, err := h.Svc.Update(ctx, id, uid, patch, expect)
+	if err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			if b, gerr := h.Svc.Get(ctx, id); gerr == nil {
+				return versionConflict(c, b, b.Version)
+			}
+		}
+		return boardWriteError(c, err)
+	}
+
+	// member yang dikeluarkan tidak boleh lagi menerima event board ini
+	var removed []primitive.ObjectID
+	if members != nil {
+		removed = removedMembers(before, *members)
+	}
+	for _, m := range removed {
+		h.Events.Evict(id, m)
+	}
+
+	var changes []realtime.BoardChange
+	if req.Name != nil {
+		changes = append(changes, realtime.BoardRenamed)
+	}
+	if req.Description != nil || req.RequireMFA != nil {
+		changes = append(changes, realtime.BoardSettingsChanged)
+	}
+	if req.Columns != nil {
+		changes = append(changes, realtime.BoardColumnsChanged)
+	}
+	if members != nil {
+		changes = append(changes, realtime.BoardMembersChanged)
+	}
+	if b, err := h.Svc.Get(ctx, id); err == nil {
+		publishBoard(h.Events, c, b, removed, changes...)
+		setETag(c, b.Version)
+	}
+	return c.SendStatus(204)
+}
+
+type memberRoleReq struct {
+	Role models.BoardRole `json:"role"`
+}
+
+// PUT /boards/:id/members/:userId  body: {role}
+func (h *BoardHandler) SetMember(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	userID, err := utils.MustObjectID(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid userId"})
+	}
+	var req memberRoleReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
 	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	if err := h.Svc.Update(ctx, id, req.Name, req.Description, req.Columns, memberOIDs); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	b, err := h.Svc.SetMember(ctx, id, uid, models.BoardMember{UserID: userID, Role: req.Role})
+	if err != nil {
+		return boardWriteError(c, err)
 	}
+	// b sebelum perubahan; userID ikut agar member baru juga menerima event
+	publishBoard(h.Events, c, b, []primitive.ObjectID{userID}, realtime.BoardMembersChanged)
+	return c.SendStatus(204)
+}
 
-	// 3. Broadcast event setelah berhasil
-	h.SocketServer.BroadcastToNamespace("/", "board_updated", nil)
-	log.Println("Broadcast [board_updated] setelah Update Board")
-
+// DELETE /boards/:id/members/:userId
+func (h *BoardHandler) RemoveMember(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	userID, err := utils.MustObjectID(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid userId"})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	b, err := h.Svc.RemoveMember(ctx, id, uid, userID)
+	if err != nil {
+		return boardWriteError(c, err)
+	}
+	h.Events.Evict(id, userID)
+	publishBoard(h.Events, c, b, nil, realtime.BoardMembersChanged)
 	return c.SendStatus(204)
 }
 
@@ -141,18 +384,117 @@ func (h *BoardHandler) Delete(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
 	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	if err := h.Svc.Delete(ctx, id); err != nil {
+	// audience diambil sebelum board hilang
+	b, err := h.Svc.Get(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	}
+	if err := h.Svc.Delete(ctx, id, uid); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	publishBoard(h.Events, c, b, nil, realtime.BoardDeleted)
+	h.Events.CloseBoard(id)
 
-	log.Println("[SOCKET][EMIT] ns=/ event=board_updated payload=nil")
-	h.SocketServer.BroadcastToNamespace("/", "board_updated", nil)
+	return c.SendStatus(204)
+}
 
-	// 3. Broadcast event setelah berhasil
-	h.SocketServer.BroadcastToNamespace("/", "board_updated", nil)
-	log.Println("Broadcast [board_updated] setelah Delete Board")
+// POST /api/boards/:id/archive
+func (h *BoardHandler) Archive(c *fiber.Ctx) error {
+	return h.setArchived(c, true)
+}
 
-	return c.SendStatus(204)
+// POST /api/boards/:id/unarchive
+func (h *BoardHandler) Unarchive(c *fiber.Ctx) error {
+	return h.setArchived(c, false)
+}
+
+func (h *BoardHandler) setArchived(c *fiber.Ctx, archived bool) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	before, err := h.Svc.Get(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	}
+	b, err := h.Svc.SetArchived(ctx, id, uid, archived)
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// sudah dalam keadaan yang diminta → tidak ada event
+	if before.IsArchived != archived {
+		change := realtime.BoardArchived
+		if !archived {
+			change = realtime.BoardUnarchived
+		}
+		publishBoard(h.Events, c, b, nil, change)
+	}
+	setETag(c, b.Version)
+	return c.JSON(b)
+}
+
+// GET /api/boards/trash: board milik user yang ada di trash
+func (h *BoardHandler) ListDeleted(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	out, err := h.Trash.ListBoards(ctx, uid)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(out)
+}
+
+// GET /api/boards/:id/trash: task & note board ini yang ada di trash
+func (h *BoardHandler) ListTrash(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	out, err := h.Trash.ListBoard(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(out)
+}
+
+// POST /api/boards/:id/restore
+func (h *BoardHandler) Restore(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	b, err := h.Svc.Restore(ctx, id, uid)
+	if err != nil {
+		return restoreError(c, err)
+	}
+	publishBoard(h.Events, c, b, nil, realtime.BoardRestored)
+	setETag(c, b.Version)
+	return c.JSON(b)
 }
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type boardFixture struct {
+	app    *fiber.App
+	events *events
+	board  *models.Board
+	owner  primitive.ObjectID
+	editor primitive.ObjectID
+}
+
+// newBoardFixture: board milik owner dengan satu editor; semua request
+// dikirim sebagai owner
+func newBoardFixture(t *testing.T) *boardFixture {
+	t.Helper()
+	repos := repository.NewMemory()
+	authz.Init(repos)
+	f := &boardFixture{events: &events{}, owner: primitive.NewObjectID(), editor: primitive.NewObjectID()}
+	boards := services.NewBoardService(repos.Boards, services.NewActivityService(repos.Activities))
+	b, err := boards.Create(context.Background(), f.owner, "board", nil, nil, []models.BoardMember{{UserID: f.editor, Role: models.BoardRoleEditor}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.board = b
+	h := NewBoardHandler(boards, f.events, nil, nil, nil, nil)
+	f.app = fiber.New()
+	f.app.Use(as(f.owner, models.BoardRoleOwner))
+	f.app.Post("/boards", h.Create)
+	f.app.Patch("/boards/:id", h.Update)
+	f.app.Put("/boards/:id/members/:userId", h.SetMember)
+	f.app.Delete("/boards/:id/members/:userId", h.RemoveMember)
+	f.app.Delete("/boards/:id", h.Delete)
+	f.app.Post("/boards/:id/archive", h.Archive)
+	f.app.Post("/boards/:id/unarchive", h.Unarchive)
+	return f
+}
+
+// updates: board_updated yang terkirim sejak panggilan sebelumnya, sebagai
+// "change:user,user" dengan user diurutkan supaya mudah dibandingkan
+func (f *boardFixture) updates(t *testing.T, names map[primitive.ObjectID]string) []string {
+	t.Helper()
+	f.events.mu.Lock()
+	defer f.events.mu.Unlock()
+	var out []string
+	for _, evt := range f.events.sent {
+		u, ok := evt.Data.(realtime.BoardUpdate)
+		if evt.Type != realtime.EventBoardUpdated || !ok {
+			t.Fatalf("unexpected event %+v", evt)
+		}
+		if u.ActorID != f.owner.Hex() || u.BoardID != evt.BoardID {
+			t.Errorf("payload %+v", u)
+		}
+		var users []string
+		for _, id := range evt.Users {
+			users = append(users, names[id])
+		}
+		sort.Strings(users)
+		out = append(out, fmt.Sprintf("%s:%v", u.Change, users))
+	}
+	f.events.sent = nil
+	return out
+}
+
+func TestBoardUpdatedGoesToTheBoardAudience(t *testing.T) {
+	f := newBoardFixture(t)
+	viewer := primitive.NewObjectID()
+	names := map[primitive.ObjectID]string{f.owner: "owner", f.editor: "editor", viewer: "viewer"}
+	path := "/boards/" + f.board.ID.Hex()
+
+	steps := []struct {
+		name         string
+		method, path string
+		body         string
+		want         []string
+	}{
+		{"add a member", "PUT", path + "/members/" + viewer.Hex(), `{"role":"viewer"}`,
+			[]string{"members_changed:[editor owner viewer]"}},
+		{"rename and columns in one patch", "PATCH", path, `{"name":"renamed","columns":[{"id":"todo","name":"Todo","order":1}]}`,
+			[]string{"renamed:[editor owner viewer]", "columns_changed:[editor owner viewer]"}},
+		// yang dikeluarkan masih diberi tahu sekali
+		{"remove a member", "DELETE", path + "/members/" + viewer.Hex(), "",
+			[]string{"members_changed:[editor owner viewer]"}},
+		{"replace members", "PATCH", path, `{"memberRoles":[]}`,
+			[]string{"members_changed:[editor owner]"}},
+		{"delete", "DELETE", path, "",
+			[]string{"deleted:[owner]"}},
+	}
+	for _, s := range steps {
+		if res := call(t, f.app, s.method, s.path, s.body); res.status >= 300 {
+			t.Fatalf("%s: %d %v", s.name, res.status, res.body)
+		}
+		if got := f.updates(t, names); fmt.Sprint(got) != fmt.Sprint(s.want) {
+			t.Errorf("%s: board_updated %v, want %v", s.name, got, s.want)
+		}
+	}
+	if fmt.Sprint(f.events.evicts) != fmt.Sprint([]primitive.ObjectID{viewer, f.editor}) {
+		t.Errorf("evicted %v, want viewer then editor", f.events.evicts)
+	}
+}
+
+func TestBoardCreatedGoesToNewMembersOnly(t *testing.T) {
+	f := newBoardFixture(t)
+	member := primitive.NewObjectID()
+	names := map[primitive.ObjectID]string{f.owner: "owner", member: "member"}
+	body := fmt.Sprintf(`{"name":"new","memberRoles":[{"userId":%q,"role":"viewer"}]}`, member.Hex())
+	if res := call(t, f.app, "POST", "/boards", body); res.status != 201 {
+		t.Fatalf("create: %d %v", res.status, res.body)
+	}
+	// editor board lain tidak ikut menerima
+	if got := f.updates(t, names); fmt.Sprint(got) != "[created:[member owner]]" {
+		t.Errorf("board_updated %v", got)
+	}
+}
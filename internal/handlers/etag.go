@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag board/task/note = version dokumen, mis. "3"
+
+func etag(version int64) string { return `"` + strconv.FormatInt(version, 10) + `"` }
+
+func setETag(c *fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, etag(version))
+}
+
+var errBadIfMatch = errors.New(`invalid If-Match header, expected a version like "3"`)
+
+// ifMatch membaca version dari header If-Match ("3", W/"3" atau 3).
+// Tanpa header atau "*" → nil (update tanpa cek version).
+func ifMatch(c *fiber.Ctx) (*int64, error) {
+	h := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if h == "" || h == "*" {
+		return nil, nil
+	}
+	h = strings.Trim(strings.TrimPrefix(h, "W/"), `"`)
+	v, err := strconv.ParseInt(h, 10, 64)
+	if err != nil || v < 0 {
+		return nil, errBadIfMatch
+	}
+	return &v, nil
+}
+
+// notModified: GET dengan If-None-Match yang masih cocok → 304 tanpa body
+func notModified(c *fiber.Ctx, version int64) bool {
+	for _, tag := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionConflict: 409 berisi salinan terbaru di server supaya klien bisa
+// menggabungkan perubahannya tanpa GET terpisah
+func versionConflict(c *fiber.Ctx, current interface{}, version int64) error {
+	setETag(c, version)
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": services.ErrVersionConflict.Error(), "current": current})
+}
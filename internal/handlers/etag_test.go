@@ -0,0 +1,71 @@
+package handlers
+
+import "testing"
+
+// Dua klien membaca task yang sama; yang kedua menulis dengan ETag basi
+func TestTaskIfMatch(t *testing.T) {
+	f := newTaskFixture(t)
+	path := "/tasks/" + f.tasks["a"].ID.Hex()
+
+	read := call(t, f.app, "GET", path, "")
+	tag := read.header["Etag"]
+	if read.status != 200 || tag == "" {
+		t.Fatalf("GET = %d, ETag %q", read.status, tag)
+	}
+	if res := call(t, f.app, "GET", path, "", "If-None-Match", tag); res.status != 304 || res.raw != "" {
+		t.Errorf("GET with a fresh If-None-Match = %d %q", res.status, res.raw)
+	}
+
+	first := call(t, f.app, "PATCH", path, `{"title":"mine"}`, "If-Match", tag)
+	if first.status != 204 || first.header["Etag"] == tag || first.header["Etag"] == "" {
+		t.Fatalf("first PATCH = %d, ETag %q", first.status, first.header["Etag"])
+	}
+	stale := call(t, f.app, "PATCH", path, `{"title":"theirs"}`, "If-Match", tag)
+	if stale.status != 409 || stale.header["Etag"] != first.header["Etag"] {
+		t.Fatalf("stale PATCH = %d, ETag %q", stale.status, stale.header["Etag"])
+	}
+	current, _ := stale.body["current"].(map[string]interface{})
+	if current["title"] != "mine" {
+		t.Errorf("409 body carries %v, want the current task", stale.body)
+	}
+	// Move ikut dicek; task tidak berpindah dan tidak ada event
+	sent := len(f.events.sent)
+	if res := call(t, f.app, "POST", path+"/move", `{"toColumnId":"doing","toPosition":1}`, "If-Match", tag); res.status != 409 {
+		t.Errorf("stale move = %d", res.status)
+	}
+	if got := f.column(t, "doing"); len(got) != 0 || len(f.events.sent) != sent {
+		t.Errorf("stale move changed doing = %v, events %v", got, f.events.types()[sent:])
+	}
+
+	// tag lemah dulu (masih versi terbaru), lalu "*" yang selalu cocok
+	for _, header := range []string{"W/" + first.header["Etag"], "*"} {
+		if res := call(t, f.app, "PATCH", path, `{"title":"again"}`, "If-Match", header); res.status != 204 {
+			t.Errorf("If-Match %s: %d %v", header, res.status, res.body)
+		}
+	}
+	for _, bad := range []string{"abc", `"-1"`} {
+		if res := call(t, f.app, "PATCH", path, `{"title":"x"}`, "If-Match", bad); res.status != 400 {
+			t.Errorf("If-Match %s: %d, want 400", bad, res.status)
+		}
+	}
+	// tanpa If-Match tetap diterima (klien lama)
+	if res := call(t, f.app, "PATCH", path, `{"title":"legacy"}`); res.status != 204 {
+		t.Errorf("PATCH without If-Match: %d", res.status)
+	}
+}
+
+func TestBoardIfMatch(t *testing.T) {
+	f := newBoardFixture(t)
+	path := "/boards/" + f.board.ID.Hex()
+	tag := etag(f.board.Version)
+
+	res := call(t, f.app, "PATCH", path, `{"name":"first"}`, "If-Match", tag)
+	if res.status != 204 || res.header["Etag"] == tag {
+		t.Fatalf("PATCH = %d, ETag %q", res.status, res.header["Etag"])
+	}
+	stale := call(t, f.app, "PATCH", path, `{"name":"second"}`, "If-Match", tag)
+	current, _ := stale.body["current"].(map[string]interface{})
+	if stale.status != 409 || current["name"] != "first" || stale.header["Etag"] != res.header["Etag"] {
+		t.Errorf("stale PATCH = %d %v, ETag %q", stale.status, stale.body, stale.header["Etag"])
+	}
+}
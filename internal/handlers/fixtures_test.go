@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Helper bersama untuk test handler: app Fiber kecil yang memasang satu
+// handler di belakang "login" palsu, tanpa JWT dan middleware board.
+
+// events: Publisher palsu yang mencatat event yang dikirim handler
+type events struct {
+	mu     sync.Mutex
+	sent   []realtime.Event
+	evicts []primitive.ObjectID
+}
+
+func (e *events) Publish(evt realtime.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sent = append(e.sent, evt)
+}
+
+func (e *events) Evict(_, userID primitive.ObjectID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.evicts = append(e.evicts, userID)
+}
+
+func (e *events) CloseBoard(primitive.ObjectID) {}
+
+func (e *events) types() []realtime.EventType {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []realtime.EventType
+	for _, evt := range e.sent {
+		out = append(out, evt.Type)
+	}
+	return out
+}
+
+// as: request berikutnya dianggap dari user (dan boardRole bila diisi)
+func as(user primitive.ObjectID, role ...models.BoardRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("userId", user.Hex())
+		if len(role) > 0 {
+			c.Locals("boardRole", role[0])
+		}
+		return c.Next()
+	}
+}
+
+type response struct {
+	status int
+	header map[string]string
+	body   map[string]interface{}
+	raw    string
+}
+
+// call mengirim request ke app; headers berpasangan nama, nilai
+func call(t *testing.T, app *fiber.App, method, path, body string, headers ...string) response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := response{status: res.StatusCode, header: map[string]string{}, raw: string(raw)}
+	for k := range res.Header {
+		out.header[k] = res.Header.Get(k)
+	}
+	if strings.HasPrefix(strings.TrimSpace(out.raw), "{") {
+		if err := json.Unmarshal(raw, &out.body); err != nil {
+			t.Fatalf("body %s: %v", raw, err)
+		}
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type InvitationHandler struct {
+	Svc    services.InvitationService
+	Boards services.BoardService // audience event board_updated
+	Events realtime.Publisher
+}
+
+func NewInvitationHandler(s services.InvitationService, boards services.BoardService, events realtime.Publisher) *InvitationHandler {
+	return &InvitationHandler{Svc: s, Boards: boards, Events: events}
+}
+
+// publishAccepted: member baru bergabung → board_updated ke seluruh member
+func (h *InvitationHandler) publishAccepted(ctx context.Context, c *fiber.Ctx, inv *models.Invitation) {
+	if b, err := h.Boards.Get(ctx, inv.BoardID); err == nil {
+		publishBoard(h.Events, c, b, nil, realtime.BoardMembersChanged)
+	}
+}
+
+// invitationStatus memetakan error service ke status HTTP
+func invitationStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInviteNotFound), errors.Is(err, repository.ErrNotFound):
+		return 404
+	case errors.Is(err, services.ErrInviteNotYours), errors.Is(err, services.ErrEmailNotVerified):
+		return 403
+	case errors.Is(err, services.ErrInvitePending), errors.Is(err, services.ErrAlreadyMember),
+		errors.Is(err, services.ErrInviteNotPending):
+		return 409
+	case errors.Is(err, services.ErrInviteExpired):
+		return 410
+	}
+	return 400
+}
+
+type invitationCreateReq struct {
+	Email string           `json:"email"`
+	Role  models.BoardRole `json:"role"`
+}
+
+// POST /boards/:id/invitations
+func (h *InvitationHandler) Create(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	boardID, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req invitationCreateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if req.Role == "" {
+		req.Role = models.BoardRoleEditor
+	}
+	// sama seperti PUT /members: hanya owner yang boleh mengangkat admin
+	if req.Role == models.BoardRoleAdmin && !isOwner(c) {
+		return c.Status(403).JSON(fiber.Map{"error": "only the owner can grant or revoke admin"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	inv, err := h.Svc.Create(ctx, boardID, uid, req.Email, req.Role)
+	if err != nil {
+		return c.Status(invitationStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	// token hanya dikirim lewat email ke invitee
+	return c.Status(201).JSON(fiber.Map{"invitation": inv})
+}
+
+// GET /boards/:id/invitations
+func (h *InvitationHandler) ListForBoard(c *fiber.Ctx) error {
+	boardID, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	out, err := h.Svc.ListForBoard(ctx, boardID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(out)
+}
+
+// DELETE /boards/:id/invitations/:inviteId
+func (h *InvitationHandler) Revoke(c *fiber.Ctx) error {
+	boardID, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	id, err := utils.MustObjectID(c.Params("inviteId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid inviteId"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Svc.Revoke(ctx, boardID, id); err != nil {
+		return c.Status(invitationStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(204)
+}
+
+// GET /me/invitations
+func (h *InvitationHandler) ListMine(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	out, err := h.Svc.ListForUser(ctx, uid)
+	if err != nil {
+		return c.Status(invitationStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(out)
+}
+
+// POST /invitations/:id/accept
+func (h *InvitationHandler) Accept(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	inv, err := h.Svc.Accept(ctx, id, uid)
+	if err != nil {
+		return c.Status(invitationStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	h.publishAccepted(ctx, c, inv)
+	return c.JSON(inv)
+}
+
+type invitationTokenReq struct {
+	Token string `json:"token"`
+}
+
+// POST /invitations/accept  body: {token} (link dari email undangan)
+func (h *InvitationHandler) AcceptToken(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req invitationTokenReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	inv, err := h.Svc.AcceptToken(ctx, req.Token, uid)
+	if err != nil {
+		return c.Status(invitationStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	h.publishAccepted(ctx, c, inv)
+	return c.JSON(inv)
+}
+
+// POST /invitations/:id/decline
+func (h *InvitationHandler) Decline(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Svc.Decline(ctx, id, uid); err != nil {
+		return c.Status(invitationStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(204)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAHandler struct{ Svc services.MFAService }
+
+func NewMFAHandler(s services.MFAService) *MFAHandler { return &MFAHandler{Svc: s} }
+
+func mfaStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrMFAAlreadyEnabled), errors.Is(err, services.ErrMFANotEnabled), errors.Is(err, services.ErrMFANoEnrollment):
+		return fiber.StatusConflict
+	case errors.Is(err, services.ErrMFAInvalidCode), errors.Is(err, services.ErrMFAChallenge), errors.Is(err, services.ErrInvalidCredentials):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, services.ErrAccountDisabled):
+		return fiber.StatusForbidden
+	}
+	return fiber.StatusInternalServerError
+}
+
+type mfaCodeReq struct {
+	Code     string `json:"code"` // kode TOTP 6 digit atau kode pemulihan
+	Password string `json:"password"`
+}
+
+// GET /me/mfa
+func (h *MFAHandler) Status(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	st, err := h.Svc.Status(ctx, uid)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(st)
+}
+
+// POST /me/mfa/totp/enroll: secret & otpauth URI (untuk QR code)
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	e, err := h.Svc.Enroll(ctx, uid)
+	if err != nil {
+		return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(e)
+}
+
+// POST /me/mfa/totp/confirm: aktifkan 2FA; kode pemulihan hanya ditampilkan sekali
+func (h *MFAHandler) Confirm(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req mfaCodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	codes, err := h.Svc.Confirm(ctx, uid, req.Code)
+	if err != nil {
+		return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"recoveryCodes": codes})
+}
+
+// POST /me/mfa/recovery-codes: ganti semua kode pemulihan
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req mfaCodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	codes, err := h.Svc.RegenerateRecoveryCodes(ctx, uid, req.Code)
+	if err != nil {
+		return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"recoveryCodes": codes})
+}
+
+// DELETE /me/mfa: matikan 2FA (butuh password + kode)
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req mfaCodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	if err := h.Svc.Disable(ctx, uid, req.Password, req.Code); err != nil {
+		return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type mfaVerifyReq struct {
+	MFAToken string `json:"mfaToken"`
+	Code     string `json:"code"`
+}
+
+// POST /auth/mfa/verify: langkah kedua login → pasangan token
+func (h *MFAHandler) Verify(c *fiber.Ctx) error {
+	var req mfaVerifyReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	pair, err := h.Svc.Verify(ctx, req.MFAToken, req.Code, sessionMeta(c))
+	var locked *services.LoginLockedError
+	if errors.As(err, &locked) {
+		return loginLocked(c, locked)
+	}
+	if err != nil {
+		return c.Status(mfaStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(pair)
+}
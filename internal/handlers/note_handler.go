@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"github.com/gofiber/fiber/v2"
@@ -47,10 +50,41 @@ func (h *NoteHandler) Create(c *fiber.Ctx) error {
 	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
+
+	// note di board/task butuh note:write di board tersebut
+	if tID != nil {
+		tb, err := authz.BoardIDFromTask(ctx, *tID)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "task not found"})
+		}
+		if bID != nil && *bID != tb {
+			return c.Status(400).JSON(fiber.Map{"error": "task does not belong to board"})
+		}
+		bID = &tb
+	}
+	if bID != nil {
+		ok, err := authz.Can(ctx, uid, *bID, authz.NoteWrite)
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "board not found"})
+		}
+		if errors.Is(err, authz.ErrMFARequired) {
+			return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if !ok {
+			return c.Status(403).JSON(fiber.Map{"error": "forbidden", "required": authz.NoteWrite})
+		}
+	}
 	n, err := h.Svc.Create(ctx, uid, req.Content, bID, tID, req.OnTimelineAt, getBool(req.Pinned))
+	if errors.Is(err, services.ErrBoardArchived) {
+		return boardArchived(c)
+	}
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	setETag(c, n.Version)
 	return c.Status(201).JSON(n)
 }
 
@@ -104,6 +138,14 @@ func (h *NoteHandler) Update(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
 	}
+	expect, err := ifMatch(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
 	patch := bson.M{}
 	if req.Content != nil {
 		patch["content"] = *req.Content
@@ -116,9 +158,20 @@ func (h *NoteHandler) Update(c *fiber.Ctx) error {
 	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	if err := h.Svc.Update(ctx, id, patch); err != nil {
+	if err := h.Svc.Update(ctx, id, uid, patch, expect); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			if n, gerr := h.Svc.Get(ctx, id); gerr == nil {
+				return versionConflict(c, n, n.Version)
+			}
+		}
+		if errors.Is(err, services.ErrBoardArchived) {
+			return boardArchived(c)
+		}
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	if n, err := h.Svc.Get(ctx, id); err == nil {
+		setETag(c, n.Version)
+	}
 	return c.SendStatus(204)
 }
 
@@ -127,10 +180,36 @@ func (h *NoteHandler) Delete(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
 	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
-	if err := h.Svc.Delete(ctx, id); err != nil {
+	if err := h.Svc.Delete(ctx, id, uid); err != nil {
+		if errors.Is(err, services.ErrBoardArchived) {
+			return boardArchived(c)
+		}
 		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.SendStatus(204)
 }
+
+func (h *NoteHandler) Restore(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	n, err := h.Svc.Restore(ctx, id, uid)
+	if err != nil {
+		return restoreError(c, err)
+	}
+	setETag(c, n.Version)
+	return c.JSON(n)
+}
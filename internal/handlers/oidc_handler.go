@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OIDCHandler struct{ Svc services.OIDCService }
+
+func NewOIDCHandler(s services.OIDCService) *OIDCHandler { return &OIDCHandler{Svc: s} }
+
+func oidcStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrOIDCDisabled):
+		return fiber.StatusNotFound
+	case errors.Is(err, services.ErrOIDCRedirect), errors.Is(err, services.ErrOIDCState):
+		return fiber.StatusBadRequest
+	case errors.Is(err, services.ErrOIDCProviderUnusable):
+		return fiber.StatusBadGateway
+	case errors.Is(err, services.ErrOIDCNoAccount), errors.Is(err, services.ErrAccountDisabled),
+		errors.Is(err, services.ErrOIDCEmailMissing), errors.Is(err, services.ErrOIDCUnverified):
+		return fiber.StatusForbidden
+	}
+	return fiber.StatusUnauthorized
+}
+
+// GET /auth/oidc/login?redirect=<url frontend>
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+	u, err := h.Svc.Begin(ctx, c.Query("redirect"))
+	if err != nil {
+		return c.Status(oidcStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Redirect(u, fiber.StatusFound)
+}
+
+// GET /auth/oidc/callback?code=...&state=...
+// Dengan redirect: token dikirim di fragment (#token=...) supaya tidak masuk
+// log server; tanpa redirect: JSON seperti /login.
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), 15*time.Second)
+	defer cancel()
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		redirect := h.Svc.Abort(ctx, c.Query("state"))
+		return h.fail(c, redirect, fiber.StatusUnauthorized, idpErr)
+	}
+	res, redirect, err := h.Svc.Complete(ctx, c.Query("code"), c.Query("state"), sessionMeta(c))
+	if err != nil {
+		return h.fail(c, redirect, oidcStatus(err), err.Error())
+	}
+	if redirect == "" {
+		return c.JSON(loginResponse(res))
+	}
+	var frag url.Values
+	if res.MFAToken != "" {
+		frag = url.Values{
+			"mfaRequired":  {"true"},
+			"mfaToken":     {res.MFAToken},
+			"mfaExpiresIn": {strconv.FormatInt(res.MFAExpiresIn, 10)},
+		}
+	} else {
+		pair := res.Tokens
+		frag = url.Values{
+			"token":        {pair.AccessToken},
+			"refreshToken": {pair.RefreshToken},
+			"expiresIn":    {strconv.FormatInt(pair.ExpiresIn, 10)},
+			"userId":       {pair.UserID},
+		}
+	}
+	return c.Redirect(redirect+"#"+frag.Encode(), fiber.StatusFound)
+}
+
+func (h *OIDCHandler) fail(c *fiber.Ctx, redirect string, status int, msg string) error {
+	if redirect == "" {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	return c.Redirect(redirect+"#"+url.Values{"error": {msg}}.Encode(), fiber.StatusFound)
+}
@@ -2,16 +2,19 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/httpx"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/validation"
 	"github.com/gofiber/fiber/v2"
-	socketio "github.com/googollee/go-socket.io"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -20,12 +23,14 @@ import (
 // Handler & ctor
 // ==============================
 type TaskHandler struct {
-	Svc    services.TaskService
-	Socket *socketio.Server
+	Svc      services.TaskService
+	Events   realtime.Publisher
+	Editor   *realtime.TaskEditor // boleh nil
+	Activity services.ActivityService
 }
 
-func NewTaskHandler(s services.TaskService, sock *socketio.Server) *TaskHandler {
-	return &TaskHandler{Svc: s, Socket: sock}
+func NewTaskHandler(s services.TaskService, events realtime.Publisher, editor *realtime.TaskEditor, activity services.ActivityService) *TaskHandler {
+	return &TaskHandler{Svc: s, Events: events, Editor: editor, Activity: activity}
 }
 
 // ==============================
@@ -94,6 +99,11 @@ func (h *TaskHandler) ListByBoard(c *fiber.Ctx) error {
 	return c.JSON(items)
 }
 
+// GET /api/tasks/:id/activity: riwayat task beserta note-nya
+func (h *TaskHandler) ListActivity(c *fiber.Ctx) error {
+	return listActivity(c, "id", h.Activity.ListByTask)
+}
+
 // GET /api/tasks/:id
 func (h *TaskHandler) Get(c *fiber.Ctx) error {
 	tid, err := mustOIDParam(c, "id")
@@ -108,6 +118,10 @@ func (h *TaskHandler) Get(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not found"})
 	}
+	setETag(c, t.Version)
+	if notModified(c, t.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 	return c.JSON(t)
 }
 
@@ -143,22 +157,16 @@ func (h *TaskHandler) Create(c *fiber.Ctx) error {
 	var assignees []primitive.ObjectID = nil
 
 	t, err := h.Svc.Create(ctx, boardID, uid, req.Title, req.Description, req.ColumnID, status, due, assignees)
+	if errors.Is(err, services.ErrBoardArchived) {
+		return boardArchived(c)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Broadcast
-	if h.Socket != nil {
-		h.Socket.BroadcastToRoom("/", t.BoardID.Hex(), "task_created", fiber.Map{
-			"id":       t.ID.Hex(),
-			"boardId":  t.BoardID.Hex(),
-			"title":    t.Title,
-			"columnId": t.ColumnID,
-			"order":    t.Order,
-			"actorId":  uid.Hex(),
-		})
-	}
+	h.Events.Publish(realtime.NewTaskCreated(t, uid))
 
+	setETag(c, t.Version)
 	return c.Status(fiber.StatusCreated).JSON(t)
 }
 
@@ -174,6 +182,11 @@ func (h *TaskHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
 
+	expect, err := ifMatch(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	var req taskUpdateReq
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
@@ -213,10 +226,21 @@ func (h *TaskHandler) Update(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), 8*time.Second)
 	defer cancel()
 
-	// Service Update signature (dari error): want (ctx, id, bson.M, userID)
-	if err := h.Svc.Update(ctx, tid, update, uid); err != nil {
+	if err := h.Svc.Update(ctx, tid, update, uid, expect); err != nil {
+		if errors.Is(err, services.ErrVersionConflict) {
+			return h.taskConflict(ctx, c, tid)
+		}
+		if errors.Is(err, services.ErrBoardArchived) {
+			return boardArchived(c)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	// deskripsi yang sedang diedit bersama ikut diganti lewat op
+	if req.Description != nil && h.Editor != nil {
+		if err := h.Editor.Replace(ctx, tid, uid, *req.Description); err != nil {
+			log.Printf("[editor] replace task=%s: %v", tid.Hex(), err)
+		}
+	}
 
 	// Ambil lagi untuk broadcast
 	t, err := h.Svc.Get(ctx, tid)
@@ -224,22 +248,9 @@ func (h *TaskHandler) Update(c *fiber.Ctx) error {
 		return c.SendStatus(204)
 	}
 
-	if h.Socket != nil {
-		h.Socket.BroadcastToRoom("/", t.BoardID.Hex(), "task_updated", fiber.Map{
-			"id":      t.ID.Hex(),
-			"boardId": t.BoardID.Hex(),
-			"actorId": uid.Hex(),
-		})
-	}
-	if t, getErr := h.Svc.Get(ctx, tid); getErr == nil && h.Socket != nil {
-		room := t.BoardID.Hex()
-		log.Printf("[SOCKET][EMIT] room=%s event=%s id=%s\n", room, "task_updated", t.ID.Hex())
-		h.Socket.BroadcastToRoom("/", room, "task_updated", fiber.Map{
-			"id":      t.ID.Hex(),
-			"actorId": c.Locals("userId"),
-		})
-	}
+	h.Events.Publish(realtime.NewTaskUpdated(t, uid))
 
+	setETag(c, t.Version)
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -258,26 +269,43 @@ func (h *TaskHandler) Delete(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
 
-	// Ambil dulu task utk tahu board room (jika perlu)
-	var room string
-	if t, _ := h.Svc.Get(ctx, tid); t != nil {
-		room = t.BoardID.Hex()
-	}
+	// Ambil dulu task utk tahu board-nya
+	t, _ := h.Svc.Get(ctx, tid)
 
-	if err := h.Svc.Delete(ctx, tid); err != nil {
+	if err := h.Svc.Delete(ctx, tid, uid); err != nil {
+		if errors.Is(err, services.ErrBoardArchived) {
+			return boardArchived(c)
+		}
 		return httpx.ServerError(c, err.Error())
 	}
 
-	if h.Socket != nil {
-		h.Socket.BroadcastToRoom("/", room, "task_deleted", fiber.Map{
-			"id":      tid.Hex(),
-			"boardId": room,
-			"actorId": uid.Hex(),
-		})
+	if t != nil {
+		h.Events.Publish(realtime.NewTaskDeleted(tid, t.BoardID, uid))
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// POST /api/tasks/:id/restore: kembalikan dari trash ke posisi semula
+func (h *TaskHandler) Restore(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	tid, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 8*time.Second)
+	defer cancel()
+	t, err := h.Svc.Restore(ctx, tid, uid)
+	if err != nil {
+		return restoreError(c, err)
+	}
+	h.Events.Publish(realtime.NewTaskCreated(t, uid))
+	setETag(c, t.Version)
+	return c.JSON(t)
+}
+
 // POST /api/tasks/:id/move
 func (h *TaskHandler) Move(c *fiber.Ctx) error {
 	uid, err := utils.UserIDFromCtx(c)
@@ -294,42 +322,88 @@ func (h *TaskHandler) Move(c *fiber.Ctx) error {
 		ToColumnID string `json:"toColumnId" validate:"required"`
 		ToPosition int    `json:"toPosition" validate:"required,min=1"`
 	}
-	if err := httpx.ValidateBody(c, &req); err != nil {
-		return nil
+	if err := c.BodyParser(&req); err != nil {
+		return httpx.BadRequest(c, "invalid_body")
+	}
+	if err := validation.V.Struct(&req); err != nil {
+		return httpx.FromValidation(c, err)
+	}
+	expect, err := ifMatch(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
 	defer cancel()
 
-	if err := h.Svc.Move(ctx, tid, req.ToColumnID, req.ToPosition); err != nil {
+	pos, err := h.Svc.Move(ctx, tid, req.ToColumnID, req.ToPosition, uid, expect)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return c.Status(404).JSON(fiber.Map{"error": "task not found"})
+		case errors.Is(err, services.ErrColumnNotFound):
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrVersionConflict):
+			return h.taskConflict(ctx, c, tid)
+		case errors.Is(err, services.ErrOrderConflict):
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrBoardArchived):
+			return boardArchived(c)
+		}
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	// ⬇️ ambil t untuk broadcast/response
 	t, err := h.Svc.Get(ctx, tid)
 	if err != nil {
-		// fallback broadcast minimal tanpa t
-		if h.Socket != nil {
-			h.Socket.BroadcastToRoom("/", "UNKNOWN_BOARD", "task_moved", fiber.Map{
-				"id":         tid.Hex(),
-				"toColumnId": req.ToColumnID,
-				"toPosition": req.ToPosition,
-				"actorId":    uid.Hex(),
-			})
-		}
 		return c.SendStatus(204)
 	}
 
-	if h.Socket != nil {
-		h.Socket.BroadcastToRoom("/", t.BoardID.Hex(), "task_moved", fiber.Map{
-			"id":         t.ID.Hex(),
-			"boardId":    t.BoardID.Hex(),
-			"toColumnId": req.ToColumnID,
-			"toPosition": req.ToPosition,
-			"actorId":    uid.Hex(),
-		})
-	}
+	h.Events.Publish(realtime.NewTaskMoved(t, pos, uid))
 
 	// return c.SendStatus(204)
+	setETag(c, t.Version)
 	return c.Status(200).JSON(t)
 }
+
+// taskConflict: 409 + task terbaru (If-Match basi)
+func (h *TaskHandler) taskConflict(ctx context.Context, c *fiber.Ctx, id primitive.ObjectID) error {
+	t, err := h.Svc.Get(ctx, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "task not found"})
+	}
+	return versionConflict(c, t, t.Version)
+}
+
+// POST /api/boards/:id/reorder
+// Rapikan order semua kolom board menjadi 1..N (repair)
+func (h *TaskHandler) Reorder(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	boardID, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid id"})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 10*time.Second)
+	defer cancel()
+
+	if err := h.Svc.Reorder(ctx, boardID); err != nil {
+		if errors.Is(err, services.ErrOrderConflict) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrBoardArchived) {
+			return boardArchived(c)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	items, err := h.Svc.ListByBoard(ctx, boardID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	h.Events.Publish(realtime.NewTasksReordered(boardID, uid))
+	return c.JSON(items)
+}
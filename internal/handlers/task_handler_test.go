@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/realtime"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type taskFixture struct {
+	app    *fiber.App
+	svc    services.TaskService
+	boards services.BoardService
+	events *events
+	board  *models.Board
+	tasks  map[string]*models.Task
+}
+
+// newTaskFixture: board milik owner dengan kolom todo & doing, task a, b, c di todo
+func newTaskFixture(t *testing.T) *taskFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	owner := primitive.NewObjectID()
+	activity := services.NewActivityService(repos.Activities)
+	boards := services.NewBoardService(repos.Boards, activity)
+	b, err := boards.Create(ctx, owner, "board", nil, []models.BoardColumn{{ID: "todo", Name: "Todo", Order: 1}, {ID: "doing", Name: "Doing", Order: 2}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &taskFixture{
+		svc:    services.NewTaskService(repos.Tasks, repos.Notes, repos.Boards, repos.Tx, activity, services.OrderingIndex),
+		boards: boards,
+		events: &events{},
+		board:  b,
+		tasks:  map[string]*models.Task{},
+	}
+	for _, title := range []string{"a", "b", "c"} {
+		task, err := f.svc.Create(ctx, b.ID, owner, title, nil, "todo", nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.tasks[title] = task
+	}
+	h := NewTaskHandler(f.svc, f.events, nil, activity)
+	f.app = fiber.New()
+	f.app.Use(as(owner, models.BoardRoleOwner))
+	f.app.Post("/tasks/:id/move", h.Move)
+	f.app.Get("/tasks/:id", h.Get)
+	f.app.Patch("/tasks/:id", h.Update)
+	return f
+}
+
+func (f *taskFixture) column(t *testing.T, id string) []string {
+	t.Helper()
+	items, err := f.svc.ListByBoard(context.Background(), f.board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := []string{}
+	for _, it := range items {
+		if it.ColumnID == id {
+			out = append(out, it.Title)
+		}
+	}
+	return out
+}
+
+func TestMoveHandler(t *testing.T) {
+	f := newTaskFixture(t)
+	res := call(t, f.app, "POST", "/tasks/"+f.tasks["c"].ID.Hex()+"/move", `{"toColumnId":"doing","toPosition":1}`)
+	if res.status != 200 {
+		t.Fatalf("status %d: %s", res.status, res.raw)
+	}
+	if res.body["columnId"] != "doing" {
+		t.Errorf("response task in %v", res.body["columnId"])
+	}
+	if got := f.column(t, "todo"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("todo = %v", got)
+	}
+	if got := f.events.types(); !reflect.DeepEqual(got, []realtime.EventType{realtime.EventTaskMoved}) {
+		t.Fatalf("events = %v", got)
+	}
+	moved := f.events.sent[0].Data.(realtime.TaskMoved)
+	if moved.ToColumnID != "doing" || moved.ToPosition != 1 {
+		t.Errorf("task_moved = %+v", moved)
+	}
+}
+
+func TestMoveHandlerRejects(t *testing.T) {
+	cases := []struct {
+		name   string
+		task   string // "" = id yang tidak ada
+		body   string
+		status int
+		field  string // field yang disebut di body validasi
+	}{
+		{"missing column", "a", `{"toPosition":1}`, 400, "ToColumnID"},
+		{"missing position", "a", `{"toColumnId":"doing"}`, 400, "ToPosition"},
+		{"position below one", "a", `{"toColumnId":"doing","toPosition":-2}`, 400, "ToPosition"},
+		{"not json", "a", `{`, 400, ""},
+		{"unknown column", "a", `{"toColumnId":"nope","toPosition":1}`, 400, ""},
+		{"unknown task", "", `{"toColumnId":"doing","toPosition":1}`, 404, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newTaskFixture(t)
+			id := primitive.NewObjectID().Hex()
+			if tc.task != "" {
+				id = f.tasks[tc.task].ID.Hex()
+			}
+			res := call(t, f.app, "POST", "/tasks/"+id+"/move", tc.body)
+			if res.status != tc.status {
+				t.Fatalf("status %d, want %d: %s", res.status, tc.status, res.raw)
+			}
+			if tc.field != "" {
+				fields, _ := res.body["fields"].(map[string]interface{})
+				if _, ok := fields[tc.field]; !ok {
+					t.Errorf("fields = %v, want %s", fields, tc.field)
+				}
+			}
+			if got := f.column(t, "todo"); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+				t.Errorf("todo = %v after a rejected move", got)
+			}
+			if len(f.events.sent) != 0 {
+				t.Errorf("events = %v", f.events.types())
+			}
+		})
+	}
+}
@@ -1,17 +1,25 @@
 package handlers
 
 import (
+	"context"
 	"time"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type TimelineHandler struct{}
+type TimelineHandler struct {
+	Tasks services.TaskService
+	Notes services.NoteService
+}
 
-func NewTimelineHandler() *TimelineHandler { return &TimelineHandler{} }
+func NewTimelineHandler(t services.TaskService, n services.NoteService) *TimelineHandler {
+	return &TimelineHandler{Tasks: t, Notes: n}
+}
 
 func parseRange(c *fiber.Ctx) (time.Time, time.Time, error) {
 	fromStr := c.Query("from", "")
@@ -44,15 +52,13 @@ func parseRange(c *fiber.Ctx) (time.Time, time.Time, error) {
 
 func (h *TimelineHandler) Get(c *fiber.Ctx) error {
 	// optional boardId filter
-	var boardFilter bson.M
+	var boardID *primitive.ObjectID
 	if bid := c.Query("boardId"); bid != "" {
 		oid, err := utils.MustObjectID(bid)
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid boardId"})
 		}
-		boardFilter = bson.M{"boardId": oid}
-	} else {
-		boardFilter = bson.M{}
+		boardID = &oid
 	}
 
 	from, to, err := parseRange(c)
@@ -60,39 +66,36 @@ func (h *TimelineHandler) Get(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid date range"})
 	}
 
-	// Tasks yang ada di rentang (pakai dueDate/startDate)
-	taskFilter := bson.M{
-		"$or": []bson.M{
-			{"dueDate": bson.M{"$gte": from, "$lte": to}},
-			{"startDate": bson.M{"$gte": from, "$lte": to}},
-		},
-	}
-	for k, v := range boardFilter {
-		taskFilter[k] = v
+	ctx, cancel := context.WithTimeout(c.Context(), 6*time.Second)
+	defer cancel()
+
+	// boardId sudah dicek middleware; tanpa boardId: semua board yang boleh
+	// dibaca + note pribadi milik sendiri
+	var scope repository.TimelineScope
+	if boardID != nil {
+		scope.BoardIDs = []primitive.ObjectID{*boardID}
+	} else {
+		uid, err := utils.UserIDFromCtx(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		if scope.BoardIDs, err = authz.Boards(ctx, uid, authz.BoardRead); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		scope.AuthorID = &uid
 	}
 
-	curT, err := config.MongoDB.Collection("tasks").Find(c.Context(), taskFilter)
+	// Tasks yang ada di rentang (pakai dueDate/startDate)
+	tasks, err := h.Tasks.ListTimeline(ctx, scope, from, to)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	var tasks []bson.M
-	if err := curT.All(c.Context(), &tasks); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
 
 	// Notes pada timeline (onTimelineAt range)
-	noteFilter := bson.M{"onTimelineAt": bson.M{"$gte": from, "$lte": to}}
-	for k, v := range boardFilter {
-		noteFilter[k] = v
-	}
-	curN, err := config.MongoDB.Collection("notes").Find(c.Context(), noteFilter)
+	notes, err := h.Notes.ListTimeline(ctx, scope, from, to)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
-	var notes []bson.M
-	if err := curN.All(c.Context(), &notes); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-	}
 
 	return c.JSON(fiber.Map{
 		"range": fiber.Map{"from": from, "to": to},
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// restoreError: tidak ada di trash → 404; induknya masih di trash atau
+// board diarsipkan → 409
+func restoreError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrBoardArchived):
+		return boardArchived(c)
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "not found in trash"})
+	case errors.Is(err, services.ErrBoardInTrash), errors.Is(err, services.ErrTaskInTrash):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UserHandler struct {
+	Users    services.UserService
+	Accounts services.AccountService
+}
+
+func NewUserHandler(u services.UserService, acc services.AccountService) *UserHandler {
+	return &UserHandler{Users: u, Accounts: acc}
+}
+
+// maxBatchUsers: batas ?ids= pada GET /users
+const maxBatchUsers = 100
+
+// meResponse: profil lengkap milik sendiri; "userId" dipertahankan untuk
+// klien lama yang membaca respons /me versi awal
+func meResponse(u *models.User) fiber.Map {
+	return fiber.Map{
+		"userId":          u.ID.Hex(),
+		"id":              u.ID.Hex(),
+		"email":           u.Email,
+		"name":            u.Name,
+		"avatarUrl":       u.AvatarURL,
+		"role":            u.Role,
+		"isActive":        u.IsActive,
+		"emailVerifiedAt": u.EmailVerifiedAt,
+		"mfaEnabled":      u.MFAEnabled(),
+		"createdAt":       u.CreatedAt,
+		"updatedAt":       u.UpdatedAt,
+	}
+}
+
+// GET /me
+func (h *UserHandler) Me(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Users.FindByID(ctx, uid)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+	}
+	return c.JSON(meResponse(u))
+}
+
+type profileReq struct {
+	Name      *string `json:"name"`
+	AvatarURL *string `json:"avatarUrl"` // "" = hapus avatar
+}
+
+// PATCH /me
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req profileReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Users.UpdateProfile(ctx, uid, req.Name, req.AvatarURL)
+	if errors.Is(err, services.ErrInvalidName) || errors.Is(err, services.ErrInvalidAvatarURL) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(meResponse(u))
+}
+
+type changePasswordReq struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// POST /me/password: session lain dicabut, session ini tetap berlaku
+func (h *UserHandler) ChangePassword(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req changePasswordReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	err = h.Accounts.ChangePassword(ctx, uid, utils.SessionIDFromCtx(c), req.CurrentPassword, req.NewPassword)
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "current password is incorrect"})
+	case errors.Is(err, services.ErrWeakPassword):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type deactivateReq struct {
+	Password string `json:"password"`
+}
+
+// POST /me/deactivate: nonaktifkan akun sendiri; semua token langsung ditolak
+func (h *UserHandler) DeactivateMe(c *fiber.Ctx) error {
+	uid, err := utils.UserIDFromCtx(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	var req deactivateReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid body"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Users.FindByID(ctx, uid)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+	}
+	if !utils.CheckPassword(u.PasswordHash, req.Password) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "password is incorrect"})
+	}
+	if err := h.Accounts.Deactivate(ctx, uid); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GET /users/:id: profil publik (nama, avatar)
+func (h *UserHandler) Get(c *fiber.Ctx) error {
+	id, err := utils.MustObjectID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	u, err := h.Users.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(services.ToPublicUser(u))
+}
+
+// GET /users?ids=a,b,c: profil publik sekaligus (mis. nama assignee)
+func (h *UserHandler) List(c *fiber.Ctx) error {
+	var ids []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{}
+	for _, raw := range strings.Split(c.Query("ids"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id: " + raw})
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "ids is required"})
+	}
+	if len(ids) > maxBatchUsers {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "too many ids"})
+	}
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+	list, err := h.Users.FindByIDs(ctx, ids)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(list)
+}
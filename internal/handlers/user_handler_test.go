@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/mailer"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/middleware"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// route akun seperti di routes.Register, di belakang JWTProtected sungguhan
+type accountApp struct {
+	app      *fiber.App
+	repos    *repository.Repos
+	users    services.UserService
+	sessions services.SessionService
+	tokens   services.AccessTokenService
+}
+
+func newAccountApp(t *testing.T) *accountApp {
+	t.Helper()
+	config.Cfg.JWTAlg = "HS256"
+	config.Cfg.JWTSecret = "handlers-test-secret-0123456789abcdef"
+	if err := utils.LoadJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	a := &accountApp{app: fiber.New(), repos: repository.NewMemory()}
+	a.users = services.NewUserService(a.repos.Users, nil)
+	a.sessions = services.NewSessionService(a.repos.Sessions, time.Minute, time.Hour)
+	a.tokens = services.NewAccessTokenService(a.repos.Tokens, a.repos.Users)
+	accounts := services.NewAccountService(a.repos.Users, a.repos.EmailTokens, a.sessions, a.tokens, &mailer.Log{Path: t.TempDir() + "/mail.log"}, services.AccountOptions{ResetTTL: time.Hour})
+	h := NewUserHandler(a.users, accounts)
+
+	prot := a.app.Group("", middleware.JWTProtected(a.sessions, a.tokens))
+	sess := middleware.SessionOnly()
+	prot.Get("/me", h.Me)
+	prot.Patch("/me", sess, h.UpdateMe)
+	prot.Post("/me/password", sess, h.ChangePassword)
+	prot.Post("/me/deactivate", sess, h.DeactivateMe)
+	prot.Get("/users", h.List)
+	prot.Get("/users/:id", h.Get)
+	return a
+}
+
+// signUp: user baru dan header Authorization untuk session-nya
+func (a *accountApp) signUp(t *testing.T, name, email string) (*models.User, []string) {
+	t.Helper()
+	u, err := a.users.Create(context.Background(), name, email, "password-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u, a.login(t, u)
+}
+
+func (a *accountApp) login(t *testing.T, u *models.User) []string {
+	t.Helper()
+	pair, err := a.sessions.Start(context.Background(), u.ID, services.SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []string{"Authorization", "Bearer " + pair.AccessToken}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	a := newAccountApp(t)
+	_, auth := a.signUp(t, "Ana", "ana@x.io")
+
+	res := call(t, a.app, "PATCH", "/me", `{"name":"  Ana B  ","avatarUrl":"https://cdn.test/a.png"}`, auth...)
+	if res.status != 200 || res.body["name"] != "Ana B" || res.body["avatarUrl"] != "https://cdn.test/a.png" || res.body["email"] != "ana@x.io" {
+		t.Fatalf("PATCH /me = %d %v", res.status, res.body)
+	}
+	// field yang tidak dikirim tidak berubah; "" menghapus avatar
+	res = call(t, a.app, "PATCH", "/me", `{"avatarUrl":""}`, auth...)
+	if res.status != 200 || res.body["name"] != "Ana B" || res.body["avatarUrl"] != nil {
+		t.Fatalf("clear avatar = %d %v", res.status, res.body)
+	}
+
+	for _, body := range []string{
+		`{"name":"   "}`,
+		fmt.Sprintf(`{"name":%q}`, strings.Repeat("x", 101)),
+		`{"avatarUrl":"javascript:alert(1)"}`,
+		`{"avatarUrl":"/relative.png"}`,
+		`not json`,
+	} {
+		if res := call(t, a.app, "PATCH", "/me", body, auth...); res.status != 400 {
+			t.Errorf("PATCH %s = %d %v, want 400", body, res.status, res.body)
+		}
+	}
+	if res := call(t, a.app, "GET", "/me", "", auth...); res.body["name"] != "Ana B" {
+		t.Errorf("GET /me after rejected patches = %v", res.body)
+	}
+}
+
+func TestPublicProfiles(t *testing.T) {
+	a := newAccountApp(t)
+	ana, auth := a.signUp(t, "Ana", "ana@x.io")
+	budi, _ := a.signUp(t, "Budi", "budi@x.io")
+
+	res := call(t, a.app, "GET", "/users/"+budi.ID.Hex(), "", auth...)
+	if res.status != 200 || res.body["name"] != "Budi" {
+		t.Fatalf("GET /users/:id = %d %v", res.status, res.body)
+	}
+	if strings.Contains(res.raw, "budi@x.io") || strings.Contains(res.raw, "password") {
+		t.Errorf("public profile leaks private fields: %s", res.raw)
+	}
+
+	unknown := primitive.NewObjectID().Hex()
+	res = call(t, a.app, "GET", "/users?ids="+ana.ID.Hex()+","+budi.ID.Hex()+","+ana.ID.Hex()+","+unknown, "", auth...)
+	if res.status != 200 || strings.Count(res.raw, `"name"`) != 2 || strings.Contains(res.raw, "@x.io") {
+		t.Errorf("batch = %d %s", res.status, res.raw)
+	}
+
+	many := make([]string, 101)
+	for i := range many {
+		many[i] = primitive.NewObjectID().Hex()
+	}
+	bad := map[string]int{
+		"/users/nope":                           400,
+		"/users/" + unknown:                     404,
+		"/users":                                400,
+		"/users?ids=" + ana.ID.Hex() + ",zz":    400,
+		"/users?ids=" + strings.Join(many, ","): 400,
+	}
+	for path, want := range bad {
+		if res := call(t, a.app, "GET", path, "", auth...); res.status != want {
+			t.Errorf("GET %s = %d, want %d", path, res.status, want)
+		}
+	}
+}
+
+func TestChangePasswordKeepsOnlyThisSession(t *testing.T) {
+	a := newAccountApp(t)
+	u, auth := a.signUp(t, "Ana", "ana@x.io")
+	other := a.login(t, u)
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{`{"currentPassword":"wrong-one","newPassword":"password-2"}`, 403},
+		{`{"currentPassword":"password-1","newPassword":"short"}`, 400},
+		{`{"currentPassword":"password-1","newPassword":"password-2"}`, 204},
+	}
+	for _, tc := range cases {
+		if res := call(t, a.app, "POST", "/me/password", tc.body, auth...); res.status != tc.want {
+			t.Fatalf("POST /me/password %s = %d %v, want %d", tc.body, res.status, res.body, tc.want)
+		}
+	}
+	if res := call(t, a.app, "GET", "/me", "", auth...); res.status != 200 {
+		t.Errorf("session that changed the password: %d", res.status)
+	}
+	if res := call(t, a.app, "GET", "/me", "", other...); res.status != 401 {
+		t.Errorf("other session: %d, want 401", res.status)
+	}
+}
+
+func TestDeactivateRejectsExistingTokens(t *testing.T) {
+	a := newAccountApp(t)
+	u, auth := a.signUp(t, "Ana", "ana@x.io")
+	_, pat, err := a.tokens.Create(context.Background(), u.ID, "ci", []models.TokenScope{models.ScopeRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patAuth := []string{"Authorization", "Bearer " + pat}
+	if res := call(t, a.app, "GET", "/me", "", patAuth...); res.status != 200 {
+		t.Fatalf("access token before deactivation: %d", res.status)
+	}
+	// akun & keamanan tidak bisa diubah lewat access token
+	if res := call(t, a.app, "POST", "/me/deactivate", `{"password":"password-1"}`, patAuth...); res.status != 403 {
+		t.Errorf("deactivate with an access token: %d, want 403", res.status)
+	}
+
+	if res := call(t, a.app, "POST", "/me/deactivate", `{"password":"nope"}`, auth...); res.status != 403 {
+		t.Fatalf("wrong password: %d", res.status)
+	}
+	if res := call(t, a.app, "POST", "/me/deactivate", `{"password":"password-1"}`, auth...); res.status != 204 {
+		t.Fatalf("deactivate: %d", res.status)
+	}
+	for name, h := range map[string][]string{"session": auth, "access token": patAuth} {
+		if res := call(t, a.app, "GET", "/me", "", h...); res.status != 401 {
+			t.Errorf("%s after deactivation: %d, want 401", name, res.status)
+		}
+	}
+	// login baru juga ditolak
+	guard := services.NewLoginGuard(a.repos.Throttles, a.repos.Users, services.NewAuditService(a.repos.Audit), services.LoginGuardOptions{})
+	login := services.NewAuthService(a.users, a.sessions, guard, nil, false)
+	if _, err := login.Login(context.Background(), "ana@x.io", "password-1", services.SessionMeta{}); !errors.Is(err, services.ErrAccountDisabled) {
+		t.Errorf("login after deactivation: err = %v, want ErrAccountDisabled", err)
+	}
+}
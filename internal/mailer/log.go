@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Log tidak mengirim apa pun: email ditulis ke file Path (append), atau ke
+// log server bila Path kosong. Untuk dev; link reset/verifikasi bisa disalin
+// dari sini.
+type Log struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (l *Log) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	entry := fmt.Sprintf("--- %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Text)
+	if l.Path == "" {
+		log.Printf("[mail]\n%s", entry)
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(entry)
+	return err
+}
@@ -0,0 +1,29 @@
+// Package mailer mengirim email transaksional (reset password, verifikasi).
+// Implementasi dipilih lewat config MAILER: "smtp" atau "log" (dev).
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string // body plain text
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var ErrInvalidHeader = errors.New("mailer: header must not contain line breaks")
+
+// validate mencegah header injection lewat alamat/subject
+func (m Message) validate() error {
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogAppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	l := &Log{Path: path}
+	for _, subject := range []string{"first", "second"} {
+		if err := l.Send(context.Background(), Message{To: "a@x.io", Subject: subject, Text: "link: https://app.test/x?token=abc"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if !strings.Contains(out, "Subject: first") || !strings.Contains(out, "Subject: second") || !strings.Contains(out, "token=abc") {
+		t.Fatalf("log file:\n%s", out)
+	}
+}
+
+func TestHeaderInjectionIsRejected(t *testing.T) {
+	msgs := []Message{
+		{To: "a@x.io\r\nBcc: everyone@x.io", Subject: "hi"},
+		{To: "a@x.io", Subject: "hi\nBcc: everyone@x.io"},
+	}
+	senders := map[string]Mailer{
+		"log":  &Log{Path: filepath.Join(t.TempDir(), "mail.log")},
+		"smtp": &SMTP{Host: "127.0.0.1", Port: 1, From: "app@x.io"},
+	}
+	for name, m := range senders {
+		for _, msg := range msgs {
+			if err := m.Send(context.Background(), msg); !errors.Is(err, ErrInvalidHeader) {
+				t.Errorf("%s: err = %v, want ErrInvalidHeader", name, err)
+			}
+		}
+	}
+}
+
+func TestSMTPMessage(t *testing.T) {
+	s := &SMTP{From: "Be-Ambis <app@x.io>"}
+	raw := string(s.build(Message{To: "a@x.io", Subject: "Atur ulang kata sandi ✓", Text: "line 1\nline 2\n"}))
+	head, body, ok := strings.Cut(raw, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no header/body separator:\n%q", raw)
+	}
+	for _, want := range []string{"From: Be-Ambis <app@x.io>", "To: a@x.io", "Subject: =?utf-8?q?", "Content-Type: text/plain; charset=utf-8"} {
+		if !strings.Contains(head, want) {
+			t.Errorf("header missing %q:\n%s", want, head)
+		}
+	}
+	if body != "line 1\r\nline 2\r\n" {
+		t.Errorf("body = %q, want CRLF line endings", body)
+	}
+}
@@ -1,14 +1,23 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"strings"
 
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // internal/middleware/auth.go
-func JWTProtected() fiber.Handler {
+// JWTProtected memvalidasi access token dan memastikan session-nya (claim jti)
+// belum dicabut. Personal access token ("pat_...") juga diterima; scope-nya
+// disimpan di Locals("tokenScopes") untuk dicek guard berikutnya.
+func JWTProtected(sessions services.SessionService, tokens services.AccessTokenService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		h := c.Get("Authorization")
 		if h == "" {
@@ -18,12 +27,102 @@ func JWTProtected() fiber.Handler {
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid auth scheme"})
 		}
-		tokenStr := parts[1]
-		claims, err := utils.ParseJWT(tokenStr)
-		if err != nil {
+		id, err := Identify(c.Context(), sessions, tokens, parts[1])
+		switch {
+		case errors.Is(err, ErrSessionRevoked):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "session revoked"})
+		case errors.Is(err, ErrInvalidToken):
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Locals("userId", id.UserID.Hex())
+		if id.AccessToken != nil {
+			c.Locals("tokenId", id.AccessToken.ID.Hex())
+			c.Locals("tokenScopes", id.AccessToken.Scopes)
+		} else {
+			c.Locals("sessionId", id.SessionID.Hex())
+		}
+		return c.Next()
+	}
+}
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrSessionRevoked = errors.New("session revoked")
+)
+
+// Identity: pemilik token; tepat satu dari SessionID (JWT) atau AccessToken (PAT)
+type Identity struct {
+	UserID      primitive.ObjectID
+	SessionID   primitive.ObjectID
+	AccessToken *models.AccessToken
+}
+
+// Identify memvalidasi JWT (beserta session-nya) atau personal access token.
+// Dipakai JWTProtected dan handshake socket.
+func Identify(ctx context.Context, sessions services.SessionService, tokens services.AccessTokenService, tokenStr string) (*Identity, error) {
+	if strings.HasPrefix(tokenStr, services.AccessTokenPrefix) {
+		t, err := tokens.Authenticate(ctx, tokenStr)
+		if errors.Is(err, services.ErrInvalidAccessToken) {
+			return nil, ErrInvalidToken
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Identity{UserID: t.UserID, AccessToken: t}, nil
+	}
+	claims, err := utils.ParseJWT(tokenStr)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	uid, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	// token lama tanpa session tidak bisa dicabut → tolak, minta login ulang
+	sid, err := primitive.ObjectIDFromHex(claims.ID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	active, err := sessions.IsActive(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrSessionRevoked
+	}
+	return &Identity{UserID: uid, SessionID: sid}, nil
+}
+
+// tokenScopes: scope personal access token; false bila request memakai login biasa
+func tokenScopes(c *fiber.Ctx) ([]models.TokenScope, bool) {
+	scopes, ok := c.Locals("tokenScopes").([]models.TokenScope)
+	return scopes, ok
+}
+
+// ScopeAllows: login biasa selalu lolos; access token hanya bila scope-nya mengizinkan
+func ScopeAllows(c *fiber.Ctx, action authz.Action) bool {
+	scopes, ok := tokenScopes(c)
+	return !ok || authz.ScopeAllows(scopes, action)
+}
+
+// RequireScope: untuk route tanpa board (mis. buat board)
+func RequireScope(action authz.Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !ScopeAllows(c, action) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "token scope does not allow this action", "required": action})
+		}
+		return c.Next()
+	}
+}
+
+// SessionOnly: route akun & keamanan menolak personal access token
+func SessionOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := tokenScopes(c); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "not available with an access token; log in instead"})
 		}
-		c.Locals("userId", claims.Subject)
 		return c.Next()
 	}
 }
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type authFixture struct {
+	repos    *repository.Repos
+	sessions services.SessionService
+	tokens   services.AccessTokenService
+	app      *fiber.App
+}
+
+func loadTestJWTKeys(t *testing.T) {
+	t.Helper()
+	config.Cfg.JWTAlg = "HS256"
+	config.Cfg.JWTSecret = "middleware-test-secret-0123456789abcdef"
+	if err := utils.LoadJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newAuthFixture: GET /me di belakang JWTProtected, membalas locals yang diisi
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	loadTestJWTKeys(t)
+	f := &authFixture{repos: repository.NewMemory(), app: fiber.New()}
+	f.sessions = services.NewSessionService(f.repos.Sessions, time.Minute, time.Hour)
+	f.tokens = services.NewAccessTokenService(f.repos.Tokens, f.repos.Users)
+	f.app.Get("/me", JWTProtected(f.sessions, f.tokens), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"userId": c.Locals("userId"), "sessionId": c.Locals("sessionId"), "tokenId": c.Locals("tokenId")})
+	})
+	return f
+}
+
+// get: status dan body JSON untuk GET /me dengan header Authorization auth
+func (f *authFixture) get(t *testing.T, auth string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/me", nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	res, err := f.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := map[string]interface{}{}
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	return res.StatusCode, body
+}
+
+func TestJWTProtectedChecksSession(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t)
+	user := primitive.NewObjectID()
+	pair, err := f.sessions.Start(ctx, user, services.SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, body := f.get(t, "Bearer "+pair.AccessToken)
+	if code != 200 || body["userId"] != user.Hex() || body["sessionId"] != pair.SessionID || body["tokenId"] != nil {
+		t.Fatalf("valid token: %d %v", code, body)
+	}
+
+	sid, _ := primitive.ObjectIDFromHex(pair.SessionID)
+	if err := f.sessions.Revoke(ctx, user, sid); err != nil {
+		t.Fatal(err)
+	}
+	// access token belum kedaluwarsa, tapi session-nya sudah dicabut
+	if code, body := f.get(t, "Bearer "+pair.AccessToken); code != 401 || body["error"] != "session revoked" {
+		t.Fatalf("revoked session: %d %v", code, body)
+	}
+}
+
+func TestJWTProtectedRejects(t *testing.T) {
+	f := newAuthFixture(t)
+	noSession, err := utils.GenerateJWT(primitive.NewObjectID().Hex(), "", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownSession, err := utils.GenerateJWT(primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := utils.GenerateJWT(primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name, auth, want string
+	}{
+		{"no header", "", "missing bearer token"},
+		{"basic auth", "Basic dXNlcjpwYXNz", "invalid auth scheme"},
+		{"garbage", "Bearer nope", "invalid token"},
+		{"expired", "Bearer " + expired, "invalid token"},
+		{"token without session", "Bearer " + noSession, "invalid token"},
+		{"session never existed", "Bearer " + unknownSession, "session revoked"},
+		{"unknown access token", "Bearer " + services.AccessTokenPrefix + "nope", "invalid token"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, body := f.get(t, tc.auth)
+			if code != 401 || body["error"] != tc.want {
+				t.Errorf("got %d %v, want 401 %q", code, body, tc.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func BoardAccessByBoardPath(param string) fiber.Handler {
+func BoardAccessByBoardPath(param string, action authz.Action) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		uid, err := utils.UserIDFromCtx(c)
 		if err != nil {
@@ -17,20 +22,11 @@ func BoardAccessByBoardPath(param string) fiber.Handler {
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid board id"})
 		}
-		ctx, cancel := authz.WithTimeout(c.Context())
-		defer cancel()
-		ok, e := authz.IsMemberOrOwner(ctx, bid, uid)
-		if e != nil {
-			return c.Status(500).JSON(fiber.Map{"error": e.Error()})
-		}
-		if !ok {
-			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
-		}
-		return c.Next()
+		return checkBoard(c, uid, bid, action)
 	}
 }
 
-func BoardAccessByTaskPath(taskParam string) fiber.Handler {
+func BoardAccessByTaskPath(taskParam string, action authz.Action) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		uid, err := utils.UserIDFromCtx(c)
 		if err != nil {
@@ -46,24 +42,18 @@ func BoardAccessByTaskPath(taskParam string) fiber.Handler {
 		if e != nil {
 			return c.Status(404).JSON(fiber.Map{"error": "task not found"})
 		}
-		ok, e := authz.IsMemberOrOwner(ctx, bid, uid)
-		if e != nil {
-			return c.Status(500).JSON(fiber.Map{"error": e.Error()})
-		}
-		if !ok {
-			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
-		}
-		return c.Next()
+		return checkBoard(c, uid, bid, action)
 	}
 }
 
 // Guard akses board lewat QUERY ?boardId=...
-func BoardAccessByBoardQuery(queryKey string) fiber.Handler {
+func BoardAccessByBoardQuery(queryKey string, action authz.Action) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		bidStr := c.Query(queryKey)
 		if bidStr == "" {
-			// tidak ada filter boardId → lewati guard
-			return c.Next()
+			// tanpa boardId handler membatasi ke board yang boleh dibaca;
+			// scope token tetap dicek
+			return RequireScope(action)(c)
 		}
 		uid, err := utils.UserIDFromCtx(c)
 		if err != nil {
@@ -73,9 +63,84 @@ func BoardAccessByBoardQuery(queryKey string) fiber.Handler {
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid board id"})
 		}
+		return checkBoard(c, uid, bid, action)
+	}
+}
+
+// DeletedTaskAccessByPath: seperti BoardAccessByTaskPath, untuk task di trash
+// (restore). Board boleh ikut di trash; service yang menolak dengan pesan jelas.
+func DeletedTaskAccessByPath(taskParam string, action authz.Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, err := utils.UserIDFromCtx(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		tid, err := primitive.ObjectIDFromHex(c.Params(taskParam))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid task id"})
+		}
 		ctx, cancel := authz.WithTimeout(c.Context())
 		defer cancel()
-		ok, e := authz.IsMemberOrOwner(ctx, bid, uid)
+		bid, e := authz.BoardIDFromDeletedTask(ctx, tid)
+		if e != nil {
+			return c.Status(404).JSON(fiber.Map{"error": "task not found in trash"})
+		}
+		return checkRole(c, action, func(ctx context.Context) (models.BoardRole, bool, error) {
+			return authz.RoleOfAny(ctx, uid, bid)
+		})
+	}
+}
+
+// DeletedBoardAccessByPath: guard untuk board yang ada di trash (restore)
+func DeletedBoardAccessByPath(param string, action authz.Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, err := utils.UserIDFromCtx(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		bid, err := primitive.ObjectIDFromHex(c.Params(param))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid board id"})
+		}
+		return checkRole(c, action, func(ctx context.Context) (models.BoardRole, bool, error) {
+			return authz.RoleOfDeleted(ctx, uid, bid)
+		})
+	}
+}
+
+// Guard ubah/hapus note: author (note:write) atau moderator board (note:moderate)
+func NoteAccessByPath(param string) fiber.Handler {
+	return noteAccess(param, authz.CanEditNote, "note not found")
+}
+
+// DeletedNoteAccessByPath: aturan NoteAccessByPath untuk note di trash (restore)
+func DeletedNoteAccessByPath(param string) fiber.Handler {
+	return noteAccess(param, authz.CanRestoreNote, "note not found in trash")
+}
+
+func noteAccess(param string, can func(context.Context, primitive.ObjectID, primitive.ObjectID) (bool, error), notFound string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, err := utils.UserIDFromCtx(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		nid, err := primitive.ObjectIDFromHex(c.Params(param))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid note id"})
+		}
+		// scope token: cukup note:write; role (author vs moderator) dicek di bawah
+		if !ScopeAllows(c, authz.NoteWrite) {
+			return c.Status(403).JSON(fiber.Map{"error": "token scope does not allow this action", "required": authz.NoteWrite})
+		}
+		ctx, cancel := authz.WithTimeout(c.Context())
+		defer cancel()
+		ok, e := can(ctx, uid, nid)
+		if errors.Is(e, repository.ErrNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": notFound})
+		}
+		if errors.Is(e, authz.ErrMFARequired) {
+			return c.Status(403).JSON(fiber.Map{"error": e.Error()})
+		}
 		if e != nil {
 			return c.Status(500).JSON(fiber.Map{"error": e.Error()})
 		}
@@ -85,3 +150,34 @@ func BoardAccessByBoardQuery(queryKey string) fiber.Handler {
 		return c.Next()
 	}
 }
+
+func checkBoard(c *fiber.Ctx, uid, bid primitive.ObjectID, action authz.Action) error {
+	return checkRole(c, action, func(ctx context.Context) (models.BoardRole, bool, error) {
+		return authz.RoleOf(ctx, uid, bid)
+	})
+}
+
+// checkRole: scope token + role dari roleOf harus mengizinkan action
+func checkRole(c *fiber.Ctx, action authz.Action, roleOf func(ctx context.Context) (models.BoardRole, bool, error)) error {
+	if !ScopeAllows(c, action) {
+		return c.Status(403).JSON(fiber.Map{"error": "token scope does not allow this action", "required": action})
+	}
+	ctx, cancel := authz.WithTimeout(c.Context())
+	defer cancel()
+	role, member, e := roleOf(ctx)
+	if errors.Is(e, repository.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "board not found"})
+	}
+	if errors.Is(e, authz.ErrMFARequired) {
+		return c.Status(403).JSON(fiber.Map{"error": e.Error()})
+	}
+	if e != nil {
+		return c.Status(500).JSON(fiber.Map{"error": e.Error()})
+	}
+	if !member || !authz.RoleAllows(role, action) {
+		return c.Status(403).JSON(fiber.Map{"error": "forbidden", "required": action})
+	}
+	// role dipakai handler untuk cek lanjutan (mis. ubah member)
+	c.Locals("boardRole", role)
+	return c.Next()
+}
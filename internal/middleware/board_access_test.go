@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// boardWorld: satu board dengan user untuk setiap role, satu task, dan note
+// milik commenter. authz memakai repository paket global, jadi test di paket
+// ini tidak dijalankan paralel.
+type boardWorld struct {
+	repos *repository.Repos
+	board *models.Board
+	users map[models.BoardRole]primitive.ObjectID
+	task  *models.Task
+	note  *models.Note
+}
+
+func newBoardWorld(t *testing.T) *boardWorld {
+	t.Helper()
+	ctx := context.Background()
+	w := &boardWorld{repos: repository.NewMemory(), users: map[models.BoardRole]primitive.ObjectID{}}
+	authz.Init(w.repos)
+	for _, r := range []models.BoardRole{models.BoardRoleOwner, models.BoardRoleAdmin, models.BoardRoleEditor, models.BoardRoleCommenter, models.BoardRoleViewer} {
+		w.users[r] = primitive.NewObjectID()
+	}
+	w.board = &models.Board{ID: primitive.NewObjectID(), OwnerID: w.users[models.BoardRoleOwner], Version: 1}
+	for _, r := range []models.BoardRole{models.BoardRoleAdmin, models.BoardRoleEditor, models.BoardRoleCommenter, models.BoardRoleViewer} {
+		w.board.Members = append(w.board.Members, w.users[r])
+		w.board.MemberRoles = append(w.board.MemberRoles, models.BoardMember{UserID: w.users[r], Role: r})
+	}
+	w.task = &models.Task{ID: primitive.NewObjectID(), BoardID: w.board.ID, ColumnID: "todo", Version: 1}
+	w.note = &models.Note{ID: primitive.NewObjectID(), BoardID: &w.board.ID, AuthorID: w.users[models.BoardRoleCommenter], Version: 1}
+	if err := w.repos.Boards.Insert(ctx, w.board); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.repos.Tasks.Insert(ctx, w.task); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.repos.Notes.Insert(ctx, w.note); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// status: kode HTTP untuk request user lewat guard; 200 = guard meloloskan
+func status(t *testing.T, user primitive.ObjectID, guard fiber.Handler, route, path string) int {
+	t.Helper()
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if !user.IsZero() {
+			c.Locals("userId", user.Hex())
+		}
+		return c.Next()
+	})
+	app.Get(route, guard, func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("boardRole").(models.BoardRole); !ok && c.Params("note") == "" {
+			return c.Status(500).SendString("boardRole not set")
+		}
+		return c.SendStatus(200)
+	})
+	res, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode
+}
+
+func TestBoardAccessFollowsRoles(t *testing.T) {
+	w := newBoardWorld(t)
+	allowed := map[authz.Action][]models.BoardRole{
+		authz.BoardRead:    {models.BoardRoleOwner, models.BoardRoleAdmin, models.BoardRoleEditor, models.BoardRoleCommenter, models.BoardRoleViewer},
+		authz.BoardUpdate:  {models.BoardRoleOwner, models.BoardRoleAdmin},
+		authz.BoardMembers: {models.BoardRoleOwner, models.BoardRoleAdmin},
+		authz.BoardDelete:  {models.BoardRoleOwner},
+		authz.TaskWrite:    {models.BoardRoleOwner, models.BoardRoleAdmin, models.BoardRoleEditor},
+		authz.NoteWrite:    {models.BoardRoleOwner, models.BoardRoleAdmin, models.BoardRoleEditor, models.BoardRoleCommenter},
+	}
+	for action, roles := range allowed {
+		ok := map[models.BoardRole]bool{}
+		for _, r := range roles {
+			ok[r] = true
+		}
+		for role, user := range w.users {
+			want := 403
+			if ok[role] {
+				want = 200
+			}
+			byBoard := status(t, user, BoardAccessByBoardPath("id", action), "/boards/:id", "/boards/"+w.board.ID.Hex())
+			byTask := status(t, user, BoardAccessByTaskPath("id", action), "/tasks/:id", "/tasks/"+w.task.ID.Hex())
+			if byBoard != want || byTask != want {
+				t.Errorf("%s %s: board path %d, task path %d, want %d", role, action, byBoard, byTask, want)
+			}
+		}
+	}
+}
+
+func TestBoardAccessRejects(t *testing.T) {
+	w := newBoardWorld(t)
+	owner := w.users[models.BoardRoleOwner]
+	cases := []struct {
+		name  string
+		user  primitive.ObjectID
+		guard fiber.Handler
+		route string
+		path  string
+		want  int
+	}{
+		{"not logged in", primitive.NilObjectID, BoardAccessByBoardPath("id", authz.BoardRead), "/boards/:id", "/boards/" + w.board.ID.Hex(), 401},
+		{"outsider", primitive.NewObjectID(), BoardAccessByBoardPath("id", authz.BoardRead), "/boards/:id", "/boards/" + w.board.ID.Hex(), 403},
+		{"invalid board id", owner, BoardAccessByBoardPath("id", authz.BoardRead), "/boards/:id", "/boards/nope", 400},
+		{"unknown board", owner, BoardAccessByBoardPath("id", authz.BoardRead), "/boards/:id", "/boards/" + primitive.NewObjectID().Hex(), 404},
+		{"unknown task", owner, BoardAccessByTaskPath("id", authz.TaskRead), "/tasks/:id", "/tasks/" + primitive.NewObjectID().Hex(), 404},
+		{"outsider by query", primitive.NewObjectID(), BoardAccessByBoardQuery("boardId", authz.TaskRead), "/timeline", "/timeline?boardId=" + w.board.ID.Hex(), 403},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := status(t, tc.user, tc.guard, tc.route, tc.path); got != tc.want {
+				t.Errorf("status %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// note: author cukup note:write, orang lain butuh note:moderate
+func TestNoteAccess(t *testing.T) {
+	w := newBoardWorld(t)
+	want := map[models.BoardRole]int{
+		models.BoardRoleOwner:     200,
+		models.BoardRoleAdmin:     200,
+		models.BoardRoleEditor:    200,
+		models.BoardRoleCommenter: 200, // author
+		models.BoardRoleViewer:    403,
+	}
+	for role, code := range want {
+		if got := status(t, w.users[role], NoteAccessByPath("note"), "/notes/:note", "/notes/"+w.note.ID.Hex()); got != code {
+			t.Errorf("%s: status %d, want %d", role, got, code)
+		}
+	}
+
+	// commenter lain tidak boleh mengubah note commenter pertama
+	other := primitive.NewObjectID()
+	members := append(w.board.MemberRoles, models.BoardMember{UserID: other, Role: models.BoardRoleCommenter})
+	err := w.repos.Boards.Update(context.Background(), w.board.ID, map[string]interface{}{
+		"members":     append(w.board.Members, other),
+		"memberRoles": members,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status(t, other, NoteAccessByPath("note"), "/notes/:note", "/notes/"+w.note.ID.Hex()); got != 403 {
+		t.Errorf("other commenter: status %d, want 403", got)
+	}
+	if got := status(t, other, NoteAccessByPath("note"), "/notes/:note", fmt.Sprintf("/notes/%s", primitive.NewObjectID().Hex())); got != 404 {
+		t.Errorf("unknown note: status %d, want 404", got)
+	}
+}
+
+// board dengan requireMfa: member tanpa 2FA ditolak sampai 2FA aktif
+func TestBoardRequiresMFA(t *testing.T) {
+	ctx := context.Background()
+	w := newBoardWorld(t)
+	if err := w.repos.Boards.Update(ctx, w.board.ID, map[string]interface{}{"requireMfa": true}); err != nil {
+		t.Fatal(err)
+	}
+	editor := w.users[models.BoardRoleEditor]
+	path := "/boards/" + w.board.ID.Hex()
+	if got := status(t, editor, BoardAccessByBoardPath("id", authz.BoardRead), "/boards/:id", path); got != 403 {
+		t.Fatalf("editor without 2FA: status %d, want 403", got)
+	}
+	if got := status(t, editor, NoteAccessByPath("note"), "/notes/:note", "/notes/"+w.note.ID.Hex()); got != 403 {
+		t.Errorf("editor without 2FA on a note: status %d, want 403", got)
+	}
+
+	now := time.Now().UTC()
+	err := w.repos.Users.Insert(ctx, &models.User{ID: editor, Email: "editor@x.io", MFA: &models.UserMFA{Secret: "S", EnabledAt: &now}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status(t, editor, BoardAccessByBoardPath("id", authz.BoardRead), "/boards/:id", path); got != 200 {
+		t.Errorf("editor with 2FA: status %d, want 200", got)
+	}
+}
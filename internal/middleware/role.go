@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole: hanya user aktif dengan salah satu role global ini.
+// Dipasang setelah JWTProtected.
+func RequireRole(roles ...models.UserRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, err := utils.UserIDFromCtx(c)
+		if err != nil {
+			return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		ctx, cancel := authz.WithTimeout(c.Context())
+		defer cancel()
+		ok, err := authz.HasRole(ctx, uid, roles...)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if !ok {
+			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
+		}
+		return c.Next()
+	}
+}
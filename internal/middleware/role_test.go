@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRequireRole(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	authz.Init(repos)
+	seed := func(role models.UserRole, active bool) primitive.ObjectID {
+		u := &models.User{ID: primitive.NewObjectID(), Email: primitive.NewObjectID().Hex() + "@x.io", Role: role, IsActive: active}
+		if err := repos.Users.Insert(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		return u.ID
+	}
+
+	cases := []struct {
+		name string
+		user primitive.ObjectID
+		want int
+	}{
+		{"admin", seed(models.RoleAdmin, true), 200},
+		{"user", seed(models.RoleUser, true), 403},
+		// admin yang dinonaktifkan kehilangan akses konsol
+		{"inactive admin", seed(models.RoleAdmin, false), 403},
+		{"unknown user", primitive.NewObjectID(), 403},
+		{"not logged in", primitive.NilObjectID, 401},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if !tc.user.IsZero() {
+					c.Locals("userId", tc.user.Hex())
+				}
+				return c.Next()
+			})
+			app.Get("/admin", RequireRole(models.RoleAdmin), func(c *fiber.Ctx) error {
+				return c.SendStatus(200)
+			})
+			res, err := app.Test(httptest.NewRequest("GET", "/admin", nil), -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tc.want {
+				t.Errorf("status %d, want %d", res.StatusCode, tc.want)
+			}
+		})
+	}
+}
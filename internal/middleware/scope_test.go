@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scopeApp: route board seperti di routes.Register, di belakang JWTProtected
+func scopeApp(t *testing.T, w *boardWorld) (*fiber.App, services.SessionService, services.AccessTokenService) {
+	t.Helper()
+	loadTestJWTKeys(t)
+	sessions := services.NewSessionService(w.repos.Sessions, time.Minute, time.Hour)
+	tokens := services.NewAccessTokenService(w.repos.Tokens, w.repos.Users)
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(200) }
+	app := fiber.New()
+	prot := app.Group("", JWTProtected(sessions, tokens))
+	prot.Post("/boards", RequireScope(authz.BoardCreate), ok)
+	prot.Get("/boards/:id", BoardAccessByBoardPath("id", authz.BoardRead), ok)
+	prot.Patch("/boards/:id", BoardAccessByBoardPath("id", authz.BoardUpdate), ok)
+	prot.Patch("/tasks/:id", BoardAccessByTaskPath("id", authz.TaskWrite), ok)
+	prot.Patch("/notes/:note", NoteAccessByPath("note"), ok)
+	prot.Post("/me/password", SessionOnly(), ok)
+	return app, sessions, tokens
+}
+
+func TestAccessTokenScopes(t *testing.T) {
+	ctx := context.Background()
+	w := newBoardWorld(t)
+	app, sessions, tokens := scopeApp(t, w)
+	owner := w.users[models.BoardRoleOwner]
+	editor := w.users[models.BoardRoleEditor]
+	for _, id := range []primitive.ObjectID{owner, editor} {
+		if err := w.repos.Users.Insert(ctx, &models.User{ID: id, Email: id.Hex() + "@x.io", IsActive: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bearer := func(user primitive.ObjectID, scope models.TokenScope) string {
+		if scope == "" {
+			pair, err := sessions.Start(ctx, user, services.SessionMeta{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return "Bearer " + pair.AccessToken
+		}
+		_, plain, err := tokens.Create(ctx, user, string(scope), []models.TokenScope{scope}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + plain
+	}
+
+	board := "/boards/" + w.board.ID.Hex()
+	task := "/tasks/" + w.task.ID.Hex()
+	note := "/notes/" + w.note.ID.Hex()
+	cases := []struct {
+		name         string
+		auth         string
+		method, path string
+		want         int
+	}{
+		{"read token reads", bearer(owner, models.ScopeRead), "GET", board, 200},
+		{"read token cannot write tasks", bearer(owner, models.ScopeRead), "PATCH", task, 403},
+		{"read token cannot create boards", bearer(owner, models.ScopeRead), "POST", "/boards", 403},
+		{"tasks token writes tasks", bearer(owner, models.ScopeTasksWrite), "PATCH", task, 200},
+		{"tasks token cannot edit the board", bearer(owner, models.ScopeTasksWrite), "PATCH", board, 403},
+		{"tasks token cannot write notes", bearer(owner, models.ScopeTasksWrite), "PATCH", note, 403},
+		{"admin token edits the board", bearer(owner, models.ScopeBoardsAdmin), "PATCH", board, 200},
+		{"admin token creates boards", bearer(owner, models.ScopeBoardsAdmin), "POST", "/boards", 200},
+		{"admin token moderates notes", bearer(owner, models.ScopeBoardsAdmin), "PATCH", note, 200},
+		// scope tidak pernah menambah izin role
+		{"admin token of an editor", bearer(editor, models.ScopeBoardsAdmin), "PATCH", board, 403},
+		{"admin token on account routes", bearer(owner, models.ScopeBoardsAdmin), "POST", "/me/password", 403},
+		{"session creates boards", bearer(owner, ""), "POST", "/boards", 200},
+		{"session on account routes", bearer(owner, ""), "POST", "/me/password", 200},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", tc.auth)
+			res, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != tc.want {
+				t.Errorf("%s %s = %d, want %d", tc.method, tc.path, res.StatusCode, tc.want)
+			}
+		})
+	}
+}
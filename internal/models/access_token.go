@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenScope: batas akses personal access token (di atas role user di board)
+type TokenScope string
+
+const (
+	ScopeRead        TokenScope = "read"         // hanya baca
+	ScopeTasksWrite  TokenScope = "tasks:write"  // baca + buat/ubah/pindah task
+	ScopeBoardsAdmin TokenScope = "boards:admin" // semua aksi board, task & note
+)
+
+func ValidTokenScope(s TokenScope) bool {
+	switch s {
+	case ScopeRead, ScopeTasksWrite, ScopeBoardsAdmin:
+		return true
+	}
+	return false
+}
+
+// AccessToken: personal access token untuk skrip/bot ("pat_..."). Hanya
+// hash-nya yang disimpan; Prefix membantu user mengenali token di daftar.
+type AccessToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"userId" json:"-"`
+	Name       string             `bson:"name" json:"name"`
+	Scopes     []TokenScope       `bson:"scopes" json:"scopes"`
+	TokenHash  string             `bson:"tokenHash" json:"-"`
+	Prefix     string             `bson:"prefix" json:"prefix"`
+	ExpiresAt  *time.Time         `bson:"expiresAt,omitempty" json:"expiresAt"` // nil = tidak kedaluwarsa
+	LastUsedAt *time.Time         `bson:"lastUsedAt,omitempty" json:"lastUsedAt"`
+	RevokedAt  *time.Time         `bson:"revokedAt,omitempty" json:"-"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+func (t *AccessToken) CollectionName() string { return "access_tokens" }
+
+// Active: belum dicabut dan belum kedaluwarsa
+func (t *AccessToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
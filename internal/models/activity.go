@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ActivityEntity string
+type ActivityAction string
+
+const (
+	ActivityBoard ActivityEntity = "board"
+	ActivityTask  ActivityEntity = "task"
+	ActivityNote  ActivityEntity = "note"
+
+	ActivityCreated  ActivityAction = "created"
+	ActivityUpdated  ActivityAction = "updated"
+	ActivityMoved    ActivityAction = "moved"
+	ActivityDeleted  ActivityAction = "deleted"
+	ActivityRestored ActivityAction = "restored" // keluar dari trash
+)
+
+// FieldChange: nilai satu field (nama field JSON API) sebelum & sesudah.
+// Nilai disimpan sebagai JSON apa adanya; kosong = field tidak ada.
+type FieldChange struct {
+	Field  string `bson:"field"`
+	Before string `bson:"before,omitempty"`
+	After  string `bson:"after,omitempty"`
+}
+
+func (f FieldChange) MarshalJSON() ([]byte, error) {
+	raw := func(s string) json.RawMessage {
+		if s == "" {
+			return json.RawMessage("null")
+		}
+		return json.RawMessage(s)
+	}
+	return json.Marshal(struct {
+		Field  string          `json:"field"`
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}{f.Field, raw(f.Before), raw(f.After)})
+}
+
+// Activity: riwayat perubahan board/task/note (append-only)
+type Activity struct {
+	ID       primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BoardID  *primitive.ObjectID `bson:"boardId,omitempty" json:"boardId,omitempty"`
+	TaskID   *primitive.ObjectID `bson:"taskId,omitempty" json:"taskId,omitempty"` // task itu sendiri, atau task tempat note menempel
+	Entity   ActivityEntity      `bson:"entity" json:"entity"`
+	EntityID primitive.ObjectID  `bson:"entityId" json:"entityId"`
+	Action   ActivityAction      `bson:"action" json:"action"`
+	ActorID  primitive.ObjectID  `bson:"actorId" json:"actorId"`
+	Changes  []FieldChange       `bson:"changes,omitempty" json:"changes"`
+	// CreatedAt: _id juga naik sesuai waktu dan dipakai sebagai cursor
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+func (a *Activity) CollectionName() string { return "activities" }
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditEventType string
+
+const (
+	AuditLoginLocked   AuditEventType = "login.locked"
+	AuditLoginIPLocked AuditEventType = "login.ip_locked"
+	AuditLoginUnlocked AuditEventType = "login.unlocked"
+
+	// admin console
+	AuditUserRoleChanged    AuditEventType = "user.role_changed"
+	AuditUserDeactivated    AuditEventType = "user.deactivated"
+	AuditUserReactivated    AuditEventType = "user.reactivated"
+	AuditUserPasswordReset  AuditEventType = "user.password_reset_forced"
+	AuditBoardOwnerTransfer AuditEventType = "board.owner_transferred"
+)
+
+// AuditEvent: catatan kejadian keamanan (append-only)
+type AuditEvent struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Type      AuditEventType         `bson:"type" json:"type"`
+	ActorID   *primitive.ObjectID    `bson:"actorId,omitempty" json:"actorId,omitempty"`     // pelaku (mis. admin); kosong = sistem
+	SubjectID *primitive.ObjectID    `bson:"subjectId,omitempty" json:"subjectId,omitempty"` // user yang terdampak
+	Email     string                 `bson:"email,omitempty" json:"email,omitempty"`
+	IP        string                 `bson:"ip,omitempty" json:"ip,omitempty"`
+	Details   map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+}
+
+func (e *AuditEvent) CollectionName() string { return "audit_events" }
@@ -2,21 +2,67 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+type BoardRole string
+
+const (
+	BoardRoleOwner     BoardRole = "owner"
+	BoardRoleAdmin     BoardRole = "admin"
+	BoardRoleEditor    BoardRole = "editor"
+	BoardRoleCommenter BoardRole = "commenter"
+	BoardRoleViewer    BoardRole = "viewer"
+)
+
+// ValidMemberRole: role yang boleh diberikan ke member (owner tidak)
+func ValidMemberRole(r BoardRole) bool {
+	switch r {
+	case BoardRoleAdmin, BoardRoleEditor, BoardRoleCommenter, BoardRoleViewer:
+		return true
+	}
+	return false
+}
+
 type BoardColumn struct {
 	ID    string `bson:"id" json:"id"`
 	Name  string `bson:"name" json:"name"`
 	Order int    `bson:"order" json:"order"`
 }
 
+type BoardMember struct {
+	UserID primitive.ObjectID `bson:"userId" json:"userId"`
+	Role   BoardRole          `bson:"role" json:"role"`
+}
+
 type Board struct {
 	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
 	OwnerID     primitive.ObjectID   `bson:"ownerId" json:"ownerId"`
 	Name        string               `bson:"name" json:"name"`
 	Description *string              `bson:"description,omitempty" json:"description,omitempty"`
 	Members     []primitive.ObjectID `bson:"members" json:"members"`
+	MemberRoles []BoardMember        `bson:"memberRoles,omitempty" json:"memberRoles,omitempty"` // member tanpa entri = editor
 	Columns     []BoardColumn        `bson:"columns" json:"columns"`
 	IsArchived  bool                 `bson:"isArchived" json:"isArchived"`
+	RequireMFA  bool                 `bson:"requireMfa,omitempty" json:"requireMfa"` // semua member wajib 2FA
+	Version     int64                `bson:"version" json:"version"`                 // naik setiap update; dipakai ETag/If-Match
 	TimeMeta    `bson:",inline"`
+	Trash       `bson:",inline"`
 }
 
 func (b *Board) CollectionName() string { return "boards" }
+
+// RoleOf mengembalikan role user di board; false bila bukan owner/member
+func (b *Board) RoleOf(userID primitive.ObjectID) (BoardRole, bool) {
+	if b.OwnerID == userID {
+		return BoardRoleOwner, true
+	}
+	for _, m := range b.MemberRoles {
+		if m.UserID == userID {
+			return m.Role, true
+		}
+	}
+	for _, m := range b.Members {
+		if m == userID {
+			return BoardRoleEditor, true
+		}
+	}
+	return "", false
+}
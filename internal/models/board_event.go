@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BoardEvent: event realtime yang sudah dikirim, disimpan per board untuk
+// replay klien yang sempat terputus. Seq naik terus per board.
+type BoardEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	BoardID   primitive.ObjectID `bson:"boardId" json:"boardId"`
+	Seq       int64              `bson:"seq" json:"seq"`
+	Type      string             `bson:"type" json:"type"`
+	Data      string             `bson:"data" json:"-"` // payload JSON apa adanya
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+func (e *BoardEvent) CollectionName() string { return "board_events" }
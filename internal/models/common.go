@@ -1,8 +1,21 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type TimeMeta struct {
 	CreatedAt time.Time `bson:"createdAt,omitempty" json:"createdAt"`
 	UpdatedAt time.Time `bson:"updatedAt,omitempty" json:"updatedAt"`
 }
+
+// Trash: penanda soft delete (board/task/note). Dokumen dengan DeletedAt
+// terisi hanya terlihat lewat trash sampai dipulihkan atau di-purge.
+type Trash struct {
+	DeletedAt *time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+	DeletedBy *primitive.ObjectID `bson:"deletedBy,omitempty" json:"deletedBy,omitempty"`
+}
+
+func (t *Trash) InTrash() bool { return t.DeletedAt != nil }
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EmailTokenPurpose string
+
+const (
+	PurposePasswordReset EmailTokenPurpose = "password_reset"
+	PurposeVerifyEmail   EmailTokenPurpose = "verify_email"
+)
+
+// EmailToken: token sekali pakai yang dikirim lewat email; hanya hash-nya
+// yang disimpan.
+type EmailToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Purpose   EmailTokenPurpose  `bson:"purpose" json:"purpose"`
+	Email     string             `bson:"email" json:"email"` // alamat tujuan saat token dibuat
+	TokenHash string             `bson:"tokenHash" json:"-"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	UsedAt    *time.Time         `bson:"usedAt,omitempty" json:"usedAt,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+func (t *EmailToken) CollectionName() string { return "email_tokens" }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+	InvitationRevoked  InvitationStatus = "revoked"
+	InvitationExpired  InvitationStatus = "expired"
+)
+
+// Invitation: undangan ke board lewat email. Token hanya disimpan hash-nya.
+type Invitation struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BoardID    primitive.ObjectID  `bson:"boardId" json:"boardId"`
+	BoardName  string              `bson:"boardName" json:"boardName"`
+	Email      string              `bson:"email" json:"email"` // lowercase
+	Role       BoardRole           `bson:"role" json:"role"`
+	InvitedBy  primitive.ObjectID  `bson:"invitedBy" json:"invitedBy"`
+	TokenHash  string              `bson:"tokenHash" json:"-"`
+	Status     InvitationStatus    `bson:"status" json:"status"`
+	ExpiresAt  time.Time           `bson:"expiresAt" json:"expiresAt"`
+	AcceptedBy *primitive.ObjectID `bson:"acceptedBy,omitempty" json:"acceptedBy,omitempty"`
+	TimeMeta   `bson:",inline"`
+}
+
+func (i *Invitation) CollectionName() string { return "invitations" }
+
+// Expired: masih pending tapi sudah lewat ExpiresAt
+func (i *Invitation) Expired(now time.Time) bool {
+	return i.Status == InvitationPending && !now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginState: data sementara antara redirect login OIDC dan callback-nya
+type LoginState struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	State     string             `bson:"state" json:"-"`
+	Nonce     string             `bson:"nonce" json:"-"`
+	Verifier  string             `bson:"verifier" json:"-"` // PKCE code_verifier
+	Redirect  string             `bson:"redirect,omitempty" json:"redirect,omitempty"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+func (s *LoginState) CollectionName() string { return "login_states" }
@@ -14,7 +14,9 @@ type Note struct {
 	Content      string              `bson:"content" json:"content"`
 	Pinned       bool                `bson:"pinned" json:"pinned"`
 	OnTimelineAt *time.Time          `bson:"onTimelineAt,omitempty" json:"onTimelineAt,omitempty"`
+	Version      int64               `bson:"version" json:"version"` // naik setiap update; dipakai ETag/If-Match
 	TimeMeta     `bson:",inline"`
+	Trash        `bson:",inline"`
 }
 
 func (n *Note) CollectionName() string { return "notes" }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Presence: satu koneksi socket yang sedang membuka board. Dihapus saat
+// leave/disconnect, atau oleh sweeper bila heartbeat berhenti (ExpiresAt).
+type Presence struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"-"`
+	BoardID      primitive.ObjectID  `bson:"boardId" json:"boardId"`
+	UserID       primitive.ObjectID  `bson:"userId" json:"userId"`
+	ConnectionID string              `bson:"connectionId" json:"connectionId"` // unik lintas instance
+	TaskID       *primitive.ObjectID `bson:"taskId,omitempty" json:"taskId"`   // task yang sedang dibuka
+	Dragging     bool                `bson:"dragging" json:"dragging"`
+	LastSeen     time.Time           `bson:"lastSeen" json:"lastSeen"`
+	ExpiresAt    time.Time           `bson:"expiresAt" json:"-"`
+}
+
+func (p *Presence) CollectionName() string { return "presence" }
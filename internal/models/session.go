@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session: satu login (perangkat). Access token membawa ID session di claim
+// jti; refresh token dirotasi setiap dipakai dan hanya hash-nya yang disimpan.
+type Session struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID          primitive.ObjectID `bson:"userId" json:"userId"`
+	RefreshHash     string             `bson:"refreshHash" json:"-"`
+	PrevRefreshHash string             `bson:"prevRefreshHash,omitempty" json:"-"` // untuk deteksi reuse
+	UserAgent       string             `bson:"userAgent,omitempty" json:"userAgent,omitempty"`
+	IP              string             `bson:"ip,omitempty" json:"ip,omitempty"`
+	LastUsedAt      time.Time          `bson:"lastUsedAt" json:"lastUsedAt"`
+	ExpiresAt       time.Time          `bson:"expiresAt" json:"expiresAt"`
+	RevokedAt       *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	TimeMeta        `bson:",inline"`
+}
+
+func (s *Session) CollectionName() string { return "sessions" }
+
+// Active: belum dicabut dan refresh token belum kedaluwarsa
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -41,9 +41,12 @@ type Task struct {
 	Tags          []string             `bson:"tags,omitempty" json:"tags,omitempty"`
 	Attachments   []Attachment         `bson:"attachments,omitempty" json:"attachments,omitempty"`
 	Order         *int                 `bson:"order,omitempty" json:"order,omitempty"`
+	Rank          string               `bson:"rank,omitempty" json:"rank,omitempty"` // dipakai bila TASK_ORDERING=rank
 	CreatedBy     primitive.ObjectID   `bson:"createdBy" json:"createdBy"`
 	UpdatedBy     primitive.ObjectID   `bson:"updatedBy" json:"updatedBy"`
+	Version       int64                `bson:"version" json:"version"` // naik setiap update; dipakai ETag/If-Match
 	TimeMeta      `bson:",inline"`
+	Trash         `bson:",inline"`
 }
 
 func (t *Task) CollectionName() string { return "tasks" }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TaskDoc: deskripsi task yang sedang diedit bersama (OT). Dibuat dari
+// Task.Description saat pertama dibuka dan disalin balik secara berkala.
+type TaskDoc struct {
+	ID        primitive.ObjectID `bson:"_id" json:"taskId"` // = id task
+	BoardID   primitive.ObjectID `bson:"boardId" json:"boardId"`
+	Session   primitive.ObjectID `bson:"session" json:"session"` // baru tiap dokumen dibuat ulang; rev sesi lama tidak berlaku
+	Text      string             `bson:"text" json:"text"`
+	Rev       int64              `bson:"rev" json:"rev"`
+	SavedRev  int64              `bson:"savedRev" json:"-"` // rev terakhir yang sudah disalin ke task
+	UpdatedBy primitive.ObjectID `bson:"updatedBy" json:"updatedBy"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+func (d *TaskDoc) CollectionName() string { return "task_docs" }
+
+// TaskDocOp: satu operasi yang sudah diterapkan; dipakai untuk transform op
+// klien yang dibuat di atas rev lama
+type TaskDocOp struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	TaskID    primitive.ObjectID `bson:"taskId"`
+	Rev       int64              `bson:"rev"` // rev dokumen setelah op ini
+	Op        string             `bson:"op"`  // JSON ot.Op
+	UserID    primitive.ObjectID `bson:"userId"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+func (o *TaskDocOp) CollectionName() string { return "task_doc_ops" }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -11,6 +13,8 @@ const (
 	RoleAdmin UserRole = "admin"
 )
 
+func ValidUserRole(r UserRole) bool { return r == RoleUser || r == RoleAdmin }
+
 type User struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Email        string             `bson:"email" json:"email"`
@@ -19,7 +23,26 @@ type User struct {
 	AvatarURL    *string            `bson:"avatarUrl,omitempty" json:"avatarUrl,omitempty"`
 	Role         UserRole           `bson:"role" json:"role"`
 	IsActive     bool               `bson:"isActive" json:"isActive"`
-	TimeMeta     `bson:",inline"`
+	// EmailVerifiedAt: nil = email belum dikonfirmasi
+	EmailVerifiedAt *time.Time `bson:"emailVerifiedAt,omitempty" json:"emailVerifiedAt,omitempty"`
+	// PasswordResetRequired: dipaksa admin; login password ditolak sampai reset
+	PasswordResetRequired bool     `bson:"passwordResetRequired,omitempty" json:"passwordResetRequired,omitempty"`
+	MFA                   *UserMFA `bson:"mfa,omitempty" json:"-"`
+	TimeMeta              `bson:",inline"`
+}
+
+// UserMFA: 2FA TOTP. PendingSecret terisi selama enrollment belum dikonfirmasi.
+type UserMFA struct {
+	Secret         string     `bson:"secret,omitempty"`
+	PendingSecret  string     `bson:"pendingSecret,omitempty"`
+	EnabledAt      *time.Time `bson:"enabledAt,omitempty"`
+	RecoveryHashes []string   `bson:"recoveryHashes,omitempty"` // sha256 kode pemulihan yang belum dipakai
+	LastStep       int64      `bson:"lastStep,omitempty"`       // langkah TOTP terakhir yang dipakai (anti replay)
 }
 
 func (u *User) CollectionName() string { return "users" }
+
+// MFAEnabled: user sudah mengaktifkan 2FA
+func (u *User) MFAEnabled() bool {
+	return u.MFA != nil && u.MFA.EnabledAt != nil && u.MFA.Secret != ""
+}
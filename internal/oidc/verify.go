@@ -0,0 +1,198 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims: isi ID token yang dipakai untuk login
+type Claims struct {
+	jwt.RegisteredClaims
+	Email         string          `json:"email"`
+	EmailVerified json.RawMessage `json:"email_verified"` // bool, kadang string "true"
+	Name          string          `json:"name"`
+	Nonce         string          `json:"nonce"`
+	AZP           string          `json:"azp"`
+}
+
+// Verified: email_verified true; tidak ada claim = dianggap belum terverifikasi
+func (c *Claims) Verified() bool {
+	v := strings.Trim(string(c.EmailVerified), `"`)
+	return v == "true"
+}
+
+// asimetris saja; HS* (client secret) sengaja tidak diterima
+var asymmetricAlgs = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// jwksRefreshInterval: kid tak dikenal memicu fetch ulang paling sering segini
+const jwksRefreshInterval = time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+
+	key interface{}
+}
+
+// Verify memeriksa tanda tangan, iss, aud, exp dan nonce ID token
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, err := p.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if !k.allows(t.Method.Alg()) {
+			return nil, fmt.Errorf("oidc: alg %s not allowed for key %q", t.Method.Alg(), kid)
+		}
+		return k.key, nil
+	},
+		jwt.WithValidMethods(asymmetricAlgs),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token: %w", err)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("oidc: nonce mismatch")
+	}
+	if len(claims.Audience) > 1 && claims.AZP != p.cfg.ClientID {
+		return nil, errors.New("oidc: azp mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("oidc: missing sub")
+	}
+	return claims, nil
+}
+
+// key mencari kid di cache; bila tidak ada, JWKS diambil ulang (rotasi provider)
+func (p *Provider) key(ctx context.Context, kid string) (*jwk, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k := p.lookup(kid); k != nil {
+		return k, nil
+	}
+	if !p.fetchedAt.IsZero() && time.Since(p.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("oidc: unknown key id %q", kid)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.meta.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+	p.fetchedAt = time.Now()
+	p.keys = map[string]jwk{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if err := k.parse(); err != nil {
+			continue // key yang tidak didukung dilewati
+		}
+		p.keys[k.Kid] = k
+	}
+	if k := p.lookup(kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("oidc: unknown key id %q", kid)
+}
+
+// lookup: kid kosong hanya boleh bila provider punya tepat satu key
+func (p *Provider) lookup(kid string) *jwk {
+	if k, ok := p.keys[kid]; ok {
+		return &k
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return &k
+		}
+	}
+	return nil
+}
+
+func (k *jwk) parse() error {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := b64Int(k.N)
+		e, err2 := b64Int(k.E)
+		if err1 != nil || err2 != nil || !e.IsInt64() {
+			return errors.New("bad RSA key")
+		}
+		k.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err1 := b64Int(k.X)
+		y, err2 := b64Int(k.Y)
+		if err1 != nil || err2 != nil {
+			return errors.New("bad EC key")
+		}
+		k.key = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return errors.New("bad OKP key")
+		}
+		k.key = ed25519.PublicKey(x)
+	default:
+		return fmt.Errorf("unsupported kty %q", k.Kty)
+	}
+	return nil
+}
+
+// allows: alg token harus cocok dengan alg key (atau keluarganya bila JWK tanpa alg)
+func (k *jwk) allows(alg string) bool {
+	if k.Alg != "" {
+		return k.Alg == alg
+	}
+	switch k.key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return alg == map[string]string{"P-256": "ES256", "P-384": "ES384", "P-521": "ES512"}[k.Crv]
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
+
+func b64Int(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("bad base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
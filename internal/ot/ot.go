@@ -0,0 +1,275 @@
+// Package ot: operational transformation untuk teks, format operasi sama
+// dengan ot.js supaya klien browser bisa langsung memakainya.
+//
+// Operasi dalam JSON berupa array komponen: angka positif = retain n
+// karakter, string = insert, angka negatif = delete n karakter. Panjang
+// dihitung dalam unit UTF-16 (sama dengan String.length di JavaScript).
+package ot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+)
+
+var (
+	ErrInvalidOp  = errors.New("ot: invalid operation")
+	ErrBaseLength = errors.New("ot: operation does not match document length")
+)
+
+// component: tepat satu dari retain (>0), delete (>0) atau insert (!= "")
+type component struct {
+	retain int
+	delete int
+	insert []uint16
+}
+
+// Op: urutan komponen yang sudah dinormalisasi (retain/delete berurutan
+// digabung, insert selalu sebelum delete di posisi yang sama)
+type Op struct {
+	ops []component
+}
+
+func (o *Op) Retain(n int) *Op {
+	if n <= 0 {
+		return o
+	}
+	if last := o.last(); last != nil && last.retain > 0 {
+		last.retain += n
+		return o
+	}
+	o.ops = append(o.ops, component{retain: n})
+	return o
+}
+
+func (o *Op) Insert(s string) *Op {
+	return o.insertUnits(utf16.Encode([]rune(s)))
+}
+
+func (o *Op) insertUnits(s []uint16) *Op {
+	if len(s) == 0 {
+		return o
+	}
+	last := o.last()
+	switch {
+	case last != nil && last.insert != nil:
+		last.insert = append(last.insert, s...)
+	case last != nil && last.delete > 0:
+		// insert sebelum delete: hasilnya sama, bentuknya kanonik
+		if n := len(o.ops); n > 1 && o.ops[n-2].insert != nil {
+			o.ops[n-2].insert = append(o.ops[n-2].insert, s...)
+		} else {
+			o.ops = append(o.ops[:n-1], component{insert: append([]uint16(nil), s...)}, *last)
+		}
+	default:
+		o.ops = append(o.ops, component{insert: append([]uint16(nil), s...)})
+	}
+	return o
+}
+
+func (o *Op) Delete(n int) *Op {
+	if n <= 0 {
+		return o
+	}
+	if last := o.last(); last != nil && last.delete > 0 {
+		last.delete += n
+		return o
+	}
+	o.ops = append(o.ops, component{delete: n})
+	return o
+}
+
+func (o *Op) last() *component {
+	if len(o.ops) == 0 {
+		return nil
+	}
+	return &o.ops[len(o.ops)-1]
+}
+
+// BaseLen: panjang dokumen yang diharapkan op
+func (o Op) BaseLen() int {
+	n := 0
+	for _, c := range o.ops {
+		n += c.retain + c.delete
+	}
+	return n
+}
+
+// TargetLen: panjang dokumen setelah op diterapkan
+func (o Op) TargetLen() int {
+	n := 0
+	for _, c := range o.ops {
+		n += c.retain + len(c.insert)
+	}
+	return n
+}
+
+// IsNoop: tidak mengubah dokumen (kosong atau hanya retain)
+func (o Op) IsNoop() bool {
+	return len(o.ops) == 0 || (len(o.ops) == 1 && o.ops[0].retain > 0)
+}
+
+// Apply menerapkan op ke doc
+func (o Op) Apply(doc string) (string, error) {
+	src := utf16.Encode([]rune(doc))
+	if len(src) != o.BaseLen() {
+		return "", ErrBaseLength
+	}
+	out := make([]uint16, 0, o.TargetLen())
+	i := 0
+	for _, c := range o.ops {
+		switch {
+		case c.retain > 0:
+			out = append(out, src[i:i+c.retain]...)
+			i += c.retain
+		case c.delete > 0:
+			i += c.delete
+		default:
+			out = append(out, c.insert...)
+		}
+	}
+	return string(utf16.Decode(out)), nil
+}
+
+// Transform: a dan b dibuat di atas dokumen yang sama; hasilnya a' dan b'
+// sehingga apply(apply(doc, a), b') == apply(apply(doc, b), a'). Bila
+// keduanya insert di posisi yang sama, insert a didahulukan.
+func Transform(a, b Op) (Op, Op, error) {
+	if a.BaseLen() != b.BaseLen() {
+		return Op{}, Op{}, ErrBaseLength
+	}
+	var a1, b1 Op
+	ia, ib := 0, 0
+	var ca, cb *component
+	next := func(ops []component, i *int) *component {
+		if *i >= len(ops) {
+			return nil
+		}
+		c := ops[*i] // salinan; sisa retain/delete dikurangi di sini
+		*i++
+		return &c
+	}
+	ca, cb = next(a.ops, &ia), next(b.ops, &ib)
+	for ca != nil || cb != nil {
+		if ca != nil && ca.insert != nil {
+			a1.insertUnits(ca.insert)
+			b1.Retain(len(ca.insert))
+			ca = next(a.ops, &ia)
+			continue
+		}
+		if cb != nil && cb.insert != nil {
+			a1.Retain(len(cb.insert))
+			b1.insertUnits(cb.insert)
+			cb = next(b.ops, &ib)
+			continue
+		}
+		if ca == nil || cb == nil {
+			return Op{}, Op{}, ErrInvalidOp
+		}
+		lenA, lenB := ca.retain+ca.delete, cb.retain+cb.delete
+		n := min(lenA, lenB)
+		switch {
+		case ca.retain > 0 && cb.retain > 0:
+			a1.Retain(n)
+			b1.Retain(n)
+		case ca.delete > 0 && cb.retain > 0:
+			a1.Delete(n)
+		case ca.retain > 0 && cb.delete > 0:
+			b1.Delete(n)
+		}
+		// delete/delete: bagian yang sama sudah dihapus kedua sisi
+		ca = shrink(ca, n, a.ops, &ia, next)
+		cb = shrink(cb, n, b.ops, &ib, next)
+	}
+	return a1, b1, nil
+}
+
+// shrink mengurangi retain/delete c sebanyak n; habis → komponen berikutnya
+func shrink(c *component, n int, ops []component, i *int, next func([]component, *int) *component) *component {
+	if c.retain > 0 {
+		c.retain -= n
+		if c.retain > 0 {
+			return c
+		}
+	} else {
+		c.delete -= n
+		if c.delete > 0 {
+			return c
+		}
+	}
+	return next(ops, i)
+}
+
+// Replace: op yang mengubah old menjadi new, hanya menyentuh bagian yang
+// berbeda (prefix & suffix yang sama dipertahankan)
+func Replace(old, new string) Op {
+	a, b := utf16.Encode([]rune(old)), utf16.Encode([]rune(new))
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		p++
+	}
+	// jangan memotong surrogate pair: separuhnya tidak bisa dikirim sebagai
+	// string JSON (jadi U+FFFD) sehingga dokumen klien berbeda dari server
+	if p > 0 && isHighSurrogate(a[p-1]) {
+		p--
+	}
+	s := 0
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	if s > 0 && isLowSurrogate(a[len(a)-s]) {
+		s--
+	}
+	var o Op
+	o.Retain(p).insertUnits(b[p : len(b)-s])
+	o.Delete(len(a) - p - s).Retain(s)
+	return o
+}
+
+func isHighSurrogate(u uint16) bool { return u >= 0xD800 && u < 0xDC00 }
+func isLowSurrogate(u uint16) bool  { return u >= 0xDC00 && u < 0xE000 }
+
+func (o Op) MarshalJSON() ([]byte, error) {
+	out := make([]interface{}, 0, len(o.ops))
+	for _, c := range o.ops {
+		switch {
+		case c.retain > 0:
+			out = append(out, c.retain)
+		case c.delete > 0:
+			out = append(out, -c.delete)
+		default:
+			out = append(out, string(utf16.Decode(c.insert)))
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (o *Op) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return ErrInvalidOp
+	}
+	var out Op
+	for _, r := range raw {
+		var s string
+		if err := json.Unmarshal(r, &s); err == nil {
+			if s == "" {
+				return fmt.Errorf("%w: empty insert", ErrInvalidOp)
+			}
+			out.Insert(s)
+			continue
+		}
+		var n int
+		if err := json.Unmarshal(r, &n); err != nil || n == 0 {
+			return fmt.Errorf("%w: component must be a non-zero integer or a string", ErrInvalidOp)
+		}
+		if n > 0 {
+			out.Retain(n)
+		} else {
+			out.Delete(-n)
+		}
+	}
+	*o = out
+	return nil
+}
@@ -0,0 +1,118 @@
+package ot
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReplace(t *testing.T) {
+	cases := []struct {
+		name     string
+		old, new string
+		wire     string // op dalam JSON seperti yang dikirim ke klien
+	}{
+		{"identical", "abc", "abc", `[3]`},
+		{"insert in the middle", "ac", "abc", `[1,"b",1]`},
+		{"delete in the middle", "abc", "ac", `[1,-1,1]`},
+		{"from empty", "", "hi", `["hi"]`},
+		{"to empty", "hi", "", `[-2]`},
+		// 😀 = D83D DE00, 😁 = D83D DE01: prefix UTF-16 sama sampai separuh pair
+		{"emoji sharing a high surrogate", "a😀b", "a😁b", `[1,"😁",-2,1]`},
+		// 😀 = D83D DE00, U+10600 = D801 DE00: suffix UTF-16 sama sampai separuh pair
+		{"emoji sharing a low surrogate", "a😀b", "a\U00010600b", "[1,\"\U00010600\",-2,1]"},
+		{"emoji at the end", "x😀", "x😁", `[1,"😁",-2]`},
+		{"emoji at the start", "😀x", "😁x", `["😁",-2,1]`},
+		{"emoji added next to the same emoji", "😀", "😀😀", `[2,"😀"]`},
+		{"cjk", "你好世界", "你们好世界", `[1,"们",3]`},
+		{"cjk replaced", "你好", "您好", `["您",-1,1]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			op := Replace(tc.old, tc.new)
+			wire, err := json.Marshal(op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(wire) != tc.wire {
+				t.Errorf("wire = %s, want %s", wire, tc.wire)
+			}
+			if strings.ContainsRune(string(wire), '�') {
+				t.Errorf("wire op contains a replacement character: %s", wire)
+			}
+
+			// yang diterapkan server
+			got, err := op.Apply(tc.old)
+			if err != nil || got != tc.new {
+				t.Errorf("Apply = %q, %v; want %q", got, err, tc.new)
+			}
+			// yang diterapkan klien setelah op lewat JSON
+			var back Op
+			if err := json.Unmarshal(wire, &back); err != nil {
+				t.Fatal(err)
+			}
+			got, err = back.Apply(tc.old)
+			if err != nil || got != tc.new {
+				t.Errorf("Apply after JSON = %q, %v; want %q", got, err, tc.new)
+			}
+		})
+	}
+}
+
+func TestTransformConverges(t *testing.T) {
+	cases := []struct {
+		name      string
+		doc, a, b string // a dan b: hasil edit dua klien atas doc
+		want      string // hasil bila keduanya diterapkan (a menang saat bentrok)
+	}{
+		{"different places", "hello world", "hello, world", "hello world!", "hello, world!"},
+		{"same place", "ab", "aXb", "aYb", "aXYb"},
+		{"overlapping deletes", "abcdef", "af", "abf", "af"},
+		{"delete and insert inside", "abcdef", "af", "abcXdef", "aXf"},
+		{"emoji and cjk", "你😀好", "你😁好", "你😀好吗", "你😁好吗"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := Replace(tc.doc, tc.a), Replace(tc.doc, tc.b)
+			a1, b1, err := Transform(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			viaA, err := b1.Apply(tc.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			viaB, err := a1.Apply(tc.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if viaA != tc.want || viaB != tc.want {
+				t.Errorf("a then b' = %q, b then a' = %q; want %q", viaA, viaB, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyChecksLength(t *testing.T) {
+	// panjang dihitung dalam UTF-16: 😀 = 2 unit
+	op := Replace("😀", "")
+	if _, err := op.Apply("ab"); err != nil {
+		t.Fatalf("2-unit doc: %v", err)
+	}
+	if _, err := op.Apply("a"); !errors.Is(err, ErrBaseLength) {
+		t.Fatalf("err = %v, want ErrBaseLength", err)
+	}
+	if _, _, err := Transform(Replace("a", "b"), Replace("ab", "")); !errors.Is(err, ErrBaseLength) {
+		t.Fatalf("Transform err = %v, want ErrBaseLength", err)
+	}
+}
+
+func TestUnmarshalRejectsInvalid(t *testing.T) {
+	for _, in := range []string{`{}`, `[0]`, `[""]`, `[1.5]`, `[true]`, `"abc"`} {
+		var op Op
+		if err := json.Unmarshal([]byte(in), &op); !errors.Is(err, ErrInvalidOp) {
+			t.Errorf("%s: err = %v, want ErrInvalidOp", in, err)
+		}
+	}
+}
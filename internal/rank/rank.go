@@ -0,0 +1,112 @@
+// Package rank membuat string urutan leksikografis (gaya LexoRank) supaya
+// memindah satu task cukup mengubah rank task itu saja.
+//
+// Rank adalah pecahan base-36 "0.<digit>": urutan string == urutan nilai,
+// selama rank tidak diakhiri '0' (Between & Spread tidak pernah membuatnya).
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// MaxLen: rank lebih panjang dari ini sebaiknya dirapikan (Spread ulang)
+const MaxLen = 12
+
+var ErrInvalidRange = errors.New("rank: lower bound must sort before upper bound")
+
+// Between mengembalikan rank di antara a dan b (a < hasil < b).
+// a == "" berarti awal kolom, b == "" berarti akhir kolom.
+func Between(a, b string) (string, error) {
+	if !valid(a) || !valid(b) || (b != "" && a >= b) {
+		return "", ErrInvalidRange
+	}
+	return midpoint(a, b), nil
+}
+
+func midpoint(a, b string) string {
+	if b != "" {
+		// buang prefix yang sama (a dianggap berisi '0' di belakang)
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := base
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db)/2])
+	}
+	// digit bersebelahan: b[0] saja sudah lebih kecil dari b bila b masih panjang
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[da]) + midpoint(rest, "")
+}
+
+// Spread membuat n rank berjarak rata, untuk migrasi & rebalance kolom
+func Spread(n int) []string {
+	width := 1
+	for span := base; span <= n+1; span *= base {
+		width++
+	}
+	total := 1
+	for i := 0; i < width; i++ {
+		total *= base
+	}
+	out := make([]string, n)
+	for i := range out {
+		out[i] = encode((i+1)*total/(n+1), width)
+	}
+	return out
+}
+
+// encode v sebagai width digit lalu buang '0' di belakang
+func encode(v, width int) string {
+	buf := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		buf[i] = digits[v%base]
+		v /= base
+	}
+	return strings.TrimRight(string(buf), "0")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return '0'
+}
+
+func valid(s string) bool {
+	if strings.HasSuffix(s, "0") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(digits, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
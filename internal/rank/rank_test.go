@@ -0,0 +1,88 @@
+package rank
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", "i"},
+		{"", "i", "9"},
+		{"i", "", "r"},
+		{"a", "c", "b"},
+		{"a", "b", "ai"},   // digit bersebelahan → turun satu tingkat
+		{"a", "a1", "a0i"}, // b hanya satu digit di atas a
+		{"az", "b", "azi"},
+		{"", "1", "0i"},
+		{"z", "", "zi"},
+	}
+	for _, tc := range cases {
+		got, err := Between(tc.a, tc.b)
+		if err != nil {
+			t.Errorf("Between(%q, %q): %v", tc.a, tc.b, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Between(%q, %q) = %q, want %q", tc.a, tc.b, got, tc.want)
+		}
+		if !(tc.a < got && (tc.b == "" || got < tc.b)) {
+			t.Errorf("Between(%q, %q) = %q is not strictly between", tc.a, tc.b, got)
+		}
+	}
+}
+
+func TestBetweenRejectsInvalid(t *testing.T) {
+	for _, tc := range [][2]string{{"b", "a"}, {"a", "a"}, {"a0", "b"}, {"A", ""}, {"", "-"}} {
+		if _, err := Between(tc[0], tc[1]); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("Between(%q, %q): err = %v, want ErrInvalidRange", tc[0], tc[1], err)
+		}
+	}
+}
+
+// sisip acak berulang: hasil selalu di antara tetangga dan tidak pernah
+// diakhiri '0' (syarat urutan string == urutan nilai)
+func TestBetweenRandomInserts(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	list := []string{}
+	for i := 0; i < 2000; i++ {
+		pos := r.Intn(len(list) + 1)
+		lo, hi := "", ""
+		if pos > 0 {
+			lo = list[pos-1]
+		}
+		if pos < len(list) {
+			hi = list[pos]
+		}
+		got, err := Between(lo, hi)
+		if err != nil {
+			t.Fatalf("Between(%q, %q): %v", lo, hi, err)
+		}
+		if got <= lo || (hi != "" && got >= hi) || strings.HasSuffix(got, "0") {
+			t.Fatalf("Between(%q, %q) = %q", lo, hi, got)
+		}
+		list = append(list[:pos], append([]string{got}, list[pos:]...)...)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 35, 36, 1000, 50000} {
+		out := Spread(n)
+		if len(out) != n {
+			t.Fatalf("Spread(%d): %d ranks", n, len(out))
+		}
+		for i, r := range out {
+			if r == "" || strings.HasSuffix(r, "0") || len(r) > MaxLen {
+				t.Fatalf("Spread(%d)[%d] = %q", n, i, r)
+			}
+			if i > 0 && out[i-1] >= r {
+				t.Fatalf("Spread(%d) not increasing at %d: %q >= %q", n, i, out[i-1], r)
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Backplane: pub/sub antar instance API. Room socket.io dan Hub hanya ada di
+// memori proses, jadi setiap event juga dikirim lewat backplane dan instance
+// lain menyiarkannya ulang ke room lokalnya.
+type Backplane interface {
+	Publish(ctx context.Context, msg []byte) error
+	// Subscribe memanggil fn untuk setiap pesan (termasuk kiriman sendiri)
+	// sampai ctx selesai. Error hanya bila langganan gagal dibuka.
+	Subscribe(ctx context.Context, fn func(msg []byte)) error
+}
+
+// MemoryBackplane: bus dalam satu proses, untuk satu instance atau beberapa
+// instance di proses yang sama (DEV_SECOND_PORT)
+type MemoryBackplane struct {
+	mu   sync.RWMutex
+	subs map[int]func([]byte)
+	next int
+}
+
+func NewMemoryBackplane() *MemoryBackplane {
+	return &MemoryBackplane{subs: map[int]func([]byte){}}
+}
+
+func (b *MemoryBackplane) Publish(_ context.Context, msg []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, fn := range b.subs {
+		fn(msg)
+	}
+	return nil
+}
+
+func (b *MemoryBackplane) Subscribe(ctx context.Context, fn func([]byte)) error {
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+	b.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+// envelope: bentuk pesan di backplane
+type envelope struct {
+	Origin  string               `json:"origin"`
+	Op      string               `json:"op"` // publish | evict | close | revoke_sessions
+	Event   *wireEvent           `json:"event,omitempty"`
+	Users   []primitive.ObjectID `json:"users,omitempty"`
+	BoardID primitive.ObjectID   `json:"boardId"`
+	UserID  primitive.ObjectID   `json:"userId"`
+	// Sessions: session yang dicabut (op revoke_sessions)
+	Sessions []primitive.ObjectID `json:"sessions,omitempty"`
+}
+
+// wireEvent: Data tetap JSON mentah supaya payload yang diterima klien sama
+// persis di semua instance
+type wireEvent struct {
+	Type    EventType       `json:"type"`
+	BoardID string          `json:"boardId"`
+	Seq     int64           `json:"seq,omitempty"`
+	Room    string          `json:"room,omitempty"`
+	Except  string          `json:"except,omitempty"`
+	Data    json.RawMessage `json:"data"`
+}
+
+const (
+	opPublish = "publish"
+	opEvict   = "evict"
+	opClose   = "close"
+	// opRevokeSessions diurus ShareRevocations, bukan distributed
+	opRevokeSessions = "revoke_sessions"
+
+	backplaneTimeout = 2 * time.Second
+)
+
+// distributed: Publisher lokal + backplane
+type distributed struct {
+	local Publisher
+	bp    Backplane
+	id    string // id instance; pesan sendiri diabaikan saat diterima
+}
+
+// Distributed membungkus Publisher lokal: setiap Publish/Evict/CloseBoard
+// dijalankan di instance ini dan diteruskan ke instance lain lewat bp
+func Distributed(ctx context.Context, local Publisher, bp Backplane) (Publisher, error) {
+	id, err := originID()
+	if err != nil {
+		return nil, err
+	}
+	d := &distributed{local: local, bp: bp, id: id}
+	if err := bp.Subscribe(ctx, d.receive); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *distributed) Publish(evt Event) {
+	d.local.Publish(evt)
+	data, err := json.Marshal(evt.Data)
+	if err != nil {
+		log.Printf("[backplane] marshal %s: %v", evt.Type, err)
+		return
+	}
+	d.send(envelope{Op: opPublish, Event: &wireEvent{
+		Type: evt.Type, BoardID: evt.BoardID, Seq: evt.Seq, Room: evt.Room, Except: evt.Except, Data: data,
+	}, Users: evt.Users})
+}
+
+func (d *distributed) Evict(boardID, userID primitive.ObjectID) {
+	d.local.Evict(boardID, userID)
+	d.send(envelope{Op: opEvict, BoardID: boardID, UserID: userID})
+}
+
+func (d *distributed) CloseBoard(boardID primitive.ObjectID) {
+	d.local.CloseBoard(boardID)
+	d.send(envelope{Op: opClose, BoardID: boardID})
+}
+
+// send: gagal kirim hanya di-log; klien di instance ini sudah menerima event
+func (d *distributed) send(env envelope) {
+	env.Origin = d.id
+	send(d.bp, env)
+}
+
+func send(bp Backplane, env envelope) {
+	msg, err := json.Marshal(env)
+	if err != nil {
+		log.Printf("[backplane] marshal: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+	defer cancel()
+	if err := bp.Publish(ctx, msg); err != nil {
+		log.Printf("[backplane] publish %s: %v", env.Op, err)
+	}
+}
+
+// originID: id acak per pemakai backplane; pesan sendiri diabaikan
+func originID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (d *distributed) receive(msg []byte) {
+	var env envelope
+	if err := json.Unmarshal(msg, &env); err != nil {
+		log.Printf("[backplane] invalid message: %v", err)
+		return
+	}
+	if env.Origin == d.id {
+		return
+	}
+	switch env.Op {
+	case opPublish:
+		if env.Event != nil {
+			w := env.Event
+			d.local.Publish(Event{Type: w.Type, BoardID: w.BoardID, Seq: w.Seq, Room: w.Room, Except: w.Except, Data: w.Data, Users: env.Users})
+		}
+	case opEvict:
+		d.local.Evict(env.BoardID, env.UserID)
+	case opClose:
+		d.local.CloseBoard(env.BoardID)
+	}
+}
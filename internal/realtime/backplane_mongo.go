@@ -0,0 +1,91 @@
+package realtime
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoBackplane: pesan ditulis ke koleksi realtime_events dan dibaca semua
+// instance lewat change stream (butuh replica set / mongos). Dokumen lama
+// dihapus index TTL (lihat config.ensureIndexes).
+type MongoBackplane struct {
+	col *mongo.Collection
+}
+
+const RealtimeEventsCollection = "realtime_events"
+
+type backplaneDoc struct {
+	Message   string    `bson:"message"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+func NewMongoBackplane(db *mongo.Database) *MongoBackplane {
+	return &MongoBackplane{col: db.Collection(RealtimeEventsCollection)}
+}
+
+func (b *MongoBackplane) Publish(ctx context.Context, msg []byte) error {
+	_, err := b.col.InsertOne(ctx, backplaneDoc{Message: string(msg), CreatedAt: time.Now().UTC()})
+	return err
+}
+
+var insertsOnly = mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+
+func (b *MongoBackplane) Subscribe(ctx context.Context, fn func([]byte)) error {
+	// dibuka di sini supaya server standalone langsung ketahuan saat startup
+	cs, err := b.col.Watch(ctx, insertsOnly)
+	if err != nil {
+		return err
+	}
+	go b.run(ctx, cs, fn)
+	return nil
+}
+
+// run membaca change stream; bila putus, dibuka ulang dari resume token
+// terakhir supaya tidak ada pesan yang terlewat
+func (b *MongoBackplane) run(ctx context.Context, cs *mongo.ChangeStream, fn func([]byte)) {
+	backoff := time.Second
+	for {
+		for cs.Next(ctx) {
+			backoff = time.Second
+			var change struct {
+				FullDocument backplaneDoc `bson:"fullDocument"`
+			}
+			if err := cs.Decode(&change); err != nil {
+				log.Printf("[backplane] decode: %v", err)
+				continue
+			}
+			fn([]byte(change.FullDocument.Message))
+		}
+		token := cs.ResumeToken()
+		err := cs.Err()
+		_ = cs.Close(context.Background())
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[backplane] change stream closed: %v; reconnecting in %s", err, backoff)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			opts := options.ChangeStream()
+			if token != nil {
+				opts.SetResumeAfter(token)
+			}
+			cs, err = b.col.Watch(ctx, insertsOnly, opts)
+			if err == nil {
+				break
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+			log.Printf("[backplane] watch: %v; retrying in %s", err, backoff)
+		}
+	}
+}
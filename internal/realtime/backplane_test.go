@@ -0,0 +1,185 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recorder: Publisher lokal palsu yang mencatat semua panggilan
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+	evicts [][2]primitive.ObjectID
+	closes []primitive.ObjectID
+}
+
+func (r *recorder) Publish(evt Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, evt)
+}
+
+func (r *recorder) Evict(boardID, userID primitive.ObjectID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evicts = append(r.evicts, [2]primitive.ObjectID{boardID, userID})
+}
+
+func (r *recorder) CloseBoard(boardID primitive.ObjectID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closes = append(r.closes, boardID)
+}
+
+// failingBackplane: Publish selalu gagal (mis. Mongo tidak terjangkau)
+type failingBackplane struct{ *MemoryBackplane }
+
+func (failingBackplane) Publish(context.Context, []byte) error { return errors.New("backplane down") }
+
+// instances: n instance API yang berbagi satu backplane
+func instances(t *testing.T, bp Backplane, n int) ([]Publisher, []*recorder) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	pubs := make([]Publisher, n)
+	recs := make([]*recorder, n)
+	for i := range pubs {
+		recs[i] = &recorder{}
+		p, err := Distributed(ctx, recs[i], bp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs[i] = p
+	}
+	return pubs, recs
+}
+
+// jsonOf: Data sisi penerima berupa json.RawMessage; bandingkan sebagai JSON
+func jsonOf(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestBackplaneDelivery(t *testing.T) {
+	board := primitive.NewObjectID()
+	user := primitive.NewObjectID()
+	evt := Event{
+		Type:    EventTaskMoved,
+		BoardID: board.Hex(),
+		Seq:     7,
+		Data:    map[string]interface{}{"taskId": "t1", "toColumn": "doing", "position": 2},
+		Users:   []primitive.ObjectID{user},
+		Room:    "board:" + board.Hex(),
+		Except:  "socket-1",
+	}
+	cases := []struct {
+		name      string
+		instances int
+		send      func(p Publisher)
+		check     func(t *testing.T, r *recorder)
+	}{
+		{
+			name:      "publish",
+			instances: 2,
+			send:      func(p Publisher) { p.Publish(evt) },
+			check: func(t *testing.T, r *recorder) {
+				if len(r.events) != 1 {
+					t.Fatalf("%d events, want 1", len(r.events))
+				}
+				got := r.events[0]
+				if got.Type != evt.Type || got.BoardID != evt.BoardID || got.Seq != evt.Seq || got.Room != evt.Room || got.Except != evt.Except {
+					t.Errorf("event = %+v, want %+v", got, evt)
+				}
+				if !reflect.DeepEqual(got.Users, evt.Users) {
+					t.Errorf("users = %v, want %v", got.Users, evt.Users)
+				}
+				if a, b := jsonOf(t, got.Data), jsonOf(t, evt.Data); a != b {
+					t.Errorf("data = %s, want %s", a, b)
+				}
+			},
+		},
+		{
+			name:      "evict",
+			instances: 2,
+			send:      func(p Publisher) { p.Evict(board, user) },
+			check: func(t *testing.T, r *recorder) {
+				if want := [][2]primitive.ObjectID{{board, user}}; !reflect.DeepEqual(r.evicts, want) {
+					t.Errorf("evicts = %v, want %v", r.evicts, want)
+				}
+			},
+		},
+		{
+			name:      "close board",
+			instances: 3,
+			send:      func(p Publisher) { p.CloseBoard(board) },
+			check: func(t *testing.T, r *recorder) {
+				if want := []primitive.ObjectID{board}; !reflect.DeepEqual(r.closes, want) {
+					t.Errorf("closes = %v, want %v", r.closes, want)
+				}
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pubs, recs := instances(t, NewMemoryBackplane(), tc.instances)
+			tc.send(pubs[0])
+			// instance pengirim menerima tepat sekali (lokal, bukan gema backplane),
+			// instance lain tepat sekali lewat backplane
+			for i, r := range recs {
+				t.Run("instance "+string(rune('A'+i)), func(t *testing.T) { tc.check(t, r) })
+			}
+		})
+	}
+}
+
+func TestBackplaneStopsAfterContextDone(t *testing.T) {
+	bp := NewMemoryBackplane()
+	sender, _ := instances(t, bp, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := &recorder{}
+	if _, err := Distributed(ctx, rec, bp); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	// pelepasan langganan berjalan di goroutine; tunggu sampai tercatat
+	deadline := time.Now().Add(time.Second)
+	for {
+		bp.mu.RLock()
+		n := len(bp.subs)
+		bp.mu.RUnlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("subscription not removed after cancel (%d subscribers)", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sender[0].CloseBoard(primitive.NewObjectID())
+	if len(rec.closes) != 0 {
+		t.Fatalf("stopped instance still received %d messages", len(rec.closes))
+	}
+}
+
+func TestBackplanePublishFailureStillDeliversLocally(t *testing.T) {
+	pubs, recs := instances(t, failingBackplane{NewMemoryBackplane()}, 2)
+	pubs[0].Publish(Event{Type: EventTaskCreated, BoardID: "b", Data: map[string]string{"id": "t"}})
+	if len(recs[0].events) != 1 {
+		t.Errorf("sender got %d events, want 1", len(recs[0].events))
+	}
+	if len(recs[1].events) != 0 {
+		t.Errorf("other instance got %d events, want 0", len(recs[1].events))
+	}
+}
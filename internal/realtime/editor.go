@@ -0,0 +1,121 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	socketio "github.com/googollee/go-socket.io"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/ot"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+)
+
+const EventTaskOp EventType = "task_op"
+
+// TaskOp: payload task_op, dikirim ke editor lain di TaskRoom (pengirim
+// hanya menerima ack)
+type TaskOp struct {
+	TaskID string `json:"taskId"`
+	Rev    int64  `json:"rev"` // rev dokumen setelah op
+	Op     ot.Op  `json:"op"`
+	UserID string `json:"userId"`
+}
+
+// TaskRoom: room socket.io para editor deskripsi task
+func TaskRoom(taskID primitive.ObjectID) string {
+	return taskRoom(taskID.Hex())
+}
+
+const taskRoomPrefix = "task:"
+
+func taskRoom(taskHex string) string { return taskRoomPrefix + taskHex }
+
+// taskDocSaveInterval: jarak antar snapshot dokumen ke Task.Description
+const taskDocSaveInterval = 5 * time.Second
+
+// TaskEditor: edit deskripsi task bersama lewat socket.io. State dokumen ada
+// di TaskDocService (bersama antar instance); task_op dikirim lewat Publisher
+// tanpa seq, snapshot diumumkan sebagai task_updated biasa.
+type TaskEditor struct {
+	svc     services.TaskDocService
+	events  Publisher // task_op
+	updates Publisher // task_updated setelah snapshot
+}
+
+func NewTaskEditor(svc services.TaskDocService) *TaskEditor {
+	return &TaskEditor{svc: svc}
+}
+
+// Attach: Publisher baru ada setelah server socket.io dibuat. events tanpa
+// seq (seperti presence), updates lewat log replay board.
+func (e *TaskEditor) Attach(events, updates Publisher) {
+	e.events, e.updates = events, updates
+}
+
+func (e *TaskEditor) publish(edit *services.DocEdit, except string) {
+	if e.events == nil || edit == nil {
+		return
+	}
+	e.events.Publish(Event{
+		Type:    EventTaskOp,
+		BoardID: edit.BoardID.Hex(),
+		Room:    TaskRoom(edit.TaskID),
+		Except:  except,
+		Data:    TaskOp{TaskID: edit.TaskID.Hex(), Rev: edit.Rev, Op: edit.Op, UserID: edit.UserID.Hex()},
+	})
+}
+
+func (e *TaskEditor) apply(ctx context.Context, c socketio.Conn, userID, taskID, session primitive.ObjectID, rev int64, op ot.Op) (*services.DocEdit, error) {
+	edit, err := e.svc.Apply(ctx, taskID, session, userID, rev, op)
+	if err != nil {
+		return nil, err
+	}
+	e.publish(edit, connectionID(c))
+	return edit, nil
+}
+
+// Replace: deskripsi diganti lewat PATCH /tasks/:id saat task sedang diedit;
+// editor menerima perubahannya sebagai op biasa
+func (e *TaskEditor) Replace(ctx context.Context, taskID, userID primitive.ObjectID, text string) error {
+	edit, err := e.svc.Replace(ctx, taskID, userID, text)
+	if err != nil {
+		return err
+	}
+	e.publish(edit, "")
+	return nil
+}
+
+// Run menyimpan snapshot dokumen yang berubah sampai ctx selesai. Boleh jalan
+// di semua instance; tiap rev hanya disimpan sekali.
+func (e *TaskEditor) Run(ctx context.Context) {
+	t := time.NewTicker(taskDocSaveInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		saved, err := e.svc.Save(ctx)
+		if err != nil {
+			log.Printf("[editor] save: %v", err)
+		}
+		if e.updates == nil {
+			continue
+		}
+		for _, s := range saved {
+			e.updates.Publish(Event{Type: EventTaskUpdated, BoardID: s.BoardID.Hex(), Data: TaskRef{
+				ID: s.TaskID.Hex(), BoardID: s.BoardID.Hex(), ActorID: s.UserID.Hex(),
+			}})
+		}
+	}
+}
+
+// rejoin: error yang hanya bisa dipulihkan klien dengan join_task ulang
+func rejoin(err error) bool {
+	return errors.Is(err, services.ErrDocSession) || errors.Is(err, services.ErrDocRevision) ||
+		errors.Is(err, services.ErrBoardArchived)
+}
@@ -0,0 +1,225 @@
+package realtime
+
+import (
+	"encoding/json"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventType: nama event; sama untuk socket.io dan WebSocket biasa
+type EventType string
+
+const (
+	EventTaskCreated        EventType = "task_created"
+	EventTaskUpdated        EventType = "task_updated"
+	EventTaskMoved          EventType = "task_moved"
+	EventTaskDeleted        EventType = "task_deleted"
+	EventTasksReordered     EventType = "tasks_reordered"
+	EventBoardUpdated       EventType = "board_updated"
+	EventBoardAccessRevoked EventType = "board_access_revoked"
+)
+
+// Event: event domain yang dipublikasikan handler. Tanpa Users dikirim ke room
+// board; dengan Users hanya ke user tersebut (lihat BoardUpdated). Seq diisi
+// Sequenced, bukan oleh handler. Room mengganti room board (mis. TaskRoom,
+// hanya socket.io) dan Except melewatkan satu koneksi (ConnectionID).
+type Event struct {
+	Type    EventType   `json:"type"`
+	BoardID string      `json:"boardId"`
+	Seq     int64       `json:"seq,omitempty"`
+	Data    interface{} `json:"data"`
+
+	Users  []primitive.ObjectID `json:"-"`
+	Room   string               `json:"-"`
+	Except string               `json:"-"`
+}
+
+// payload: Data untuk socket.io. Payload socket.io tidak dibungkus seperti
+// pesan WebSocket, jadi seq ditambahkan sebagai field di dalamnya.
+func (e Event) payload() interface{} {
+	if e.Seq == 0 {
+		return e.Data
+	}
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return e.Data
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return e.Data
+	}
+	m["seq"], _ = json.Marshal(e.Seq)
+	return m
+}
+
+// Publisher: satu pintu untuk semua transport realtime
+type Publisher interface {
+	Publish(evt Event)
+	// Evict: user bukan lagi member → berhenti menerima event board
+	Evict(boardID, userID primitive.ObjectID)
+	// CloseBoard: board dihapus → semua subscriber dilepas
+	CloseBoard(boardID primitive.ObjectID)
+}
+
+// Multi meneruskan ke beberapa Publisher sekaligus (nil dilewati)
+func Multi(pubs ...Publisher) Publisher {
+	var out multi
+	for _, p := range pubs {
+		if p != nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+type multi []Publisher
+
+func (m multi) Publish(evt Event) {
+	for _, p := range m {
+		p.Publish(evt)
+	}
+}
+
+func (m multi) Evict(boardID, userID primitive.ObjectID) {
+	for _, p := range m {
+		p.Evict(boardID, userID)
+	}
+}
+
+func (m multi) CloseBoard(boardID primitive.ObjectID) {
+	for _, p := range m {
+		p.CloseBoard(boardID)
+	}
+}
+
+// ---- task ----
+
+type TaskCreated struct {
+	ID       string `json:"id"`
+	BoardID  string `json:"boardId"`
+	Title    string `json:"title"`
+	ColumnID string `json:"columnId"`
+	Order    *int   `json:"order"`
+	ActorID  string `json:"actorId"`
+}
+
+type TaskMoved struct {
+	ID         string `json:"id"`
+	BoardID    string `json:"boardId"`
+	ToColumnID string `json:"toColumnId"`
+	ToPosition int    `json:"toPosition"`
+	Rank       string `json:"rank,omitempty"`
+	ActorID    string `json:"actorId"`
+}
+
+// TaskRef: task_updated & task_deleted
+type TaskRef struct {
+	ID      string `json:"id"`
+	BoardID string `json:"boardId"`
+	ActorID string `json:"actorId"`
+}
+
+type TasksReordered struct {
+	BoardID string `json:"boardId"`
+	ActorID string `json:"actorId"`
+}
+
+func NewTaskCreated(t *models.Task, actor primitive.ObjectID) Event {
+	return Event{Type: EventTaskCreated, BoardID: t.BoardID.Hex(), Data: TaskCreated{
+		ID: t.ID.Hex(), BoardID: t.BoardID.Hex(), Title: t.Title, ColumnID: t.ColumnID, Order: t.Order, ActorID: actor.Hex(),
+	}}
+}
+
+func NewTaskUpdated(t *models.Task, actor primitive.ObjectID) Event {
+	return Event{Type: EventTaskUpdated, BoardID: t.BoardID.Hex(), Data: TaskRef{
+		ID: t.ID.Hex(), BoardID: t.BoardID.Hex(), ActorID: actor.Hex(),
+	}}
+}
+
+// NewTaskMoved: pos = posisi final (toPosition bisa di-clamp ke ukuran kolom)
+func NewTaskMoved(t *models.Task, pos int, actor primitive.ObjectID) Event {
+	return Event{Type: EventTaskMoved, BoardID: t.BoardID.Hex(), Data: TaskMoved{
+		ID: t.ID.Hex(), BoardID: t.BoardID.Hex(), ToColumnID: t.ColumnID, ToPosition: pos, Rank: t.Rank, ActorID: actor.Hex(),
+	}}
+}
+
+func NewTaskDeleted(taskID, boardID, actor primitive.ObjectID) Event {
+	return Event{Type: EventTaskDeleted, BoardID: boardID.Hex(), Data: TaskRef{
+		ID: taskID.Hex(), BoardID: boardID.Hex(), ActorID: actor.Hex(),
+	}}
+}
+
+func NewTasksReordered(boardID, actor primitive.ObjectID) Event {
+	return Event{Type: EventTasksReordered, BoardID: boardID.Hex(), Data: TasksReordered{
+		BoardID: boardID.Hex(), ActorID: actor.Hex(),
+	}}
+}
+
+// ---- board ----
+
+// BoardChange: jenis perubahan pada event board_updated
+type BoardChange string
+
+const (
+	BoardCreated         BoardChange = "created"
+	BoardRenamed         BoardChange = "renamed"
+	BoardSettingsChanged BoardChange = "settings_changed" // description, requireMfa
+	BoardColumnsChanged  BoardChange = "columns_changed"
+	BoardMembersChanged  BoardChange = "members_changed"
+	BoardDeleted         BoardChange = "deleted"
+	BoardRestored        BoardChange = "restored" // keluar dari trash
+	BoardArchived        BoardChange = "archived"
+	BoardUnarchived      BoardChange = "unarchived"
+)
+
+// BoardUpdate: payload board_updated
+type BoardUpdate struct {
+	BoardID string      `json:"boardId"`
+	Change  BoardChange `json:"change"`
+	ActorID string      `json:"actorId"`
+}
+
+// NewBoardUpdated: dikirim ke owner + member b, ditambah extra (mis. user
+// yang baru saja dikeluarkan)
+func NewBoardUpdated(b *models.Board, change BoardChange, actor primitive.ObjectID, extra ...primitive.ObjectID) Event {
+	return Event{
+		Type:    EventBoardUpdated,
+		BoardID: b.ID.Hex(),
+		Data:    BoardUpdate{BoardID: b.ID.Hex(), Change: change, ActorID: actor.Hex()},
+		Users:   BoardAudience(b, extra...),
+	}
+}
+
+// BoardAccessRevoked: payload board_access_revoked
+type BoardAccessRevoked struct {
+	BoardID string `json:"boardId"`
+}
+
+// BoardAudience: owner + semua member board, ditambah extra; tanpa duplikat
+func BoardAudience(b *models.Board, extra ...primitive.ObjectID) []primitive.ObjectID {
+	seen := map[primitive.ObjectID]bool{}
+	var out []primitive.ObjectID
+	add := func(id primitive.ObjectID) {
+		if !id.IsZero() && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	add(b.OwnerID)
+	for _, id := range b.Members {
+		add(id)
+	}
+	for _, m := range b.MemberRoles {
+		add(m.UserID)
+	}
+	for _, id := range extra {
+		add(id)
+	}
+	return out
+}
+
+// UserRoom: room pribadi tiap user di socket.io, di-join otomatis saat connect
+func UserRoom(userID primitive.ObjectID) string {
+	return "user:" + userID.Hex()
+}
@@ -2,37 +2,44 @@ package realtime
 
 import (
 	"encoding/json"
+	"log"
 	"sync"
+	"time"
 
 	"github.com/gofiber/websocket/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type Event struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
-}
-
+// Hub: adapter Publisher untuk WebSocket biasa (/ws/boards/:boardId), untuk
+// klien tanpa socket.io (CLI, mobile). Satu koneksi = satu board; pesan
+// berupa JSON Event {type, boardId, data}.
 type Hub struct {
 	mu    sync.RWMutex
-	rooms map[string]map[*websocket.Conn]struct{} // room = boardIdHex
+	rooms map[string]map[*hubConn]struct{} // room = boardIdHex
+}
+
+type hubConn struct {
+	ws     *websocket.Conn
+	userID primitive.ObjectID
+	mu     sync.Mutex // websocket.Conn tidak aman untuk penulis paralel
 }
 
-var H = NewHub()
+const hubWriteTimeout = 5 * time.Second
 
 func NewHub() *Hub {
-	return &Hub{rooms: make(map[string]map[*websocket.Conn]struct{})}
+	return &Hub{rooms: make(map[string]map[*hubConn]struct{})}
 }
 
-func (h *Hub) Join(room string, c *websocket.Conn) {
+func (h *Hub) join(room string, c *hubConn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	if h.rooms[room] == nil {
-		h.rooms[room] = make(map[*websocket.Conn]struct{})
+		h.rooms[room] = make(map[*hubConn]struct{})
 	}
 	h.rooms[room][c] = struct{}{}
 }
 
-func (h *Hub) Leave(room string, c *websocket.Conn) {
+func (h *Hub) leave(room string, c *hubConn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	if cs, ok := h.rooms[room]; ok {
@@ -43,16 +50,74 @@ func (h *Hub) Leave(room string, c *websocket.Conn) {
 	}
 }
 
-func (h *Hub) Broadcast(room string, evt Event) {
+// conns: salinan isi room, opsional hanya milik users tertentu
+func (h *Hub) conns(room string, users map[primitive.ObjectID]bool) []*hubConn {
 	h.mu.RLock()
-	conns := h.rooms[room]
-	h.mu.RUnlock()
+	defer h.mu.RUnlock()
+	var out []*hubConn
+	for c := range h.rooms[room] {
+		if users == nil || users[c.userID] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
 
+func (h *Hub) Publish(evt Event) {
+	if evt.Room != "" {
+		return // room khusus socket.io (mis. editor task)
+	}
+	var users map[primitive.ObjectID]bool
+	if len(evt.Users) > 0 {
+		users = make(map[primitive.ObjectID]bool, len(evt.Users))
+		for _, u := range evt.Users {
+			users[u] = true
+		}
+	}
+	conns := h.conns(evt.BoardID, users)
 	if len(conns) == 0 {
 		return
 	}
-	b, _ := json.Marshal(evt)
-	for c := range conns {
-		_ = c.WriteMessage(websocket.TextMessage, b)
+	b, err := json.Marshal(evt)
+	if err != nil {
+		log.Printf("[WS] marshal %s: %v", evt.Type, err)
+		return
+	}
+	for _, c := range conns {
+		c.write(websocket.TextMessage, b)
 	}
 }
+
+// Evict: koneksi user ke board ini diberi tahu lalu ditutup
+func (h *Hub) Evict(boardID, userID primitive.ObjectID) {
+	room := boardID.Hex()
+	b, _ := json.Marshal(Event{Type: EventBoardAccessRevoked, BoardID: room, Data: BoardAccessRevoked{BoardID: room}})
+	for _, c := range h.conns(room, map[primitive.ObjectID]bool{userID: true}) {
+		h.leave(room, c)
+		c.write(websocket.TextMessage, b)
+		c.close("board access revoked")
+	}
+}
+
+func (h *Hub) CloseBoard(boardID primitive.ObjectID) {
+	room := boardID.Hex()
+	for _, c := range h.conns(room, nil) {
+		h.leave(room, c)
+		c.close("board deleted")
+	}
+}
+
+func (c *hubConn) write(typ int, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(hubWriteTimeout))
+	if err := c.ws.WriteMessage(typ, b); err != nil {
+		// read loop di Handle akan berhenti dan melepas koneksi
+		_ = c.ws.Close()
+	}
+}
+
+func (c *hubConn) close(reason string) {
+	c.write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason))
+	_ = c.ws.Close()
+}
@@ -0,0 +1,136 @@
+package realtime
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	fiberws "github.com/gofiber/websocket/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// serveHub: /ws/boards/:boardId seperti di Mount, di port acak
+func serveHub(t *testing.T, w *socketWorld, hub *Hub) string {
+	t.Helper()
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/ws/boards/:boardId", WSAuth(w.opts), fiberws.New(hub.Handle))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = app.Listener(ln) }()
+	// Shutdown fasthttp berlomba dengan RequestCtx.Done koneksi WebSocket
+	t.Cleanup(func() { _ = ln.Close() })
+	return "ws://" + ln.Addr().String() + "/ws/boards/" + w.board.ID.Hex()
+}
+
+func dialHub(t *testing.T, url, token string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Authorization": {"Bearer " + token}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// waitJoined: Handle mendaftarkan koneksi setelah upgrade selesai
+func waitJoined(t *testing.T, hub *Hub, room string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(hub.conns(room, nil)) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections joined %s, want %d", len(hub.conns(room, nil)), room, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// receive: pesan berikutnya; "" bila tidak ada dalam wait, "closed" bila
+// koneksi ditutup server
+func receive(t *testing.T, conn *websocket.Conn, wait time.Duration) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(wait))
+	_, msg, err := conn.ReadMessage()
+	if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		return "closed"
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt struct {
+		Type EventType `json:"type"`
+	}
+	if err := json.Unmarshal(msg, &evt); err != nil {
+		t.Fatal(err)
+	}
+	return string(evt.Type)
+}
+
+func TestHubDeliversBoardEvents(t *testing.T) {
+	w := newSocketWorld(t)
+	hub := NewHub()
+	url := serveHub(t, w, hub)
+	owner := dialHub(t, url, w.sessions["owner"].AccessToken)
+	member := dialHub(t, url, w.sessions["member"].AccessToken)
+	room := w.board.ID.Hex()
+	waitJoined(t, hub, room, 2)
+
+	// socket.io (di sini recorder) dan WebSocket menerima event yang sama
+	rec := &recorder{}
+	pub := Multi(hub, nil, rec)
+	pub.Publish(NewTasksReordered(w.board.ID, w.board.OwnerID))
+	if got := receive(t, owner, time.Second) + "," + receive(t, member, time.Second); got != "tasks_reordered,tasks_reordered" {
+		t.Errorf("board event: %s", got)
+	}
+	pub.Publish(NewBoardUpdated(&models.Board{ID: w.board.ID, OwnerID: w.board.OwnerID}, BoardRenamed, w.board.OwnerID))
+	if got := receive(t, owner, time.Second); got != "board_updated" {
+		t.Errorf("owner: %q", got)
+	}
+	if got := receive(t, member, 100*time.Millisecond); got != "" {
+		t.Errorf("member outside Users received %q", got)
+	}
+	// room khusus socket.io tidak dikirim lewat WebSocket
+	pub.Publish(Event{Type: "task_op", BoardID: room, Room: TaskRoom(primitive.NewObjectID())})
+	if got := receive(t, owner, 100*time.Millisecond); got != "" {
+		t.Errorf("task room event on the hub: %q", got)
+	}
+	if len(rec.events) != 3 {
+		t.Errorf("socket.io side got %d events, want 3", len(rec.events))
+	}
+}
+
+func TestHubEvictAndClose(t *testing.T) {
+	w := newSocketWorld(t)
+	hub := NewHub()
+	url := serveHub(t, w, hub)
+	owner := dialHub(t, url, w.sessions["owner"].AccessToken)
+	member := dialHub(t, url, w.sessions["member"].AccessToken)
+	room := w.board.ID.Hex()
+	waitJoined(t, hub, room, 2)
+
+	hub.Evict(w.board.ID, w.member)
+	if got := receive(t, member, time.Second) + "," + receive(t, member, time.Second); got != "board_access_revoked,closed" {
+		t.Errorf("evicted member: %s", got)
+	}
+	hub.Publish(NewTasksReordered(w.board.ID, w.board.OwnerID))
+	if got := receive(t, owner, time.Second); got != "tasks_reordered" {
+		t.Errorf("owner after evict: %q", got)
+	}
+
+	hub.CloseBoard(w.board.ID)
+	if got := receive(t, owner, time.Second); got != "closed" {
+		t.Errorf("owner after CloseBoard: %q", got)
+	}
+	if n := len(hub.conns(room, nil)); n != 0 {
+		t.Errorf("%d connections left in the room", n)
+	}
+}
@@ -0,0 +1,157 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	socketio "github.com/googollee/go-socket.io"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+)
+
+const EventPresenceUpdate EventType = "presence_update"
+
+// PresenceStatus: online (termasuk perubahan task/dragging) atau offline
+type PresenceStatus string
+
+const (
+	PresenceOnline  PresenceStatus = "online"
+	PresenceOffline PresenceStatus = "offline"
+)
+
+// PresenceUpdate: payload presence_update, satu per koneksi
+type PresenceUpdate struct {
+	BoardID      string         `json:"boardId"`
+	UserID       string         `json:"userId"`
+	ConnectionID string         `json:"connectionId"`
+	Status       PresenceStatus `json:"status"`
+	TaskID       *string        `json:"taskId"`
+	Dragging     bool           `json:"dragging"`
+	LastSeen     time.Time      `json:"lastSeen"`
+}
+
+func newPresenceUpdate(p *models.Presence, status PresenceStatus) Event {
+	var task *string
+	if p.TaskID != nil && status == PresenceOnline {
+		h := p.TaskID.Hex()
+		task = &h
+	}
+	return Event{Type: EventPresenceUpdate, BoardID: p.BoardID.Hex(), Data: PresenceUpdate{
+		BoardID:      p.BoardID.Hex(),
+		UserID:       p.UserID.Hex(),
+		ConnectionID: p.ConnectionID,
+		Status:       status,
+		TaskID:       task,
+		Dragging:     p.Dragging && status == PresenceOnline,
+		LastSeen:     p.LastSeen,
+	}}
+}
+
+// presenceSweepInterval: jarak antar penyapuan koneksi yang heartbeat-nya berhenti
+const presenceSweepInterval = 10 * time.Second
+
+// Presence melacak koneksi socket.io yang membuka board. State disimpan lewat
+// PresenceService (bersama antar instance); presence_update dikirim lewat
+// Publisher tanpa nomor urut karena tidak perlu di-replay.
+type Presence struct {
+	svc    services.PresenceService
+	events Publisher
+}
+
+func NewPresence(svc services.PresenceService) *Presence {
+	return &Presence{svc: svc}
+}
+
+// Attach: Publisher baru ada setelah server socket.io dibuat
+func (p *Presence) Attach(events Publisher) { p.events = events }
+
+func (p *Presence) publish(evt Event) {
+	if p.events != nil {
+		p.events.Publish(evt)
+	}
+}
+
+func (p *Presence) set(ctx context.Context, c socketio.Conn, u *socketUser, boardID primitive.ObjectID, st services.PresenceState) error {
+	entry, changed, err := p.svc.Set(ctx, boardID, u.identity.UserID, connectionID(c), st)
+	if err != nil {
+		return err
+	}
+	u.track(boardID.Hex())
+	if changed {
+		p.publish(newPresenceUpdate(entry, PresenceOnline))
+	}
+	return nil
+}
+
+// heartbeat; restored = entri sudah kedaluwarsa dan dibuat ulang tanpa state
+func (p *Presence) heartbeat(ctx context.Context, c socketio.Conn, u *socketUser, boardID primitive.ObjectID) (restored bool, err error) {
+	err = p.svc.Heartbeat(ctx, boardID, connectionID(c))
+	if errors.Is(err, services.ErrPresenceUnknown) {
+		return true, p.set(ctx, c, u, boardID, services.PresenceState{})
+	}
+	return false, err
+}
+
+func (p *Presence) leave(ctx context.Context, c socketio.Conn, u *socketUser, boardHex string) {
+	u.untrack(boardHex)
+	boardID, err := primitive.ObjectIDFromHex(boardHex)
+	if err != nil {
+		return
+	}
+	entry, err := p.svc.Leave(ctx, boardID, connectionID(c))
+	if err != nil {
+		log.Printf("[presence] leave %s board=%s: %v", c.ID(), boardHex, err)
+		return
+	}
+	if entry != nil {
+		p.publish(newPresenceUpdate(entry, PresenceOffline))
+	}
+}
+
+// disconnect: koneksi putus → offline di semua board yang dibuka
+func (p *Presence) disconnect(c socketio.Conn, u *socketUser) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, board := range u.tracked() {
+		p.leave(ctx, c, u, board)
+	}
+}
+
+// closeBoard: board dihapus; entri dibuang tanpa presence_update
+func (p *Presence) closeBoard(boardID primitive.ObjectID, conns []socketio.Conn) {
+	for _, c := range conns {
+		if u, ok := c.Context().(*socketUser); ok {
+			u.untrack(boardID.Hex())
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.svc.DeleteBoard(ctx, boardID); err != nil {
+		log.Printf("[presence] delete board=%s: %v", boardID.Hex(), err)
+	}
+}
+
+// Run menyapu koneksi tanpa heartbeat sampai ctx selesai. Boleh jalan di
+// semua instance; tiap entri hanya diumumkan sekali.
+func (p *Presence) Run(ctx context.Context) {
+	t := time.NewTicker(presenceSweepInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		expired, err := p.svc.Expire(ctx)
+		if err != nil {
+			log.Printf("[presence] sweep: %v", err)
+		}
+		for i := range expired {
+			p.publish(newPresenceUpdate(&expired[i], PresenceOffline))
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// offline tidak lagi membawa task & dragging, supaya klien bisa langsung
+// menghapus avatar dari kartu
+func TestPresenceUpdatePayload(t *testing.T) {
+	task := primitive.NewObjectID()
+	p := &models.Presence{BoardID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), ConnectionID: "i:1", TaskID: &task, Dragging: true, LastSeen: time.Now()}
+
+	online := newPresenceUpdate(p, PresenceOnline)
+	if online.Type != EventPresenceUpdate || online.BoardID != p.BoardID.Hex() || len(online.Users) != 0 {
+		t.Errorf("event = %+v", online)
+	}
+	on := online.Data.(PresenceUpdate)
+	if on.TaskID == nil || *on.TaskID != task.Hex() || !on.Dragging || on.UserID != p.UserID.Hex() || on.ConnectionID != "i:1" {
+		t.Errorf("online payload = %+v", on)
+	}
+
+	off := newPresenceUpdate(p, PresenceOffline).Data.(PresenceUpdate)
+	if off.Status != PresenceOffline || off.TaskID != nil || off.Dragging {
+		t.Errorf("offline payload = %+v", off)
+	}
+}
@@ -0,0 +1,74 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sequenced: memberi seq per board dan menyimpan event ke log replay
+type sequenced struct {
+	events services.BoardEventService
+	next   Publisher
+}
+
+// Sequenced memberi setiap event nomor urut per board dan mencatatnya untuk
+// replay sebelum diteruskan ke next. Dipasang di luar Distributed supaya
+// semua instance mengirim seq yang sama.
+func Sequenced(events services.BoardEventService, next Publisher) Publisher {
+	return &sequenced{events: events, next: next}
+}
+
+func (s *sequenced) Publish(evt Event) {
+	if boardID, err := primitive.ObjectIDFromHex(evt.BoardID); err == nil {
+		if data, err := json.Marshal(evt.Data); err != nil {
+			log.Printf("[events] marshal %s: %v", evt.Type, err)
+		} else {
+			ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+			// gagal simpan tetap dikirim; seq 0 = tanpa nomor urut
+			evt.Seq, err = s.events.Append(ctx, boardID, string(evt.Type), data)
+			cancel()
+			if err != nil {
+				log.Printf("[events] append %s board=%s: %v", evt.Type, evt.BoardID, err)
+			}
+		}
+	}
+	s.next.Publish(evt)
+}
+
+func (s *sequenced) Evict(boardID, userID primitive.ObjectID) {
+	s.next.Evict(boardID, userID)
+}
+
+func (s *sequenced) CloseBoard(boardID primitive.ObjectID) {
+	s.next.CloseBoard(boardID)
+	ctx, cancel := context.WithTimeout(context.Background(), backplaneTimeout)
+	defer cancel()
+	if err := s.events.DeleteBoard(ctx, boardID); err != nil {
+		log.Printf("[events] delete log board=%s: %v", boardID.Hex(), err)
+	}
+}
+
+// FromLog: event dari log replay, bentuknya sama dengan yang dikirim live
+func FromLog(e models.BoardEvent) Event {
+	return Event{Type: EventType(e.Type), BoardID: e.BoardID.Hex(), Seq: e.Seq, Data: json.RawMessage(e.Data)}
+}
+
+// Replay: body GET /boards/:id/events dan ack resume socket.io
+type Replay struct {
+	Seq    int64   `json:"seq"`
+	Resync bool    `json:"resync"`
+	Events []Event `json:"events"`
+}
+
+func NewReplay(r *services.EventReplay) Replay {
+	out := Replay{Seq: r.Seq, Resync: r.Resync, Events: make([]Event, 0, len(r.Events))}
+	for _, e := range r.Events {
+		out.Events = append(out.Events, FromLog(e))
+	}
+	return out
+}
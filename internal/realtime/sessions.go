@@ -0,0 +1,34 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShareRevocations meneruskan pencabutan session antar instance lewat bp.
+// Tanpa ini instance lain masih menerima session yang dicabut (logout, reuse
+// refresh token, akun dinonaktifkan) sampai cache IsActive-nya basi.
+func ShareRevocations(ctx context.Context, sessions services.SessionService, bp Backplane) error {
+	id, err := originID()
+	if err != nil {
+		return err
+	}
+	err = bp.Subscribe(ctx, func(msg []byte) {
+		var env envelope
+		// pesan lain di backplane (event board) bukan urusan di sini
+		if json.Unmarshal(msg, &env) != nil || env.Op != opRevokeSessions || env.Origin == id {
+			return
+		}
+		sessions.Forget(env.Sessions)
+	})
+	if err != nil {
+		return err
+	}
+	sessions.OnRevoke(func(ids []primitive.ObjectID) {
+		send(bp, envelope{Origin: id, Op: opRevokeSessions, Sessions: ids})
+	})
+	return nil
+}
@@ -0,0 +1,85 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dua instance berbagi database dan backplane. Instance B sudah menyimpan
+// session sebagai aktif di cache; pencabutan di A harus langsung berlaku di B.
+func TestRevocationReachesOtherInstances(t *testing.T) {
+	revocations := map[string]func(ctx context.Context, svc services.SessionService, user, sess primitive.ObjectID) error{
+		"revoke": func(ctx context.Context, svc services.SessionService, user, sess primitive.ObjectID) error {
+			return svc.Revoke(ctx, user, sess)
+		},
+		"revoke all": func(ctx context.Context, svc services.SessionService, user, _ primitive.ObjectID) error {
+			return svc.RevokeAll(ctx, user)
+		},
+		"revoke others": func(ctx context.Context, svc services.SessionService, user, _ primitive.ObjectID) error {
+			return svc.RevokeOthers(ctx, user, primitive.NewObjectID())
+		},
+	}
+	for name, revoke := range revocations {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			repos := repository.NewMemory()
+			bp := NewMemoryBackplane()
+			// event board ikut lewat backplane yang sama
+			if _, err := Distributed(ctx, &recorder{}, bp); err != nil {
+				t.Fatal(err)
+			}
+			a := services.NewSessionService(repos.Sessions, time.Minute, time.Hour)
+			b := services.NewSessionService(repos.Sessions, time.Minute, time.Hour)
+			for _, svc := range []services.SessionService{a, b} {
+				if err := ShareRevocations(ctx, svc, bp); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			now := time.Now().UTC()
+			sess := &models.Session{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), ExpiresAt: now.Add(time.Hour)}
+			if err := repos.Sessions.Insert(ctx, sess); err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := b.IsActive(ctx, sess.ID); err != nil || !ok {
+				t.Fatalf("before revoke: active=%v err=%v", ok, err)
+			}
+
+			if err := revoke(ctx, a, sess.UserID, sess.ID); err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := b.IsActive(ctx, sess.ID); err != nil || ok {
+				t.Fatalf("other instance after revoke: active=%v err=%v", ok, err)
+			}
+		})
+	}
+}
+
+// backplane mati: pencabutan tetap berhasil dan berlaku di instance ini
+func TestRevokeWorksWhenBackplaneIsDown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repos := repository.NewMemory()
+	bp := failingBackplane{NewMemoryBackplane()}
+	a := services.NewSessionService(repos.Sessions, time.Minute, time.Hour)
+	if err := ShareRevocations(ctx, a, bp); err != nil {
+		t.Fatal(err)
+	}
+	sess := &models.Session{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repos.Sessions.Insert(ctx, sess); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Revoke(ctx, sess.UserID, sess.ID); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := a.IsActive(ctx, sess.ID); err != nil || ok {
+		t.Fatalf("active=%v err=%v", ok, err)
+	}
+}
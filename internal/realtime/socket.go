@@ -2,60 +2,713 @@
 package realtime
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	socketio "github.com/googollee/go-socket.io"
 	engineio "github.com/googollee/go-socket.io/engineio"
 	"github.com/googollee/go-socket.io/engineio/transport"
 	"github.com/googollee/go-socket.io/engineio/transport/polling"
 	"github.com/googollee/go-socket.io/engineio/transport/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/middleware"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/ot"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
 	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/fiber/v2"
+	fiberws "github.com/gofiber/websocket/v2"
 )
 
-func New() *socketio.Server {
+// SocketOptions: dependensi handshake & pengecekan origin
+type SocketOptions struct {
+	Sessions services.SessionService
+	Tokens   services.AccessTokenService
+	// Events: log untuk resume; nil = resume tidak tersedia
+	Events services.BoardEventService
+	// Presence: nil = presence tidak dilacak
+	Presence *Presence
+	// Editor: nil = edit deskripsi bersama (join_task/task_op) tidak tersedia
+	Editor *TaskEditor
+	// AllowedOrigins: origin browser selain same-origin; "*" = semua
+	AllowedOrigins []string
+	// AllowAllOrigins: dipakai DEV_MODE bila AllowedOrigins kosong
+	AllowAllOrigins bool
+}
+
+// instanceID membedakan proses API; id koneksi socket.io hanya unik per proses
+var instanceID = func() string {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf) // crypto/rand tidak pernah gagal sejak Go 1.24
+	return hex.EncodeToString(buf)
+}()
+
+// connectionID: id koneksi yang unik di semua instance (presence, Event.Except)
+func connectionID(c socketio.Conn) string { return instanceID + ":" + c.ID() }
+
+// socketUser disimpan di Conn.Context() setelah handshake berhasil
+type socketUser struct {
+	identity *middleware.Identity
+
+	mu     sync.Mutex
+	boards map[string]bool // board dengan presence online; room sudah kosong saat OnDisconnect
+	tasks  map[string]taskJoin
+}
+
+// taskJoin: task yang deskripsinya sedang dibuka lewat join_task
+type taskJoin struct {
+	board   string
+	canEdit bool
+}
+
+func (u *socketUser) joinTask(task string, j taskJoin) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.tasks == nil {
+		u.tasks = map[string]taskJoin{}
+	}
+	u.tasks[task] = j
+}
+
+func (u *socketUser) leaveTask(task string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.tasks, task)
+}
+
+func (u *socketUser) joinedTask(task string) (taskJoin, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	j, ok := u.tasks[task]
+	return j, ok
+}
+
+func (u *socketUser) track(board string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.boards == nil {
+		u.boards = map[string]bool{}
+	}
+	u.boards[board] = true
+}
+
+func (u *socketUser) untrack(board string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.boards, board)
+}
+
+func (u *socketUser) isTracked(board string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.boards[board]
+}
+
+func (u *socketUser) tracked() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	out := make([]string, 0, len(u.boards))
+	for b := range u.boards {
+		out = append(out, b)
+	}
+	return out
+}
+
+// New membuat server socket.io. Handshake wajib membawa access token (JWT atau
+// PAT) lewat header Authorization (cadangan: query ?token=); join_board hanya
+// untuk owner/member board.
+func New(opts SocketOptions) *socketio.Server {
+	checkOrigin := func(r *http.Request) bool { return opts.allowOrigin(r.Header.Get("Origin"), r.Host) }
+	// token dibaca saat request handshake masih hidup: lewat adaptor fiber,
+	// Conn.URL() sesudahnya bisa menunjuk buffer request lain
+	var pending sync.Map // id koneksi -> token
 	srv := socketio.NewServer(&engineio.Options{
 		Transports: []transport.Transport{
-			&polling.Transport{}, // fallback awal (handshake via HTTP)
-			&websocket.Transport{
-				// izinkan semua origin saat dev; perketat di produksi
-				CheckOrigin: func(r *http.Request) bool { return true },
-			},
+			&polling.Transport{CheckOrigin: checkOrigin}, // fallback awal (handshake via HTTP)
+			&websocket.Transport{CheckOrigin: checkOrigin},
+		},
+		ConnInitor: func(r *http.Request, conn engineio.Conn) {
+			if t := handshakeToken(r); t != "" {
+				pending.Store(conn.ID(), strings.Clone(t))
+			}
 		},
 	})
 
 	srv.OnConnect("/", func(c socketio.Conn) error {
-		log.Printf("[SOCKET] connect id=%s", c.ID())
+		v, _ := pending.LoadAndDelete(c.ID())
+		token, _ := v.(string)
+		if token == "" {
+			log.Printf("[SOCKET] reject id=%s: missing token", c.ID())
+			return errors.New("missing token")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		id, err := middleware.Identify(ctx, opts.Sessions, opts.Tokens, token)
+		if err != nil {
+			log.Printf("[SOCKET] reject id=%s: %v", c.ID(), err)
+			return err
+		}
+		c.SetContext(&socketUser{identity: id})
+		// event board_updated dikirim per user, bukan ke seluruh namespace
+		c.Join(UserRoom(id.UserID))
+		log.Printf("[SOCKET] connect id=%s user=%s", c.ID(), id.UserID.Hex())
 		return nil
 	})
 	srv.OnError("/", func(c socketio.Conn, err error) {
+		if c == nil {
+			log.Printf("[SOCKET] error err=%v", err)
+			return
+		}
 		log.Printf("[SOCKET] error id=%s err=%v", c.ID(), err)
 	})
 	srv.OnDisconnect("/", func(c socketio.Conn, reason string) {
+		pending.Delete(c.ID())
+		if u, ok := c.Context().(*socketUser); ok && opts.Presence != nil {
+			opts.Presence.disconnect(c, u)
+		}
 		log.Printf("[SOCKET] disconnect id=%s reason=%s", c.ID(), reason)
 	})
 
-	// join/leave room board
-	srv.OnEvent("/", "join_board", func(c socketio.Conn, boardID string) {
+	// join/leave room board; ack: {ok:true} atau {ok:false, error}
+	srv.OnEvent("/", "join_board", func(c socketio.Conn, boardID string) map[string]interface{} {
+		if err := canJoin(c, opts, boardID); err != nil {
+			log.Printf("[SOCKET] join refused %s -> room=%s: %v", c.ID(), boardID, err)
+			return map[string]interface{}{"ok": false, "error": err.Error()}
+		}
 		c.Join(boardID)
+		online(c, opts, boardID)
 		log.Printf("[SOCKET] join %s -> room=%s", c.ID(), boardID)
+		return map[string]interface{}{"ok": true}
 	})
 	srv.OnEvent("/", "leave_board", func(c socketio.Conn, boardID string) {
 		c.Leave(boardID)
+		if u, ok := c.Context().(*socketUser); ok && opts.Presence != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			opts.Presence.leave(ctx, c, u, boardID)
+			cancel()
+		}
+		c.Emit("left_board", boardID)
 		log.Printf("[SOCKET] leave %s -> room=%s", c.ID(), boardID)
 	})
 
+	// resume: join ulang setelah reconnect + kirim ulang event sejak seq
+	// terakhir yang diterima klien. Event dikirim dengan nama aslinya
+	// sebelum ack; ack resync=true berarti klien harus mengambil ulang board.
+	srv.OnEvent("/", "resume", func(c socketio.Conn, req resumeRequest) map[string]interface{} {
+		replay, err := resume(c, opts, req)
+		if err != nil {
+			log.Printf("[SOCKET] resume refused %s -> room=%s: %v", c.ID(), req.BoardID, err)
+			return map[string]interface{}{"ok": false, "error": err.Error()}
+		}
+		online(c, opts, req.BoardID)
+		for _, evt := range replay.Events {
+			c.Emit(string(evt.Type), evt.payload())
+		}
+		log.Printf("[SOCKET] resume %s -> room=%s since=%d replayed=%d resync=%v", c.ID(), req.BoardID, req.Since, len(replay.Events), replay.Resync)
+		return map[string]interface{}{"ok": true, "seq": replay.Seq, "resync": replay.Resync, "replayed": len(replay.Events)}
+	})
+
+	// presence: task yang dibuka & status drag; hanya untuk board yang sudah
+	// di-join. presence_heartbeat menjaga koneksi tetap online.
+	srv.OnEvent("/", "presence", func(c socketio.Conn, req presenceRequest) map[string]interface{} {
+		if err := updatePresence(c, opts, req); err != nil {
+			return map[string]interface{}{"ok": false, "error": err.Error()}
+		}
+		return map[string]interface{}{"ok": true}
+	})
+	srv.OnEvent("/", "presence_heartbeat", func(c socketio.Conn, boardID string) map[string]interface{} {
+		restored, err := presenceHeartbeat(c, opts, boardID)
+		if err != nil {
+			return map[string]interface{}{"ok": false, "error": err.Error()}
+		}
+		return map[string]interface{}{"ok": true, "restored": restored}
+	})
+
+	// join_task: buka deskripsi task untuk diedit bersama; ack berisi teks,
+	// rev & session dokumen. canEdit=false hanya menerima task_op.
+	srv.OnEvent("/", "join_task", func(c socketio.Conn, taskID string) map[string]interface{} {
+		doc, canEdit, err := joinTask(c, opts, taskID)
+		if err != nil {
+			log.Printf("[SOCKET] join_task refused %s -> task=%s: %v", c.ID(), taskID, err)
+			return map[string]interface{}{"ok": false, "error": err.Error()}
+		}
+		log.Printf("[SOCKET] join_task %s -> task=%s rev=%d", c.ID(), taskID, doc.Rev)
+		return map[string]interface{}{
+			"ok": true, "taskId": taskID, "session": doc.Session.Hex(), "rev": doc.Rev, "text": doc.Text, "canEdit": canEdit,
+		}
+	})
+	srv.OnEvent("/", "leave_task", func(c socketio.Conn, taskID string) {
+		c.Leave(taskRoom(taskID))
+		if u, ok := c.Context().(*socketUser); ok {
+			u.leaveTask(taskID)
+		}
+		c.Emit("left_task", taskID)
+	})
+	// task_op: op ot.js di atas rev; ack {ok, rev} atau {ok:false, error,
+	// rejoin}. Editor lain menerima task_op yang sudah di-transform.
+	srv.OnEvent("/", "task_op", func(c socketio.Conn, req taskOpRequest) map[string]interface{} {
+		edit, err := applyTaskOp(c, opts, req)
+		if err != nil {
+			return map[string]interface{}{"ok": false, "error": err.Error(), "rejoin": rejoin(err)}
+		}
+		return map[string]interface{}{"ok": true, "rev": edit.Rev}
+	})
+
 	return srv
 }
 
-func Mount(app *fiber.App, srv *socketio.Server) {
-	// lihat semua hit ke jalur engine.io
+var (
+	errSocketUnauthenticated = errors.New("unauthorized")
+	errSocketForbidden       = errors.New("forbidden")
+	errSocketScope           = errors.New("token scope does not allow this action")
+	errInvalidBoardID        = errors.New("invalid boardId")
+)
+
+// canJoin: session/token masih berlaku dan user owner/member board
+func canJoin(c socketio.Conn, opts SocketOptions, boardHex string) error {
+	u, ok := c.Context().(*socketUser)
+	if !ok {
+		return errSocketUnauthenticated
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := authorizeBoard(ctx, opts, u.identity, boardHex)
+	return err
+}
+
+// online: presence awal setelah join_board/resume; gagal hanya di-log
+func online(c socketio.Conn, opts SocketOptions, boardHex string) {
+	u, ok := c.Context().(*socketUser)
+	boardID, err := primitive.ObjectIDFromHex(boardHex)
+	if !ok || err != nil || opts.Presence == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := opts.Presence.set(ctx, c, u, boardID, services.PresenceState{}); err != nil {
+		log.Printf("[presence] join %s board=%s: %v", c.ID(), boardHex, err)
+	}
+}
+
+type presenceRequest struct {
+	BoardID  string `json:"boardId"`
+	TaskID   string `json:"taskId"` // kosong = tidak membuka task
+	Dragging bool   `json:"dragging"`
+}
+
+var (
+	errPresenceDisabled = errors.New("presence not available")
+	errNotJoined        = errors.New("join the board first")
+	errInvalidTaskID    = errors.New("invalid taskId")
+)
+
+// joinedBoard: presence hanya untuk board yang sudah lolos join_board/resume
+func joinedBoard(c socketio.Conn, opts SocketOptions, boardHex string) (*socketUser, primitive.ObjectID, error) {
+	if opts.Presence == nil {
+		return nil, primitive.NilObjectID, errPresenceDisabled
+	}
+	u, ok := c.Context().(*socketUser)
+	if !ok {
+		return nil, primitive.NilObjectID, errSocketUnauthenticated
+	}
+	boardID, err := primitive.ObjectIDFromHex(boardHex)
+	if err != nil {
+		return nil, primitive.NilObjectID, errInvalidBoardID
+	}
+	if !u.isTracked(boardHex) {
+		return nil, primitive.NilObjectID, errNotJoined
+	}
+	return u, boardID, nil
+}
+
+func updatePresence(c socketio.Conn, opts SocketOptions, req presenceRequest) error {
+	u, boardID, err := joinedBoard(c, opts, req.BoardID)
+	if err != nil {
+		return err
+	}
+	st := services.PresenceState{Dragging: req.Dragging}
+	if req.TaskID != "" {
+		tid, err := primitive.ObjectIDFromHex(req.TaskID)
+		if err != nil {
+			return errInvalidTaskID
+		}
+		st.TaskID = &tid
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := opts.Presence.set(ctx, c, u, boardID, st); err != nil {
+		log.Printf("[presence] update %s board=%s: %v", c.ID(), req.BoardID, err)
+		return errors.New("failed to update presence")
+	}
+	return nil
+}
+
+func presenceHeartbeat(c socketio.Conn, opts SocketOptions, boardHex string) (bool, error) {
+	u, boardID, err := joinedBoard(c, opts, boardHex)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	restored, err := opts.Presence.heartbeat(ctx, c, u, boardID)
+	if err != nil {
+		log.Printf("[presence] heartbeat %s board=%s: %v", c.ID(), boardHex, err)
+		return false, errors.New("failed to update presence")
+	}
+	return restored, nil
+}
+
+var (
+	errEditorDisabled = errors.New("collaborative editing not available")
+	errNotJoinedTask  = errors.New("join the task first")
+)
+
+// joinTask: hanya owner/member board task; canEdit butuh task:write (role dan
+// scope token) dan board yang tidak diarsipkan. Room di-join sebelum dokumen dibaca supaya tidak ada op yang
+// terlewat; op dengan rev <= rev di ack diabaikan klien.
+func joinTask(c socketio.Conn, opts SocketOptions, taskHex string) (*models.TaskDoc, bool, error) {
+	if opts.Editor == nil {
+		return nil, false, errEditorDisabled
+	}
+	u, ok := c.Context().(*socketUser)
+	if !ok {
+		return nil, false, errSocketUnauthenticated
+	}
+	taskID, err := primitive.ObjectIDFromHex(taskHex)
+	if err != nil {
+		return nil, false, errInvalidTaskID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	boardID, err := authz.BoardIDFromTask(ctx, taskID)
+	if err != nil {
+		return nil, false, errSocketForbidden // task tidak ada = bukan member
+	}
+	if _, err := authorizeBoard(ctx, opts, u.identity, boardID.Hex()); err != nil {
+		return nil, false, err
+	}
+	canEdit, err := authz.Can(ctx, u.identity.UserID, boardID, authz.TaskWrite)
+	if err != nil {
+		return nil, false, errSocketForbidden
+	}
+	if t := u.identity.AccessToken; t != nil && !authz.ScopeAllows(t.Scopes, authz.TaskWrite) {
+		canEdit = false
+	}
+	if canEdit {
+		archived, err := authz.IsArchived(ctx, boardID)
+		if err != nil {
+			return nil, false, errSocketForbidden
+		}
+		canEdit = !archived
+	}
+	c.Join(taskRoom(taskHex))
+	doc, err := opts.Editor.svc.Open(ctx, taskID)
+	if err != nil {
+		c.Leave(taskRoom(taskHex))
+		log.Printf("[editor] open task=%s: %v", taskHex, err)
+		return nil, false, errors.New("failed to open description")
+	}
+	u.joinTask(taskHex, taskJoin{board: boardID.Hex(), canEdit: canEdit})
+	return doc, canEdit, nil
+}
+
+type taskOpRequest struct {
+	TaskID  string          `json:"taskId"`
+	Session string          `json:"session"`
+	Rev     int64           `json:"rev"`
+	Op      json.RawMessage `json:"op"` // diurai di sini supaya op rusak tetap mendapat ack
+}
+
+func applyTaskOp(c socketio.Conn, opts SocketOptions, req taskOpRequest) (*services.DocEdit, error) {
+	if opts.Editor == nil {
+		return nil, errEditorDisabled
+	}
+	u, ok := c.Context().(*socketUser)
+	if !ok {
+		return nil, errSocketUnauthenticated
+	}
+	j, ok := u.joinedTask(req.TaskID)
+	if !ok {
+		return nil, errNotJoinedTask
+	}
+	if !j.canEdit {
+		return nil, errSocketForbidden
+	}
+	taskID, err := primitive.ObjectIDFromHex(req.TaskID)
+	if err != nil {
+		return nil, errInvalidTaskID
+	}
+	session, err := primitive.ObjectIDFromHex(req.Session)
+	if err != nil {
+		return nil, services.ErrDocSession
+	}
+	var op ot.Op
+	if err := json.Unmarshal(req.Op, &op); err != nil {
+		return nil, ot.ErrInvalidOp
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// board bisa diarsipkan setelah join_task; join ulang memberi canEdit=false
+	if boardID, err := primitive.ObjectIDFromHex(j.board); err == nil {
+		archived, err := authz.IsArchived(ctx, boardID)
+		if err != nil {
+			return nil, errSocketForbidden
+		}
+		if archived {
+			return nil, services.ErrBoardArchived
+		}
+	}
+	edit, err := opts.Editor.apply(ctx, c, u.identity.UserID, taskID, session, req.Rev, op)
+	switch {
+	case err == nil:
+		return edit, nil
+	case rejoin(err), errors.Is(err, services.ErrDocTooLarge), errors.Is(err, services.ErrDocBusy),
+		errors.Is(err, ot.ErrBaseLength), errors.Is(err, ot.ErrInvalidOp):
+		return nil, err
+	default:
+		log.Printf("[editor] apply task=%s: %v", req.TaskID, err)
+		return nil, errors.New("failed to apply operation")
+	}
+}
+
+type resumeRequest struct {
+	BoardID string `json:"boardId"`
+	Since   int64  `json:"since"`
+}
+
+var errInvalidSince = errors.New("since must be >= 0")
+
+// resume: otorisasi seperti join_board, join room dulu baru baca log supaya
+// tidak ada event yang jatuh di antaranya (duplikat dibuang klien lewat seq)
+func resume(c socketio.Conn, opts SocketOptions, req resumeRequest) (*Replay, error) {
+	if opts.Events == nil {
+		return nil, errors.New("resume not available")
+	}
+	if req.Since < 0 {
+		return nil, errInvalidSince
+	}
+	u, ok := c.Context().(*socketUser)
+	if !ok {
+		return nil, errSocketUnauthenticated
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	boardID, err := authorizeBoard(ctx, opts, u.identity, req.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	c.Join(req.BoardID)
+	r, err := opts.Events.Since(ctx, boardID, req.Since)
+	if err != nil {
+		log.Printf("[SOCKET] resume load board=%s: %v", req.BoardID, err)
+		return nil, errors.New("failed to load events")
+	}
+	replay := NewReplay(r)
+	return &replay, nil
+}
+
+// authorizeBoard: dipakai join_board socket.io dan /ws/boards/:boardId
+func authorizeBoard(ctx context.Context, opts SocketOptions, id *middleware.Identity, boardHex string) (primitive.ObjectID, error) {
+	boardID, err := primitive.ObjectIDFromHex(boardHex)
+	if err != nil {
+		return primitive.NilObjectID, errInvalidBoardID
+	}
+	// session bisa dicabut setelah handshake (logout, deactivate)
+	if t := id.AccessToken; t != nil {
+		if !t.Active(time.Now()) {
+			return boardID, errSocketUnauthenticated
+		}
+		if !authz.ScopeAllows(t.Scopes, authz.BoardRead) {
+			return boardID, errSocketScope
+		}
+	} else {
+		active, err := opts.Sessions.IsActive(ctx, id.SessionID)
+		if err != nil {
+			return boardID, err
+		}
+		if !active {
+			return boardID, errSocketUnauthenticated
+		}
+	}
+
+	ok, err := authz.IsMemberOrOwner(ctx, boardID, id.UserID)
+	if errors.Is(err, authz.ErrMFARequired) {
+		return boardID, err
+	}
+	if err != nil || !ok {
+		// board tidak ada diperlakukan sama dengan bukan member
+		return boardID, errSocketForbidden
+	}
+	return boardID, nil
+}
+
+// handshakeToken: "Authorization: Bearer ..." atau ?token= (klien browser)
+func handshakeToken(r *http.Request) string {
+	return bearerToken(r.URL.Query().Get("token"), r.Header.Get("Authorization"))
+}
+
+// bearerToken: header Authorization diutamakan; query hanya cadangan untuk
+// klien yang tidak bisa mengirim header (token ikut tercatat di URL)
+func bearerToken(query, authorization string) string {
+	parts := strings.SplitN(authorization, " ", 2)
+	if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+		if t := strings.TrimSpace(parts[1]); t != "" {
+			return t
+		}
+	}
+	return query
+}
+
+// allowOrigin: request tanpa Origin (klien non-browser) dan same-origin
+// selalu boleh; selain itu harus ada di AllowedOrigins
+func (o SocketOptions) allowOrigin(origin, host string) bool {
+	if origin == "" || (o.AllowAllOrigins && len(o.AllowedOrigins) == 0) {
+		return true
+	}
+	origin = strings.TrimRight(strings.ToLower(origin), "/")
+	for _, a := range o.AllowedOrigins {
+		if a == "*" || strings.TrimRight(strings.ToLower(a), "/") == origin {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, host)
+}
+
+// SocketIOPublisher: adapter Publisher untuk room socket.io. Event board
+// dikirim ke room board; event ber-Users ke room pribadi user (UserRoom).
+type SocketIOPublisher struct {
+	Server   *socketio.Server
+	Presence *Presence // boleh nil
+}
+
+func NewSocketIOPublisher(srv *socketio.Server, presence *Presence) *SocketIOPublisher {
+	return &SocketIOPublisher{Server: srv, Presence: presence}
+}
+
+func (p *SocketIOPublisher) Publish(evt Event) {
+	if evt.Room != "" {
+		p.toRoom(evt)
+		return
+	}
+	if len(evt.Users) == 0 {
+		p.Server.BroadcastToRoom("/", evt.BoardID, string(evt.Type), evt.payload())
+		return
+	}
+	data := evt.payload()
+	for _, uid := range evt.Users {
+		p.Server.BroadcastToRoom("/", UserRoom(uid), string(evt.Type), data)
+	}
+}
+
+// toRoom: event ber-Room, tanpa koneksi Except (pengirim op)
+func (p *SocketIOPublisher) toRoom(evt Event) {
+	if evt.Except == "" {
+		p.Server.BroadcastToRoom("/", evt.Room, string(evt.Type), evt.payload())
+		return
+	}
+	var conns []socketio.Conn
+	p.Server.ForEach("/", evt.Room, func(c socketio.Conn) {
+		if connectionID(c) != evt.Except {
+			conns = append(conns, c)
+		}
+	})
+	data := evt.payload()
+	for _, c := range conns {
+		c.Emit(string(evt.Type), data)
+	}
+}
+
+// leaveTasks: keluarkan koneksi dari room task milik board (user dikeluarkan
+// atau board dihapus); userID nil = semua user
+func (p *SocketIOPublisher) leaveTasks(boardID primitive.ObjectID, userID *primitive.ObjectID) {
+	board := boardID.Hex()
+	type member struct {
+		c    socketio.Conn
+		u    *socketUser
+		task string
+	}
+	var out []member
+	for _, room := range p.Server.Rooms("/") {
+		task, ok := strings.CutPrefix(room, taskRoomPrefix)
+		if !ok {
+			continue
+		}
+		p.Server.ForEach("/", room, func(c socketio.Conn) {
+			u, ok := c.Context().(*socketUser)
+			if !ok || (userID != nil && u.identity.UserID != *userID) {
+				return
+			}
+			if j, ok := u.joinedTask(task); ok && j.board == board {
+				out = append(out, member{c: c, u: u, task: task})
+			}
+		})
+	}
+	for _, m := range out {
+		p.Server.LeaveRoom("/", taskRoom(m.task), m.c)
+		m.u.leaveTask(m.task)
+		m.c.Emit("left_task", m.task)
+	}
+}
+
+// Evict mengeluarkan semua koneksi user dari room board dan memberi tahu
+// klien lewat event board_access_revoked
+func (p *SocketIOPublisher) Evict(boardID, userID primitive.ObjectID) {
+	room := boardID.Hex()
+	var conns []socketio.Conn
+	// ForEach memegang lock room; Leave dilakukan setelahnya
+	p.Server.ForEach("/", room, func(c socketio.Conn) {
+		if u, ok := c.Context().(*socketUser); ok && u.identity.UserID == userID {
+			conns = append(conns, c)
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, c := range conns {
+		p.Server.LeaveRoom("/", room, c)
+		if p.Presence != nil {
+			p.Presence.leave(ctx, c, c.Context().(*socketUser), room)
+		}
+		c.Emit(string(EventBoardAccessRevoked), BoardAccessRevoked{BoardID: room})
+		log.Printf("[SOCKET] evict %s user=%s -> room=%s", c.ID(), userID.Hex(), room)
+	}
+	p.leaveTasks(boardID, &userID)
+}
+
+func (p *SocketIOPublisher) CloseBoard(boardID primitive.ObjectID) {
+	if p.Presence != nil {
+		var conns []socketio.Conn
+		p.Server.ForEach("/", boardID.Hex(), func(c socketio.Conn) { conns = append(conns, c) })
+		p.Presence.closeBoard(boardID, conns)
+	}
+	p.Server.ClearRoom("/", boardID.Hex())
+	p.leaveTasks(boardID, nil)
+}
+
+// Mount memasang server socket.io (/socket.io/*) dan WebSocket biasa
+// (/ws/boards/:boardId). Origin yang tidak diizinkan ditolak di sini karena
+// transport polling hanya memakai CheckOrigin untuk header CORS.
+func Mount(app *fiber.App, srv *socketio.Server, hub *Hub, opts SocketOptions) {
+	// URL tidak di-log: bisa membawa ?token=
 	app.Use("/socket.io/*", func(c *fiber.Ctx) error {
-		log.Printf("[SOCKETIO] HIT %s", c.OriginalURL())
+		if !opts.allowOrigin(c.Get("Origin"), string(c.Request().Host())) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "origin not allowed"})
+		}
 		return c.Next()
 	})
 	// W A J I B wildcard
 	app.All("/socket.io/*", adaptor.HTTPHandler(srv))
+
+	app.Get("/ws/boards/:boardId", WSAuth(opts), fiberws.New(hub.Handle))
 }
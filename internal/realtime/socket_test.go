@@ -0,0 +1,198 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/middleware"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/services"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBearerToken(t *testing.T) {
+	cases := []struct {
+		query, header, want string
+	}{
+		{"", "", ""},
+		{"q", "", "q"},
+		{"q", "Bearer h", "h"},
+		{"q", "bearer  h ", "h"},
+		{"q", "Basic h", "q"},
+		{"q", "Bearer ", "q"},
+	}
+	for _, tc := range cases {
+		if got := bearerToken(tc.query, tc.header); got != tc.want {
+			t.Errorf("bearerToken(%q, %q) = %q, want %q", tc.query, tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestAllowOrigin(t *testing.T) {
+	strict := SocketOptions{AllowedOrigins: []string{"https://app.test/"}}
+	dev := SocketOptions{AllowAllOrigins: true}
+	cases := []struct {
+		opts   SocketOptions
+		origin string
+		want   bool
+	}{
+		{strict, "", true}, // klien non-browser
+		{strict, "https://APP.test", true},
+		{strict, "https://api.test", true}, // same-origin
+		{strict, "https://evil.test", false},
+		{strict, "null", false},
+		{SocketOptions{}, "https://evil.test", false},
+		{dev, "https://evil.test", true},
+		{SocketOptions{AllowedOrigins: []string{"*"}}, "https://evil.test", true},
+	}
+	for _, tc := range cases {
+		if got := tc.opts.allowOrigin(tc.origin, "api.test"); got != tc.want {
+			t.Errorf("%+v origin %q: %v, want %v", tc.opts, tc.origin, got, tc.want)
+		}
+	}
+}
+
+// socketWorld: board dengan owner & satu member, session untuk keduanya dan
+// orang luar, serta WSAuth di depan /ws/boards/:boardId
+type socketWorld struct {
+	repos    *repository.Repos
+	opts     SocketOptions
+	board    *models.Board
+	app      *fiber.App
+	member   primitive.ObjectID
+	sessions map[string]services.TokenPair
+}
+
+func newSocketWorld(t *testing.T) *socketWorld {
+	t.Helper()
+	ctx := context.Background()
+	config.Cfg.JWTAlg = "HS256"
+	config.Cfg.JWTSecret = "realtime-test-secret-0123456789abcdef"
+	if err := utils.LoadJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	w := &socketWorld{repos: repository.NewMemory(), member: primitive.NewObjectID(), sessions: map[string]services.TokenPair{}}
+	authz.Init(w.repos)
+	w.opts = SocketOptions{
+		Sessions:       services.NewSessionService(w.repos.Sessions, time.Minute, time.Hour),
+		Tokens:         services.NewAccessTokenService(w.repos.Tokens, w.repos.Users),
+		AllowedOrigins: []string{"https://app.test"},
+	}
+	owner := primitive.NewObjectID()
+	w.board = &models.Board{ID: primitive.NewObjectID(), OwnerID: owner, Members: []primitive.ObjectID{w.member},
+		MemberRoles: []models.BoardMember{{UserID: w.member, Role: models.BoardRoleViewer}}}
+	if err := w.repos.Boards.Insert(ctx, w.board); err != nil {
+		t.Fatal(err)
+	}
+	for name, id := range map[string]primitive.ObjectID{"owner": owner, "member": w.member, "outsider": primitive.NewObjectID()} {
+		pair, err := w.opts.Sessions.Start(ctx, id, services.SessionMeta{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.sessions[name] = *pair
+	}
+
+	w.app = fiber.New()
+	w.app.Get("/ws/boards/:boardId", WSAuth(w.opts), func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("wsUserId").(primitive.ObjectID).Hex())
+	})
+	return w
+}
+
+// dial: status handshake WebSocket; 200 = lolos WSAuth
+func (w *socketWorld) dial(t *testing.T, path string, header map[string]string) int {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	res, err := w.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode
+}
+
+func TestWSAuth(t *testing.T) {
+	w := newSocketWorld(t)
+	board := "/ws/boards/" + w.board.ID.Hex()
+	member := w.sessions["member"].AccessToken
+	cases := []struct {
+		name   string
+		path   string
+		header map[string]string
+		want   int
+	}{
+		{"member via header", board, map[string]string{"Authorization": "Bearer " + member}, 200},
+		{"owner via query", board + "?token=" + w.sessions["owner"].AccessToken, nil, 200},
+		{"allowed origin", board, map[string]string{"Authorization": "Bearer " + member, "Origin": "https://app.test"}, 200},
+		{"missing token", board, nil, 401},
+		{"invalid token", board + "?token=nope", nil, 401},
+		{"foreign origin", board, map[string]string{"Authorization": "Bearer " + member, "Origin": "https://evil.test"}, 403},
+		{"outsider", board, map[string]string{"Authorization": "Bearer " + w.sessions["outsider"].AccessToken}, 403},
+		{"unknown board", "/ws/boards/" + primitive.NewObjectID().Hex(), map[string]string{"Authorization": "Bearer " + member}, 403},
+		{"invalid board id", "/ws/boards/nope", map[string]string{"Authorization": "Bearer " + member}, 400},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := w.dial(t, tc.path, tc.header); got != tc.want {
+				t.Errorf("status %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	// tanpa header upgrade bukan handshake WebSocket
+	res, err := w.app.Test(httptest.NewRequest("GET", board+"?token="+member, nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusUpgradeRequired {
+		t.Errorf("plain GET: status %d", res.StatusCode)
+	}
+}
+
+// join_board & WSAuth memakai authorizeBoard: session yang dicabut setelah
+// handshake dan member yang dikeluarkan ditolak saat join berikutnya
+func TestAuthorizeBoardRechecks(t *testing.T) {
+	ctx := context.Background()
+	w := newSocketWorld(t)
+	pair := w.sessions["member"]
+	id, err := middleware.Identify(ctx, w.opts.Sessions, w.opts.Tokens, pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authorizeBoard(ctx, w.opts, id, w.board.ID.Hex()); err != nil {
+		t.Fatalf("member: %v", err)
+	}
+
+	err = w.repos.Boards.Update(ctx, w.board.ID, map[string]interface{}{"members": []primitive.ObjectID{}, "memberRoles": []models.BoardMember{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authorizeBoard(ctx, w.opts, id, w.board.ID.Hex()); !errors.Is(err, errSocketForbidden) {
+		t.Errorf("removed member: err = %v, want forbidden", err)
+	}
+
+	owner := w.sessions["owner"]
+	ownerID, err := middleware.Identify(ctx, w.opts.Sessions, w.opts.Tokens, owner.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sid, _ := primitive.ObjectIDFromHex(owner.SessionID)
+	if err := w.opts.Sessions.Revoke(ctx, w.board.OwnerID, sid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authorizeBoard(ctx, w.opts, ownerID, w.board.ID.Hex()); !errors.Is(err, errSocketUnauthenticated) {
+		t.Errorf("revoked session: err = %v, want unauthorized", err)
+	}
+}
@@ -1,25 +1,75 @@
 package realtime
 
 import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/middleware"
+	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func WSHandler(c *websocket.Conn) {
-	room := c.Params("boardId")
-	if room == "" {
-		_ = c.WriteMessage(websocket.TextMessage, []byte(`{"type":"error","data":"missing boardId"}`))
-		c.Close()
-		return
+// WSAuth: dicek sebelum upgrade supaya penolakan berupa status HTTP biasa.
+// Token lewat ?token= atau header Authorization, seperti handshake socket.io.
+func WSAuth(opts SocketOptions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{"error": "websocket upgrade required"})
+		}
+		if !opts.allowOrigin(c.Get("Origin"), string(c.Request().Host())) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "origin not allowed"})
+		}
+		token := bearerToken(c.Query("token"), c.Get("Authorization"))
+		if token == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "missing token"})
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+		defer cancel()
+		id, err := middleware.Identify(ctx, opts.Sessions, opts.Tokens, token)
+		switch {
+		case errors.Is(err, middleware.ErrInvalidToken), errors.Is(err, middleware.ErrSessionRevoked):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		boardID, err := authorizeBoard(ctx, opts, id, c.Params("boardId"))
+		switch {
+		case errors.Is(err, errInvalidBoardID):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, errSocketUnauthenticated):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, errSocketForbidden), errors.Is(err, errSocketScope), errors.Is(err, authz.ErrMFARequired):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		case err != nil:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Locals("wsUserId", id.UserID)
+		c.Locals("wsBoardId", boardID)
+		return c.Next()
 	}
-	H.Join(room, c)
+}
+
+// Handle: koneksi WebSocket yang sudah lolos WSAuth; pesan dari klien diabaikan
+func (h *Hub) Handle(ws *websocket.Conn) {
+	userID, _ := ws.Locals("wsUserId").(primitive.ObjectID)
+	boardID, _ := ws.Locals("wsBoardId").(primitive.ObjectID)
+	room := boardID.Hex()
+	c := &hubConn{ws: ws, userID: userID}
+	h.join(room, c)
+	log.Printf("[WS] join user=%s -> room=%s", userID.Hex(), room)
 	defer func() {
-		H.Leave(room, c)
-		c.Close()
+		h.leave(room, c)
+		_ = ws.Close()
+		log.Printf("[WS] leave user=%s -> room=%s", userID.Hex(), room)
 	}()
 
 	// keep connection; ignore client messages
 	for {
-		if _, _, err := c.ReadMessage(); err != nil {
+		if _, _, err := ws.ReadMessage(); err != nil {
 			break
 		}
 	}
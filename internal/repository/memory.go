@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NewMemory: backend in-memory (tanpa Mongo), untuk dev mode & unit test.
+// Data hilang saat proses berhenti.
+func NewMemory() *Repos {
+	return &Repos{
+		Boards:      &memBoards{t: newTable(func(b *models.Board) primitive.ObjectID { return b.ID }).withTrash(func(b *models.Board) bool { return b.InTrash() })},
+		Tasks:       &memTasks{t: newTable(func(t *models.Task) primitive.ObjectID { return t.ID }).withTrash(func(t *models.Task) bool { return t.InTrash() })},
+		Notes:       &memNotes{t: newTable(func(n *models.Note) primitive.ObjectID { return n.ID }).withTrash(func(n *models.Note) bool { return n.InTrash() })},
+		Users:       &memUsers{t: newTable(func(u *models.User) primitive.ObjectID { return u.ID })},
+		Invitations: &memInvitations{t: newTable(func(i *models.Invitation) primitive.ObjectID { return i.ID })},
+		Sessions:    &memSessions{t: newTable(func(s *models.Session) primitive.ObjectID { return s.ID })},
+		LoginStates: &memLoginStates{t: newTable(func(s *models.LoginState) primitive.ObjectID { return s.ID })},
+		EmailTokens: &memEmailTokens{t: newTable(func(t *models.EmailToken) primitive.ObjectID { return t.ID })},
+		Throttles:   &memThrottles{t: newTable(func(t *models.LoginThrottle) primitive.ObjectID { return t.ID })},
+		Audit:       &memAudit{t: newTable(func(e *models.AuditEvent) primitive.ObjectID { return e.ID })},
+		MFA:         &memMFAChallenges{t: newTable(func(c *models.MFAChallenge) primitive.ObjectID { return c.ID })},
+		Tokens:      &memAccessTokens{t: newTable(func(t *models.AccessToken) primitive.ObjectID { return t.ID })},
+		BoardEvents: &memBoardEvents{t: newTable(func(e *models.BoardEvent) primitive.ObjectID { return e.ID }), seqs: map[primitive.ObjectID]int64{}},
+		Presence:    &memPresence{t: newTable(func(p *models.Presence) primitive.ObjectID { return p.ID })},
+		TaskDocs:    &memTaskDocs{t: newTable(func(d *models.TaskDoc) primitive.ObjectID { return d.ID }), ops: newTable(func(o *models.TaskDocOp) primitive.ObjectID { return o.ID })},
+		Activities:  &memActivities{t: newTable(func(a *models.Activity) primitive.ObjectID { return a.ID })},
+		Tx:          &memTx{},
+	}
+}
+
+// memTx: "transaksi" = semua fn dijalankan berurutan di bawah satu lock.
+// Cukup untuk dev/test karena hanya ada satu proses.
+type memTx struct{ mu sync.Mutex }
+
+func (t *memTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(ctx)
+}
+
+// table menyimpan salinan dokumen per _id. Semua baca/tulis lewat clone
+// supaya pemanggil tidak bisa mengubah isi store tanpa Update.
+type table[T any] struct {
+	mu   sync.RWMutex
+	rows map[primitive.ObjectID]*T
+	id   func(*T) primitive.ObjectID
+	// trashed (boleh nil): dokumen soft delete; get/filter/count/set/bump
+	// menganggapnya tidak ada (seperti filter deletedAt: null di Mongo)
+	trashed func(*T) bool
+}
+
+func newTable[T any](id func(*T) primitive.ObjectID) *table[T] {
+	return &table[T]{rows: make(map[primitive.ObjectID]*T), id: id}
+}
+
+func (t *table[T]) withTrash(trashed func(*T) bool) *table[T] {
+	t.trashed = trashed
+	return t
+}
+
+func (t *table[T]) live(doc *T) bool { return t.trashed == nil || !t.trashed(doc) }
+
+// insert menolak _id ganda, juga dokumen lain yang cocok dengan conflict
+// (pengganti unique index); conflict boleh nil.
+func (t *table[T]) insert(doc *T, conflict func(*T) bool) error {
+	cp, err := clone(doc)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.id(cp)
+	if _, ok := t.rows[id]; ok {
+		return ErrDuplicate
+	}
+	if conflict != nil {
+		for _, v := range t.rows {
+			if conflict(v) {
+				return ErrDuplicate
+			}
+		}
+	}
+	t.rows[id] = cp
+	return nil
+}
+
+func (t *table[T]) get(id primitive.ObjectID) (*T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	doc, ok := t.rows[id]
+	if !ok || !t.live(doc) {
+		return nil, ErrNotFound
+	}
+	return clone(doc)
+}
+
+// getTrashed: seperti get, tapi hanya dokumen yang ada di trash
+func (t *table[T]) getTrashed(id primitive.ObjectID) (*T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	doc, ok := t.rows[id]
+	if !ok || t.live(doc) {
+		return nil, ErrNotFound
+	}
+	return clone(doc)
+}
+
+// filter mengembalikan salinan semua dokumen yang lolos match
+func (t *table[T]) filter(match func(*T) bool) ([]T, error) {
+	return t.scan(func(doc *T) bool { return t.live(doc) && (match == nil || match(doc)) })
+}
+
+// filterTrashed: seperti filter, tapi hanya dokumen yang ada di trash
+func (t *table[T]) filterTrashed(match func(*T) bool) ([]T, error) {
+	return t.scan(func(doc *T) bool { return !t.live(doc) && (match == nil || match(doc)) })
+}
+
+func (t *table[T]) scan(match func(*T) bool) ([]T, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var out []T
+	for _, doc := range t.rows {
+		if !match(doc) {
+			continue
+		}
+		cp, err := clone(doc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *cp)
+	}
+	return out, nil
+}
+
+func (t *table[T]) count(match func(*T) bool) int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var n int64
+	for _, doc := range t.rows {
+		if t.live(doc) && (match == nil || match(doc)) {
+			n++
+		}
+	}
+	return n
+}
+
+// page meniru skip/limit Mongo pada hasil yang sudah diurutkan; limit 0 = semua
+func page[T any](list []T, skip, limit int) []T {
+	if skip >= len(list) {
+		return nil
+	}
+	list = list[skip:]
+	if limit > 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	return list
+}
+
+func (t *table[T]) set(id primitive.ObjectID, set bson.M) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	doc, ok := t.rows[id]
+	if !ok || !t.live(doc) {
+		return ErrNotFound
+	}
+	return applySet(doc, set)
+}
+
+// setIf: seperti set, tapi ErrConflict bila dokumen tidak lolos cond
+func (t *table[T]) setIf(id primitive.ObjectID, cond func(*T) bool, set bson.M) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	doc, ok := t.rows[id]
+	if !ok || !t.live(doc) {
+		return ErrNotFound
+	}
+	if !cond(doc) {
+		return ErrConflict
+	}
+	return applySet(doc, set)
+}
+
+// bump: seperti set, tapi juga menaikkan version (lihat Board/Task/Note.Version).
+// expect nil = tanpa cek; selain itu ErrConflict bila version sudah berbeda.
+func (t *table[T]) bump(id primitive.ObjectID, version func(*T) int64, expect *int64, set bson.M) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	doc, ok := t.rows[id]
+	if !ok || !t.live(doc) {
+		return ErrNotFound
+	}
+	cur := version(doc)
+	if expect != nil && cur != *expect {
+		return ErrConflict
+	}
+	next := bson.M{"version": cur + 1}
+	for k, v := range set {
+		next[k] = v
+	}
+	return applySet(doc, next)
+}
+
+// bumpAll: bump tanpa cek version untuk semua dokumen yang lolos match,
+// termasuk yang di trash (pengganti UpdateMany)
+func (t *table[T]) bumpAll(match func(*T) bool, version func(*T) int64, set bson.M) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, doc := range t.rows {
+		if !match(doc) {
+			continue
+		}
+		next := bson.M{"version": version(doc) + 1}
+		for k, v := range set {
+			next[k] = v
+		}
+		if err := applySet(doc, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restore: keluarkan dokumen dari trash (deletedAt/deletedBy dikosongkan),
+// terapkan set dan naikkan version; ErrNotFound bila tidak ada di trash
+func (t *table[T]) restore(id primitive.ObjectID, version func(*T) int64, set bson.M) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	doc, ok := t.rows[id]
+	if !ok || t.live(doc) {
+		return ErrNotFound
+	}
+	next := bson.M{"version": version(doc) + 1, "deletedAt": nil, "deletedBy": nil}
+	for k, v := range set {
+		next[k] = v
+	}
+	return applySet(doc, next)
+}
+
+// take: hapus & kembalikan satu dokumen yang lolos match (atomik)
+func (t *table[T]) take(match func(*T) bool) (*T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, doc := range t.rows {
+		if match(doc) {
+			delete(t.rows, id)
+			return doc, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (t *table[T]) remove(match func(*T) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, doc := range t.rows {
+		if match(doc) {
+			delete(t.rows, id)
+		}
+	}
+}
+
+// clone lewat round-trip BSON: sama persis dengan yang disimpan Mongo
+// (termasuk presisi waktu milidetik & field omitempty).
+func clone[T any](src *T) (*T, error) {
+	raw, err := bson.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+	var out T
+	if err := bson.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// applySet meniru {$set: set}, termasuk key bertitik
+func applySet[T any](doc *T, set bson.M) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	m := bson.M{}
+	if err := bson.Unmarshal(raw, &m); err != nil {
+		return err
+	}
+	for k, v := range set {
+		setPath(m, k, v)
+	}
+	if raw, err = bson.Marshal(m); err != nil {
+		return err
+	}
+	var out T
+	if err := bson.Unmarshal(raw, &out); err != nil {
+		return err
+	}
+	*doc = out
+	return nil
+}
+
+// setPath: key bertitik ("mfa.lastStep") mengisi field dokumen bersarang,
+// seperti $set di Mongo; dokumen perantara dibuat bila belum ada
+func setPath(m bson.M, key string, v interface{}) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(bson.M)
+		if !ok {
+			next = bson.M{}
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = v
+}
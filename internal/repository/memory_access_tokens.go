@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memAccessTokens struct{ t *table[models.AccessToken] }
+
+func (r *memAccessTokens) Insert(_ context.Context, t *models.AccessToken) error {
+	return r.t.insert(t, func(v *models.AccessToken) bool { return v.TokenHash == t.TokenHash })
+}
+
+func (r *memAccessTokens) FindByHash(_ context.Context, hash string) (*models.AccessToken, error) {
+	out, err := r.t.filter(func(t *models.AccessToken) bool { return t.TokenHash == hash })
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return &out[0], nil
+}
+
+func (r *memAccessTokens) ListByUser(_ context.Context, userID primitive.ObjectID) ([]models.AccessToken, error) {
+	out, err := r.t.filter(func(t *models.AccessToken) bool { return t.UserID == userID && t.RevokedAt == nil })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *memAccessTokens) Revoke(_ context.Context, userID, id primitive.ObjectID, at time.Time) error {
+	err := r.t.setIf(id, func(t *models.AccessToken) bool { return t.UserID == userID && t.RevokedAt == nil }, bson.M{"revokedAt": at})
+	if errors.Is(err, ErrConflict) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *memAccessTokens) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, at time.Time) error {
+	list, err := r.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, t := range list {
+		if err := r.Revoke(ctx, userID, t.ID, at); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memAccessTokens) Touch(_ context.Context, id primitive.ObjectID, at time.Time) error {
+	return r.t.set(id, bson.M{"lastUsedAt": at})
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memActivities struct{ t *table[models.Activity] }
+
+func (r *memActivities) Insert(_ context.Context, a *models.Activity) error {
+	return r.t.insert(a, nil)
+}
+
+func (r *memActivities) List(_ context.Context, f ActivityFilter, before *primitive.ObjectID, limit int) ([]models.Activity, error) {
+	out, err := r.t.filter(func(a *models.Activity) bool {
+		if f.BoardID != nil && (a.BoardID == nil || *a.BoardID != *f.BoardID) {
+			return false
+		}
+		if f.TaskID != nil && (a.TaskID == nil || *a.TaskID != *f.TaskID) {
+			return false
+		}
+		return before == nil || bytes.Compare(a.ID[:], before[:]) < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].ID[:], out[j].ID[:]) > 0 })
+	return page(out, 0, limit), nil
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+)
+
+type memAudit struct{ t *table[models.AuditEvent] }
+
+func (r *memAudit) Insert(_ context.Context, e *models.AuditEvent) error { return r.t.insert(e, nil) }
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memBoardEvents struct {
+	t    *table[models.BoardEvent]
+	mu   sync.Mutex
+	seqs map[primitive.ObjectID]int64
+}
+
+func (r *memBoardEvents) NextSeq(_ context.Context, boardID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seqs[boardID]++
+	return r.seqs[boardID], nil
+}
+
+func (r *memBoardEvents) LastSeq(_ context.Context, boardID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seqs[boardID], nil
+}
+
+func (r *memBoardEvents) Insert(_ context.Context, e *models.BoardEvent) error {
+	return r.t.insert(e, nil)
+}
+
+func (r *memBoardEvents) ListSince(_ context.Context, boardID primitive.ObjectID, since int64) ([]models.BoardEvent, error) {
+	out, err := r.t.filter(func(e *models.BoardEvent) bool { return e.BoardID == boardID && e.Seq > since })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Seq < out[j].Seq })
+	return out, nil
+}
+
+func (r *memBoardEvents) Prune(_ context.Context, boardID primitive.ObjectID, upTo int64) error {
+	r.t.remove(func(e *models.BoardEvent) bool { return e.BoardID == boardID && e.Seq <= upTo })
+	return nil
+}
+
+func (r *memBoardEvents) DeleteByBoard(_ context.Context, boardID primitive.ObjectID) error {
+	r.t.remove(func(e *models.BoardEvent) bool { return e.BoardID == boardID })
+	r.mu.Lock()
+	delete(r.seqs, boardID)
+	r.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memBoards struct{ t *table[models.Board] }
+
+func (r *memBoards) Insert(_ context.Context, b *models.Board) error { return r.t.insert(b, nil) }
+
+func (r *memBoards) FindByID(_ context.Context, id primitive.ObjectID) (*models.Board, error) {
+	return r.t.get(id)
+}
+
+func (r *memBoards) ListForUser(_ context.Context, userID primitive.ObjectID) ([]models.Board, error) {
+	out, err := r.t.filter(func(b *models.Board) bool {
+		return b.OwnerID == userID || containsID(b.Members, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UpdatedAt.After(out[j].UpdatedAt) })
+	return out, nil
+}
+
+func boardVersion(b *models.Board) int64 { return b.Version }
+
+func (r *memBoards) Update(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.bump(id, boardVersion, nil, set)
+}
+
+func (r *memBoards) UpdateIfVersion(_ context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return r.t.bump(id, boardVersion, &version, set)
+}
+
+func (r *memBoards) Delete(_ context.Context, id primitive.ObjectID) error {
+	r.t.remove(func(b *models.Board) bool { return b.ID == id })
+	return nil
+}
+
+func (r *memBoards) SoftDelete(_ context.Context, id, by primitive.ObjectID, at time.Time) error {
+	return r.t.bump(id, boardVersion, nil, bson.M{"deletedAt": at, "deletedBy": by})
+}
+
+func (r *memBoards) FindDeleted(_ context.Context, id primitive.ObjectID) (*models.Board, error) {
+	return r.t.getTrashed(id)
+}
+
+func (r *memBoards) ListDeletedByOwner(_ context.Context, ownerID primitive.ObjectID) ([]models.Board, error) {
+	out, err := r.t.filterTrashed(func(b *models.Board) bool { return b.OwnerID == ownerID })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(*out[j].DeletedAt) })
+	return out, nil
+}
+
+func (r *memBoards) ListDeletedBefore(_ context.Context, before time.Time, limit int) ([]models.Board, error) {
+	out, err := r.t.filterTrashed(func(b *models.Board) bool { return b.DeletedAt.Before(before) })
+	if err != nil {
+		return nil, err
+	}
+	return page(out, 0, limit), nil
+}
+
+func (r *memBoards) Restore(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.restore(id, boardVersion, set)
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memBoards) Search(_ context.Context, f BoardFilter, skip, limit int) ([]models.Board, error) {
+	out, err := r.t.filter(func(b *models.Board) bool { return matchBoard(b, f) })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return page(out, skip, limit), nil
+}
+
+func (r *memBoards) Count(_ context.Context, f BoardFilter) (int64, error) {
+	return r.t.count(func(b *models.Board) bool { return matchBoard(b, f) }), nil
+}
+
+func matchBoard(b *models.Board, f BoardFilter) bool {
+	if f.OwnerID != nil && b.OwnerID != *f.OwnerID {
+		return false
+	}
+	if f.Archived != nil && b.IsArchived != *f.Archived {
+		return false
+	}
+	return f.Query == "" || strings.Contains(strings.ToLower(b.Name), strings.ToLower(f.Query))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memEmailTokens struct{ t *table[models.EmailToken] }
+
+func (r *memEmailTokens) Insert(_ context.Context, t *models.EmailToken) error {
+	return r.t.insert(t, func(v *models.EmailToken) bool { return v.TokenHash == t.TokenHash })
+}
+
+func (r *memEmailTokens) Consume(_ context.Context, hash string, purpose models.EmailTokenPurpose, now time.Time) (*models.EmailToken, error) {
+	usable := func(t *models.EmailToken) bool {
+		return t.TokenHash == hash && t.Purpose == purpose && t.UsedAt == nil && now.Before(t.ExpiresAt)
+	}
+	out, err := r.t.filter(usable)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	// setIf mengecek ulang di bawah lock: pemakaian bersamaan hanya satu yang menang
+	if err := r.t.setIf(out[0].ID, usable, bson.M{"usedAt": now}); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return r.t.get(out[0].ID)
+}
+
+func (r *memEmailTokens) InvalidateForUser(_ context.Context, userID primitive.ObjectID, purpose models.EmailTokenPurpose, at time.Time) error {
+	out, err := r.t.filter(func(t *models.EmailToken) bool {
+		return t.UserID == userID && t.Purpose == purpose && t.UsedAt == nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, t := range out {
+		if err := r.t.set(t.ID, bson.M{"usedAt": at}); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memInvitations struct{ t *table[models.Invitation] }
+
+func (r *memInvitations) Insert(_ context.Context, inv *models.Invitation) error {
+	// sama seperti partial index uniq_pending_board_email di Mongo
+	return r.t.insert(inv, func(v *models.Invitation) bool {
+		return v.Status == models.InvitationPending && inv.Status == models.InvitationPending &&
+			v.BoardID == inv.BoardID && v.Email == inv.Email
+	})
+}
+
+func (r *memInvitations) FindByID(_ context.Context, id primitive.ObjectID) (*models.Invitation, error) {
+	return r.t.get(id)
+}
+
+func (r *memInvitations) FindByTokenHash(_ context.Context, hash string) (*models.Invitation, error) {
+	out, err := r.t.filter(func(v *models.Invitation) bool { return v.TokenHash == hash })
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return &out[0], nil
+}
+
+func (r *memInvitations) ListByBoard(_ context.Context, boardID primitive.ObjectID) ([]models.Invitation, error) {
+	return r.newestFirst(func(v *models.Invitation) bool { return v.BoardID == boardID })
+}
+
+func (r *memInvitations) ListPendingByEmail(_ context.Context, email string) ([]models.Invitation, error) {
+	return r.newestFirst(func(v *models.Invitation) bool {
+		return v.Email == email && v.Status == models.InvitationPending
+	})
+}
+
+func (r *memInvitations) Resolve(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.setIf(id, func(v *models.Invitation) bool { return v.Status == models.InvitationPending }, set)
+}
+
+func (r *memInvitations) newestFirst(match func(*models.Invitation) bool) ([]models.Invitation, error) {
+	out, err := r.t.filter(match)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+)
+
+type memLoginStates struct{ t *table[models.LoginState] }
+
+func (r *memLoginStates) Insert(_ context.Context, s *models.LoginState) error {
+	return r.t.insert(s, func(v *models.LoginState) bool { return v.State == s.State })
+}
+
+func (r *memLoginStates) Take(_ context.Context, state string) (*models.LoginState, error) {
+	return r.t.take(func(v *models.LoginState) bool { return v.State == state })
+}
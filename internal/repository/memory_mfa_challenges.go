@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memMFAChallenges struct {
+	t  *table[models.MFAChallenge]
+	mu sync.Mutex // IncAttempts = baca lalu tulis
+}
+
+func (r *memMFAChallenges) Insert(_ context.Context, c *models.MFAChallenge) error {
+	return r.t.insert(c, func(v *models.MFAChallenge) bool { return v.TokenHash == c.TokenHash })
+}
+
+func (r *memMFAChallenges) FindByTokenHash(_ context.Context, hash string) (*models.MFAChallenge, error) {
+	out, err := r.t.filter(func(c *models.MFAChallenge) bool { return c.TokenHash == hash })
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return &out[0], nil
+}
+
+func (r *memMFAChallenges) IncAttempts(_ context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, err := r.t.get(id)
+	if err != nil {
+		return err
+	}
+	return r.t.set(id, bson.M{"attempts": c.Attempts + 1})
+}
+
+func (r *memMFAChallenges) Take(_ context.Context, id primitive.ObjectID) (*models.MFAChallenge, error) {
+	return r.t.take(func(c *models.MFAChallenge) bool { return c.ID == id })
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memNotes struct{ t *table[models.Note] }
+
+func (r *memNotes) Insert(_ context.Context, n *models.Note) error { return r.t.insert(n, nil) }
+
+func (r *memNotes) FindByID(_ context.Context, id primitive.ObjectID) (*models.Note, error) {
+	return r.t.get(id)
+}
+
+func (r *memNotes) ListByBoard(_ context.Context, boardID primitive.ObjectID) ([]models.Note, error) {
+	return r.t.filter(func(n *models.Note) bool { return n.BoardID != nil && *n.BoardID == boardID })
+}
+
+func (r *memNotes) ListByTask(_ context.Context, taskID primitive.ObjectID) ([]models.Note, error) {
+	return r.t.filter(func(n *models.Note) bool { return n.TaskID != nil && *n.TaskID == taskID })
+}
+
+func (r *memNotes) ListInRange(_ context.Context, scope TimelineScope, from, to time.Time) ([]models.Note, error) {
+	return r.t.filter(func(n *models.Note) bool {
+		if n.BoardID != nil && !containsID(scope.BoardIDs, *n.BoardID) {
+			return false
+		}
+		if n.BoardID == nil && (scope.AuthorID == nil || n.AuthorID != *scope.AuthorID) {
+			return false
+		}
+		return inRange(n.OnTimelineAt, from, to)
+	})
+}
+
+func noteVersion(n *models.Note) int64 { return n.Version }
+
+func (r *memNotes) Update(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.bump(id, noteVersion, nil, set)
+}
+
+func (r *memNotes) UpdateIfVersion(_ context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return r.t.bump(id, noteVersion, &version, set)
+}
+
+func (r *memNotes) Delete(_ context.Context, id primitive.ObjectID) error {
+	r.t.remove(func(n *models.Note) bool { return n.ID == id })
+	return nil
+}
+
+func (r *memNotes) DeleteByBoard(_ context.Context, boardID primitive.ObjectID) error {
+	r.t.remove(func(n *models.Note) bool { return n.BoardID != nil && *n.BoardID == boardID })
+	return nil
+}
+
+func (r *memNotes) DeleteByTask(_ context.Context, taskID primitive.ObjectID) error {
+	r.t.remove(func(n *models.Note) bool { return n.TaskID != nil && *n.TaskID == taskID })
+	return nil
+}
+
+func (r *memNotes) SoftDelete(_ context.Context, id, by primitive.ObjectID, at time.Time) error {
+	return r.t.bump(id, noteVersion, nil, bson.M{"deletedAt": at, "deletedBy": by})
+}
+
+func (r *memNotes) SoftDeleteByTask(_ context.Context, taskID, by primitive.ObjectID, at time.Time) error {
+	return r.t.bumpAll(func(n *models.Note) bool {
+		return !n.InTrash() && n.TaskID != nil && *n.TaskID == taskID
+	}, noteVersion, bson.M{"deletedAt": at, "deletedBy": by})
+}
+
+func (r *memNotes) RestoreByTask(_ context.Context, taskID primitive.ObjectID, at time.Time, set bson.M) error {
+	next := bson.M{"deletedAt": nil, "deletedBy": nil}
+	for k, v := range set {
+		next[k] = v
+	}
+	return r.t.bumpAll(func(n *models.Note) bool {
+		return n.InTrash() && n.TaskID != nil && *n.TaskID == taskID && n.DeletedAt.Equal(at)
+	}, noteVersion, next)
+}
+
+func (r *memNotes) FindDeleted(_ context.Context, id primitive.ObjectID) (*models.Note, error) {
+	return r.t.getTrashed(id)
+}
+
+func (r *memNotes) ListDeletedByBoard(_ context.Context, boardID primitive.ObjectID) ([]models.Note, error) {
+	out, err := r.t.filterTrashed(func(n *models.Note) bool { return n.BoardID != nil && *n.BoardID == boardID })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(*out[j].DeletedAt) })
+	return out, nil
+}
+
+func (r *memNotes) Restore(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.restore(id, noteVersion, set)
+}
+
+func (r *memNotes) PurgeDeleted(_ context.Context, before time.Time) (int64, error) {
+	var n int64
+	r.t.remove(func(note *models.Note) bool {
+		purge := note.InTrash() && note.DeletedAt.Before(before)
+		if purge {
+			n++
+		}
+		return purge
+	})
+	return n, nil
+}
+
+func (r *memNotes) Count(_ context.Context) (int64, error) { return r.t.count(nil), nil }
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memPresence struct {
+	t  *table[models.Presence]
+	mu sync.Mutex // Upsert = baca lalu tulis
+}
+
+func (r *memPresence) find(boardID primitive.ObjectID, connectionID string) *models.Presence {
+	out, _ := r.t.filter(func(p *models.Presence) bool { return p.BoardID == boardID && p.ConnectionID == connectionID })
+	if len(out) == 0 {
+		return nil
+	}
+	return &out[0]
+}
+
+func (r *memPresence) Upsert(_ context.Context, p *models.Presence, now time.Time) (*models.Presence, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := r.find(p.BoardID, p.ConnectionID)
+	if prev == nil {
+		if p.ID.IsZero() {
+			p.ID = primitive.NewObjectID()
+		}
+		return nil, r.t.insert(p, nil)
+	}
+	p.ID = prev.ID
+	set := bson.M{"userId": p.UserID, "taskId": p.TaskID, "dragging": p.Dragging, "lastSeen": p.LastSeen, "expiresAt": p.ExpiresAt}
+	if err := r.t.set(prev.ID, set); err != nil {
+		return nil, err
+	}
+	if !prev.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return prev, nil
+}
+
+func (r *memPresence) Touch(_ context.Context, boardID primitive.ObjectID, connectionID string, lastSeen, expiresAt time.Time) error {
+	prev := r.find(boardID, connectionID)
+	if prev == nil {
+		return ErrNotFound
+	}
+	return r.t.set(prev.ID, bson.M{"lastSeen": lastSeen, "expiresAt": expiresAt})
+}
+
+func (r *memPresence) Remove(_ context.Context, boardID primitive.ObjectID, connectionID string) (*models.Presence, error) {
+	return r.t.take(func(p *models.Presence) bool { return p.BoardID == boardID && p.ConnectionID == connectionID })
+}
+
+func (r *memPresence) ListByBoard(_ context.Context, boardID primitive.ObjectID, now time.Time) ([]models.Presence, error) {
+	out, err := r.t.filter(func(p *models.Presence) bool { return p.BoardID == boardID && p.ExpiresAt.After(now) })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastSeen.Before(out[j].LastSeen) })
+	return out, nil
+}
+
+func (r *memPresence) TakeExpired(_ context.Context, now time.Time) ([]models.Presence, error) {
+	var out []models.Presence
+	for {
+		p, err := r.t.take(func(p *models.Presence) bool { return !p.ExpiresAt.After(now) })
+		if errors.Is(err, ErrNotFound) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, *p)
+	}
+}
+
+func (r *memPresence) DeleteByBoard(_ context.Context, boardID primitive.ObjectID) error {
+	r.t.remove(func(p *models.Presence) bool { return p.BoardID == boardID })
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memSessions struct{ t *table[models.Session] }
+
+func (r *memSessions) Insert(_ context.Context, s *models.Session) error { return r.t.insert(s, nil) }
+
+func (r *memSessions) FindByID(_ context.Context, id primitive.ObjectID) (*models.Session, error) {
+	return r.t.get(id)
+}
+
+func (r *memSessions) FindByRefreshHash(_ context.Context, hash string) (*models.Session, error) {
+	out, err := r.t.filter(func(s *models.Session) bool {
+		return s.RefreshHash == hash || (s.PrevRefreshHash != "" && s.PrevRefreshHash == hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return &out[0], nil
+}
+
+func (r *memSessions) ListActiveByUser(_ context.Context, userID primitive.ObjectID, now time.Time) ([]models.Session, error) {
+	out, err := r.t.filter(func(s *models.Session) bool { return s.UserID == userID && s.Active(now) })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastUsedAt.After(out[j].LastUsedAt) })
+	return out, nil
+}
+
+func (r *memSessions) Rotate(_ context.Context, id primitive.ObjectID, oldHash string, set bson.M) error {
+	err := r.t.setIf(id, func(s *models.Session) bool { return s.RefreshHash == oldHash && s.RevokedAt == nil }, set)
+	if errors.Is(err, ErrNotFound) {
+		return ErrConflict
+	}
+	return err
+}
+
+func (r *memSessions) Revoke(_ context.Context, id primitive.ObjectID, at time.Time) error {
+	err := r.t.setIf(id, func(s *models.Session) bool { return s.RevokedAt == nil }, bson.M{"revokedAt": at, "updatedAt": at})
+	if errors.Is(err, ErrConflict) {
+		return nil // sudah dicabut
+	}
+	return err
+}
+
+func (r *memSessions) RevokeAllForUser(_ context.Context, userID primitive.ObjectID, at time.Time) error {
+	ids, err := r.t.filter(func(s *models.Session) bool { return s.UserID == userID && s.RevokedAt == nil })
+	if err != nil {
+		return err
+	}
+	for _, s := range ids {
+		if err := r.Revoke(context.Background(), s.ID, at); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memTaskDocs struct {
+	t   *table[models.TaskDoc]
+	ops *table[models.TaskDocOp]
+}
+
+func (r *memTaskDocs) Get(_ context.Context, taskID primitive.ObjectID) (*models.TaskDoc, error) {
+	return r.t.get(taskID)
+}
+
+func (r *memTaskDocs) Create(_ context.Context, d *models.TaskDoc) error {
+	return r.t.insert(d, nil)
+}
+
+func (r *memTaskDocs) Advance(_ context.Context, taskID primitive.ObjectID, rev int64, text string, op *models.TaskDocOp) error {
+	err := r.t.setIf(taskID, func(d *models.TaskDoc) bool { return d.Rev == rev },
+		bson.M{"text": text, "rev": rev + 1, "updatedBy": op.UserID, "updatedAt": op.CreatedAt})
+	if err != nil {
+		return err
+	}
+	return r.ops.insert(op, func(o *models.TaskDocOp) bool { return o.TaskID == op.TaskID && o.Rev == op.Rev })
+}
+
+func (r *memTaskDocs) ListOps(_ context.Context, taskID primitive.ObjectID, after int64) ([]models.TaskDocOp, error) {
+	out, err := r.ops.filter(func(o *models.TaskDocOp) bool { return o.TaskID == taskID && o.Rev > after })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Rev < out[j].Rev })
+	return out, nil
+}
+
+func (r *memTaskDocs) PruneOps(_ context.Context, taskID primitive.ObjectID, upTo int64) error {
+	r.ops.remove(func(o *models.TaskDocOp) bool { return o.TaskID == taskID && o.Rev <= upTo })
+	return nil
+}
+
+func (r *memTaskDocs) ListUnsaved(_ context.Context, limit int) ([]models.TaskDoc, error) {
+	out, err := r.t.filter(func(d *models.TaskDoc) bool { return d.Rev > d.SavedRev })
+	if err != nil {
+		return nil, err
+	}
+	return page(out, 0, limit), nil
+}
+
+func (r *memTaskDocs) MarkSaved(_ context.Context, taskID primitive.ObjectID, from, rev int64) error {
+	return r.t.setIf(taskID, func(d *models.TaskDoc) bool { return d.SavedRev == from }, bson.M{"savedRev": rev})
+}
+
+func (r *memTaskDocs) DeleteIdle(_ context.Context, before time.Time) error {
+	idle := map[primitive.ObjectID]bool{}
+	r.t.remove(func(d *models.TaskDoc) bool {
+		if d.UpdatedAt.Before(before) && d.Rev <= d.SavedRev {
+			idle[d.ID] = true
+			return true
+		}
+		return false
+	})
+	r.ops.remove(func(o *models.TaskDocOp) bool { return idle[o.TaskID] })
+	return nil
+}
+
+func (r *memTaskDocs) Delete(_ context.Context, taskID primitive.ObjectID) error {
+	r.t.remove(func(d *models.TaskDoc) bool { return d.ID == taskID })
+	r.ops.remove(func(o *models.TaskDocOp) bool { return o.TaskID == taskID })
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memTasks struct{ t *table[models.Task] }
+
+func (r *memTasks) Insert(_ context.Context, t *models.Task) error { return r.t.insert(t, nil) }
+
+func (r *memTasks) FindByID(_ context.Context, id primitive.ObjectID) (*models.Task, error) {
+	return r.t.get(id)
+}
+
+func (r *memTasks) ListByBoard(_ context.Context, boardID primitive.ObjectID) ([]models.Task, error) {
+	out, err := r.t.filter(func(t *models.Task) bool { return t.BoardID == boardID })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].ColumnID != out[j].ColumnID {
+			return out[i].ColumnID < out[j].ColumnID
+		}
+		if out[i].Rank != out[j].Rank {
+			return out[i].Rank < out[j].Rank
+		}
+		return orderOf(&out[i]) < orderOf(&out[j])
+	})
+	return out, nil
+}
+
+func (r *memTasks) ListInRange(_ context.Context, scope TimelineScope, from, to time.Time) ([]models.Task, error) {
+	return r.t.filter(func(t *models.Task) bool {
+		if !containsID(scope.BoardIDs, t.BoardID) {
+			return false
+		}
+		return inRange(t.DueDate, from, to) || inRange(t.StartDate, from, to)
+	})
+}
+
+func (r *memTasks) ListByColumn(_ context.Context, boardID primitive.ObjectID, columnID string) ([]models.Task, error) {
+	out, err := r.t.filter(func(t *models.Task) bool { return t.BoardID == boardID && t.ColumnID == columnID })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Rank != out[j].Rank {
+			return out[i].Rank < out[j].Rank
+		}
+		if orderOf(&out[i]) != orderOf(&out[j]) {
+			return orderOf(&out[i]) < orderOf(&out[j])
+		}
+		return out[i].ID.Hex() < out[j].ID.Hex()
+	})
+	return out, nil
+}
+
+func (r *memTasks) MaxOrder(_ context.Context, boardID primitive.ObjectID, columnID string) (int, error) {
+	max := 0
+	r.t.mu.RLock()
+	defer r.t.mu.RUnlock()
+	for _, t := range r.t.rows {
+		if t.BoardID == boardID && t.ColumnID == columnID && !t.InTrash() && orderOf(t) > max {
+			max = orderOf(t)
+		}
+	}
+	return max, nil
+}
+
+func taskVersion(t *models.Task) int64 { return t.Version }
+
+func (r *memTasks) Update(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.bump(id, taskVersion, nil, set)
+}
+
+func (r *memTasks) UpdateIfVersion(_ context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return r.t.bump(id, taskVersion, &version, set)
+}
+
+func (r *memTasks) SetOrders(_ context.Context, changes []OrderChange) error {
+	r.t.mu.Lock()
+	defer r.t.mu.Unlock()
+	// cek semua dulu supaya perubahan all-or-nothing
+	for _, ch := range changes {
+		t, ok := r.t.rows[ch.ID]
+		if !ok || t.InTrash() || t.ColumnID != ch.FromColumn || !sameOrder(t.Order, ch.FromOrder) {
+			return ErrConflict
+		}
+	}
+	for _, ch := range changes {
+		t := r.t.rows[ch.ID]
+		v := ch.ToOrder
+		t.ColumnID = ch.ToColumn
+		t.Order = &v
+	}
+	return nil
+}
+
+func (r *memTasks) SetRanks(_ context.Context, changes []RankChange) error {
+	r.t.mu.Lock()
+	defer r.t.mu.Unlock()
+	for _, ch := range changes {
+		t, ok := r.t.rows[ch.ID]
+		if !ok || t.InTrash() || t.ColumnID != ch.FromColumn || t.Rank != ch.FromRank {
+			return ErrConflict
+		}
+	}
+	for _, ch := range changes {
+		t := r.t.rows[ch.ID]
+		t.ColumnID = ch.ToColumn
+		t.Rank = ch.ToRank
+	}
+	return nil
+}
+
+func sameOrder(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (r *memTasks) Delete(_ context.Context, id primitive.ObjectID) error {
+	r.t.remove(func(t *models.Task) bool { return t.ID == id })
+	return nil
+}
+
+func (r *memTasks) DeleteByBoard(_ context.Context, boardID primitive.ObjectID) error {
+	r.t.remove(func(t *models.Task) bool { return t.BoardID == boardID })
+	return nil
+}
+
+func (r *memTasks) SoftDelete(_ context.Context, id, by primitive.ObjectID, at time.Time) error {
+	return r.t.bump(id, taskVersion, nil, bson.M{"deletedAt": at, "deletedBy": by})
+}
+
+func (r *memTasks) FindDeleted(_ context.Context, id primitive.ObjectID) (*models.Task, error) {
+	return r.t.getTrashed(id)
+}
+
+func (r *memTasks) ListDeleted(_ context.Context, boardID primitive.ObjectID) ([]models.Task, error) {
+	out, err := r.t.filterTrashed(func(t *models.Task) bool { return t.BoardID == boardID })
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(*out[j].DeletedAt) })
+	return out, nil
+}
+
+func (r *memTasks) Restore(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.restore(id, taskVersion, set)
+}
+
+func (r *memTasks) ListDeletedBefore(_ context.Context, before time.Time, limit int) ([]models.Task, error) {
+	out, err := r.t.filterTrashed(func(t *models.Task) bool { return t.DeletedAt.Before(before) })
+	if err != nil {
+		return nil, err
+	}
+	return page(out, 0, limit), nil
+}
+
+// orderOf: order nil diperlakukan 0 (Mongo juga mengurutkan null paling awal)
+func orderOf(t *models.Task) int {
+	if t.Order == nil {
+		return 0
+	}
+	return *t.Order
+}
+
+func inRange(v *time.Time, from, to time.Time) bool {
+	return v != nil && !v.Before(from) && !v.After(to)
+}
+
+func (r *memTasks) Count(_ context.Context) (int64, error) { return r.t.count(nil), nil }
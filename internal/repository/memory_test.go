@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Backend memory dipakai unit test service sebagai pengganti Mongo, jadi
+// semantiknya harus sama: salinan terpisah, version, trash, unique index.
+
+func TestMemoryReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	b := &models.Board{ID: primitive.NewObjectID(), Name: "a", Version: 1}
+	if err := repos.Boards.Insert(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	b.Name = "changed after insert"
+
+	got, err := repos.Boards.FindByID(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Name = "changed after read"
+	again, err := repos.Boards.FindByID(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Name != "a" {
+		t.Fatalf("stored name = %q", again.Name)
+	}
+}
+
+func TestMemoryStoresMillisecondsLikeMongo(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	at := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	b := &models.Board{ID: primitive.NewObjectID(), TimeMeta: models.TimeMeta{CreatedAt: at}}
+	if err := repos.Boards.Insert(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.Boards.FindByID(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := at.Truncate(time.Millisecond); !got.CreatedAt.Equal(want) {
+		t.Fatalf("createdAt = %v, want %v", got.CreatedAt, want)
+	}
+}
+
+func TestMemoryUniqueIndexes(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	b := &models.Board{ID: primitive.NewObjectID()}
+	if err := repos.Boards.Insert(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Boards.Insert(ctx, b); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("same _id: err = %v, want ErrDuplicate", err)
+	}
+	if err := repos.Users.Insert(ctx, &models.User{ID: primitive.NewObjectID(), Email: "a@x.io"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Users.Insert(ctx, &models.User{ID: primitive.NewObjectID(), Email: "a@x.io"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("same email: err = %v, want ErrDuplicate", err)
+	}
+}
+
+func TestMemoryVersionedUpdates(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	task := &models.Task{ID: primitive.NewObjectID(), Title: "a", Version: 1}
+	if err := repos.Tasks.Insert(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repos.Tasks.Update(ctx, task.ID, bson.M{"title": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Tasks.UpdateIfVersion(ctx, task.ID, 1, bson.M{"title": "stale"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("stale version: err = %v, want ErrConflict", err)
+	}
+	if err := repos.Tasks.UpdateIfVersion(ctx, task.ID, 2, bson.M{"title": "c"}); err != nil {
+		t.Errorf("current version: %v", err)
+	}
+	if err := repos.Tasks.Update(ctx, primitive.NewObjectID(), bson.M{"title": "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown id: err = %v, want ErrNotFound", err)
+	}
+
+	got, err := repos.Tasks.FindByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "c" || got.Version != 3 {
+		t.Fatalf("task = %q version %d, want c version 3", got.Title, got.Version)
+	}
+}
+
+// key bertitik di $set mengisi dokumen bersarang, dokumen perantara dibuat
+func TestMemoryDottedSet(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	u := &models.User{ID: primitive.NewObjectID(), Email: "a@x.io", Name: "A"}
+	if err := repos.Users.Insert(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Users.Update(ctx, u.ID, bson.M{"mfa.pendingSecret": "S"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.Users.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MFA == nil || got.MFA.PendingSecret != "S" || got.Name != "A" {
+		t.Fatalf("user = %+v, mfa = %+v", got, got.MFA)
+	}
+}
+
+func TestMemoryTrash(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	board := primitive.NewObjectID()
+	order := 1
+	task := &models.Task{ID: primitive.NewObjectID(), BoardID: board, ColumnID: "todo", Order: &order, Version: 1}
+	if err := repos.Tasks.Insert(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+	by := primitive.NewObjectID()
+	if err := repos.Tasks.SoftDelete(ctx, task.ID, by, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repos.Tasks.FindByID(ctx, task.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindByID in trash: err = %v, want ErrNotFound", err)
+	}
+	if items, _ := repos.Tasks.ListByBoard(ctx, board); len(items) != 0 {
+		t.Errorf("ListByBoard lists %d trashed tasks", len(items))
+	}
+	if max, _ := repos.Tasks.MaxOrder(ctx, board, "todo"); max != 0 {
+		t.Errorf("MaxOrder counts trashed task: %d", max)
+	}
+	if err := repos.Tasks.Update(ctx, task.ID, bson.M{"title": "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update in trash: err = %v, want ErrNotFound", err)
+	}
+	if err := repos.Tasks.SoftDelete(ctx, task.ID, by, time.Now()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second SoftDelete: err = %v, want ErrNotFound", err)
+	}
+	trashed, err := repos.Tasks.FindDeleted(ctx, task.ID)
+	if err != nil || trashed.DeletedBy == nil || *trashed.DeletedBy != by {
+		t.Fatalf("FindDeleted = %+v, %v", trashed, err)
+	}
+
+	if err := repos.Tasks.Restore(ctx, task.ID, bson.M{"title": "back"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repos.Tasks.FindByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.InTrash() || got.Title != "back" || got.Version != 3 {
+		t.Errorf("restored task = %q trash=%v version %d", got.Title, got.InTrash(), got.Version)
+	}
+	if err := repos.Tasks.Restore(ctx, task.ID, bson.M{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Restore of a live task: err = %v, want ErrNotFound", err)
+	}
+}
+
+// memory menulis SetOrders sekaligus: satu perubahan basi, tidak ada yang ditulis
+func TestMemorySetOrdersIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	repos := NewMemory()
+	board := primitive.NewObjectID()
+	var ids []primitive.ObjectID
+	for i := 1; i <= 2; i++ {
+		o := i
+		task := &models.Task{ID: primitive.NewObjectID(), BoardID: board, ColumnID: "todo", Order: &o}
+		if err := repos.Tasks.Insert(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+	}
+	one, two, stale := 1, 2, 5
+	err := repos.Tasks.SetOrders(ctx, []OrderChange{
+		{ID: ids[0], FromColumn: "todo", FromOrder: &one, ToColumn: "todo", ToOrder: 2},
+		{ID: ids[1], FromColumn: "todo", FromOrder: &stale, ToColumn: "todo", ToOrder: 1},
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want ErrConflict", err)
+	}
+	items, err := repos.Tasks.ListByColumn(ctx, board, "todo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items[0].ID != ids[0] || *items[0].Order != one || *items[1].Order != two {
+		t.Fatalf("orders changed after a rejected SetOrders")
+	}
+}
+
+func TestMemoryTxSerializes(t *testing.T) {
+	repos := NewMemory()
+	ctx := context.Background()
+	n := 0
+	done := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		go func() {
+			_ = repos.Tx.WithTx(ctx, func(context.Context) error {
+				v := n
+				time.Sleep(time.Millisecond)
+				n = v + 1
+				return nil
+			})
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		<-done
+	}
+	if n != 20 {
+		t.Fatalf("n = %d, want 20: transactions overlapped", n)
+	}
+}
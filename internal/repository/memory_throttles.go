@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memThrottles struct {
+	t  *table[models.LoginThrottle]
+	mu sync.Mutex // RecordFailure = baca lalu tulis
+}
+
+func (r *memThrottles) Get(_ context.Context, key string) (*models.LoginThrottle, error) {
+	out, err := r.t.filter(func(t *models.LoginThrottle) bool { return t.Key == key })
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return &out[0], nil
+}
+
+func (r *memThrottles) RecordFailure(ctx context.Context, key string, now, expiresAt time.Time) (*models.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, err := r.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) || (err == nil && !now.Before(cur.ExpiresAt)) {
+		r.t.remove(func(t *models.LoginThrottle) bool { return t.Key == key })
+		t := &models.LoginThrottle{ID: primitive.NewObjectID(), Key: key, Failures: 1, LastFailureAt: now, ExpiresAt: expiresAt}
+		if err := r.t.insert(t, nil); err != nil {
+			return nil, err
+		}
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cur.ExpiresAt.After(expiresAt) {
+		expiresAt = cur.ExpiresAt
+	}
+	if err := r.t.set(cur.ID, bson.M{"failures": cur.Failures + 1, "lastFailureAt": now, "expiresAt": expiresAt}); err != nil {
+		return nil, err
+	}
+	return r.t.get(cur.ID)
+}
+
+func (r *memThrottles) Lock(ctx context.Context, key string, until, expiresAt time.Time) error {
+	cur, err := r.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.t.set(cur.ID, bson.M{"lockedUntil": until, "expiresAt": expiresAt})
+}
+
+func (r *memThrottles) Clear(_ context.Context, key string) error {
+	r.t.remove(func(t *models.LoginThrottle) bool { return t.Key == key })
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memUsers struct{ t *table[models.User] }
+
+func (r *memUsers) Insert(_ context.Context, u *models.User) error {
+	// sama seperti index uniq_email di Mongo
+	return r.t.insert(u, func(v *models.User) bool { return v.Email == u.Email })
+}
+
+func (r *memUsers) FindByID(_ context.Context, id primitive.ObjectID) (*models.User, error) {
+	return r.t.get(id)
+}
+
+func (r *memUsers) FindByEmail(_ context.Context, email string) (*models.User, error) {
+	out, err := r.t.filter(func(u *models.User) bool { return u.Email == email })
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return &out[0], nil
+}
+
+func (r *memUsers) Update(_ context.Context, id primitive.ObjectID, set bson.M) error {
+	return r.t.set(id, set)
+}
+
+func (r *memUsers) UseMFAStep(_ context.Context, id primitive.ObjectID, step int64) error {
+	return r.t.setIf(id, func(u *models.User) bool {
+		return u.MFA != nil && u.MFA.Secret != "" && u.MFA.LastStep < step
+	}, bson.M{"mfa.lastStep": step})
+}
+
+func (r *memUsers) UseRecoveryCode(_ context.Context, id primitive.ObjectID, hash string, at time.Time) error {
+	r.t.mu.Lock()
+	defer r.t.mu.Unlock()
+	u, ok := r.t.rows[id]
+	if !ok || u.MFA == nil {
+		return ErrNotFound
+	}
+	for i, h := range u.MFA.RecoveryHashes {
+		if h == hash {
+			u.MFA.RecoveryHashes = append(append([]string{}, u.MFA.RecoveryHashes[:i]...), u.MFA.RecoveryHashes[i+1:]...)
+			u.UpdatedAt = at
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *memUsers) FindByIDs(_ context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	want := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	return r.t.filter(func(u *models.User) bool { return want[u.ID] })
+}
+
+func (r *memUsers) Search(_ context.Context, f UserFilter, skip, limit int) ([]models.User, error) {
+	out, err := r.t.filter(func(u *models.User) bool { return matchUser(u, f) })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return page(out, skip, limit), nil
+}
+
+func (r *memUsers) Count(_ context.Context, f UserFilter) (int64, error) {
+	return r.t.count(func(u *models.User) bool { return matchUser(u, f) }), nil
+}
+
+func matchUser(u *models.User, f UserFilter) bool {
+	if f.Role != "" && u.Role != f.Role {
+		return false
+	}
+	if f.Active != nil && u.IsActive != *f.Active {
+		return false
+	}
+	if f.Query != "" {
+		q := strings.ToLower(f.Query)
+		return strings.Contains(strings.ToLower(u.Name), q) || strings.Contains(strings.ToLower(u.Email), q)
+	}
+	return true
+}
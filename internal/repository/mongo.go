@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// NewMongo: semua repository di atas satu database Mongo
+func NewMongo(db *mongo.Database) *Repos {
+	return &Repos{
+		Boards:      &mongoBoards{col: db.Collection("boards")},
+		Tasks:       &mongoTasks{col: db.Collection("tasks")},
+		Notes:       &mongoNotes{col: db.Collection("notes")},
+		Users:       &mongoUsers{col: db.Collection("users")},
+		Invitations: &mongoInvitations{col: db.Collection("invitations")},
+		Sessions:    &mongoSessions{col: db.Collection("sessions")},
+		LoginStates: &mongoLoginStates{col: db.Collection("login_states")},
+		EmailTokens: &mongoEmailTokens{col: db.Collection("email_tokens")},
+		Throttles:   &mongoThrottles{col: db.Collection("login_throttles")},
+		Audit:       &mongoAudit{col: db.Collection("audit_events")},
+		MFA:         &mongoMFAChallenges{col: db.Collection("mfa_challenges")},
+		Tokens:      &mongoAccessTokens{col: db.Collection("access_tokens")},
+		BoardEvents: &mongoBoardEvents{col: db.Collection("board_events"), seqs: db.Collection("board_event_seqs")},
+		Presence:    &mongoPresence{col: db.Collection("presence")},
+		TaskDocs:    &mongoTaskDocs{col: db.Collection("task_docs"), ops: db.Collection("task_doc_ops")},
+		Activities:  &mongoActivities{col: db.Collection("activities")},
+		Tx:          &mongoTx{client: db.Client()},
+	}
+}
+
+// mongoTx memakai session transaction. Transaksi hanya ada di replica set /
+// mongos; di server standalone WithTx mengembalikan ErrTxUnsupported supaya
+// pemanggil bisa pakai jalur optimistic.
+type mongoTx struct {
+	client *mongo.Client
+
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+func (t *mongoTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	ok, err := t.isSupported(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTxUnsupported
+	}
+	sess, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer sess.EndSession(ctx)
+	opts := options.Transaction().
+		SetReadConcern(readconcern.Snapshot()).
+		SetWriteConcern(writeconcern.Majority())
+	_, err = sess.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	}, opts)
+	return err
+}
+
+// isSupported: cek topologi sekali lewat perintah hello
+func (t *mongoTx) isSupported(ctx context.Context) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.checked {
+		return t.supported, nil
+	}
+	var hello bson.M
+	if err := t.client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	_, replset := hello["setName"]
+	t.supported = replset || hello["msg"] == "isdbgrid"
+	t.checked = true
+	return t.supported, nil
+}
+
+// mongoErr menerjemahkan error driver ke error repository
+func mongoErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
+
+// live: filter + hanya dokumen yang tidak di trash
+func live(filter bson.M) bson.M {
+	filter["deletedAt"] = nil
+	return filter
+}
+
+// nonNilIDs: $in menolak null, slice nil dijadikan array kosong
+func nonNilIDs(ids []primitive.ObjectID) []primitive.ObjectID {
+	if ids == nil {
+		return []primitive.ObjectID{}
+	}
+	return ids
+}
+
+// trashed: filter + hanya dokumen di trash
+func trashed(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$ne": nil}
+	return filter
+}
+
+// softDelete: pindah ke trash (version naik); ErrNotFound bila sudah di trash
+func softDelete(ctx context.Context, col *mongo.Collection, id, by primitive.ObjectID, at time.Time) error {
+	res, err := col.UpdateOne(ctx, live(bson.M{"_id": id}), bson.M{
+		"$set": bson.M{"deletedAt": at, "deletedBy": by},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// restoreDeleted: keluarkan dari trash + $set + version+1
+func restoreDeleted(ctx context.Context, col *mongo.Collection, id primitive.ObjectID, set bson.M) error {
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": "", "deletedBy": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	res, err := col.UpdateOne(ctx, trashed(bson.M{"_id": id}), update)
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// findDeleted: satu dokumen yang ada di trash
+func findDeleted[T any](ctx context.Context, col *mongo.Collection, id primitive.ObjectID) (*T, error) {
+	var out T
+	if err := col.FindOne(ctx, trashed(bson.M{"_id": id})).Decode(&out); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &out, nil
+}
+
+// updateVersioned: $set + version+1. expect nil = tanpa cek; selain itu hanya
+// bila version masih sama (dokumen lama tanpa field version dianggap 0).
+// Dokumen di trash dianggap tidak ada.
+func updateVersioned(ctx context.Context, col *mongo.Collection, id primitive.ObjectID, expect *int64, set bson.M) error {
+	filter := live(bson.M{"_id": id})
+	if expect != nil {
+		filter["version"] = *expect
+		if *expect == 0 {
+			filter["version"] = bson.M{"$in": bson.A{0, nil}}
+		}
+	}
+	res, err := col.UpdateOne(ctx, filter, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount > 0 {
+		return nil
+	}
+	if expect == nil {
+		return ErrNotFound
+	}
+	// bedakan dokumen hilang dengan version yang sudah berubah
+	n, err := col.CountDocuments(ctx, live(bson.M{"_id": id}), options.Count().SetLimit(1))
+	if err != nil {
+		return mongoErr(err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return ErrConflict
+}
+
+func findAll[T any](ctx context.Context, cur *mongo.Cursor, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+	var out []T
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoAccessTokens struct{ col *mongo.Collection }
+
+func (r *mongoAccessTokens) Insert(ctx context.Context, t *models.AccessToken) error {
+	_, err := r.col.InsertOne(ctx, t)
+	return mongoErr(err)
+}
+
+func (r *mongoAccessTokens) FindByHash(ctx context.Context, hash string) (*models.AccessToken, error) {
+	var t models.AccessToken
+	if err := r.col.FindOne(ctx, bson.M{"tokenHash": hash}).Decode(&t); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &t, nil
+}
+
+func (r *mongoAccessTokens) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.AccessToken, error) {
+	cur, err := r.col.Find(ctx,
+		bson.M{"userId": userID, "revokedAt": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+	return findAll[models.AccessToken](ctx, cur, err)
+}
+
+func (r *mongoAccessTokens) Revoke(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "userId": userID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": at}})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoAccessTokens) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, at time.Time) error {
+	_, err := r.col.UpdateMany(ctx,
+		bson.M{"userId": userID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": at}})
+	return mongoErr(err)
+}
+
+func (r *mongoAccessTokens) Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := r.col.UpdateByID(ctx, id, bson.M{"$set": bson.M{"lastUsedAt": at}})
+	return mongoErr(err)
+}
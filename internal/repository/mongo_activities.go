@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoActivities struct{ col *mongo.Collection }
+
+func (r *mongoActivities) Insert(ctx context.Context, a *models.Activity) error {
+	_, err := r.col.InsertOne(ctx, a)
+	return mongoErr(err)
+}
+
+func (r *mongoActivities) List(ctx context.Context, f ActivityFilter, before *primitive.ObjectID, limit int) ([]models.Activity, error) {
+	filter := bson.M{}
+	if f.BoardID != nil {
+		filter["boardId"] = *f.BoardID
+	}
+	if f.TaskID != nil {
+		filter["taskId"] = *f.TaskID
+	}
+	if before != nil {
+		filter["_id"] = bson.M{"$lt": *before}
+	}
+	cur, err := r.col.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+	return findAll[models.Activity](ctx, cur, err)
+}
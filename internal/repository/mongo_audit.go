@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoAudit struct{ col *mongo.Collection }
+
+func (r *mongoAudit) Insert(ctx context.Context, e *models.AuditEvent) error {
+	_, err := r.col.InsertOne(ctx, e)
+	return mongoErr(err)
+}
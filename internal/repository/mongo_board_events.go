@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoBoardEvents: log di board_events, counter per board di
+// board_event_seqs ({_id: boardId, seq})
+type mongoBoardEvents struct {
+	col  *mongo.Collection
+	seqs *mongo.Collection
+}
+
+type boardEventSeq struct {
+	Seq int64 `bson:"seq"`
+}
+
+func (r *mongoBoardEvents) NextSeq(ctx context.Context, boardID primitive.ObjectID) (int64, error) {
+	var s boardEventSeq
+	err := r.seqs.FindOneAndUpdate(ctx, bson.M{"_id": boardID}, bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&s)
+	if err != nil {
+		return 0, mongoErr(err)
+	}
+	return s.Seq, nil
+}
+
+func (r *mongoBoardEvents) LastSeq(ctx context.Context, boardID primitive.ObjectID) (int64, error) {
+	var s boardEventSeq
+	err := r.seqs.FindOne(ctx, bson.M{"_id": boardID}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return s.Seq, nil
+}
+
+func (r *mongoBoardEvents) Insert(ctx context.Context, e *models.BoardEvent) error {
+	_, err := r.col.InsertOne(ctx, e)
+	return mongoErr(err)
+}
+
+func (r *mongoBoardEvents) ListSince(ctx context.Context, boardID primitive.ObjectID, since int64) ([]models.BoardEvent, error) {
+	cur, err := r.col.Find(ctx, bson.M{"boardId": boardID, "seq": bson.M{"$gt": since}},
+		options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}))
+	return findAll[models.BoardEvent](ctx, cur, err)
+}
+
+func (r *mongoBoardEvents) Prune(ctx context.Context, boardID primitive.ObjectID, upTo int64) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"boardId": boardID, "seq": bson.M{"$lte": upTo}})
+	return mongoErr(err)
+}
+
+func (r *mongoBoardEvents) DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	if _, err := r.col.DeleteMany(ctx, bson.M{"boardId": boardID}); err != nil {
+		return mongoErr(err)
+	}
+	_, err := r.seqs.DeleteOne(ctx, bson.M{"_id": boardID})
+	return mongoErr(err)
+}
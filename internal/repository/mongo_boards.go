@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoBoards struct{ col *mongo.Collection }
+
+func (r *mongoBoards) Insert(ctx context.Context, b *models.Board) error {
+	_, err := r.col.InsertOne(ctx, b)
+	return mongoErr(err)
+}
+
+func (r *mongoBoards) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Board, error) {
+	var b models.Board
+	if err := r.col.FindOne(ctx, live(bson.M{"_id": id})).Decode(&b); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &b, nil
+}
+
+func (r *mongoBoards) ListForUser(ctx context.Context, userID primitive.ObjectID) ([]models.Board, error) {
+	cur, err := r.col.Find(ctx, live(bson.M{
+		"$or": []bson.M{
+			{"ownerId": userID},
+			{"members": userID},
+		},
+	}), options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+	return findAll[models.Board](ctx, cur, err)
+}
+
+func (r *mongoBoards) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	return updateVersioned(ctx, r.col, id, nil, set)
+}
+
+func (r *mongoBoards) UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return updateVersioned(ctx, r.col, id, &version, set)
+}
+
+func (r *mongoBoards) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
+	return mongoErr(err)
+}
+
+func (r *mongoBoards) SoftDelete(ctx context.Context, id, by primitive.ObjectID, at time.Time) error {
+	return softDelete(ctx, r.col, id, by, at)
+}
+
+func (r *mongoBoards) FindDeleted(ctx context.Context, id primitive.ObjectID) (*models.Board, error) {
+	return findDeleted[models.Board](ctx, r.col, id)
+}
+
+func (r *mongoBoards) ListDeletedByOwner(ctx context.Context, ownerID primitive.ObjectID) ([]models.Board, error) {
+	cur, err := r.col.Find(ctx, trashed(bson.M{"ownerId": ownerID}),
+		options.Find().SetSort(bson.D{{Key: "deletedAt", Value: -1}}))
+	return findAll[models.Board](ctx, cur, err)
+}
+
+func (r *mongoBoards) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]models.Board, error) {
+	cur, err := r.col.Find(ctx, bson.M{"deletedAt": bson.M{"$lt": before}}, options.Find().SetLimit(int64(limit)))
+	return findAll[models.Board](ctx, cur, err)
+}
+
+func (r *mongoBoards) Restore(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	return restoreDeleted(ctx, r.col, id, set)
+}
+
+func (r *mongoBoards) Search(ctx context.Context, f BoardFilter, skip, limit int) ([]models.Board, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cur, err := r.col.Find(ctx, boardFilterDoc(f), opts)
+	return findAll[models.Board](ctx, cur, err)
+}
+
+func (r *mongoBoards) Count(ctx context.Context, f BoardFilter) (int64, error) {
+	return r.col.CountDocuments(ctx, boardFilterDoc(f))
+}
+
+func boardFilterDoc(f BoardFilter) bson.M {
+	q := live(bson.M{})
+	if f.OwnerID != nil {
+		q["ownerId"] = *f.OwnerID
+	}
+	if f.Archived != nil {
+		q["isArchived"] = *f.Archived
+	}
+	if f.Query != "" {
+		q["name"] = primitive.Regex{Pattern: regexp.QuoteMeta(f.Query), Options: "i"}
+	}
+	return q
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoEmailTokens struct{ col *mongo.Collection }
+
+func (r *mongoEmailTokens) Insert(ctx context.Context, t *models.EmailToken) error {
+	_, err := r.col.InsertOne(ctx, t)
+	return mongoErr(err)
+}
+
+func (r *mongoEmailTokens) Consume(ctx context.Context, hash string, purpose models.EmailTokenPurpose, now time.Time) (*models.EmailToken, error) {
+	var t models.EmailToken
+	err := r.col.FindOneAndUpdate(ctx,
+		bson.M{"tokenHash": hash, "purpose": purpose, "usedAt": bson.M{"$exists": false}, "expiresAt": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"usedAt": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&t)
+	if err != nil {
+		return nil, mongoErr(err)
+	}
+	return &t, nil
+}
+
+func (r *mongoEmailTokens) InvalidateForUser(ctx context.Context, userID primitive.ObjectID, purpose models.EmailTokenPurpose, at time.Time) error {
+	_, err := r.col.UpdateMany(ctx,
+		bson.M{"userId": userID, "purpose": purpose, "usedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"usedAt": at}})
+	return mongoErr(err)
+}
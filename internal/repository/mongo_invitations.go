@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoInvitations struct{ col *mongo.Collection }
+
+var newestFirst = options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+func (r *mongoInvitations) Insert(ctx context.Context, inv *models.Invitation) error {
+	// uniq_pending_board_email (partial index) → ErrDuplicate
+	_, err := r.col.InsertOne(ctx, inv)
+	return mongoErr(err)
+}
+
+func (r *mongoInvitations) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invitation, error) {
+	var inv models.Invitation
+	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&inv); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &inv, nil
+}
+
+func (r *mongoInvitations) FindByTokenHash(ctx context.Context, hash string) (*models.Invitation, error) {
+	var inv models.Invitation
+	if err := r.col.FindOne(ctx, bson.M{"tokenHash": hash}).Decode(&inv); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &inv, nil
+}
+
+func (r *mongoInvitations) ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Invitation, error) {
+	cur, err := r.col.Find(ctx, bson.M{"boardId": boardID}, newestFirst)
+	return findAll[models.Invitation](ctx, cur, err)
+}
+
+func (r *mongoInvitations) ListPendingByEmail(ctx context.Context, email string) ([]models.Invitation, error) {
+	cur, err := r.col.Find(ctx, bson.M{"email": email, "status": models.InvitationPending}, newestFirst)
+	return findAll[models.Invitation](ctx, cur, err)
+}
+
+func (r *mongoInvitations) Resolve(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.InvitationPending},
+		bson.M{"$set": set})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
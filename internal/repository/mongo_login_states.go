@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoLoginStates struct{ col *mongo.Collection }
+
+func (r *mongoLoginStates) Insert(ctx context.Context, s *models.LoginState) error {
+	_, err := r.col.InsertOne(ctx, s)
+	return mongoErr(err)
+}
+
+func (r *mongoLoginStates) Take(ctx context.Context, state string) (*models.LoginState, error) {
+	var s models.LoginState
+	if err := r.col.FindOneAndDelete(ctx, bson.M{"state": state}).Decode(&s); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &s, nil
+}
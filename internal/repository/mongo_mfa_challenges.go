@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoMFAChallenges struct{ col *mongo.Collection }
+
+func (r *mongoMFAChallenges) Insert(ctx context.Context, c *models.MFAChallenge) error {
+	_, err := r.col.InsertOne(ctx, c)
+	return mongoErr(err)
+}
+
+func (r *mongoMFAChallenges) FindByTokenHash(ctx context.Context, hash string) (*models.MFAChallenge, error) {
+	var c models.MFAChallenge
+	if err := r.col.FindOne(ctx, bson.M{"tokenHash": hash}).Decode(&c); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &c, nil
+}
+
+func (r *mongoMFAChallenges) IncAttempts(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.col.UpdateByID(ctx, id, bson.M{"$inc": bson.M{"attempts": 1}})
+	return mongoErr(err)
+}
+
+func (r *mongoMFAChallenges) Take(ctx context.Context, id primitive.ObjectID) (*models.MFAChallenge, error) {
+	var c models.MFAChallenge
+	if err := r.col.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&c); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &c, nil
+}
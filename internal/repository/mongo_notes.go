@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoNotes struct{ col *mongo.Collection }
+
+func (r *mongoNotes) Insert(ctx context.Context, n *models.Note) error {
+	_, err := r.col.InsertOne(ctx, n)
+	return mongoErr(err)
+}
+
+func (r *mongoNotes) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Note, error) {
+	var n models.Note
+	if err := r.col.FindOne(ctx, live(bson.M{"_id": id})).Decode(&n); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &n, nil
+}
+
+func (r *mongoNotes) ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error) {
+	cur, err := r.col.Find(ctx, live(bson.M{"boardId": boardID}))
+	return findAll[models.Note](ctx, cur, err)
+}
+
+func (r *mongoNotes) ListByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Note, error) {
+	cur, err := r.col.Find(ctx, live(bson.M{"taskId": taskID}))
+	return findAll[models.Note](ctx, cur, err)
+}
+
+func (r *mongoNotes) ListInRange(ctx context.Context, scope TimelineScope, from, to time.Time) ([]models.Note, error) {
+	or := []bson.M{{"boardId": bson.M{"$in": nonNilIDs(scope.BoardIDs)}}}
+	if scope.AuthorID != nil {
+		or = append(or, bson.M{"boardId": nil, "authorId": *scope.AuthorID})
+	}
+	filter := live(bson.M{"onTimelineAt": bson.M{"$gte": from, "$lte": to}, "$or": or})
+	cur, err := r.col.Find(ctx, filter)
+	return findAll[models.Note](ctx, cur, err)
+}
+
+func (r *mongoNotes) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	return updateVersioned(ctx, r.col, id, nil, set)
+}
+
+func (r *mongoNotes) UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return updateVersioned(ctx, r.col, id, &version, set)
+}
+
+func (r *mongoNotes) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
+	return mongoErr(err)
+}
+
+func (r *mongoNotes) DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"boardId": boardID})
+	return mongoErr(err)
+}
+
+func (r *mongoNotes) DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"taskId": taskID})
+	return mongoErr(err)
+}
+
+func (r *mongoNotes) SoftDelete(ctx context.Context, id, by primitive.ObjectID, at time.Time) error {
+	return softDelete(ctx, r.col, id, by, at)
+}
+
+func (r *mongoNotes) SoftDeleteByTask(ctx context.Context, taskID, by primitive.ObjectID, at time.Time) error {
+	_, err := r.col.UpdateMany(ctx, live(bson.M{"taskId": taskID}), bson.M{
+		"$set": bson.M{"deletedAt": at, "deletedBy": by},
+		"$inc": bson.M{"version": 1},
+	})
+	return mongoErr(err)
+}
+
+func (r *mongoNotes) RestoreByTask(ctx context.Context, taskID primitive.ObjectID, at time.Time, set bson.M) error {
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": "", "deletedBy": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	_, err := r.col.UpdateMany(ctx, bson.M{"taskId": taskID, "deletedAt": at}, update)
+	return mongoErr(err)
+}
+
+func (r *mongoNotes) FindDeleted(ctx context.Context, id primitive.ObjectID) (*models.Note, error) {
+	return findDeleted[models.Note](ctx, r.col, id)
+}
+
+func (r *mongoNotes) ListDeletedByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error) {
+	cur, err := r.col.Find(ctx, trashed(bson.M{"boardId": boardID}),
+		options.Find().SetSort(bson.D{{Key: "deletedAt", Value: -1}}))
+	return findAll[models.Note](ctx, cur, err)
+}
+
+func (r *mongoNotes) Restore(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	return restoreDeleted(ctx, r.col, id, set)
+}
+
+func (r *mongoNotes) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.col.DeleteMany(ctx, bson.M{"deletedAt": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, mongoErr(err)
+	}
+	return res.DeletedCount, nil
+}
+
+func (r *mongoNotes) Count(ctx context.Context) (int64, error) {
+	return r.col.CountDocuments(ctx, live(bson.M{}))
+}
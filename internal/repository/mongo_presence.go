@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPresence struct{ col *mongo.Collection }
+
+func (r *mongoPresence) Upsert(ctx context.Context, p *models.Presence, now time.Time) (*models.Presence, error) {
+	if p.ID.IsZero() {
+		p.ID = primitive.NewObjectID()
+	}
+	var prev models.Presence
+	err := r.col.FindOneAndUpdate(ctx,
+		bson.M{"boardId": p.BoardID, "connectionId": p.ConnectionID},
+		bson.M{
+			"$set":         bson.M{"userId": p.UserID, "taskId": p.TaskID, "dragging": p.Dragging, "lastSeen": p.LastSeen, "expiresAt": p.ExpiresAt},
+			"$setOnInsert": bson.M{"_id": p.ID},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)).Decode(&prev)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mongoErr(err)
+	}
+	p.ID = prev.ID
+	if !prev.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return &prev, nil
+}
+
+func (r *mongoPresence) Touch(ctx context.Context, boardID primitive.ObjectID, connectionID string, lastSeen, expiresAt time.Time) error {
+	res, err := r.col.UpdateOne(ctx, bson.M{"boardId": boardID, "connectionId": connectionID},
+		bson.M{"$set": bson.M{"lastSeen": lastSeen, "expiresAt": expiresAt}})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPresence) Remove(ctx context.Context, boardID primitive.ObjectID, connectionID string) (*models.Presence, error) {
+	var p models.Presence
+	if err := r.col.FindOneAndDelete(ctx, bson.M{"boardId": boardID, "connectionId": connectionID}).Decode(&p); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &p, nil
+}
+
+func (r *mongoPresence) ListByBoard(ctx context.Context, boardID primitive.ObjectID, now time.Time) ([]models.Presence, error) {
+	cur, err := r.col.Find(ctx, bson.M{"boardId": boardID, "expiresAt": bson.M{"$gt": now}},
+		options.Find().SetSort(bson.D{{Key: "lastSeen", Value: 1}}))
+	return findAll[models.Presence](ctx, cur, err)
+}
+
+func (r *mongoPresence) TakeExpired(ctx context.Context, now time.Time) ([]models.Presence, error) {
+	expired := bson.M{"expiresAt": bson.M{"$lte": now}}
+	cur, err := r.col.Find(ctx, expired, options.Find().SetProjection(bson.M{"_id": 1}))
+	ids, err := findAll[struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}](ctx, cur, err)
+	if err != nil {
+		return nil, err
+	}
+	// hapus satu per satu: instance lain yang menyapu bersamaan tidak ikut
+	// mendapat entri yang sama
+	var out []models.Presence
+	for _, d := range ids {
+		var p models.Presence
+		err := r.col.FindOneAndDelete(ctx, bson.M{"_id": d.ID, "expiresAt": bson.M{"$lte": now}}).Decode(&p)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (r *mongoPresence) DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"boardId": boardID})
+	return mongoErr(err)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSessions struct{ col *mongo.Collection }
+
+func (r *mongoSessions) Insert(ctx context.Context, s *models.Session) error {
+	_, err := r.col.InsertOne(ctx, s)
+	return mongoErr(err)
+}
+
+func (r *mongoSessions) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error) {
+	var s models.Session
+	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&s); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &s, nil
+}
+
+func (r *mongoSessions) FindByRefreshHash(ctx context.Context, hash string) (*models.Session, error) {
+	var s models.Session
+	filter := bson.M{"$or": []bson.M{{"refreshHash": hash}, {"prevRefreshHash": hash}}}
+	if err := r.col.FindOne(ctx, filter).Decode(&s); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &s, nil
+}
+
+func (r *mongoSessions) ListActiveByUser(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]models.Session, error) {
+	cur, err := r.col.Find(ctx, bson.M{
+		"userId":    userID,
+		"revokedAt": bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": now},
+	}, options.Find().SetSort(bson.D{{Key: "lastUsedAt", Value: -1}}))
+	return findAll[models.Session](ctx, cur, err)
+}
+
+func (r *mongoSessions) Rotate(ctx context.Context, id primitive.ObjectID, oldHash string, set bson.M) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "refreshHash": oldHash, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": set})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoSessions) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	res, err := r.col.UpdateOne(ctx,
+		bson.M{"_id": id, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": at, "updatedAt": at}})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		// sudah dicabut → tetap sukses; tidak ada → ErrNotFound
+		_, err := r.FindByID(ctx, id)
+		return err
+	}
+	return nil
+}
+
+func (r *mongoSessions) RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, at time.Time) error {
+	_, err := r.col.UpdateMany(ctx,
+		bson.M{"userId": userID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": at, "updatedAt": at}})
+	return mongoErr(err)
+}
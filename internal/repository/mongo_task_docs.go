@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoTaskDocs: dokumen di task_docs (_id = id task), log op di task_doc_ops
+type mongoTaskDocs struct {
+	col *mongo.Collection
+	ops *mongo.Collection
+}
+
+// unsaved: rev > savedRev
+var unsaved = bson.M{"$expr": bson.M{"$gt": bson.A{"$rev", "$savedRev"}}}
+
+func (r *mongoTaskDocs) Get(ctx context.Context, taskID primitive.ObjectID) (*models.TaskDoc, error) {
+	var d models.TaskDoc
+	if err := r.col.FindOne(ctx, bson.M{"_id": taskID}).Decode(&d); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &d, nil
+}
+
+func (r *mongoTaskDocs) Create(ctx context.Context, d *models.TaskDoc) error {
+	_, err := r.col.InsertOne(ctx, d)
+	return mongoErr(err)
+}
+
+func (r *mongoTaskDocs) Advance(ctx context.Context, taskID primitive.ObjectID, rev int64, text string, op *models.TaskDocOp) error {
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": taskID, "rev": rev}, bson.M{
+		"$set": bson.M{"text": text, "rev": rev + 1, "updatedBy": op.UserID, "updatedAt": op.CreatedAt},
+	})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	_, err = r.ops.InsertOne(ctx, op)
+	return mongoErr(err)
+}
+
+func (r *mongoTaskDocs) ListOps(ctx context.Context, taskID primitive.ObjectID, after int64) ([]models.TaskDocOp, error) {
+	cur, err := r.ops.Find(ctx, bson.M{"taskId": taskID, "rev": bson.M{"$gt": after}},
+		options.Find().SetSort(bson.D{{Key: "rev", Value: 1}}))
+	return findAll[models.TaskDocOp](ctx, cur, err)
+}
+
+func (r *mongoTaskDocs) PruneOps(ctx context.Context, taskID primitive.ObjectID, upTo int64) error {
+	_, err := r.ops.DeleteMany(ctx, bson.M{"taskId": taskID, "rev": bson.M{"$lte": upTo}})
+	return mongoErr(err)
+}
+
+func (r *mongoTaskDocs) ListUnsaved(ctx context.Context, limit int) ([]models.TaskDoc, error) {
+	cur, err := r.col.Find(ctx, unsaved, options.Find().SetLimit(int64(limit)))
+	return findAll[models.TaskDoc](ctx, cur, err)
+}
+
+func (r *mongoTaskDocs) MarkSaved(ctx context.Context, taskID primitive.ObjectID, from, rev int64) error {
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": taskID, "savedRev": from}, bson.M{"$set": bson.M{"savedRev": rev}})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTaskDocs) DeleteIdle(ctx context.Context, before time.Time) error {
+	cur, err := r.col.Find(ctx, bson.M{
+		"updatedAt": bson.M{"$lt": before},
+		"$expr":     bson.M{"$lte": bson.A{"$rev", "$savedRev"}},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	idle, err := findAll[models.TaskDoc](ctx, cur, err)
+	if err != nil || len(idle) == 0 {
+		return err
+	}
+	ids := make([]primitive.ObjectID, 0, len(idle))
+	for _, d := range idle {
+		ids = append(ids, d.ID)
+	}
+	// updatedAt dicek ulang: dokumen yang baru saja diedit tidak ikut terhapus
+	if _, err := r.col.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "updatedAt": bson.M{"$lt": before}}); err != nil {
+		return mongoErr(err)
+	}
+	_, err = r.ops.DeleteMany(ctx, bson.M{"taskId": bson.M{"$in": ids}, "createdAt": bson.M{"$lt": before}})
+	return mongoErr(err)
+}
+
+func (r *mongoTaskDocs) Delete(ctx context.Context, taskID primitive.ObjectID) error {
+	if _, err := r.col.DeleteOne(ctx, bson.M{"_id": taskID}); err != nil {
+		return mongoErr(err)
+	}
+	_, err := r.ops.DeleteMany(ctx, bson.M{"taskId": taskID})
+	return mongoErr(err)
+}
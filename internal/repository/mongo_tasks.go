@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTasks struct{ col *mongo.Collection }
+
+func (r *mongoTasks) Insert(ctx context.Context, t *models.Task) error {
+	_, err := r.col.InsertOne(ctx, t)
+	return mongoErr(err)
+}
+
+func (r *mongoTasks) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
+	var t models.Task
+	if err := r.col.FindOne(ctx, live(bson.M{"_id": id})).Decode(&t); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &t, nil
+}
+
+func (r *mongoTasks) ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error) {
+	cur, err := r.col.Find(ctx, live(bson.M{"boardId": boardID}),
+		options.Find().SetSort(bson.D{{Key: "columnId", Value: 1}, {Key: "rank", Value: 1}, {Key: "order", Value: 1}}))
+	return findAll[models.Task](ctx, cur, err)
+}
+
+func (r *mongoTasks) ListInRange(ctx context.Context, scope TimelineScope, from, to time.Time) ([]models.Task, error) {
+	filter := live(bson.M{
+		"boardId": bson.M{"$in": nonNilIDs(scope.BoardIDs)},
+		"$or": []bson.M{
+			{"dueDate": bson.M{"$gte": from, "$lte": to}},
+			{"startDate": bson.M{"$gte": from, "$lte": to}},
+		},
+	})
+	cur, err := r.col.Find(ctx, filter)
+	return findAll[models.Task](ctx, cur, err)
+}
+
+func (r *mongoTasks) ListByColumn(ctx context.Context, boardID primitive.ObjectID, columnID string) ([]models.Task, error) {
+	cur, err := r.col.Find(ctx, live(bson.M{"boardId": boardID, "columnId": columnID}),
+		options.Find().SetSort(bson.D{{Key: "rank", Value: 1}, {Key: "order", Value: 1}, {Key: "_id", Value: 1}}))
+	return findAll[models.Task](ctx, cur, err)
+}
+
+func (r *mongoTasks) MaxOrder(ctx context.Context, boardID primitive.ObjectID, columnID string) (int, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "order", Value: -1}})
+	var t models.Task
+	err := r.col.FindOne(ctx, live(bson.M{"boardId": boardID, "columnId": columnID}), opts).Decode(&t)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil // kolom kosong
+	}
+	if err != nil {
+		return 0, err
+	}
+	if t.Order == nil {
+		return 0, nil
+	}
+	return *t.Order, nil
+}
+
+func (r *mongoTasks) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	return updateVersioned(ctx, r.col, id, nil, set)
+}
+
+func (r *mongoTasks) UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	return updateVersioned(ctx, r.col, id, &version, set)
+}
+
+func (r *mongoTasks) SetOrders(ctx context.Context, changes []OrderChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	ops := make([]mongo.WriteModel, 0, len(changes))
+	for _, ch := range changes {
+		var from interface{} // nil → cocok dengan order null / tidak ada
+		if ch.FromOrder != nil {
+			from = *ch.FromOrder
+		}
+		ops = append(ops, mongo.NewUpdateOneModel().
+			SetFilter(live(bson.M{"_id": ch.ID, "columnId": ch.FromColumn, "order": from})).
+			SetUpdate(bson.M{"$set": bson.M{"columnId": ch.ToColumn, "order": ch.ToOrder}}))
+	}
+	// ordered: berhenti di perubahan pertama yang tidak cocok; yang sebelumnya
+	// tetap tertulis bila di luar transaksi (lihat kontrak di TaskRepo)
+	res, err := r.col.BulkWrite(ctx, ops, options.BulkWrite().SetOrdered(true))
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount != int64(len(changes)) {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTasks) SetRanks(ctx context.Context, changes []RankChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	ops := make([]mongo.WriteModel, 0, len(changes))
+	for _, ch := range changes {
+		var from interface{} // "" → belum punya rank (null / tidak ada)
+		if ch.FromRank != "" {
+			from = ch.FromRank
+		}
+		ops = append(ops, mongo.NewUpdateOneModel().
+			SetFilter(live(bson.M{"_id": ch.ID, "columnId": ch.FromColumn, "rank": from})).
+			SetUpdate(bson.M{"$set": bson.M{"columnId": ch.ToColumn, "rank": ch.ToRank}}))
+	}
+	res, err := r.col.BulkWrite(ctx, ops, options.BulkWrite().SetOrdered(true))
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount != int64(len(changes)) {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTasks) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"_id": id})
+	return mongoErr(err)
+}
+
+func (r *mongoTasks) DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	_, err := r.col.DeleteMany(ctx, bson.M{"boardId": boardID})
+	return err
+}
+
+func (r *mongoTasks) SoftDelete(ctx context.Context, id, by primitive.ObjectID, at time.Time) error {
+	return softDelete(ctx, r.col, id, by, at)
+}
+
+func (r *mongoTasks) FindDeleted(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
+	return findDeleted[models.Task](ctx, r.col, id)
+}
+
+func (r *mongoTasks) ListDeleted(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error) {
+	cur, err := r.col.Find(ctx, trashed(bson.M{"boardId": boardID}),
+		options.Find().SetSort(bson.D{{Key: "deletedAt", Value: -1}}))
+	return findAll[models.Task](ctx, cur, err)
+}
+
+func (r *mongoTasks) Restore(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	return restoreDeleted(ctx, r.col, id, set)
+}
+
+func (r *mongoTasks) ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]models.Task, error) {
+	cur, err := r.col.Find(ctx, bson.M{"deletedAt": bson.M{"$lt": before}}, options.Find().SetLimit(int64(limit)))
+	return findAll[models.Task](ctx, cur, err)
+}
+
+func (r *mongoTasks) Count(ctx context.Context) (int64, error) {
+	return r.col.CountDocuments(ctx, live(bson.M{}))
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoThrottles struct{ col *mongo.Collection }
+
+func (r *mongoThrottles) Get(ctx context.Context, key string) (*models.LoginThrottle, error) {
+	var t models.LoginThrottle
+	if err := r.col.FindOne(ctx, bson.M{"key": key}).Decode(&t); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &t, nil
+}
+
+func (r *mongoThrottles) RecordFailure(ctx context.Context, key string, now, expiresAt time.Time) (*models.LoginThrottle, error) {
+	// update pipeline: reset ke 1 bila record lama sudah kedaluwarsa (TTL
+	// monitor Mongo bisa terlambat sampai ±1 menit)
+	live := bson.M{"$gt": bson.A{"$expiresAt", now}}
+	pipeline := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"key":           key,
+		"failures":      bson.M{"$cond": bson.A{live, bson.M{"$add": bson.A{"$failures", 1}}, 1}},
+		"lastFailureAt": now,
+		"expiresAt":     bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$expiresAt", expiresAt}}, "$expiresAt", expiresAt}},
+	}}}}
+	var t models.LoginThrottle
+	err := r.col.FindOneAndUpdate(ctx, bson.M{"key": key}, pipeline,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&t)
+	if err != nil {
+		return nil, mongoErr(err)
+	}
+	return &t, nil
+}
+
+func (r *mongoThrottles) Lock(ctx context.Context, key string, until, expiresAt time.Time) error {
+	_, err := r.col.UpdateOne(ctx, bson.M{"key": key},
+		bson.M{"$set": bson.M{"lockedUntil": until, "expiresAt": expiresAt}})
+	return mongoErr(err)
+}
+
+func (r *mongoThrottles) Clear(ctx context.Context, key string) error {
+	_, err := r.col.DeleteOne(ctx, bson.M{"key": key})
+	return mongoErr(err)
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoUsers struct{ col *mongo.Collection }
+
+func (r *mongoUsers) Insert(ctx context.Context, u *models.User) error {
+	_, err := r.col.InsertOne(ctx, u)
+	return mongoErr(err)
+}
+
+func (r *mongoUsers) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	var u models.User
+	if err := r.col.FindOne(ctx, bson.M{"_id": id}).Decode(&u); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &u, nil
+}
+
+func (r *mongoUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var u models.User
+	if err := r.col.FindOne(ctx, bson.M{"email": email}).Decode(&u); err != nil {
+		return nil, mongoErr(err)
+	}
+	return &u, nil
+}
+
+func (r *mongoUsers) Update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	res, err := r.col.UpdateByID(ctx, id, bson.M{"$set": set})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUsers) UseMFAStep(ctx context.Context, id primitive.ObjectID, step int64) error {
+	res, err := r.col.UpdateOne(ctx, bson.M{
+		"_id":        id,
+		"mfa.secret": bson.M{"$exists": true},
+		"$or": []bson.M{
+			{"mfa.lastStep": bson.M{"$lt": step}},
+			{"mfa.lastStep": bson.M{"$exists": false}},
+		},
+	}, bson.M{"$set": bson.M{"mfa.lastStep": step}})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoUsers) UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string, at time.Time) error {
+	res, err := r.col.UpdateOne(ctx, bson.M{"_id": id, "mfa.recoveryHashes": hash}, bson.M{
+		"$pull": bson.M{"mfa.recoveryHashes": hash},
+		"$set":  bson.M{"updatedAt": at},
+	})
+	if err != nil {
+		return mongoErr(err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoUsers) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	cur, err := r.col.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return findAll[models.User](ctx, cur, err)
+}
+
+func (r *mongoUsers) Search(ctx context.Context, f UserFilter, skip, limit int) ([]models.User, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cur, err := r.col.Find(ctx, userFilterDoc(f), opts)
+	return findAll[models.User](ctx, cur, err)
+}
+
+func (r *mongoUsers) Count(ctx context.Context, f UserFilter) (int64, error) {
+	return r.col.CountDocuments(ctx, userFilterDoc(f))
+}
+
+func userFilterDoc(f UserFilter) bson.M {
+	q := bson.M{}
+	if f.Role != "" {
+		q["role"] = f.Role
+	}
+	if f.Active != nil {
+		q["isActive"] = *f.Active
+	}
+	if f.Query != "" {
+		re := primitive.Regex{Pattern: regexp.QuoteMeta(f.Query), Options: "i"}
+		q["$or"] = []bson.M{{"name": re}, {"email": re}}
+	}
+	return q
+}
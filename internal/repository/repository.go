@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("already exists")
+	// ErrConflict: dokumen berubah sejak dibaca (update kondisional gagal)
+	ErrConflict = errors.New("concurrent modification")
+	// ErrTxUnsupported: backend tidak mendukung transaksi (mis. Mongo standalone)
+	ErrTxUnsupported = errors.New("transactions not supported")
+)
+
+// Repos mengumpulkan semua repository satu backend (mongo / memory).
+type Repos struct {
+	Boards      BoardRepo
+	Tasks       TaskRepo
+	Notes       NoteRepo
+	Users       UserRepo
+	Invitations InvitationRepo
+	Sessions    SessionRepo
+	LoginStates LoginStateRepo
+	EmailTokens EmailTokenRepo
+	Throttles   LoginThrottleRepo
+	Audit       AuditRepo
+	MFA         MFAChallengeRepo
+	Tokens      AccessTokenRepo
+	BoardEvents BoardEventRepo
+	Presence    PresenceRepo
+	TaskDocs    TaskDocRepo
+	Activities  ActivityRepo
+	Tx          Transactor
+}
+
+type Transactor interface {
+	// WithTx menjalankan fn dalam satu transaksi. Repository harus dipanggil
+	// dengan ctx milik fn agar ikut transaksi. fn bisa diulang bila transien.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OrderChange memindah satu task ke (ToColumn, ToOrder), hanya bila posisinya
+// masih (FromColumn, FromOrder) seperti saat dibaca.
+type OrderChange struct {
+	ID         primitive.ObjectID
+	FromColumn string
+	FromOrder  *int
+	ToColumn   string
+	ToOrder    int
+}
+
+// RankChange: seperti OrderChange tapi untuk mode rank
+type RankChange struct {
+	ID         primitive.ObjectID
+	FromColumn string
+	FromRank   string
+	ToColumn   string
+	ToRank     string
+}
+
+type BoardRepo interface {
+	Insert(ctx context.Context, b *models.Board) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Board, error)
+	// ListForUser: board milik user atau yang ia ikuti, terbaru dulu (updatedAt desc)
+	ListForUser(ctx context.Context, userID primitive.ObjectID) ([]models.Board, error)
+	// Update menerapkan set dan menaikkan version
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// UpdateIfVersion: seperti Update, tapi ErrConflict bila version sudah berbeda
+	UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	// Delete menghapus permanen (purge); biasanya lewat SoftDelete dulu
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// SoftDelete memindah ke trash (version naik); ErrNotFound bila tidak ada
+	// atau sudah di trash. Semua method lain mengabaikan dokumen di trash
+	// kecuali yang namanya menyebut Deleted.
+	SoftDelete(ctx context.Context, id, by primitive.ObjectID, at time.Time) error
+	FindDeleted(ctx context.Context, id primitive.ObjectID) (*models.Board, error)
+	// ListDeletedByOwner: trash milik owner, terakhir dihapus dulu
+	ListDeletedByOwner(ctx context.Context, ownerID primitive.ObjectID) ([]models.Board, error)
+	// ListDeletedBefore: board yang masuk trash sebelum before (untuk purge)
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]models.Board, error)
+	// Restore mengeluarkan dari trash, menerapkan set & menaikkan version;
+	// ErrNotFound bila tidak ada di trash
+	Restore(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// Search: semua board (bukan hanya milik user), terbaru dulu (createdAt desc)
+	Search(ctx context.Context, f BoardFilter, skip, limit int) ([]models.Board, error)
+	Count(ctx context.Context, f BoardFilter) (int64, error)
+}
+
+type TaskRepo interface {
+	Insert(ctx context.Context, t *models.Task) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Task, error)
+	// ListByBoard: urut columnId, rank, lalu order (tanpa rank → urut order)
+	ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error)
+	// ListInRange: task board dalam scope dengan dueDate/startDate di [from, to]
+	ListInRange(ctx context.Context, scope TimelineScope, from, to time.Time) ([]models.Task, error)
+	// ListByColumn: urut rank, order, _id
+	ListByColumn(ctx context.Context, boardID primitive.ObjectID, columnID string) ([]models.Task, error)
+	MaxOrder(ctx context.Context, boardID primitive.ObjectID, columnID string) (int, error)
+	// Update menerapkan set dan menaikkan version
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// UpdateIfVersion: seperti Update, tapi ErrConflict bila version sudah berbeda
+	UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	// SetOrders menerapkan semua perubahan; ErrConflict bila ada task yang
+	// posisinya sudah tidak sama dengan From*. Version tidak dinaikkan
+	// (hanya renumber tetangga). Di luar transaksi Mongo tidak all-or-nothing:
+	// perubahan sebelum yang konflik tetap tersimpan (memory: tidak ada yang
+	// ditulis); pemanggil harus membaca ulang dan merapikan kolom.
+	SetOrders(ctx context.Context, changes []OrderChange) error
+	SetRanks(ctx context.Context, changes []RankChange) error
+	// Delete & DeleteByBoard menghapus permanen (termasuk yang di trash)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error
+	// SoftDelete, FindDeleted & Restore: seperti di BoardRepo. Task di trash
+	// tetap menyimpan columnId & order/rank posisi terakhirnya.
+	SoftDelete(ctx context.Context, id, by primitive.ObjectID, at time.Time) error
+	FindDeleted(ctx context.Context, id primitive.ObjectID) (*models.Task, error)
+	// ListDeleted: trash board, terakhir dihapus dulu
+	ListDeleted(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error)
+	Restore(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// ListDeletedBefore: task yang masuk trash sebelum before (untuk purge)
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]models.Task, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+type NoteRepo interface {
+	Insert(ctx context.Context, n *models.Note) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Note, error)
+	ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error)
+	ListByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Note, error)
+	// ListInRange: note dalam scope dengan onTimelineAt di [from, to]
+	ListInRange(ctx context.Context, scope TimelineScope, from, to time.Time) ([]models.Note, error)
+	// Update menerapkan set dan menaikkan version
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// UpdateIfVersion: seperti Update, tapi ErrConflict bila version sudah berbeda
+	UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error
+	// Delete, DeleteByBoard & DeleteByTask menghapus permanen (termasuk yang di trash)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error
+	DeleteByTask(ctx context.Context, taskID primitive.ObjectID) error
+	// SoftDelete, FindDeleted & Restore: seperti di BoardRepo
+	SoftDelete(ctx context.Context, id, by primitive.ObjectID, at time.Time) error
+	// SoftDeleteByTask: semua note task ikut ke trash dengan deletedAt yang sama
+	SoftDeleteByTask(ctx context.Context, taskID, by primitive.ObjectID, at time.Time) error
+	// RestoreByTask: keluarkan note task yang masuk trash bersama task-nya
+	// (deletedAt = at); note yang dihapus sendiri sebelumnya tetap di trash
+	RestoreByTask(ctx context.Context, taskID primitive.ObjectID, at time.Time, set bson.M) error
+	FindDeleted(ctx context.Context, id primitive.ObjectID) (*models.Note, error)
+	// ListDeletedByBoard: trash board, terakhir dihapus dulu
+	ListDeletedByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error)
+	Restore(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// PurgeDeleted menghapus permanen note yang masuk trash sebelum before
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+type UserRepo interface {
+	Insert(ctx context.Context, u *models.User) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByIDs: user yang ditemukan saja (urutan tidak dijamin)
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error)
+	Update(ctx context.Context, id primitive.ObjectID, set bson.M) error
+	// UseMFAStep menyimpan langkah TOTP hanya bila lebih baru dari mfa.lastStep
+	// (atomik, anti replay); ErrConflict bila langkah itu sudah dipakai
+	UseMFAStep(ctx context.Context, id primitive.ObjectID, step int64) error
+	// UseRecoveryCode menghapus satu hash kode pemulihan (atomik); ErrNotFound
+	// bila tidak ada, termasuk bila baru saja dipakai request lain
+	UseRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string, at time.Time) error
+	// Search: terbaru dulu (createdAt desc), untuk admin console
+	Search(ctx context.Context, f UserFilter, skip, limit int) ([]models.User, error)
+	Count(ctx context.Context, f UserFilter) (int64, error)
+}
+
+// TimelineScope: isi timeline yang boleh dilihat. Tidak ada "semua board":
+// BoardIDs kosong = tidak ada task/note board.
+type TimelineScope struct {
+	BoardIDs []primitive.ObjectID
+	AuthorID *primitive.ObjectID // nil = tanpa note pribadi (tanpa board)
+}
+
+// UserFilter: field kosong/nil = tidak difilter
+type UserFilter struct {
+	Query  string // potongan nama atau email, tanpa beda huruf besar/kecil
+	Role   models.UserRole
+	Active *bool
+}
+
+// BoardFilter: field kosong/nil = tidak difilter
+type BoardFilter struct {
+	Query    string // potongan nama board
+	OwnerID  *primitive.ObjectID
+	Archived *bool
+}
+
+type InvitationRepo interface {
+	// Insert: ErrDuplicate bila sudah ada undangan pending untuk board+email
+	Insert(ctx context.Context, inv *models.Invitation) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Invitation, error)
+	FindByTokenHash(ctx context.Context, hash string) (*models.Invitation, error)
+	// ListByBoard / ListPendingByEmail: terbaru dulu
+	ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Invitation, error)
+	ListPendingByEmail(ctx context.Context, email string) ([]models.Invitation, error)
+	// Resolve mengubah undangan yang masih pending; ErrConflict bila sudah tidak pending
+	Resolve(ctx context.Context, id primitive.ObjectID, set bson.M) error
+}
+
+type SessionRepo interface {
+	Insert(ctx context.Context, s *models.Session) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Session, error)
+	// FindByRefreshHash mencocokkan refreshHash maupun prevRefreshHash
+	FindByRefreshHash(ctx context.Context, hash string) (*models.Session, error)
+	// ListActiveByUser: belum dicabut & belum kedaluwarsa, terakhir dipakai dulu
+	ListActiveByUser(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]models.Session, error)
+	// Rotate menerapkan set hanya bila refreshHash masih oldHash (ErrConflict bila tidak)
+	Rotate(ctx context.Context, id primitive.ObjectID, oldHash string, set bson.M) error
+	Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, at time.Time) error
+}
+
+type LoginStateRepo interface {
+	Insert(ctx context.Context, s *models.LoginState) error
+	// Take mengambil sekaligus menghapus state (sekali pakai); ErrNotFound bila tidak ada
+	Take(ctx context.Context, state string) (*models.LoginState, error)
+}
+
+type EmailTokenRepo interface {
+	Insert(ctx context.Context, t *models.EmailToken) error
+	// Consume menandai token terpakai bila belum dipakai & belum kedaluwarsa
+	// (atomik, sekali pakai); ErrNotFound bila tidak ada yang cocok
+	Consume(ctx context.Context, hash string, purpose models.EmailTokenPurpose, now time.Time) (*models.EmailToken, error)
+	// InvalidateForUser menandai semua token purpose milik user sebagai terpakai
+	InvalidateForUser(ctx context.Context, userID primitive.ObjectID, purpose models.EmailTokenPurpose, at time.Time) error
+}
+
+type LoginThrottleRepo interface {
+	// Get: ErrNotFound bila belum ada kegagalan untuk key
+	Get(ctx context.Context, key string) (*models.LoginThrottle, error)
+	// RecordFailure menambah failures secara atomik; record yang sudah lewat
+	// expiresAt mulai lagi dari 1
+	RecordFailure(ctx context.Context, key string, now, expiresAt time.Time) (*models.LoginThrottle, error)
+	Lock(ctx context.Context, key string, until, expiresAt time.Time) error
+	// Clear menghapus hitungan (login sukses / unlock admin)
+	Clear(ctx context.Context, key string) error
+}
+
+type AuditRepo interface {
+	Insert(ctx context.Context, e *models.AuditEvent) error
+}
+
+type MFAChallengeRepo interface {
+	Insert(ctx context.Context, c *models.MFAChallenge) error
+	FindByTokenHash(ctx context.Context, hash string) (*models.MFAChallenge, error)
+	IncAttempts(ctx context.Context, id primitive.ObjectID) error
+	// Take menghapus & mengembalikan challenge (sekali pakai); ErrNotFound bila sudah diambil
+	Take(ctx context.Context, id primitive.ObjectID) (*models.MFAChallenge, error)
+}
+
+type AccessTokenRepo interface {
+	Insert(ctx context.Context, t *models.AccessToken) error
+	FindByHash(ctx context.Context, hash string) (*models.AccessToken, error)
+	// ListByUser: yang belum dicabut (termasuk kedaluwarsa), terbaru dulu
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.AccessToken, error)
+	// Revoke: ErrNotFound bila token bukan milik userID atau sudah dicabut
+	Revoke(ctx context.Context, userID, id primitive.ObjectID, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID primitive.ObjectID, at time.Time) error
+	Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+type BoardEventRepo interface {
+	// NextSeq menaikkan counter board secara atomik dan mengembalikan nilai barunya
+	NextSeq(ctx context.Context, boardID primitive.ObjectID) (int64, error)
+	// LastSeq: nilai counter sekarang, 0 bila board belum punya event
+	LastSeq(ctx context.Context, boardID primitive.ObjectID) (int64, error)
+	Insert(ctx context.Context, e *models.BoardEvent) error
+	// ListSince: event dengan seq > since, urut seq naik
+	ListSince(ctx context.Context, boardID primitive.ObjectID, since int64) ([]models.BoardEvent, error)
+	// Prune menghapus event dengan seq <= upTo
+	Prune(ctx context.Context, boardID primitive.ObjectID, upTo int64) error
+	// DeleteByBoard menghapus log sekaligus counter board
+	DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error
+}
+
+type PresenceRepo interface {
+	// Upsert menyimpan p per (boardId, connectionId); mengembalikan isi
+	// sebelumnya (nil bila baru atau sudah kedaluwarsa)
+	Upsert(ctx context.Context, p *models.Presence, now time.Time) (*models.Presence, error)
+	// Touch memperpanjang expiresAt; ErrNotFound bila entri tidak ada
+	Touch(ctx context.Context, boardID primitive.ObjectID, connectionID string, lastSeen, expiresAt time.Time) error
+	// Remove menghapus & mengembalikan entri; ErrNotFound bila tidak ada
+	Remove(ctx context.Context, boardID primitive.ObjectID, connectionID string) (*models.Presence, error)
+	// ListByBoard: entri yang belum kedaluwarsa, urut lastSeen
+	ListByBoard(ctx context.Context, boardID primitive.ObjectID, now time.Time) ([]models.Presence, error)
+	// TakeExpired menghapus & mengembalikan entri kedaluwarsa; tiap entri
+	// hanya dikembalikan ke satu pemanggil (aman dipanggil beberapa instance)
+	TakeExpired(ctx context.Context, now time.Time) ([]models.Presence, error)
+	DeleteByBoard(ctx context.Context, boardID primitive.ObjectID) error
+}
+
+type TaskDocRepo interface {
+	Get(ctx context.Context, taskID primitive.ObjectID) (*models.TaskDoc, error)
+	// Create: ErrDuplicate bila task sudah punya dokumen
+	Create(ctx context.Context, d *models.TaskDoc) error
+	// Advance menyimpan text sebagai rev+1 lalu mencatat op (op.Rev = rev+1);
+	// ErrConflict bila rev dokumen sudah bukan rev
+	Advance(ctx context.Context, taskID primitive.ObjectID, rev int64, text string, op *models.TaskDocOp) error
+	// ListOps: op dengan rev > after, urut rev naik
+	ListOps(ctx context.Context, taskID primitive.ObjectID, after int64) ([]models.TaskDocOp, error)
+	// PruneOps menghapus op dengan rev <= upTo
+	PruneOps(ctx context.Context, taskID primitive.ObjectID, upTo int64) error
+	// ListUnsaved: dokumen dengan rev > savedRev
+	ListUnsaved(ctx context.Context, limit int) ([]models.TaskDoc, error)
+	// MarkSaved: savedRev = rev hanya bila savedRev masih from (ErrConflict bila tidak)
+	MarkSaved(ctx context.Context, taskID primitive.ObjectID, from, rev int64) error
+	// DeleteIdle menghapus dokumen yang sudah tersimpan dan tidak diubah sejak
+	// before, beserta op-nya
+	DeleteIdle(ctx context.Context, before time.Time) error
+	Delete(ctx context.Context, taskID primitive.ObjectID) error
+}
+
+type ActivityRepo interface {
+	Insert(ctx context.Context, a *models.Activity) error
+	// List: terbaru dulu (_id desc), hanya yang _id < before (nil = dari awal)
+	List(ctx context.Context, f ActivityFilter, before *primitive.ObjectID, limit int) ([]models.Activity, error)
+}
+
+// ActivityFilter: tepat satu field diisi
+type ActivityFilter struct {
+	BoardID *primitive.ObjectID
+	TaskID  *primitive.ObjectID
+}
@@ -1,8 +1,10 @@
 package routes
 
 import (
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/authz"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/handlers"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/middleware"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -13,50 +15,128 @@ func Register(
 	tasks *handlers.TaskHandler,
 	notes *handlers.NoteHandler,
 	timeline *handlers.TimelineHandler,
+	invitations *handlers.InvitationHandler,
+	sso *handlers.OIDCHandler,
+	mfa *handlers.MFAHandler,
+	users *handlers.UserHandler,
+	admin *handlers.AdminHandler,
+	tokens *handlers.AccessTokenHandler,
 	dev *handlers.DevHandler,
 ) {
+	// Public key JWT untuk service lain
+	app.Get("/.well-known/jwks.json", auth.JWKS)
+
 	api := app.Group("/api")
 
 	// Public
 	api.Post("/login", auth.Login)
 	api.Post("/register", auth.Register)
+	api.Post("/auth/refresh", auth.Refresh)
+	api.Post("/auth/forgot", auth.ForgotPassword)
+	api.Post("/auth/reset", auth.ResetPassword)
+	api.Post("/auth/verify", auth.VerifyEmail)
+	api.Post("/auth/verify/resend", auth.ResendVerification)
+	api.Get("/auth/oidc/login", sso.Login)
+	api.Get("/auth/oidc/callback", sso.Callback)
+	api.Post("/auth/mfa/verify", mfa.Verify)
+
+	// Protected (JWT atau personal access token)
+	prot := api.Group("", middleware.JWTProtected(auth.Sessions, tokens.Svc))
+	// route akun & keamanan: hanya login biasa, bukan access token
+	sess := middleware.SessionOnly()
+
+	// Sessions
+	prot.Post("/auth/logout", sess, auth.Logout)
+	prot.Get("/me/sessions", sess, auth.ListSessions)
+	prot.Delete("/me/sessions/:id", sess, auth.RevokeSession)
+
+	// Profil & akun
+	prot.Get("/me", users.Me)
+	prot.Patch("/me", sess, users.UpdateMe)
+	prot.Post("/me/password", sess, users.ChangePassword)
+	prot.Post("/me/deactivate", sess, users.DeactivateMe)
+	prot.Get("/users", users.List) // ?ids=a,b,c
+	prot.Get("/users/:id", users.Get)
 
-	// Protected (JWT)
-	prot := api.Group("", middleware.JWTProtected())
+	// Personal access tokens
+	prot.Get("/me/tokens", sess, tokens.List)
+	prot.Post("/me/tokens", sess, tokens.Create)
+	prot.Delete("/me/tokens/:id", sess, tokens.Revoke)
 
-	// Dev seed
-	prot.Post("/dev/seed", dev.Seed)
+	// 2FA
+	prot.Get("/me/mfa", sess, mfa.Status)
+	prot.Post("/me/mfa/totp/enroll", sess, mfa.Enroll)
+	prot.Post("/me/mfa/totp/confirm", sess, mfa.Confirm)
+	prot.Post("/me/mfa/recovery-codes", sess, mfa.RegenerateRecoveryCodes)
+	prot.Delete("/me/mfa", sess, mfa.Disable)
+
+	// Admin console (role global admin)
+	adm := prot.Group("/admin", sess, middleware.RequireRole(models.RoleAdmin))
+	adm.Get("/stats", admin.Stats)
+	adm.Get("/users", admin.ListUsers) // ?q=&role=&active=&offset=&limit=
+	adm.Get("/users/:id", admin.GetUser)
+	adm.Put("/users/:id/role", admin.SetRole)
+	adm.Post("/users/:id/deactivate", admin.Deactivate)
+	adm.Post("/users/:id/reactivate", admin.Reactivate)
+	adm.Post("/users/:id/force-reset", admin.ForcePasswordReset)
+	adm.Post("/users/:id/unlock", admin.Unlock)
+	adm.Get("/boards", admin.ListBoards) // ?q=&ownerId=&archived=&offset=&limit=
+	adm.Get("/boards/:id", admin.GetBoard)
+	adm.Post("/boards/:id/transfer", admin.TransferBoard)
+
+	// Dev seed: hanya terdaftar bila DEV_MODE=true (dev nil di luar dev mode)
+	if dev != nil {
+		prot.Post("/dev/seed", sess, dev.Seed)
+	}
 
 	// Boards
-	prot.Post("/boards", boards.Create)
+	prot.Post("/boards", middleware.RequireScope(authz.BoardCreate), boards.Create)
 	prot.Get("/boards", boards.List) // list milik user; tak perlu guard tambahan
-	prot.Get("/boards/:id", middleware.BoardAccessByBoardPath("id"), boards.Get)
-	prot.Patch("/boards/:id", middleware.BoardAccessByBoardPath("id"), boards.Update)
-	prot.Delete("/boards/:id", middleware.BoardAccessByBoardPath("id"), boards.Delete)
+	prot.Get("/boards/trash", boards.ListDeleted)
+	prot.Get("/boards/:id", middleware.BoardAccessByBoardPath("id", authz.BoardRead), boards.Get)
+	prot.Patch("/boards/:id", middleware.BoardAccessByBoardPath("id", authz.BoardUpdate), boards.Update)
+	prot.Delete("/boards/:id", middleware.BoardAccessByBoardPath("id", authz.BoardDelete), boards.Delete)
+	prot.Post("/boards/:id/archive", middleware.BoardAccessByBoardPath("id", authz.BoardUpdate), boards.Archive)
+	prot.Post("/boards/:id/unarchive", middleware.BoardAccessByBoardPath("id", authz.BoardUpdate), boards.Unarchive)
+	prot.Get("/boards/:id/events", middleware.BoardAccessByBoardPath("id", authz.BoardRead), boards.EventsSince) // ?since=<seq>
+	prot.Get("/boards/:id/activity", middleware.BoardAccessByBoardPath("id", authz.BoardRead), boards.ListActivity)
+	prot.Get("/boards/:id/presence", middleware.BoardAccessByBoardPath("id", authz.BoardRead), boards.ListPresence)
+	prot.Get("/boards/:id/trash", middleware.BoardAccessByBoardPath("id", authz.BoardRead), boards.ListTrash)
+	prot.Post("/boards/:id/restore", middleware.DeletedBoardAccessByPath("id", authz.BoardDelete), boards.Restore)
+	prot.Post("/boards/:id/reorder", middleware.BoardAccessByBoardPath("id", authz.TaskWrite), tasks.Reorder)
+	prot.Put("/boards/:id/members/:userId", middleware.BoardAccessByBoardPath("id", authz.BoardMembers), boards.SetMember)
+	prot.Delete("/boards/:id/members/:userId", middleware.BoardAccessByBoardPath("id", authz.BoardMembers), boards.RemoveMember)
+
+	// Invitations
+	prot.Post("/boards/:id/invitations", middleware.BoardAccessByBoardPath("id", authz.BoardMembers), invitations.Create)
+	prot.Get("/boards/:id/invitations", middleware.BoardAccessByBoardPath("id", authz.BoardMembers), invitations.ListForBoard)
+	prot.Delete("/boards/:id/invitations/:inviteId", middleware.BoardAccessByBoardPath("id", authz.BoardMembers), invitations.Revoke)
+	prot.Get("/me/invitations", invitations.ListMine) // undangan ke email user sendiri
+	prot.Post("/invitations/accept", sess, invitations.AcceptToken)
+	prot.Post("/invitations/:id/accept", sess, invitations.Accept)
+	prot.Post("/invitations/:id/decline", sess, invitations.Decline)
 
 	// Tasks (scoped by board)
-	prot.Get("/boards/:boardId/tasks", middleware.BoardAccessByBoardPath("boardId"), tasks.ListByBoard)
-	prot.Post("/boards/:boardId/tasks", middleware.BoardAccessByBoardPath("boardId"), tasks.Create)
+	prot.Get("/boards/:boardId/tasks", middleware.BoardAccessByBoardPath("boardId", authz.TaskRead), tasks.ListByBoard)
+	prot.Post("/boards/:boardId/tasks", middleware.BoardAccessByBoardPath("boardId", authz.TaskWrite), tasks.Create)
 
 	// Single task ops (guard by task -> resolve board)
-	prot.Get("/tasks/:id", middleware.BoardAccessByTaskPath("id"), tasks.Get)
-	prot.Patch("/tasks/:id", middleware.BoardAccessByTaskPath("id"), tasks.Update)
-	prot.Delete("/tasks/:id", middleware.BoardAccessByTaskPath("id"), tasks.Delete)
-	prot.Post("/tasks/:id/move", middleware.BoardAccessByTaskPath("id"), tasks.Move)
+	prot.Get("/tasks/:id", middleware.BoardAccessByTaskPath("id", authz.TaskRead), tasks.Get)
+	prot.Patch("/tasks/:id", middleware.BoardAccessByTaskPath("id", authz.TaskWrite), tasks.Update)
+	prot.Delete("/tasks/:id", middleware.BoardAccessByTaskPath("id", authz.TaskWrite), tasks.Delete)
+	prot.Post("/tasks/:id/move", middleware.BoardAccessByTaskPath("id", authz.TaskWrite), tasks.Move)
+	prot.Get("/tasks/:id/activity", middleware.BoardAccessByTaskPath("id", authz.TaskRead), tasks.ListActivity)
+	prot.Post("/tasks/:id/restore", middleware.DeletedTaskAccessByPath("id", authz.TaskWrite), tasks.Restore)
 
 	// Notes
-	prot.Post("/notes", notes.Create) // create boleh; validasi akses dilakukan saat baca
-	prot.Get("/boards/:boardId/notes", middleware.BoardAccessByBoardPath("boardId"), notes.ListByBoard)
-	prot.Get("/tasks/:taskId/notes", middleware.BoardAccessByTaskPath("taskId"), notes.ListByTask)
-	prot.Patch("/notes/:id", notes.Update)
-	prot.Delete("/notes/:id", notes.Delete)
+	prot.Post("/notes", middleware.RequireScope(authz.NoteWrite), notes.Create) // cek note:write di handler (board diturunkan dari task)
+	prot.Get("/boards/:boardId/notes", middleware.BoardAccessByBoardPath("boardId", authz.NoteRead), notes.ListByBoard)
+	prot.Get("/tasks/:taskId/notes", middleware.BoardAccessByTaskPath("taskId", authz.NoteRead), notes.ListByTask)
+	prot.Patch("/notes/:id", middleware.NoteAccessByPath("id"), notes.Update)
+	prot.Delete("/notes/:id", middleware.NoteAccessByPath("id"), notes.Delete)
+	prot.Post("/notes/:id/restore", middleware.DeletedNoteAccessByPath("id"), notes.Restore)
 
 	// Timeline (guard jika ada boardId query)
 	// Timeline (jika ada ?boardId=, guard member/owner)
-	prot.Get("/timeline", middleware.BoardAccessByBoardQuery("boardId"), timeline.Get)
-
-	// Whoami
-	prot.Get("/me", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{"userId": c.Locals("userId")})
-	})
+	prot.Get("/timeline", middleware.BoardAccessByBoardQuery("boardId", authz.BoardRead), timeline.Get)
 }
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessTokenPrefix: awalan personal access token; membedakannya dari JWT
+const AccessTokenPrefix = "pat_"
+
+// AccessTokenService: personal access token untuk skrip & bot
+type AccessTokenService interface {
+	// Create mengembalikan token plain (hanya sekali, tidak bisa dilihat lagi)
+	Create(ctx context.Context, userID primitive.ObjectID, name string, scopes []models.TokenScope, expiresAt *time.Time) (*models.AccessToken, string, error)
+	List(ctx context.Context, userID primitive.ObjectID) ([]models.AccessToken, error)
+	Revoke(ctx context.Context, userID, id primitive.ObjectID) error
+	RevokeAll(ctx context.Context, userID primitive.ObjectID) error
+	// Authenticate dipakai JWTProtected: token aktif milik user aktif.
+	// Hasilnya di-cache sebentar per proses, seperti status session.
+	Authenticate(ctx context.Context, token string) (*models.AccessToken, error)
+}
+
+const (
+	maxAccessTokensPerUser = 50
+	maxTokenNameLength     = 100
+)
+
+var (
+	ErrInvalidAccessToken = errors.New("invalid, expired or revoked access token")
+	ErrInvalidTokenName   = fmt.Errorf("name must be 1-%d characters", maxTokenNameLength)
+	ErrInvalidTokenScope  = errors.New("scopes must be one or more of: read, tasks:write, boards:admin")
+	ErrTokenExpiry        = errors.New("expiresAt must be in the future")
+	ErrTooManyTokens      = fmt.Errorf("at most %d access tokens per user", maxAccessTokensPerUser)
+	ErrAccessTokenUnknown = errors.New("access token not found")
+)
+
+type accessTokenService struct {
+	tokens repository.AccessTokenRepo
+	users  repository.UserRepo
+
+	mu    sync.Mutex
+	cache map[string]tokenCacheEntry // per hash token
+}
+
+type tokenCacheEntry struct {
+	token *models.AccessToken // nil = tidak valid
+	until time.Time
+}
+
+func NewAccessTokenService(tokens repository.AccessTokenRepo, users repository.UserRepo) AccessTokenService {
+	return &accessTokenService{tokens: tokens, users: users, cache: map[string]tokenCacheEntry{}}
+}
+
+func (s *accessTokenService) Create(ctx context.Context, userID primitive.ObjectID, name string, scopes []models.TokenScope, expiresAt *time.Time) (*models.AccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxTokenNameLength {
+		return nil, "", ErrInvalidTokenName
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", ErrTokenExpiry
+	}
+	existing, err := s.tokens.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= maxAccessTokensPerUser {
+		return nil, "", ErrTooManyTokens
+	}
+	secret, _, err := utils.NewToken()
+	if err != nil {
+		return nil, "", err
+	}
+	plain := AccessTokenPrefix + secret
+	t := &models.AccessToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Name:      name,
+		Scopes:    scopes,
+		TokenHash: utils.HashToken(plain),
+		Prefix:    plain[:len(AccessTokenPrefix)+8],
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	if err := s.tokens.Insert(ctx, t); err != nil {
+		return nil, "", err
+	}
+	return t, plain, nil
+}
+
+// normalizeScopes: minimal satu, tanpa duplikat
+func normalizeScopes(scopes []models.TokenScope) ([]models.TokenScope, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidTokenScope
+	}
+	seen := map[models.TokenScope]bool{}
+	out := make([]models.TokenScope, 0, len(scopes))
+	for _, sc := range scopes {
+		if !models.ValidTokenScope(sc) {
+			return nil, ErrInvalidTokenScope
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	return out, nil
+}
+
+func (s *accessTokenService) List(ctx context.Context, userID primitive.ObjectID) ([]models.AccessToken, error) {
+	return s.tokens.ListByUser(ctx, userID)
+}
+
+func (s *accessTokenService) Revoke(ctx context.Context, userID, id primitive.ObjectID) error {
+	if err := s.tokens.Revoke(ctx, userID, id, time.Now().UTC()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrAccessTokenUnknown
+		}
+		return err
+	}
+	s.forget(func(t *models.AccessToken) bool { return t.ID == id })
+	return nil
+}
+
+func (s *accessTokenService) RevokeAll(ctx context.Context, userID primitive.ObjectID) error {
+	if err := s.tokens.RevokeAllForUser(ctx, userID, time.Now().UTC()); err != nil {
+		return err
+	}
+	s.forget(func(t *models.AccessToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func (s *accessTokenService) Authenticate(ctx context.Context, token string) (*models.AccessToken, error) {
+	if !strings.HasPrefix(token, AccessTokenPrefix) {
+		return nil, ErrInvalidAccessToken
+	}
+	hash := utils.HashToken(token)
+	now := time.Now()
+	s.mu.Lock()
+	e, ok := s.cache[hash]
+	s.mu.Unlock()
+	if ok && now.Before(e.until) {
+		if e.token == nil || !e.token.Active(now) {
+			return nil, ErrInvalidAccessToken
+		}
+		return e.token, nil
+	}
+
+	t, err := s.tokens.FindByHash(ctx, hash)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.remember(hash, nil)
+		return nil, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !t.Active(now) {
+		s.remember(hash, nil)
+		return nil, ErrInvalidAccessToken
+	}
+	u, err := s.users.FindByID(ctx, t.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || !u.IsActive {
+		s.remember(hash, nil)
+		return nil, ErrInvalidAccessToken
+	}
+	// lastUsedAt ditulis paling sering sekali per masa cache
+	used := now.UTC()
+	if err := s.tokens.Touch(ctx, t.ID, used); err != nil {
+		return nil, err
+	}
+	t.LastUsedAt = &used
+	s.remember(hash, t)
+	return t, nil
+}
+
+func (s *accessTokenService) remember(hash string, t *models.AccessToken) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.cache) > 10000 {
+		for k, e := range s.cache {
+			if now.After(e.until) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	s.cache[hash] = tokenCacheEntry{token: t, until: now.Add(sessionCacheTTL)}
+}
+
+// forget membuang token yang dicabut dari cache (berlaku langsung di instance ini)
+func (s *accessTokenService) forget(match func(*models.AccessToken) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, e := range s.cache {
+		if e.token != nil && match(e.token) {
+			delete(s.cache, k)
+		}
+	}
+}
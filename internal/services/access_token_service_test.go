@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAccessTokenCreate(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	svc := NewAccessTokenService(repos.Tokens, repos.Users)
+	u := seedUser(t, repos, "ci@x.io", true)
+
+	past := time.Now().Add(-time.Minute)
+	rejects := []struct {
+		name    string
+		tokName string
+		scopes  []models.TokenScope
+		expires *time.Time
+		want    error
+	}{
+		{"blank name", "   ", []models.TokenScope{models.ScopeRead}, nil, ErrInvalidTokenName},
+		{"long name", strings.Repeat("n", maxTokenNameLength+1), []models.TokenScope{models.ScopeRead}, nil, ErrInvalidTokenName},
+		{"no scopes", "ci", nil, nil, ErrInvalidTokenScope},
+		{"unknown scope", "ci", []models.TokenScope{"admin"}, nil, ErrInvalidTokenScope},
+		{"expired already", "ci", []models.TokenScope{models.ScopeRead}, &past, ErrTokenExpiry},
+	}
+	for _, tc := range rejects {
+		if _, _, err := svc.Create(ctx, u.ID, tc.tokName, tc.scopes, tc.expires); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	tok, plain, err := svc.Create(ctx, u.ID, " ci bot ", []models.TokenScope{models.ScopeTasksWrite, models.ScopeRead, models.ScopeTasksWrite}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(plain, AccessTokenPrefix) || !strings.HasPrefix(plain, tok.Prefix) || tok.Name != "ci bot" {
+		t.Errorf("token %q = %+v", plain, tok)
+	}
+	if len(tok.Scopes) != 2 {
+		t.Errorf("duplicate scopes kept: %v", tok.Scopes)
+	}
+	// yang disimpan hanya hash
+	stored, err := repos.Tokens.FindByHash(ctx, utils.HashToken(plain))
+	if err != nil || stored.TokenHash == plain || strings.Contains(stored.TokenHash, plain) {
+		t.Errorf("stored token = %+v, %v", stored, err)
+	}
+
+	for i := 1; i < maxAccessTokensPerUser; i++ {
+		if _, _, err := svc.Create(ctx, u.ID, "bulk", []models.TokenScope{models.ScopeRead}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := svc.Create(ctx, u.ID, "one more", []models.TokenScope{models.ScopeRead}, nil); !errors.Is(err, ErrTooManyTokens) {
+		t.Errorf("over the limit: err = %v", err)
+	}
+}
+
+func TestAccessTokenAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	svc := NewAccessTokenService(repos.Tokens, repos.Users)
+	u := seedUser(t, repos, "ci@x.io", true)
+	tok, plain, err := svc.Create(ctx, u.ID, "ci", []models.TokenScope{models.ScopeRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.Authenticate(ctx, plain)
+	if err != nil || got.ID != tok.ID || got.UserID != u.ID {
+		t.Fatalf("authenticate = %+v, %v", got, err)
+	}
+	list, _ := svc.List(ctx, u.ID)
+	if len(list) != 1 || list[0].LastUsedAt == nil {
+		t.Errorf("lastUsedAt not recorded: %+v", list)
+	}
+
+	for _, bad := range []string{"", plain[len(AccessTokenPrefix):], plain + "x", AccessTokenPrefix} {
+		if _, err := svc.Authenticate(ctx, bad); !errors.Is(err, ErrInvalidAccessToken) {
+			t.Errorf("token %q: err = %v", bad, err)
+		}
+	}
+
+	// token yang kedaluwarsa atau milik user nonaktif ditolak
+	soon := time.Now().Add(50 * time.Millisecond)
+	_, short, err := svc.Create(ctx, u.ID, "short", []models.TokenScope{models.ScopeRead}, &soon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := svc.Authenticate(ctx, short); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("expired token: err = %v", err)
+	}
+	off := seedUser(t, repos, "off@x.io", true)
+	_, offToken, err := svc.Create(ctx, off.ID, "ci", []models.TokenScope{models.ScopeRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Users.Update(ctx, off.ID, bson.M{"isActive": false}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Authenticate(ctx, offToken); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("inactive user: err = %v", err)
+	}
+}
+
+func TestAccessTokenRevoke(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	svc := NewAccessTokenService(repos.Tokens, repos.Users)
+	u := seedUser(t, repos, "ci@x.io", true)
+	other := seedUser(t, repos, "other@x.io", true)
+	tok, plain, err := svc.Create(ctx, u.ID, "ci", []models.TokenScope{models.ScopeRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := svc.Create(ctx, u.ID, "bot", []models.TokenScope{models.ScopeRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sudah di-cache sebelum dicabut
+	if _, err := svc.Authenticate(ctx, plain); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.Revoke(ctx, other.ID, tok.ID); !errors.Is(err, ErrAccessTokenUnknown) {
+		t.Errorf("revoke someone else's token: err = %v", err)
+	}
+	if err := svc.Revoke(ctx, u.ID, primitive.NewObjectID()); !errors.Is(err, ErrAccessTokenUnknown) {
+		t.Errorf("revoke unknown token: err = %v", err)
+	}
+	if err := svc.Revoke(ctx, u.ID, tok.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Authenticate(ctx, plain); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("revoked token still accepted: err = %v", err)
+	}
+	if err := svc.Revoke(ctx, u.ID, tok.ID); !errors.Is(err, ErrAccessTokenUnknown) {
+		t.Errorf("revoke twice: err = %v", err)
+	}
+	if list, _ := svc.List(ctx, u.ID); len(list) != 1 || list[0].Name != "bot" {
+		t.Errorf("list after revoke = %+v", list)
+	}
+
+	if err := svc.RevokeAll(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Authenticate(ctx, second); !errors.Is(err, ErrInvalidAccessToken) {
+		t.Errorf("token after RevokeAll: err = %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/mailer"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountService: lupa/reset password dan verifikasi email lewat token
+// sekali pakai yang dikirim dengan Mailer.
+type AccountService interface {
+	// ForgotPassword selalu sukses untuk email tak dikenal (tidak membocorkan akun)
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword mengganti password dan mencabut semua session user
+	ResetPassword(ctx context.Context, token, password string) error
+	// SendVerification mengirim link verifikasi ke email user (no-op bila sudah terverifikasi)
+	SendVerification(ctx context.Context, u *models.User) error
+	// ResendVerification: seperti ForgotPassword, tidak membocorkan akun
+	ResendVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	// ChangePassword butuh password lama; session lain (selain keep) dicabut
+	ChangePassword(ctx context.Context, userID, keepSession primitive.ObjectID, current, password string) error
+	// Deactivate menonaktifkan akun (IsActive=false) dan mencabut semua
+	// session & personal access token-nya
+	Deactivate(ctx context.Context, userID primitive.ObjectID) error
+	// ForceReset (admin): login password ditolak sampai user memakai link reset
+	// yang dikirim ke emailnya; semua session & access token dicabut
+	ForceReset(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// AccountOptions: isi dari config
+type AccountOptions struct {
+	AppURL    string // link di email: AppURL/reset-password?token=..., AppURL/verify-email?token=...
+	ResetTTL  time.Duration
+	VerifyTTL time.Duration
+}
+
+var (
+	ErrInvalidEmailToken     = errors.New("invalid or expired token")
+	ErrEmailNotVerified      = errors.New("email not verified")
+	ErrPasswordResetRequired = errors.New("password reset required; check your email for the reset link")
+)
+
+// mailTimeout: batas kirim email yang berjalan di background
+const mailTimeout = 30 * time.Second
+
+type accountService struct {
+	users    repository.UserRepo
+	tokens   repository.EmailTokenRepo
+	sessions SessionService
+	pats     AccessTokenService
+	mail     mailer.Mailer
+	opts     AccountOptions
+}
+
+func NewAccountService(users repository.UserRepo, tokens repository.EmailTokenRepo, sessions SessionService, pats AccessTokenService, mail mailer.Mailer, opts AccountOptions) AccountService {
+	return &accountService{users: users, tokens: tokens, sessions: sessions, pats: pats, mail: mail, opts: opts}
+}
+
+func (s *accountService) ForgotPassword(ctx context.Context, email string) error {
+	u, err := s.users.FindByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !u.IsActive {
+		return nil
+	}
+	token, err := s.issue(ctx, u, models.PurposePasswordReset, s.opts.ResetTTL)
+	if err != nil {
+		return err
+	}
+	s.deliver(mailer.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Text: fmt.Sprintf("Hi %s,\n\nSomeone (hopefully you) asked to reset your password. Open this link to choose a new one:\n\n%s\n\nThe link expires in %s and can be used once. If you did not ask for this, you can ignore this email.\n",
+			u.Name, s.link("/reset-password", token), ttlText(s.opts.ResetTTL)),
+	})
+	return nil
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, token, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	t, err := s.tokens.Consume(ctx, utils.HashToken(token), models.PurposePasswordReset, now)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidEmailToken
+	}
+	if err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	set := bson.M{"passwordHash": hash, "passwordResetRequired": false, "updatedAt": now}
+	// link dari email membuktikan kepemilikan alamat
+	u, err := s.users.FindByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt == nil && strings.EqualFold(u.Email, t.Email) {
+		set["emailVerifiedAt"] = now
+	}
+	if err := s.users.Update(ctx, t.UserID, set); err != nil {
+		return err
+	}
+	// token reset lain & semua login lama tidak berlaku lagi
+	if err := s.tokens.InvalidateForUser(ctx, t.UserID, models.PurposePasswordReset, now); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(ctx, t.UserID)
+}
+
+func (s *accountService) SendVerification(ctx context.Context, u *models.User) error {
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+	token, err := s.issue(ctx, u, models.PurposeVerifyEmail, s.opts.VerifyTTL)
+	if err != nil {
+		return err
+	}
+	s.deliver(mailer.Message{
+		To:      u.Email,
+		Subject: "Confirm your email address",
+		Text: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\nThe link expires in %s.\n",
+			u.Name, s.link("/verify-email", token), ttlText(s.opts.VerifyTTL)),
+	})
+	return nil
+}
+
+func (s *accountService) ResendVerification(ctx context.Context, email string) error {
+	u, err := s.users.FindByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.SendVerification(ctx, u)
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	now := time.Now().UTC()
+	t, err := s.tokens.Consume(ctx, utils.HashToken(token), models.PurposeVerifyEmail, now)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInvalidEmailToken
+	}
+	if err != nil {
+		return err
+	}
+	u, err := s.users.FindByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+	// email sudah diganti setelah token dikirim → token untuk alamat lama
+	if !strings.EqualFold(u.Email, t.Email) {
+		return ErrInvalidEmailToken
+	}
+	if u.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.users.Update(ctx, u.ID, bson.M{"emailVerifiedAt": now, "updatedAt": now})
+}
+
+func (s *accountService) ChangePassword(ctx context.Context, userID, keepSession primitive.ObjectID, current, password string) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !utils.CheckPassword(u.PasswordHash, current) {
+		return ErrInvalidCredentials
+	}
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if err := s.users.Update(ctx, userID, bson.M{"passwordHash": hash, "passwordResetRequired": false, "updatedAt": now}); err != nil {
+		return err
+	}
+	// link reset yang masih beredar tidak boleh menimpa password baru
+	if err := s.tokens.InvalidateForUser(ctx, userID, models.PurposePasswordReset, now); err != nil {
+		return err
+	}
+	return s.sessions.RevokeOthers(ctx, userID, keepSession)
+}
+
+func (s *accountService) ForceReset(ctx context.Context, userID primitive.ObjectID) error {
+	if err := s.users.Update(ctx, userID, bson.M{"passwordResetRequired": true, "updatedAt": time.Now().UTC()}); err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.pats.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	token, err := s.issue(ctx, u, models.PurposePasswordReset, s.opts.ResetTTL)
+	if err != nil {
+		return err
+	}
+	s.deliver(mailer.Message{
+		To:      u.Email,
+		Subject: "You need to reset your password",
+		Text: fmt.Sprintf("Hi %s,\n\nAn administrator has required a password reset on your account and signed you out everywhere. Open this link to choose a new password:\n\n%s\n\nThe link expires in %s. If it expires, use \"Forgot password\" on the login page to get a new one.\n",
+			u.Name, s.link("/reset-password", token), ttlText(s.opts.ResetTTL)),
+	})
+	return nil
+}
+
+func (s *accountService) Deactivate(ctx context.Context, userID primitive.ObjectID) error {
+	if err := s.users.Update(ctx, userID, bson.M{"isActive": false, "updatedAt": time.Now().UTC()}); err != nil {
+		return err
+	}
+	if err := s.pats.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessions.RevokeAll(ctx, userID)
+}
+
+// issue membuat token baru; token lama dengan purpose sama dibatalkan supaya
+// hanya link terakhir yang berlaku
+func (s *accountService) issue(ctx context.Context, u *models.User, purpose models.EmailTokenPurpose, ttl time.Duration) (string, error) {
+	now := time.Now().UTC()
+	if err := s.tokens.InvalidateForUser(ctx, u.ID, purpose, now); err != nil {
+		return "", err
+	}
+	plain, hash, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.tokens.Insert(ctx, &models.EmailToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    u.ID,
+		Purpose:   purpose,
+		Email:     u.Email,
+		TokenHash: hash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (s *accountService) link(path, token string) string {
+	return s.opts.AppURL + path + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// ttlText: "60 minutes", "48 hours" untuk isi email
+func ttlText(d time.Duration) string {
+	if d >= 2*time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
+}
+
+func (s *accountService) deliver(msg mailer.Message) { deliverMail(s.mail, msg) }
+
+// deliverMail mengirim di background: respons tidak menunggu SMTP dan waktunya
+// tidak membedakan email terdaftar/tidak
+func deliverMail(mail mailer.Mailer, msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := mail.Send(ctx, msg); err != nil {
+			log.Printf("[mail] send %q to %s failed: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type accountFixture struct {
+	repos    *repository.Repos
+	mail     mailbox
+	sessions SessionService
+	svc      AccountService
+	// auth menolak login sebelum email dikonfirmasi
+	auth AuthService
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	loadTestJWTKeys(t)
+	repos := repository.NewMemory()
+	f := &accountFixture{repos: repos, mail: make(mailbox, 4)}
+	f.sessions = NewSessionService(repos.Sessions, time.Minute, time.Hour)
+	f.svc = NewAccountService(repos.Users, repos.EmailTokens, f.sessions, NewAccessTokenService(repos.Tokens, repos.Users), f.mail, AccountOptions{
+		AppURL: "https://app.test", ResetTTL: time.Hour, VerifyTTL: 48 * time.Hour,
+	})
+	guard := NewLoginGuard(repos.Throttles, repos.Users, NewAuditService(repos.Audit), LoginGuardOptions{})
+	f.auth = NewAuthService(NewUserService(repos.Users, nil), f.sessions, guard, NewMFAService(repos.Users, repos.MFA, f.sessions, guard, "test"), true)
+	return f
+}
+
+// user dengan password; verified menentukan emailVerifiedAt
+func (f *accountFixture) user(t *testing.T, email, password string, verified bool) *models.User {
+	t.Helper()
+	u := seedUser(t, f.repos, email, verified)
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.repos.Users.Update(context.Background(), u.ID, bson.M{"passwordHash": hash}); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func (f *accountFixture) login(email, password string) error {
+	_, err := f.auth.Login(context.Background(), email, password, SessionMeta{IP: "10.0.0.1"})
+	return err
+}
+
+func TestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	u := f.user(t, "ana@x.io", "old-password", false)
+	old, err := f.sessions.Start(ctx, u.ID, SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.svc.ForgotPassword(ctx, " ANA@x.io"); err != nil {
+		t.Fatal(err)
+	}
+	first := linkToken(t, f.mail.next(t))
+	if err := f.svc.ForgotPassword(ctx, "ana@x.io"); err != nil {
+		t.Fatal(err)
+	}
+	msg := f.mail.next(t)
+	if msg.To != "ana@x.io" || msg.Subject != "Reset your password" {
+		t.Errorf("mail = %q to %q", msg.Subject, msg.To)
+	}
+	latest := linkToken(t, msg)
+
+	// hanya link terakhir yang berlaku
+	if err := f.svc.ResetPassword(ctx, first, "new-password"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("older link: err = %v, want ErrInvalidEmailToken", err)
+	}
+	if err := f.svc.ResetPassword(ctx, latest, "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak password: err = %v, want ErrWeakPassword", err)
+	}
+	if err := f.svc.ResetPassword(ctx, latest, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.svc.ResetPassword(ctx, latest, "another-password"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("link reused: err = %v, want ErrInvalidEmailToken", err)
+	}
+
+	if err := f.login("ana@x.io", "old-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("old password: err = %v", err)
+	}
+	// link reset dari email sekaligus membuktikan alamatnya
+	if err := f.login("ana@x.io", "new-password"); err != nil {
+		t.Errorf("new password: %v", err)
+	}
+	if _, err := f.sessions.Refresh(ctx, old.RefreshToken, SessionMeta{}); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("session from before the reset: err = %v", err)
+	}
+}
+
+func TestForgotPasswordDoesNotRevealAccounts(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	disabled := f.user(t, "off@x.io", "password-1", true)
+	if err := f.repos.Users.Update(ctx, disabled.ID, bson.M{"isActive": false}); err != nil {
+		t.Fatal(err)
+	}
+	for _, email := range []string{"nobody@x.io", "off@x.io"} {
+		if err := f.svc.ForgotPassword(ctx, email); err != nil {
+			t.Errorf("%s: %v", email, err)
+		}
+		if err := f.svc.ResendVerification(ctx, email); err != nil {
+			t.Errorf("%s: %v", email, err)
+		}
+	}
+	select {
+	case msg := <-f.mail:
+		t.Errorf("sent %q to %s", msg.Subject, msg.To)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	u := f.user(t, "budi@x.io", "password-1", false)
+	if err := f.login("budi@x.io", "password-1"); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("unverified login: err = %v, want ErrEmailNotVerified", err)
+	}
+
+	if err := f.svc.SendVerification(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	token := linkToken(t, f.mail.next(t))
+	if err := f.svc.VerifyEmail(ctx, "bogus"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("bogus token: err = %v", err)
+	}
+	if err := f.svc.VerifyEmail(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.login("budi@x.io", "password-1"); err != nil {
+		t.Errorf("verified login: %v", err)
+	}
+
+	// sudah terverifikasi: tidak ada email baru
+	verified, err := f.repos.Users.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.svc.SendVerification(ctx, verified); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-f.mail:
+		t.Errorf("sent %q to a verified user", msg.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// link verifikasi untuk alamat lama tidak memverifikasi alamat baru
+func TestVerificationLinkIsBoundToTheAddress(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	u := f.user(t, "old@x.io", "password-1", false)
+	if err := f.svc.SendVerification(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	token := linkToken(t, f.mail.next(t))
+	if err := f.repos.Users.Update(ctx, u.ID, bson.M{"email": "new@x.io"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.svc.VerifyEmail(ctx, token); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Fatalf("err = %v, want ErrInvalidEmailToken", err)
+	}
+	got, err := f.repos.Users.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.EmailVerifiedAt != nil {
+		t.Error("new address marked verified")
+	}
+}
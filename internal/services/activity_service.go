@@ -0,0 +1,150 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ActivityDefaultLimit = 50
+	ActivityMaxLimit     = 200
+)
+
+// field yang tidak dicatat di diff: metadata yang berubah di setiap update,
+// atau posisi internal (order/rank) yang juga berubah pada task tetangga
+var activitySkipFields = map[string]bool{
+	"id": true, "version": true, "createdAt": true, "updatedAt": true,
+	"createdBy": true, "updatedBy": true, "order": true, "rank": true,
+}
+
+// ActivityPage: satu halaman riwayat; NextCursor kosong = sudah habis
+type ActivityPage struct {
+	Items      []models.Activity `json:"items"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+// ActivityService: riwayat perubahan board/task/note. Seperti audit, gagal
+// simpan hanya di-log supaya tidak menggagalkan perubahan yang sudah terjadi.
+type ActivityService interface {
+	// Record mengisi a.Changes dari before & after (nil = belum ada / sudah
+	// dihapus). Update yang tidak mengubah field apa pun tidak dicatat.
+	Record(ctx context.Context, a *models.Activity, before, after interface{})
+	ListByBoard(ctx context.Context, boardID primitive.ObjectID, cursor *primitive.ObjectID, limit int) (*ActivityPage, error)
+	// ListByTask: task beserta note yang menempel padanya
+	ListByTask(ctx context.Context, taskID primitive.ObjectID, cursor *primitive.ObjectID, limit int) (*ActivityPage, error)
+}
+
+type activityService struct{ repo repository.ActivityRepo }
+
+func NewActivityService(repo repository.ActivityRepo) ActivityService {
+	return &activityService{repo: repo}
+}
+
+func (s *activityService) Record(ctx context.Context, a *models.Activity, before, after interface{}) {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		log.Printf("[activity] diff %s %s: %v", a.Entity, a.EntityID.Hex(), err)
+		return
+	}
+	if len(changes) == 0 && a.Action == models.ActivityUpdated {
+		return
+	}
+	a.Changes = changes
+	if a.ID.IsZero() {
+		a.ID = primitive.NewObjectID()
+	}
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+	if err := s.repo.Insert(ctx, a); err != nil {
+		log.Printf("[activity] insert %s %s: %v", a.Entity, a.EntityID.Hex(), err)
+	}
+}
+
+func (s *activityService) ListByBoard(ctx context.Context, boardID primitive.ObjectID, cursor *primitive.ObjectID, limit int) (*ActivityPage, error) {
+	return s.list(ctx, repository.ActivityFilter{BoardID: &boardID}, cursor, limit)
+}
+
+func (s *activityService) ListByTask(ctx context.Context, taskID primitive.ObjectID, cursor *primitive.ObjectID, limit int) (*ActivityPage, error) {
+	return s.list(ctx, repository.ActivityFilter{TaskID: &taskID}, cursor, limit)
+}
+
+func (s *activityService) list(ctx context.Context, f repository.ActivityFilter, cursor *primitive.ObjectID, limit int) (*ActivityPage, error) {
+	if limit <= 0 || limit > ActivityMaxLimit {
+		limit = ActivityDefaultLimit
+	}
+	// ambil satu lebih untuk tahu masih ada halaman berikutnya
+	items, err := s.repo.List(ctx, f, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	out := &ActivityPage{Items: []models.Activity{}}
+	if len(items) > limit {
+		items = items[:limit]
+		out.NextCursor = items[limit-1].ID.Hex()
+	}
+	for i := range items {
+		if items[i].Changes == nil {
+			items[i].Changes = []models.FieldChange{}
+		}
+	}
+	out.Items = append(out.Items, items...)
+	return out, nil
+}
+
+// diffFields membandingkan representasi JSON (nama field sama dengan API)
+func diffFields(before, after interface{}) ([]models.FieldChange, error) {
+	b, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var out []models.FieldChange
+	for _, k := range keys {
+		if activitySkipFields[k] || bytes.Equal(b[k], a[k]) {
+			continue
+		}
+		out = append(out, models.FieldChange{Field: k, Before: string(b[k]), After: string(a[k])})
+	}
+	return out, nil
+}
+
+// jsonFields: field → nilai JSON; null & array kosong dianggap tidak ada
+// supaya nil vs [] tidak tercatat sebagai perubahan
+func jsonFields(v interface{}) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range m {
+		if s := string(v); s == "null" || s == "[]" {
+			delete(m, k)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// history: "entity action field,field" per activity, terbaru dulu
+func history(items []models.Activity) []string {
+	out := make([]string, 0, len(items))
+	for _, a := range items {
+		line := fmt.Sprintf("%s %s", a.Entity, a.Action)
+		for i, c := range a.Changes {
+			if i == 0 {
+				line += " "
+			} else {
+				line += ","
+			}
+			line += c.Field
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func TestTaskActivityHistory(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	activity := NewActivityService(repos.Activities)
+	tasks := NewTaskService(repos.Tasks, repos.Notes, repos.Boards, repos.Tx, activity, OrderingIndex)
+	notes := NewNoteService(repos.Notes, repos.Tasks, repos.Boards, activity)
+	owner, editor := primitive.NewObjectID(), primitive.NewObjectID()
+	b := seedBoard(t, repos, owner, "todo", "done")
+	ids := seedTasks(t, tasks, b.ID, owner, "todo", "spec", "other")
+	id := ids["spec"]
+
+	if err := tasks.Update(ctx, id, bson.M{"status": models.TaskStatus("done"), "title": "spec"}, editor, nil); err != nil {
+		t.Fatal(err)
+	}
+	// tidak ada field yang berubah → tidak dicatat
+	if err := tasks.Update(ctx, id, bson.M{"title": "spec"}, editor, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tasks.Move(ctx, id, "done", 1, editor, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := notes.Create(ctx, editor, "looks good", &b.ID, &id, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tasks.Delete(ctx, id, owner); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := activity.ListByTask(ctx, id, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"task deleted boardId,columnId,priority,status,title",
+		"note created authorId,boardId,content,pinned,taskId",
+		"task moved columnId,status",
+		"task updated status",
+		"task created boardId,columnId,priority,status,title",
+	}
+	if got := history(page.Items); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("task history:\n got %q\nwant %q", got, want)
+	}
+	upd := page.Items[3]
+	if upd.ActorID != editor || upd.Changes[0].Before != `"planned"` || upd.Changes[0].After != `"done"` {
+		t.Errorf("update = %+v", upd)
+	}
+
+	// riwayat board mencakup task lain, tapi tidak board lain
+	seedTasks(t, tasks, seedBoard(t, repos, owner, "todo").ID, owner, "todo", "elsewhere")
+	boardPage, err := activity.ListByBoard(ctx, b.ID, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boardPage.Items) != len(want)+1 {
+		t.Errorf("board history = %q", history(boardPage.Items))
+	}
+}
+
+func TestActivityPages(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	activity := NewActivityService(repos.Activities)
+	board := primitive.NewObjectID()
+	for i := 0; i < 5; i++ {
+		activity.Record(ctx, &models.Activity{BoardID: &board, Entity: models.ActivityBoard, EntityID: board, Action: models.ActivityCreated}, nil, map[string]int{"n": i})
+	}
+
+	var seen []string
+	var cursor *primitive.ObjectID
+	for _, size := range []int{2, 2, 1} {
+		page, err := activity.ListByBoard(ctx, board, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Items) != size {
+			t.Fatalf("page of %d, want %d", len(page.Items), size)
+		}
+		for _, a := range page.Items {
+			seen = append(seen, a.Changes[0].After)
+		}
+		if page.NextCursor == "" {
+			cursor = nil
+			break
+		}
+		next, err := primitive.ObjectIDFromHex(page.NextCursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor = &next
+	}
+	if cursor != nil {
+		t.Error("last page has a next cursor")
+	}
+	if fmt.Sprint(seen) != "[4 3 2 1 0]" {
+		t.Errorf("pages = %v, want newest first without gaps", seen)
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminService: operasi admin console lintas user & board. Cek role admin
+// dilakukan middleware RequireRole; actor hanya dicatat di audit.
+type AdminService interface {
+	ListUsers(ctx context.Context, f repository.UserFilter, skip, limit int) ([]models.User, int64, error)
+	GetUser(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	SetRole(ctx context.Context, actor, userID primitive.ObjectID, role models.UserRole) (*models.User, error)
+	// SetActive: nonaktif = semua session dicabut (lihat AccountService.Deactivate)
+	SetActive(ctx context.Context, actor, userID primitive.ObjectID, active bool) (*models.User, error)
+	ForcePasswordReset(ctx context.Context, actor, userID primitive.ObjectID) error
+	ListBoards(ctx context.Context, f repository.BoardFilter, skip, limit int) ([]models.Board, int64, error)
+	TransferBoard(ctx context.Context, actor, boardID, newOwnerID primitive.ObjectID) (*models.Board, error)
+	Stats(ctx context.Context) (*SystemStats, error)
+}
+
+type SystemStats struct {
+	Users          int64 `json:"users"`
+	ActiveUsers    int64 `json:"activeUsers"`
+	Admins         int64 `json:"admins"`
+	Boards         int64 `json:"boards"`
+	ArchivedBoards int64 `json:"archivedBoards"`
+	Tasks          int64 `json:"tasks"`
+	Notes          int64 `json:"notes"`
+}
+
+var (
+	ErrInvalidUserRole = errors.New("invalid user role")
+	// ErrAdminSelf: admin tidak bisa menurunkan/menonaktifkan dirinya sendiri
+	// (mencegah sistem tanpa admin)
+	ErrAdminSelf     = errors.New("admins cannot change their own role or status")
+	ErrInactiveOwner = errors.New("new owner must be an active user")
+	// ErrUnverifiedAdmin: hanya email terverifikasi yang boleh jadi admin
+	ErrUnverifiedAdmin = errors.New("user must verify their email before becoming admin")
+)
+
+type adminService struct {
+	users    repository.UserRepo
+	boards   repository.BoardRepo
+	tasks    repository.TaskRepo
+	notes    repository.NoteRepo
+	boardSvc BoardService
+	accounts AccountService
+	audit    AuditService
+}
+
+func NewAdminService(users repository.UserRepo, boards repository.BoardRepo, tasks repository.TaskRepo, notes repository.NoteRepo, boardSvc BoardService, accounts AccountService, audit AuditService) AdminService {
+	return &adminService{users: users, boards: boards, tasks: tasks, notes: notes, boardSvc: boardSvc, accounts: accounts, audit: audit}
+}
+
+func (s *adminService) ListUsers(ctx context.Context, f repository.UserFilter, skip, limit int) ([]models.User, int64, error) {
+	total, err := s.users.Count(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := s.users.Search(ctx, f, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return s.users.FindByID(ctx, id)
+}
+
+func (s *adminService) SetRole(ctx context.Context, actor, userID primitive.ObjectID, role models.UserRole) (*models.User, error) {
+	if !models.ValidUserRole(role) {
+		return nil, ErrInvalidUserRole
+	}
+	if actor == userID {
+		return nil, ErrAdminSelf
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Role == role {
+		return u, nil
+	}
+	if role == models.RoleAdmin && u.EmailVerifiedAt == nil {
+		return nil, ErrUnverifiedAdmin
+	}
+	if err := s.users.Update(ctx, userID, bson.M{"role": role, "updatedAt": time.Now().UTC()}); err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.AuditUserRoleChanged, actor, u, map[string]interface{}{"from": u.Role, "to": role})
+	return s.users.FindByID(ctx, userID)
+}
+
+func (s *adminService) SetActive(ctx context.Context, actor, userID primitive.ObjectID, active bool) (*models.User, error) {
+	if actor == userID {
+		return nil, ErrAdminSelf
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsActive == active {
+		return u, nil
+	}
+	event := models.AuditUserDeactivated
+	if active {
+		event = models.AuditUserReactivated
+		err = s.users.Update(ctx, userID, bson.M{"isActive": true, "updatedAt": time.Now().UTC()})
+	} else {
+		err = s.accounts.Deactivate(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, event, actor, u, nil)
+	return s.users.FindByID(ctx, userID)
+}
+
+func (s *adminService) ForcePasswordReset(ctx context.Context, actor, userID primitive.ObjectID) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.accounts.ForceReset(ctx, userID); err != nil {
+		return err
+	}
+	s.record(ctx, models.AuditUserPasswordReset, actor, u, nil)
+	return nil
+}
+
+func (s *adminService) ListBoards(ctx context.Context, f repository.BoardFilter, skip, limit int) ([]models.Board, int64, error) {
+	total, err := s.boards.Count(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := s.boards.Search(ctx, f, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (s *adminService) TransferBoard(ctx context.Context, actor, boardID, newOwnerID primitive.ObjectID) (*models.Board, error) {
+	u, err := s.users.FindByID(ctx, newOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, ErrInactiveOwner
+	}
+	before, err := s.boardSvc.Get(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.boardSvc.TransferOwnership(ctx, boardID, actor, newOwnerID)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.AuditBoardOwnerTransfer, actor, u, map[string]interface{}{
+		"boardId": boardID.Hex(), "fromOwnerId": before.OwnerID.Hex(),
+	})
+	return b, nil
+}
+
+func (s *adminService) Stats(ctx context.Context) (*SystemStats, error) {
+	active, archived := true, true
+	var st SystemStats
+	var err error
+	if st.Users, err = s.users.Count(ctx, repository.UserFilter{}); err != nil {
+		return nil, err
+	}
+	if st.ActiveUsers, err = s.users.Count(ctx, repository.UserFilter{Active: &active}); err != nil {
+		return nil, err
+	}
+	if st.Admins, err = s.users.Count(ctx, repository.UserFilter{Role: models.RoleAdmin}); err != nil {
+		return nil, err
+	}
+	if st.Boards, err = s.boards.Count(ctx, repository.BoardFilter{}); err != nil {
+		return nil, err
+	}
+	if st.ArchivedBoards, err = s.boards.Count(ctx, repository.BoardFilter{Archived: &archived}); err != nil {
+		return nil, err
+	}
+	if st.Tasks, err = s.tasks.Count(ctx); err != nil {
+		return nil, err
+	}
+	if st.Notes, err = s.notes.Count(ctx); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// record: subject = user yang terdampak (untuk transfer: owner baru)
+func (s *adminService) record(ctx context.Context, t models.AuditEventType, actor primitive.ObjectID, subject *models.User, details map[string]interface{}) {
+	s.audit.Record(ctx, &models.AuditEvent{
+		Type:      t,
+		ActorID:   &actor,
+		SubjectID: &subject.ID,
+		Email:     subject.Email,
+		Details:   details,
+	})
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type adminFixture struct {
+	*accountFixture
+	console AdminService
+	boards  BoardService
+	audit   *auditLog
+	admin   *models.User
+}
+
+func newAdminFixture(t *testing.T) *adminFixture {
+	t.Helper()
+	f := &adminFixture{accountFixture: newAccountFixture(t), audit: &auditLog{}}
+	f.boards = NewBoardService(f.repos.Boards, NewActivityService(f.repos.Activities))
+	f.console = NewAdminService(f.repos.Users, f.repos.Boards, f.repos.Tasks, f.repos.Notes, f.boards, f.svc, f.audit)
+	f.admin = seedUser(t, f.repos, "root@x.io", true)
+	if err := f.repos.Users.Update(context.Background(), f.admin.ID, bson.M{"role": models.RoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestAdminSetRole(t *testing.T) {
+	ctx := context.Background()
+	f := newAdminFixture(t)
+	verified := seedUser(t, f.repos, "v@x.io", true)
+	unverified := seedUser(t, f.repos, "u@x.io", false)
+
+	cases := []struct {
+		name string
+		user *models.User
+		role models.UserRole
+		want error
+	}{
+		{"own role", f.admin, models.RoleUser, ErrAdminSelf},
+		{"unknown role", verified, models.UserRole("root"), ErrInvalidUserRole},
+		{"unverified email", unverified, models.RoleAdmin, ErrUnverifiedAdmin},
+		{"unknown user", &models.User{}, models.RoleAdmin, repository.ErrNotFound},
+		{"promote", verified, models.RoleAdmin, nil},
+		{"same role again", verified, models.RoleAdmin, nil},
+	}
+	for _, tc := range cases {
+		if _, err := f.console.SetRole(ctx, f.admin.ID, tc.user.ID, tc.role); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	got, _ := f.repos.Users.FindByID(ctx, verified.ID)
+	if got.Role != models.RoleAdmin {
+		t.Errorf("role = %s", got.Role)
+	}
+	// perubahan yang tidak mengubah apa pun tidak dicatat
+	if events := f.audit.ofType(models.AuditUserRoleChanged); len(events) != 1 || *events[0].ActorID != f.admin.ID {
+		t.Errorf("role audit events = %+v", events)
+	}
+}
+
+func TestAdminDeactivateAndReactivate(t *testing.T) {
+	ctx := context.Background()
+	f := newAdminFixture(t)
+	u := f.user(t, "ana@x.io", "password-1", true)
+	pair, err := f.sessions.Start(ctx, u.ID, SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.console.SetActive(ctx, f.admin.ID, f.admin.ID, false); !errors.Is(err, ErrAdminSelf) {
+		t.Errorf("deactivate self: err = %v", err)
+	}
+	got, err := f.console.SetActive(ctx, f.admin.ID, u.ID, false)
+	if err != nil || got.IsActive {
+		t.Fatalf("deactivate = %+v, %v", got, err)
+	}
+	if _, err := f.sessions.Refresh(ctx, pair.RefreshToken, SessionMeta{}); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("session after deactivation: err = %v", err)
+	}
+	if err := f.login("ana@x.io", "password-1"); !errors.Is(err, ErrAccountDisabled) {
+		t.Errorf("login while deactivated: err = %v", err)
+	}
+
+	if _, err := f.console.SetActive(ctx, f.admin.ID, u.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.login("ana@x.io", "password-1"); err != nil {
+		t.Errorf("login after reactivation: %v", err)
+	}
+	if n := len(f.audit.ofType(models.AuditUserDeactivated)) + len(f.audit.ofType(models.AuditUserReactivated)); n != 2 {
+		t.Errorf("%d activation audit events, want 2", n)
+	}
+}
+
+func TestAdminForcePasswordReset(t *testing.T) {
+	ctx := context.Background()
+	f := newAdminFixture(t)
+	u := f.user(t, "ana@x.io", "password-1", true)
+	if err := f.console.ForcePasswordReset(ctx, f.admin.ID, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.login("ana@x.io", "password-1"); !errors.Is(err, ErrPasswordResetRequired) {
+		t.Fatalf("login before reset: err = %v", err)
+	}
+	token := linkToken(t, f.mail.next(t))
+	if err := f.svc.ResetPassword(ctx, token, "password-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.login("ana@x.io", "password-2"); err != nil {
+		t.Errorf("login after reset: %v", err)
+	}
+}
+
+func TestAdminTransferBoard(t *testing.T) {
+	ctx := context.Background()
+	f := newAdminFixture(t)
+	owner := seedUser(t, f.repos, "owner@x.io", true)
+	heir := seedUser(t, f.repos, "heir@x.io", true)
+	gone := seedUser(t, f.repos, "gone@x.io", true)
+	if err := f.repos.Users.Update(ctx, gone.ID, bson.M{"isActive": false}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := f.boards.Create(ctx, owner.ID, "board", nil, nil, []models.BoardMember{{UserID: heir.ID, Role: models.BoardRoleViewer}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.console.TransferBoard(ctx, f.admin.ID, b.ID, gone.ID); !errors.Is(err, ErrInactiveOwner) {
+		t.Errorf("to an inactive user: err = %v", err)
+	}
+	if _, err := f.console.TransferBoard(ctx, f.admin.ID, b.ID, owner.ID); !errors.Is(err, ErrAlreadyOwner) {
+		t.Errorf("to the current owner: err = %v", err)
+	}
+	got, err := f.console.TransferBoard(ctx, f.admin.ID, b.ID, heir.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.OwnerID != heir.ID {
+		t.Errorf("owner = %s", got.OwnerID.Hex())
+	}
+	if role, _ := got.RoleOf(owner.ID); role != models.BoardRoleAdmin {
+		t.Errorf("previous owner is %q, want admin", role)
+	}
+	events := f.audit.ofType(models.AuditBoardOwnerTransfer)
+	if len(events) != 1 || events[0].Details["fromOwnerId"] != owner.ID.Hex() {
+		t.Errorf("transfer audit events = %+v", events)
+	}
+}
+
+func TestAdminStatsAndSearch(t *testing.T) {
+	ctx := context.Background()
+	f := newAdminFixture(t)
+	ana := seedUser(t, f.repos, "ana@x.io", true)
+	seedUser(t, f.repos, "anabel@x.io", true)
+	off := seedUser(t, f.repos, "off@x.io", true)
+	if err := f.repos.Users.Update(ctx, off.ID, bson.M{"isActive": false}); err != nil {
+		t.Fatal(err)
+	}
+	b := seedBoard(t, f.repos, ana.ID, "todo")
+	seedBoard(t, f.repos, ana.ID)
+	tasks := newTestTaskService(f.repos, nil, OrderingIndex)
+	seedTasks(t, tasks, b.ID, ana.ID, "todo", "x", "y")
+	if err := f.repos.Notes.Insert(ctx, &models.Note{ID: primitive.NewObjectID(), AuthorID: ana.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.boards.SetArchived(ctx, b.ID, ana.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := f.console.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SystemStats{Users: 4, ActiveUsers: 3, Admins: 1, Boards: 2, ArchivedBoards: 1, Tasks: 2, Notes: 1}
+	if *st != want {
+		t.Errorf("stats = %+v, want %+v", *st, want)
+	}
+
+	list, total, err := f.console.ListUsers(ctx, repository.UserFilter{Query: "ANA"}, 0, 1)
+	if err != nil || total != 2 || len(list) != 1 {
+		t.Errorf("search ana = %d of %d, %v", len(list), total, err)
+	}
+}
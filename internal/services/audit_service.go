@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditService mencatat kejadian keamanan. Gagal simpan hanya di-log:
+// audit tidak boleh menggagalkan request yang memicunya.
+type AuditService interface {
+	Record(ctx context.Context, e *models.AuditEvent)
+}
+
+type auditService struct{ repo repository.AuditRepo }
+
+func NewAuditService(repo repository.AuditRepo) AuditService { return &auditService{repo: repo} }
+
+func (s *auditService) Record(ctx context.Context, e *models.AuditEvent) {
+	if e.ID.IsZero() {
+		e.ID = primitive.NewObjectID()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	log.Printf("[audit] %s email=%s ip=%s details=%v", e.Type, e.Email, e.IP, e.Details)
+	if err := s.repo.Insert(ctx, e); err != nil {
+		log.Printf("[audit] insert %s failed: %v", e.Type, err)
+	}
+}
@@ -2,33 +2,75 @@ package services
 
 import (
 	"context"
+	"errors"
 
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 )
 
 type AuthService interface {
-	Login(ctx context.Context, email, password string) (token string, userID string, err error)
+	// Login memverifikasi password lalu membuka session baru; bila user memakai
+	// 2FA hasilnya berupa token "mfa pending" untuk /auth/mfa/verify
+	Login(ctx context.Context, email, password string, meta SessionMeta) (*LoginResult, error)
 }
 
 type authService struct {
-	users UserService
+	users    UserService
+	sessions SessionService
+	guard    LoginGuard
+	mfa      MFAService
+	// requireVerified: tolak login sebelum email dikonfirmasi
+	requireVerified bool
 }
 
-func NewAuthService(users UserService) AuthService {
-	return &authService{users: users}
+func NewAuthService(users UserService, sessions SessionService, guard LoginGuard, mfa MFAService, requireVerified bool) AuthService {
+	return &authService{users: users, sessions: sessions, guard: guard, mfa: mfa, requireVerified: requireVerified}
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (string, string, error) {
+func (s *authService) Login(ctx context.Context, email, password string, meta SessionMeta) (*LoginResult, error) {
+	if err := s.guard.Check(ctx, email, meta.IP); err != nil {
+		return nil, err
+	}
 	u, err := s.users.FindByEmail(ctx, email)
 	if err != nil {
-		return "", "", ErrInvalidCredentials
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		// email tak dikenal: tetap bcrypt & tetap dihitung, sama seperti password salah
+		utils.BurnPasswordCheck(password)
+		if err := s.guard.Failure(ctx, email, meta.IP, nil); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 	if !utils.CheckPassword(u.PasswordHash, password) {
-		return "", "", ErrInvalidCredentials
+		if err := s.guard.Failure(ctx, email, meta.IP, &u.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+	// dengan 2FA hitungan baru dihapus setelah langkah kedua berhasil (MFAService.Verify)
+	if !u.MFAEnabled() {
+		if err := s.guard.Success(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+	// status akun hanya diungkap setelah password benar
+	if !u.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if s.requireVerified && u.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+	if u.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	if u.MFAEnabled() {
+		return s.mfa.Challenge(ctx, u.ID)
 	}
-	token, err := utils.GenerateJWT(u.ID.Hex())
+	pair, err := s.sessions.Start(ctx, u.ID, meta)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	return token, u.ID.Hex(), nil
+	return &LoginResult{Tokens: pair}, nil
 }
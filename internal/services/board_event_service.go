@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// BoardEventLogSize: jumlah event terakhir per board yang bisa di-replay
+	BoardEventLogSize = 500
+	// pruning tidak tiap append; log bisa berisi sedikit lebih dari BoardEventLogSize
+	boardEventPruneEvery = 50
+)
+
+// EventReplay: hasil Since. Resync = sebagian event sudah tidak ada di log,
+// klien harus mengambil ulang board & task lalu lanjut dari Seq.
+type EventReplay struct {
+	Seq    int64
+	Resync bool
+	Events []models.BoardEvent
+}
+
+// BoardEventService: nomor urut & log event realtime per board
+type BoardEventService interface {
+	// Append memberi seq berikutnya untuk board dan menyimpan payload (JSON)
+	Append(ctx context.Context, boardID primitive.ObjectID, eventType string, data []byte) (int64, error)
+	Since(ctx context.Context, boardID primitive.ObjectID, since int64) (*EventReplay, error)
+	DeleteBoard(ctx context.Context, boardID primitive.ObjectID) error
+}
+
+type boardEventService struct{ repo repository.BoardEventRepo }
+
+func NewBoardEventService(repo repository.BoardEventRepo) BoardEventService {
+	return &boardEventService{repo: repo}
+}
+
+func (s *boardEventService) Append(ctx context.Context, boardID primitive.ObjectID, eventType string, data []byte) (int64, error) {
+	seq, err := s.repo.NextSeq(ctx, boardID)
+	if err != nil {
+		return 0, err
+	}
+	e := &models.BoardEvent{
+		ID:        primitive.NewObjectID(),
+		BoardID:   boardID,
+		Seq:       seq,
+		Type:      eventType,
+		Data:      string(data),
+		CreatedAt: time.Now().UTC(),
+	}
+	// seq tetap dipakai walau insert gagal; Since melihat lubangnya dan
+	// meminta resync ke klien yang replay melewatinya
+	if err := s.repo.Insert(ctx, e); err != nil {
+		return seq, err
+	}
+	if seq%boardEventPruneEvery == 0 && seq > BoardEventLogSize {
+		if err := s.repo.Prune(ctx, boardID, seq-BoardEventLogSize); err != nil {
+			log.Printf("[events] prune board=%s: %v", boardID.Hex(), err)
+		}
+	}
+	return seq, nil
+}
+
+func (s *boardEventService) Since(ctx context.Context, boardID primitive.ObjectID, since int64) (*EventReplay, error) {
+	last, err := s.repo.LastSeq(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	out := &EventReplay{Seq: last, Events: []models.BoardEvent{}}
+	// since > last: counter sudah direset (mis. storage memory di-restart)
+	if since > last || last-since > BoardEventLogSize {
+		out.Resync = true
+		return out, nil
+	}
+	if since == last {
+		return out, nil
+	}
+	events, err := s.repo.ListSince(ctx, boardID, since)
+	if err != nil {
+		return nil, err
+	}
+	// seq yang sudah diberikan tapi tidak ada di log (insert gagal, atau
+	// Append lain belum selesai menyimpan) berarti replay tidak lengkap
+	next := since + 1
+	for _, e := range events {
+		if e.Seq != next {
+			break
+		}
+		next++
+	}
+	if next <= last {
+		out.Resync = true
+		return out, nil
+	}
+	// event setelah LastSeq dibaca ikut dikirim bila masih bersambung
+	out.Seq = next - 1
+	out.Events = events[:next-since-1]
+	return out, nil
+}
+
+func (s *boardEventService) DeleteBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	return s.repo.DeleteByBoard(ctx, boardID)
+}
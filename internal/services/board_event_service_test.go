@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lossyEvents: Insert gagal untuk seq yang ada di lose
+type lossyEvents struct {
+	repository.BoardEventRepo
+	lose map[int64]bool
+}
+
+func (l *lossyEvents) Insert(ctx context.Context, e *models.BoardEvent) error {
+	if l.lose[e.Seq] {
+		return errors.New("insert failed")
+	}
+	return l.BoardEventRepo.Insert(ctx, e)
+}
+
+func appendEvents(t *testing.T, svc BoardEventService, boardID primitive.ObjectID, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		// error dari insert yang sengaja gagal diabaikan, seperti Sequenced
+		_, _ = svc.Append(context.Background(), boardID, "task_updated", []byte(fmt.Sprintf(`{"n":%d}`, i)))
+	}
+}
+
+func seqs(events []models.BoardEvent) []int64 {
+	out := []int64{}
+	for _, e := range events {
+		out = append(out, e.Seq)
+	}
+	return out
+}
+
+func TestSinceReplaysMissedEvents(t *testing.T) {
+	ctx := context.Background()
+	svc := NewBoardEventService(repository.NewMemory().BoardEvents)
+	board := primitive.NewObjectID()
+	appendEvents(t, svc, board, 5)
+
+	r, err := svc.Since(ctx, board, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Resync || r.Seq != 5 || fmt.Sprint(seqs(r.Events)) != "[3 4 5]" {
+		t.Fatalf("replay = seq %d resync %v events %v", r.Seq, r.Resync, seqs(r.Events))
+	}
+	if r, err = svc.Since(ctx, board, 5); err != nil || r.Resync || len(r.Events) != 0 {
+		t.Fatalf("up to date: %+v, %v", r, err)
+	}
+}
+
+func TestSinceResync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("event whose insert failed", func(t *testing.T) {
+		repo := &lossyEvents{BoardEventRepo: repository.NewMemory().BoardEvents, lose: map[int64]bool{3: true}}
+		svc := NewBoardEventService(repo)
+		board := primitive.NewObjectID()
+		appendEvents(t, svc, board, 5)
+
+		for _, since := range []int64{0, 2} {
+			r, err := svc.Since(ctx, board, since)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !r.Resync || len(r.Events) != 0 || r.Seq != 5 {
+				t.Errorf("since %d: seq %d resync %v events %v", since, r.Seq, r.Resync, seqs(r.Events))
+			}
+		}
+		// setelah resync klien lanjut dari seq 5; yang sesudah lubang utuh
+		r, err := svc.Since(ctx, board, 3)
+		if err != nil || r.Resync || fmt.Sprint(seqs(r.Events)) != "[4 5]" {
+			t.Errorf("since 3: %+v, %v", r, err)
+		}
+	})
+
+	t.Run("append still in flight", func(t *testing.T) {
+		repos := repository.NewMemory()
+		svc := NewBoardEventService(repos.BoardEvents)
+		board := primitive.NewObjectID()
+		appendEvents(t, svc, board, 2)
+		// seq 3 sudah diberikan, event-nya belum tersimpan
+		if _, err := repos.BoardEvents.NextSeq(ctx, board); err != nil {
+			t.Fatal(err)
+		}
+		if r, err := svc.Since(ctx, board, 1); err != nil || !r.Resync {
+			t.Errorf("since 1: %+v, %v", r, err)
+		}
+	})
+
+	t.Run("older than the log", func(t *testing.T) {
+		svc := NewBoardEventService(repository.NewMemory().BoardEvents)
+		board := primitive.NewObjectID()
+		appendEvents(t, svc, board, BoardEventLogSize+boardEventPruneEvery)
+		if r, err := svc.Since(ctx, board, 0); err != nil || !r.Resync {
+			t.Errorf("since 0: resync %v, %v", r.Resync, err)
+		}
+		r, err := svc.Since(ctx, board, boardEventPruneEvery)
+		if err != nil || r.Resync || len(r.Events) != BoardEventLogSize {
+			t.Errorf("since %d: resync %v, %d events, %v", boardEventPruneEvery, r.Resync, len(r.Events), err)
+		}
+	})
+
+	t.Run("ahead of the server", func(t *testing.T) {
+		svc := NewBoardEventService(repository.NewMemory().BoardEvents)
+		board := primitive.NewObjectID()
+		appendEvents(t, svc, board, 1)
+		if r, err := svc.Since(ctx, board, 7); err != nil || !r.Resync || r.Seq != 1 {
+			t.Errorf("since 7: %+v, %v", r, err)
+		}
+	})
+}
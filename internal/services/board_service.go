@@ -2,27 +2,78 @@ package services
 
 import (
 	"context"
+	"errors"
+	"log"
 	"time"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BoardService interface {
-	Create(ctx context.Context, ownerID primitive.ObjectID, name string, desc *string, columns []models.BoardColumn, members []primitive.ObjectID) (*models.Board, error)
-	ListForUser(ctx context.Context, userID primitive.ObjectID) ([]models.Board, error)
+	Create(ctx context.Context, ownerID primitive.ObjectID, name string, desc *string, columns []models.BoardColumn, members []models.BoardMember) (*models.Board, error)
+	// ListForUser: board yang diarsipkan hanya ikut bila includeArchived
+	ListForUser(ctx context.Context, userID primitive.ObjectID, includeArchived bool) ([]models.Board, error)
 	Get(ctx context.Context, id primitive.ObjectID) (*models.Board, error)
-	Update(ctx context.Context, id primitive.ObjectID, name *string, desc *string, columns *[]models.BoardColumn, members *[]primitive.ObjectID) error
-	Delete(ctx context.Context, id primitive.ObjectID) error
+	// Update menerapkan patch dalam satu tulis; expect (boleh nil) = version
+	// yang dilihat klien, ErrVersionConflict bila berbeda. Mengembalikan board
+	// sebelum perubahan, yaitu version yang benar-benar ditimpa.
+	Update(ctx context.Context, id, actor primitive.ObjectID, patch BoardPatch, expect *int64) (*models.Board, error)
+	// SetMember menambah member atau mengubah role-nya; hasilnya board sebelum perubahan
+	SetMember(ctx context.Context, id, actor primitive.ObjectID, member models.BoardMember) (*models.Board, error)
+	RemoveMember(ctx context.Context, id, actor, userID primitive.ObjectID) (*models.Board, error)
+	// AddInvited menambah userID dari undangan invitedBy. Role admin hanya
+	// diberikan bila invitedBy masih owner; yang sudah member tetap pada role lamanya.
+	AddInvited(ctx context.Context, id, userID, invitedBy primitive.ObjectID, role models.BoardRole) error
+	// TransferOwnership: owner lama menjadi member admin, owner baru keluar dari daftar member
+	TransferOwnership(ctx context.Context, id, actor, newOwnerID primitive.ObjectID) (*models.Board, error)
+	// SetArchived: board arsip tetap bisa dibaca, tapi task & note-nya tidak
+	// bisa diubah (ErrBoardArchived) sampai dikeluarkan dari arsip
+	SetArchived(ctx context.Context, id, actor primitive.ObjectID, archived bool) (*models.Board, error)
+	// Delete memindahkan board ke trash; task & note-nya tetap utuh sampai
+	// board di-restore atau dihapus permanen oleh TrashService
+	Delete(ctx context.Context, id, actor primitive.ObjectID) error
+	Restore(ctx context.Context, id, actor primitive.ObjectID) (*models.Board, error)
 }
 
-type boardService struct{}
+// BoardPatch: field yang diubah Update; nil = tidak diubah
+type BoardPatch struct {
+	Name        *string
+	Description *string
+	Columns     *[]models.BoardColumn
+	Members     *[]models.BoardMember
+	// RequireMFA: wajibkan 2FA untuk semua member (termasuk owner); handler
+	// memastikan hanya owner yang sudah 2FA yang mengubahnya
+	RequireMFA *bool
+}
+
+var (
+	ErrInvalidRole  = errors.New("invalid member role")
+	ErrOwnerMember  = errors.New("board owner cannot be a member")
+	ErrNotAMember   = errors.New("user is not a board member")
+	ErrAlreadyOwner = errors.New("user already owns this board")
+	// ErrBoardArchived: tulis task/note di board yang diarsipkan
+	ErrBoardArchived = errors.New("board is archived and read-only, unarchive it first")
+	// ErrAdminOwnerOnly: selain owner tidak boleh mengangkat/mencabut admin
+	ErrAdminOwnerOnly = errors.New("only the owner can grant or revoke admin")
+	// ErrBoardBusy: board terus berubah bersamaan, retry habis
+	ErrBoardBusy = errors.New("board changed concurrently, please retry")
+)
+
+// berapa kali tulis board tanpa If-Match diulang saat version-nya berubah
+const maxBoardRetries = 5
+
+type boardService struct {
+	boards   repository.BoardRepo
+	activity ActivityService
+}
 
-func NewBoardService() BoardService { return &boardService{} }
+func NewBoardService(boards repository.BoardRepo, activity ActivityService) BoardService {
+	return &boardService{boards: boards, activity: activity}
+}
 
 func defaultColumns() []models.BoardColumn {
 	return []models.BoardColumn{
@@ -32,7 +83,11 @@ func defaultColumns() []models.BoardColumn {
 	}
 }
 
-func (s *boardService) Create(ctx context.Context, ownerID primitive.ObjectID, name string, desc *string, columns []models.BoardColumn, members []primitive.ObjectID) (*models.Board, error) {
+func (s *boardService) Create(ctx context.Context, ownerID primitive.ObjectID, name string, desc *string, columns []models.BoardColumn, members []models.BoardMember) (*models.Board, error) {
+	ids, roles, err := memberFields(ownerID, members)
+	if err != nil {
+		return nil, err
+	}
 	if len(columns) == 0 {
 		columns = defaultColumns()
 	}
@@ -42,69 +97,329 @@ func (s *boardService) Create(ctx context.Context, ownerID primitive.ObjectID, n
 		OwnerID:     ownerID,
 		Name:        name,
 		Description: desc,
-		Members:     members,
+		Members:     ids,
+		MemberRoles: roles,
 		Columns:     columns,
 		IsArchived:  false,
+		Version:     1,
 		TimeMeta:    models.TimeMeta{CreatedAt: now, UpdatedAt: now},
 	}
-	_, err := config.MongoDB.Collection("boards").InsertOne(ctx, b)
-	if err != nil {
+	if err := s.boards.Insert(ctx, b); err != nil {
 		return nil, err
 	}
+	s.record(ctx, models.ActivityCreated, ownerID, nil, b)
 	return b, nil
 }
 
-func (s *boardService) ListForUser(ctx context.Context, userID primitive.ObjectID) ([]models.Board, error) {
-	cur, err := config.MongoDB.Collection("boards").Find(ctx, bson.M{
-		"$or": []bson.M{
-			{"ownerId": userID},
-			{"members": userID},
-		},
-	}, options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}}))
+// record mencatat activity board; before nil = baru dibuat, after nil = dihapus
+func (s *boardService) record(ctx context.Context, action models.ActivityAction, actor primitive.ObjectID, before, after *models.Board) {
+	b := after
+	if b == nil {
+		b = before
+	}
+	s.activity.Record(ctx, &models.Activity{
+		BoardID:  &b.ID,
+		Entity:   models.ActivityBoard,
+		EntityID: b.ID,
+		Action:   action,
+		ActorID:  actor,
+	}, before, after)
+}
+
+// recordChange: seperti record, keadaan sesudahnya dibaca ulang dari store
+func (s *boardService) recordChange(ctx context.Context, actor primitive.ObjectID, before *models.Board) {
+	after, err := s.boards.FindByID(ctx, before.ID)
 	if err != nil {
-		return nil, err
+		log.Printf("[activity] reload board=%s: %v", before.ID.Hex(), err)
+		return
 	}
-	defer cur.Close(ctx)
-	var out []models.Board
-	if err := cur.All(ctx, &out); err != nil {
-		return nil, err
+	s.record(ctx, models.ActivityUpdated, actor, before, after)
+}
+
+func (s *boardService) ListForUser(ctx context.Context, userID primitive.ObjectID, includeArchived bool) ([]models.Board, error) {
+	all, err := s.boards.ListForUser(ctx, userID)
+	if err != nil || includeArchived {
+		return all, err
+	}
+	out := []models.Board{}
+	for _, b := range all {
+		if !b.IsArchived {
+			out = append(out, b)
+		}
 	}
 	return out, nil
 }
 
 func (s *boardService) Get(ctx context.Context, id primitive.ObjectID) (*models.Board, error) {
-	var b models.Board
-	err := config.MongoDB.Collection("boards").FindOne(ctx, bson.M{"_id": id}).Decode(&b)
+	return s.boards.FindByID(ctx, id)
+}
+
+func (s *boardService) Update(ctx context.Context, id, actor primitive.ObjectID, p BoardPatch, expect *int64) (*models.Board, error) {
+	return s.write(ctx, id, actor, expect, func(b *models.Board) (bson.M, error) {
+		set := bson.M{}
+		if p.Name != nil {
+			set["name"] = *p.Name
+		}
+		if p.Description != nil {
+			set["description"] = p.Description
+		}
+		if p.Columns != nil {
+			set["columns"] = *p.Columns
+		}
+		if p.Members != nil {
+			if err := setMembers(set, b, actor, *p.Members); err != nil {
+				return nil, err
+			}
+		}
+		if p.RequireMFA != nil {
+			set["requireMfa"] = *p.RequireMFA
+		}
+		return set, nil
+	})
+}
+
+func (s *boardService) SetMember(ctx context.Context, id, actor primitive.ObjectID, member models.BoardMember) (*models.Board, error) {
+	return s.write(ctx, id, actor, nil, func(b *models.Board) (bson.M, error) {
+		set := bson.M{}
+		return set, setMembers(set, b, actor, withMember(b, member))
+	})
+}
+
+func (s *boardService) RemoveMember(ctx context.Context, id, actor, userID primitive.ObjectID) (*models.Board, error) {
+	return s.write(ctx, id, actor, nil, func(b *models.Board) (bson.M, error) {
+		if _, ok := b.RoleOf(userID); !ok || b.OwnerID == userID {
+			return nil, ErrNotAMember
+		}
+		var members []models.BoardMember
+		for _, m := range boardMembers(b) {
+			if m.UserID != userID {
+				members = append(members, m)
+			}
+		}
+		set := bson.M{}
+		return set, setMembers(set, b, actor, members)
+	})
+}
+
+func (s *boardService) AddInvited(ctx context.Context, id, userID, invitedBy primitive.ObjectID, role models.BoardRole) error {
+	_, err := s.write(ctx, id, userID, nil, func(b *models.Board) (bson.M, error) {
+		// sudah owner/member (mis. ditambah manual) → role lama dipertahankan
+		if _, ok := b.RoleOf(userID); ok {
+			return nil, nil
+		}
+		set := bson.M{}
+		return set, setMembers(set, b, invitedBy, withMember(b, models.BoardMember{UserID: userID, Role: role}))
+	})
+	return err
+}
+
+// write: baca board, susun $set lewat fn, lalu simpan hanya bila version
+// belum berubah. Dengan expect konflik langsung ErrVersionConflict; tanpa
+// expect diulang dari awal supaya fn (termasuk cek izin di dalamnya) selalu
+// dinilai terhadap version yang ditimpa. fn mengembalikan nil = tidak ada
+// perubahan. Hasilnya board sebelum perubahan.
+func (s *boardService) write(ctx context.Context, id, actor primitive.ObjectID, expect *int64, fn func(b *models.Board) (bson.M, error)) (*models.Board, error) {
+	for i := 0; i < maxBoardRetries; i++ {
+		b, err := s.boards.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVersion(b.Version, expect); err != nil {
+			return nil, err
+		}
+		set, err := fn(b)
+		if err != nil || set == nil {
+			return b, err
+		}
+		set["updatedAt"] = time.Now().UTC()
+		err = s.boards.UpdateIfVersion(ctx, id, b.Version, set)
+		if errors.Is(err, repository.ErrConflict) {
+			if expect != nil {
+				return nil, ErrVersionConflict
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.recordChange(ctx, actor, b)
+		return b, nil
+	}
+	return nil, ErrBoardBusy
+}
+
+// withMember: member board ditambah member (atau role-nya diganti)
+func withMember(b *models.Board, member models.BoardMember) []models.BoardMember {
+	members := boardMembers(b)
+	for i := range members {
+		if members[i].UserID == member.UserID {
+			members[i].Role = member.Role
+			return members
+		}
+	}
+	return append(members, member)
+}
+
+// setMembers mengisi members & memberRoles di set. Hanya owner (by) yang
+// boleh mengubah siapa saja yang admin.
+func setMembers(set bson.M, b *models.Board, by primitive.ObjectID, members []models.BoardMember) error {
+	ids, roles, err := memberFields(b.OwnerID, members)
+	if err != nil {
+		return err
+	}
+	if by != b.OwnerID && !sameAdmins(boardMembers(b), roles) {
+		return ErrAdminOwnerOnly
+	}
+	set["members"] = ids
+	set["memberRoles"] = roles
+	return nil
+}
+
+func sameAdmins(before, after []models.BoardMember) bool {
+	admins := func(ms []models.BoardMember) map[primitive.ObjectID]bool {
+		out := map[primitive.ObjectID]bool{}
+		for _, m := range ms {
+			if m.Role == models.BoardRoleAdmin {
+				out[m.UserID] = true
+			}
+		}
+		return out
+	}
+	a, b := admins(before), admins(after)
+	if len(a) != len(b) {
+		return false
+	}
+	for id := range a {
+		if !b[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *boardService) TransferOwnership(ctx context.Context, id, actor, newOwnerID primitive.ObjectID) (*models.Board, error) {
+	b, err := s.boards.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.OwnerID == newOwnerID {
+		return nil, ErrAlreadyOwner
+	}
+	members := []models.BoardMember{{UserID: b.OwnerID, Role: models.BoardRoleAdmin}}
+	for _, m := range boardMembers(b) {
+		if m.UserID != newOwnerID && m.UserID != b.OwnerID {
+			members = append(members, m)
+		}
+	}
+	ids, roles, err := memberFields(newOwnerID, members)
 	if err != nil {
 		return nil, err
 	}
-	return &b, nil
+	if err := s.boards.Update(ctx, id, bson.M{
+		"ownerId":     newOwnerID,
+		"members":     ids,
+		"memberRoles": roles,
+		"updatedAt":   time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	after, err := s.boards.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.ActivityUpdated, actor, b, after)
+	return after, nil
 }
 
-func (s *boardService) Update(ctx context.Context, id primitive.ObjectID, name *string, desc *string, columns *[]models.BoardColumn, members *[]primitive.ObjectID) error {
-	set := bson.M{"updatedAt": time.Now().UTC()}
-	if name != nil {
-		set["name"] = *name
+func (s *boardService) SetArchived(ctx context.Context, id, actor primitive.ObjectID, archived bool) (*models.Board, error) {
+	b, err := s.boards.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	if desc != nil {
-		set["description"] = desc
+	if b.IsArchived == archived {
+		return b, nil
 	}
-	if columns != nil {
-		set["columns"] = *columns
+	if err := s.boards.Update(ctx, id, bson.M{"isArchived": archived, "updatedAt": time.Now().UTC()}); err != nil {
+		return nil, err
 	}
-	if members != nil {
-		set["members"] = *members
+	after, err := s.boards.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	_, err := config.MongoDB.Collection("boards").UpdateByID(ctx, id, bson.M{"$set": set})
-	return err
+	s.record(ctx, models.ActivityUpdated, actor, b, after)
+	return after, nil
 }
 
-func (s *boardService) Delete(ctx context.Context, id primitive.ObjectID) error {
-	// Hapus board
-	if _, err := config.MongoDB.Collection("boards").DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+// writableBoard: board untuk perubahan task/note; ErrBoardArchived bila diarsipkan
+func writableBoard(ctx context.Context, boards repository.BoardRepo, id primitive.ObjectID) (*models.Board, error) {
+	b, err := boards.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.IsArchived {
+		return nil, ErrBoardArchived
+	}
+	return b, nil
+}
+
+// boardMembers: semua member board beserta role (member lama = editor)
+func boardMembers(b *models.Board) []models.BoardMember {
+	out := make([]models.BoardMember, 0, len(b.Members))
+	for _, id := range b.Members {
+		role, _ := b.RoleOf(id)
+		out = append(out, models.BoardMember{UserID: id, Role: role})
+	}
+	return out
+}
+
+// memberFields: isi field members (untuk query ListForUser) & memberRoles.
+// Duplikat memakai entri terakhir.
+func memberFields(ownerID primitive.ObjectID, members []models.BoardMember) ([]primitive.ObjectID, []models.BoardMember, error) {
+	ids := []primitive.ObjectID{}
+	roles := []models.BoardMember{}
+	idx := map[primitive.ObjectID]int{}
+	for _, m := range members {
+		if m.UserID == ownerID {
+			return nil, nil, ErrOwnerMember
+		}
+		if !models.ValidMemberRole(m.Role) {
+			return nil, nil, ErrInvalidRole
+		}
+		if i, ok := idx[m.UserID]; ok {
+			roles[i].Role = m.Role
+			continue
+		}
+		idx[m.UserID] = len(roles)
+		ids = append(ids, m.UserID)
+		roles = append(roles, m)
+	}
+	return ids, roles, nil
+}
+
+func (s *boardService) Delete(ctx context.Context, id, actor primitive.ObjectID) error {
+	b, err := s.boards.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.boards.SoftDelete(ctx, id, actor, time.Now().UTC()); err != nil {
 		return err
 	}
-	// Hapus semua tasks board (soft-cascade)
-	_, _ = config.MongoDB.Collection("tasks").DeleteMany(ctx, bson.M{"boardId": id})
+	s.record(ctx, models.ActivityDeleted, actor, b, nil)
 	return nil
 }
+
+func (s *boardService) Restore(ctx context.Context, id, actor primitive.ObjectID) (*models.Board, error) {
+	before, err := s.boards.FindDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.boards.Restore(ctx, id, bson.M{"updatedAt": time.Now().UTC()}); err != nil {
+		return nil, err
+	}
+	after, err := s.boards.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.ActivityRestored, actor, before, after)
+	return after, nil
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// racingBoards: tepat sebelum tulis bersyarat pertama, race dijalankan
+// seolah request lain menulis board lebih dulu
+type racingBoards struct {
+	repository.BoardRepo
+	race func(ctx context.Context)
+}
+
+func (r *racingBoards) UpdateIfVersion(ctx context.Context, id primitive.ObjectID, version int64, set bson.M) error {
+	if race := r.race; race != nil {
+		r.race = nil
+		race(ctx)
+	}
+	return r.BoardRepo.UpdateIfVersion(ctx, id, version, set)
+}
+
+type memberFixture struct {
+	svc                  BoardService
+	boards               *racingBoards
+	board                *models.Board
+	owner, admin, editor primitive.ObjectID
+}
+
+func newMemberFixture(t *testing.T) *memberFixture {
+	t.Helper()
+	repos := repository.NewMemory()
+	f := &memberFixture{owner: primitive.NewObjectID(), admin: primitive.NewObjectID(), editor: primitive.NewObjectID()}
+	f.boards = &racingBoards{BoardRepo: repos.Boards}
+	f.svc = NewBoardService(f.boards, NewActivityService(repos.Activities))
+	b, err := f.svc.Create(context.Background(), f.owner, "board", nil, nil, []models.BoardMember{
+		{UserID: f.admin, Role: models.BoardRoleAdmin},
+		{UserID: f.editor, Role: models.BoardRoleEditor},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.board = b
+	return f
+}
+
+func (f *memberFixture) role(t *testing.T, user primitive.ObjectID) models.BoardRole {
+	t.Helper()
+	b, err := f.svc.Get(context.Background(), f.board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	role, _ := b.RoleOf(user)
+	return role
+}
+
+func TestOnlyOwnerChangesAdmins(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("admin promotes an editor", func(t *testing.T) {
+		f := newMemberFixture(t)
+		_, err := f.svc.SetMember(ctx, f.board.ID, f.admin, models.BoardMember{UserID: f.editor, Role: models.BoardRoleAdmin})
+		if !errors.Is(err, ErrAdminOwnerOnly) {
+			t.Fatalf("err = %v, want ErrAdminOwnerOnly", err)
+		}
+		if got := f.role(t, f.editor); got != models.BoardRoleEditor {
+			t.Errorf("editor is now %s", got)
+		}
+	})
+	t.Run("admin removes an admin", func(t *testing.T) {
+		f := newMemberFixture(t)
+		other := primitive.NewObjectID()
+		if _, err := f.svc.SetMember(ctx, f.board.ID, f.owner, models.BoardMember{UserID: other, Role: models.BoardRoleAdmin}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.svc.RemoveMember(ctx, f.board.ID, f.admin, other); !errors.Is(err, ErrAdminOwnerOnly) {
+			t.Fatalf("err = %v, want ErrAdminOwnerOnly", err)
+		}
+	})
+	t.Run("admin replaces the member list", func(t *testing.T) {
+		f := newMemberFixture(t)
+		members := []models.BoardMember{{UserID: f.editor, Role: models.BoardRoleEditor}}
+		if _, err := f.svc.Update(ctx, f.board.ID, f.admin, BoardPatch{Members: &members}, nil); !errors.Is(err, ErrAdminOwnerOnly) {
+			t.Fatalf("err = %v, want ErrAdminOwnerOnly", err)
+		}
+	})
+	t.Run("admin manages non-admins", func(t *testing.T) {
+		f := newMemberFixture(t)
+		if _, err := f.svc.SetMember(ctx, f.board.ID, f.admin, models.BoardMember{UserID: f.editor, Role: models.BoardRoleViewer}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.svc.RemoveMember(ctx, f.board.ID, f.admin, f.editor); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("owner demotes an admin", func(t *testing.T) {
+		f := newMemberFixture(t)
+		if _, err := f.svc.SetMember(ctx, f.board.ID, f.owner, models.BoardMember{UserID: f.admin, Role: models.BoardRoleEditor}); err != nil {
+			t.Fatal(err)
+		}
+		if got := f.role(t, f.admin); got != models.BoardRoleEditor {
+			t.Errorf("admin is now %s", got)
+		}
+	})
+}
+
+// Admin mengirim daftar member dari tampilan lama; sementara itu owner
+// mengangkat editor menjadi admin. Daftar lama tidak boleh diam-diam
+// menurunkan admin baru itu.
+func TestMemberListIsCheckedAgainstTheWrittenVersion(t *testing.T) {
+	ctx := context.Background()
+	f := newMemberFixture(t)
+	f.boards.race = func(ctx context.Context) {
+		if _, err := f.svc.SetMember(ctx, f.board.ID, f.owner, models.BoardMember{UserID: f.editor, Role: models.BoardRoleAdmin}); err != nil {
+			t.Error(err)
+		}
+	}
+	stale := []models.BoardMember{
+		{UserID: f.admin, Role: models.BoardRoleAdmin},
+		{UserID: f.editor, Role: models.BoardRoleEditor},
+	}
+	if _, err := f.svc.Update(ctx, f.board.ID, f.admin, BoardPatch{Members: &stale}, nil); !errors.Is(err, ErrAdminOwnerOnly) {
+		t.Fatalf("err = %v, want ErrAdminOwnerOnly", err)
+	}
+	if got := f.role(t, f.editor); got != models.BoardRoleAdmin {
+		t.Errorf("promoted member is now %s", got)
+	}
+}
+
+// board yang dikembalikan Update adalah version yang ditimpa, sehingga member
+// yang ditambah bersamaan ikut terhitung sebagai yang dikeluarkan
+func TestUpdateReturnsTheOverwrittenBoard(t *testing.T) {
+	ctx := context.Background()
+	f := newMemberFixture(t)
+	late := primitive.NewObjectID()
+	f.boards.race = func(ctx context.Context) {
+		if _, err := f.svc.SetMember(ctx, f.board.ID, f.owner, models.BoardMember{UserID: late, Role: models.BoardRoleViewer}); err != nil {
+			t.Error(err)
+		}
+	}
+	members := []models.BoardMember{{UserID: f.admin, Role: models.BoardRoleAdmin}}
+	before, err := f.svc.Update(ctx, f.board.ID, f.owner, BoardPatch{Members: &members}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := before.RoleOf(late); !ok {
+		t.Errorf("returned board (version %d) does not contain the member added concurrently", before.Version)
+	}
+	if got := f.role(t, late); got != "" {
+		t.Errorf("late member still has role %s", got)
+	}
+}
+
+func TestUpdateWithStaleIfMatchIsNotRetried(t *testing.T) {
+	ctx := context.Background()
+	f := newMemberFixture(t)
+	f.boards.race = func(ctx context.Context) {
+		if _, err := f.svc.Update(ctx, f.board.ID, f.owner, BoardPatch{Name: ptr("other")}, nil); err != nil {
+			t.Error(err)
+		}
+	}
+	_, err := f.svc.Update(ctx, f.board.ID, f.owner, BoardPatch{Name: ptr("mine")}, ptr(f.board.Version))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+}
+
+func TestAddInvited(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name        string
+		invitee, by string // "new" = user baru, selain itu nama di fixture
+		role        models.BoardRole
+		wantErr     error
+		want        models.BoardRole
+	}{
+		{"owner invited an admin", "new", "owner", models.BoardRoleAdmin, nil, models.BoardRoleAdmin},
+		{"admin invited a viewer", "new", "admin", models.BoardRoleViewer, nil, models.BoardRoleViewer},
+		{"inviter is no longer owner", "new", "admin", models.BoardRoleAdmin, ErrAdminOwnerOnly, ""},
+		{"already a member keeps the role", "editor", "owner", models.BoardRoleViewer, nil, models.BoardRoleEditor},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newMemberFixture(t)
+			users := map[string]primitive.ObjectID{
+				"new": primitive.NewObjectID(), "owner": f.owner, "admin": f.admin, "editor": f.editor,
+			}
+			user := users[tc.invitee]
+			err := f.svc.AddInvited(ctx, f.board.ID, user, users[tc.by], tc.role)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if got := f.role(t, user); got != tc.want {
+				t.Errorf("role = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// board arsip tetap bisa dibaca; semua tulis task/note ditolak
+func TestArchivedBoardIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	f := newTrashFixture(t, OrderingIndex)
+	n := f.note(t, "a")
+	if err := f.tasks.Delete(ctx, f.ids["c"], f.owner); err != nil {
+		t.Fatal(err)
+	}
+	b, err := f.board.SetArchived(ctx, f.b.ID, f.owner, true)
+	if err != nil || !b.IsArchived {
+		t.Fatalf("archive = %+v, %v", b, err)
+	}
+	if again, err := f.board.SetArchived(ctx, f.b.ID, f.owner, true); err != nil || again.Version != b.Version {
+		t.Errorf("archive twice bumps the version: %+v, %v", again, err)
+	}
+
+	a := f.ids["a"]
+	writes := map[string]func() error{
+		"task create": func() error {
+			_, err := f.tasks.Create(ctx, f.b.ID, f.owner, "new", nil, "todo", nil, nil, nil)
+			return err
+		},
+		"task update": func() error { return f.tasks.Update(ctx, a, bson.M{"title": "x"}, f.owner, nil) },
+		"task move": func() error {
+			_, err := f.tasks.Move(ctx, a, "done", 1, f.owner, nil)
+			return err
+		},
+		"task delete": func() error { return f.tasks.Delete(ctx, a, f.owner) },
+		"task restore": func() error {
+			_, err := f.tasks.Restore(ctx, f.ids["c"], f.owner)
+			return err
+		},
+		"note create": func() error {
+			_, err := f.notes.Create(ctx, f.owner, "new", &f.b.ID, &a, nil, false)
+			return err
+		},
+		"note update": func() error { return f.notes.Update(ctx, n.ID, f.owner, bson.M{"content": "x"}, nil) },
+		"note delete": func() error { return f.notes.Delete(ctx, n.ID, f.owner) },
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, ErrBoardArchived) {
+			t.Errorf("%s: err = %v, want ErrBoardArchived", name, err)
+		}
+	}
+	if task, err := f.tasks.Get(ctx, a); err != nil || task.Title != "a" {
+		t.Errorf("read of an archived board = %+v, %v", task, err)
+	}
+
+	listed := func(includeArchived bool) bool {
+		out, err := f.board.ListForUser(ctx, f.owner, includeArchived)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(out) == 1
+	}
+	if listed(false) || !listed(true) {
+		t.Errorf("archived board listed = %v, with includeArchived = %v", listed(false), listed(true))
+	}
+
+	if _, err := f.board.SetArchived(ctx, f.b.ID, f.owner, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := writes["task update"](); err != nil {
+		t.Errorf("update after unarchive: %v", err)
+	}
+	if !listed(false) {
+		t.Error("unarchived board missing from the list")
+	}
+}
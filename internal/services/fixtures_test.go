@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/mailer"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Helper bersama untuk test service. Semua test memakai repository.NewMemory()
+// supaya bisa jalan tanpa Mongo.
+
+// seedBoard menyimpan board milik owner dengan kolom berid columns (nama = id)
+func seedBoard(t *testing.T, repos *repository.Repos, owner primitive.ObjectID, columns ...string) *models.Board {
+	t.Helper()
+	b := &models.Board{ID: primitive.NewObjectID(), Name: "board", OwnerID: owner, Version: 1}
+	for i, c := range columns {
+		b.Columns = append(b.Columns, models.BoardColumn{ID: c, Name: c, Order: i + 1})
+	}
+	if err := repos.Boards.Insert(context.Background(), b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func newTestTaskService(repos *repository.Repos, tx repository.Transactor, mode OrderingMode) TaskService {
+	if tx == nil {
+		tx = repos.Tx
+	}
+	return NewTaskService(repos.Tasks, repos.Notes, repos.Boards, tx, NewActivityService(repos.Activities), mode)
+}
+
+// seedTasks membuat task berjudul titles (berurutan) di akhir column
+func seedTasks(t *testing.T, svc TaskService, boardID, owner primitive.ObjectID, column string, titles ...string) map[string]primitive.ObjectID {
+	t.Helper()
+	ids := map[string]primitive.ObjectID{}
+	for _, title := range titles {
+		task, err := svc.Create(context.Background(), boardID, owner, title, nil, column, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = task.ID
+	}
+	return ids
+}
+
+// columnTitles: judul task per kolom sesuai urutan ListByBoard; gagal bila
+// order yang tersimpan bukan 1..N
+func columnTitles(t *testing.T, svc TaskService, boardID primitive.ObjectID) map[string][]string {
+	t.Helper()
+	items, err := svc.ListByBoard(context.Background(), boardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string][]string{}
+	for _, it := range items {
+		out[it.ColumnID] = append(out[it.ColumnID], it.Title)
+		if it.Order == nil {
+			t.Fatalf("task %s in %s has no order", it.Title, it.ColumnID)
+		}
+		if *it.Order != len(out[it.ColumnID]) {
+			t.Fatalf("task %s in %s: order %d, want %d", it.Title, it.ColumnID, *it.Order, len(out[it.ColumnID]))
+		}
+	}
+	return out
+}
+
+// noTx meniru Mongo standalone supaya jalur optimistic ikut dites
+type noTx struct{}
+
+func (noTx) WithTx(context.Context, func(ctx context.Context) error) error {
+	return repository.ErrTxUnsupported
+}
+
+// mailbox: Mailer tiruan. deliverMail mengirim di goroutine, jadi test
+// menunggu email lewat next.
+type mailbox chan mailer.Message
+
+func (m mailbox) Send(_ context.Context, msg mailer.Message) error {
+	m <- msg
+	return nil
+}
+
+func (m mailbox) next(t *testing.T) mailer.Message {
+	t.Helper()
+	select {
+	case msg := <-m:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no email sent")
+		return mailer.Message{}
+	}
+}
+
+// linkToken: nilai ?token= dari link pertama di body email
+func linkToken(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Text) {
+		if u, err := url.Parse(field); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+	t.Fatalf("no token link in %q", msg.Text)
+	return ""
+}
+
+func seedUser(t *testing.T, repos *repository.Repos, email string, verified bool) *models.User {
+	t.Helper()
+	u := &models.User{ID: primitive.NewObjectID(), Email: email, Name: strings.Split(email, "@")[0], IsActive: true}
+	if verified {
+		now := time.Now().UTC()
+		u.EmailVerifiedAt = &now
+	}
+	if err := repos.Users.Insert(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// loadTestJWTKeys: service yang menerbitkan access token butuh key JWT
+func loadTestJWTKeys(t *testing.T) {
+	t.Helper()
+	config.Cfg.JWTAlg = "HS256"
+	config.Cfg.JWTSecret = "services-test-secret-0123456789abcdef"
+	if err := utils.LoadJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+}
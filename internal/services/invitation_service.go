@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/mailer"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InvitationService interface {
+	// Create menyimpan undangan dan mengirim link berisi token ke email invitee;
+	// token plain hanya ada di email itu
+	Create(ctx context.Context, boardID, inviterID primitive.ObjectID, email string, role models.BoardRole) (*models.Invitation, error)
+	ListForBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Invitation, error)
+	// ListForUser: undangan pending (belum kedaluwarsa) ke email user;
+	// ErrEmailNotVerified bila email user belum dikonfirmasi
+	ListForUser(ctx context.Context, userID primitive.ObjectID) ([]models.Invitation, error)
+	// Accept/Decline lewat id: email user harus sama dan sudah terverifikasi
+	Accept(ctx context.Context, id, userID primitive.ObjectID) (*models.Invitation, error)
+	Decline(ctx context.Context, id, userID primitive.ObjectID) error
+	// AcceptToken: pemegang token dianggap pemilik email (link dari email undangan)
+	AcceptToken(ctx context.Context, token string, userID primitive.ObjectID) (*models.Invitation, error)
+	Revoke(ctx context.Context, boardID, id primitive.ObjectID) error
+}
+
+var (
+	ErrInvalidEmail      = errors.New("invalid email")
+	ErrAlreadyMember     = errors.New("user is already a board member")
+	ErrInvitePending     = errors.New("an invitation for this email is already pending")
+	ErrInviteNotPending  = errors.New("invitation is no longer pending")
+	ErrInviteExpired     = errors.New("invitation has expired")
+	ErrInviteNotYours    = errors.New("invitation was sent to a different email")
+	ErrInviteNotFound    = errors.New("invitation not found")
+	ErrInviteTokenNeeded = errors.New("invitation token required")
+)
+
+// InvitationOptions: isi dari config
+type InvitationOptions struct {
+	AppURL string // link di email: AppURL/invitations/accept?token=...
+	TTL    time.Duration
+}
+
+type invitationService struct {
+	invitations repository.InvitationRepo
+	users       repository.UserRepo
+	boards      BoardService
+	mail        mailer.Mailer
+	opts        InvitationOptions
+}
+
+func NewInvitationService(invitations repository.InvitationRepo, users repository.UserRepo, boards BoardService, mail mailer.Mailer, opts InvitationOptions) InvitationService {
+	return &invitationService{invitations: invitations, users: users, boards: boards, mail: mail, opts: opts}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *invitationService) Create(ctx context.Context, boardID, inviterID primitive.ObjectID, email string, role models.BoardRole) (*models.Invitation, error) {
+	email = normalizeEmail(email)
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if !models.ValidMemberRole(role) {
+		return nil, ErrInvalidRole
+	}
+	b, err := s.boards.Get(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	// email sudah terdaftar & sudah member/owner → tidak perlu undangan
+	if u, err := s.users.FindByEmail(ctx, email); err == nil {
+		if _, ok := b.RoleOf(u.ID); ok {
+			return nil, ErrAlreadyMember
+		}
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	inviter, err := s.users.FindByID(ctx, inviterID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := utils.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	inv := &models.Invitation{
+		ID:        primitive.NewObjectID(),
+		BoardID:   boardID,
+		BoardName: b.Name,
+		Email:     email,
+		Role:      role,
+		InvitedBy: inviterID,
+		TokenHash: hash,
+		Status:    models.InvitationPending,
+		ExpiresAt: now.Add(s.opts.TTL),
+		TimeMeta:  models.TimeMeta{CreatedAt: now, UpdatedAt: now},
+	}
+	err = s.invitations.Insert(ctx, inv)
+	if errors.Is(err, repository.ErrDuplicate) {
+		// undangan lama yang sudah kedaluwarsa tidak boleh menghalangi
+		if expired, e := s.expirePending(ctx, boardID, email, now); e != nil || !expired {
+			return nil, ErrInvitePending
+		}
+		err = s.invitations.Insert(ctx, inv)
+	}
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrInvitePending
+	}
+	if err != nil {
+		return nil, err
+	}
+	link := s.opts.AppURL + "/invitations/accept?" + url.Values{"token": {token}}.Encode()
+	deliverMail(s.mail, mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("%s invited you to %s", inviter.Name, b.Name),
+		Text: fmt.Sprintf("Hi,\n\n%s invited you to join the board \"%s\" as %s. Open this link to accept:\n\n%s\n\nThe invitation expires in %s. If you do not have an account yet, you can sign up from the same link.\n",
+			inviter.Name, b.Name, role, link, ttlText(s.opts.TTL)),
+	})
+	return inv, nil
+}
+
+// expirePending menandai undangan pending board+email yang sudah lewat waktu
+func (s *invitationService) expirePending(ctx context.Context, boardID primitive.ObjectID, email string, now time.Time) (bool, error) {
+	list, err := s.invitations.ListPendingByEmail(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	expired := false
+	for _, inv := range list {
+		if inv.BoardID != boardID || !inv.Expired(now) {
+			continue
+		}
+		err := s.invitations.Resolve(ctx, inv.ID, bson.M{"status": models.InvitationExpired, "updatedAt": now})
+		if err != nil && !errors.Is(err, repository.ErrConflict) {
+			return false, err
+		}
+		expired = true
+	}
+	return expired, nil
+}
+
+func (s *invitationService) ListForBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Invitation, error) {
+	out, err := s.invitations.ListByBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for i := range out {
+		if out[i].Expired(now) {
+			out[i].Status = models.InvitationExpired
+		}
+	}
+	return out, nil
+}
+
+func (s *invitationService) ListForUser(ctx context.Context, userID primitive.ObjectID) ([]models.Invitation, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// siapa pun bisa register dengan email orang lain; tanpa verifikasi hanya link token
+	if u.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+	list, err := s.invitations.ListPendingByEmail(ctx, normalizeEmail(u.Email))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	out := []models.Invitation{}
+	for _, inv := range list {
+		if !inv.Expired(now) {
+			out = append(out, inv)
+		}
+	}
+	return out, nil
+}
+
+func (s *invitationService) Accept(ctx context.Context, id, userID primitive.ObjectID) (*models.Invitation, error) {
+	inv, err := s.forUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return inv, s.accept(ctx, inv, userID)
+}
+
+func (s *invitationService) Decline(ctx context.Context, id, userID primitive.ObjectID) error {
+	inv, err := s.forUser(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	return s.resolve(ctx, inv.ID, bson.M{"status": models.InvitationDeclined})
+}
+
+func (s *invitationService) AcceptToken(ctx context.Context, token string, userID primitive.ObjectID) (*models.Invitation, error) {
+	if token == "" {
+		return nil, ErrInviteTokenNeeded
+	}
+	inv, err := s.invitations.FindByTokenHash(ctx, utils.HashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPending(inv); err != nil {
+		return nil, err
+	}
+	return inv, s.accept(ctx, inv, userID)
+}
+
+func (s *invitationService) Revoke(ctx context.Context, boardID, id primitive.ObjectID) error {
+	inv, err := s.invitations.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrInviteNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if inv.BoardID != boardID {
+		return ErrInviteNotFound
+	}
+	return s.resolve(ctx, id, bson.M{"status": models.InvitationRevoked})
+}
+
+// forUser: undangan pending milik email user yang sudah terverifikasi
+func (s *invitationService) forUser(ctx context.Context, id, userID primitive.ObjectID) (*models.Invitation, error) {
+	inv, err := s.invitations.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if normalizeEmail(u.Email) != inv.Email {
+		return nil, ErrInviteNotYours
+	}
+	if u.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+	return inv, checkPending(inv)
+}
+
+func checkPending(inv *models.Invitation) error {
+	if inv.Status != models.InvitationPending {
+		return ErrInviteNotPending
+	}
+	if inv.Expired(time.Now().UTC()) {
+		return ErrInviteExpired
+	}
+	return nil
+}
+
+// accept: klaim undangan dulu (sekali saja), baru tambahkan member
+func (s *invitationService) accept(ctx context.Context, inv *models.Invitation, userID primitive.ObjectID) error {
+	if err := s.resolve(ctx, inv.ID, bson.M{"status": models.InvitationAccepted, "acceptedBy": userID}); err != nil {
+		return err
+	}
+	inv.Status = models.InvitationAccepted
+	inv.AcceptedBy = &userID
+	return s.boards.AddInvited(ctx, inv.BoardID, userID, inv.InvitedBy, inv.Role)
+}
+
+func (s *invitationService) resolve(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	set["updatedAt"] = time.Now().UTC()
+	err := s.invitations.Resolve(ctx, id, set)
+	switch {
+	case errors.Is(err, repository.ErrConflict):
+		return ErrInviteNotPending
+	case errors.Is(err, repository.ErrNotFound):
+		return ErrInviteNotFound
+	}
+	return err
+}
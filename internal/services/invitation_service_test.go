@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+)
+
+type inviteFixture struct {
+	repos *repository.Repos
+	svc   InvitationService
+	mail  mailbox
+	owner *models.User
+	board *models.Board
+}
+
+func newInviteFixture(t *testing.T, ttl time.Duration) *inviteFixture {
+	t.Helper()
+	repos := repository.NewMemory()
+	f := &inviteFixture{repos: repos, mail: make(mailbox, 4)}
+	f.owner = seedUser(t, repos, "owner@x.io", true)
+	f.board = seedBoard(t, repos, f.owner.ID, "todo")
+	boards := NewBoardService(repos.Boards, NewActivityService(repos.Activities))
+	f.svc = NewInvitationService(repos.Invitations, repos.Users, boards, f.mail, InvitationOptions{AppURL: "https://app.test", TTL: ttl})
+	return f
+}
+
+func (f *inviteFixture) role(t *testing.T, user *models.User) models.BoardRole {
+	t.Helper()
+	b, err := f.repos.Boards.FindByID(context.Background(), f.board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	role, _ := b.RoleOf(user.ID)
+	return role
+}
+
+// undangan ke email yang belum terdaftar diklaim lewat token dari email
+func TestInvitationClaimedAfterSignUp(t *testing.T) {
+	ctx := context.Background()
+	f := newInviteFixture(t, time.Hour)
+	if _, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, " New@X.io ", models.BoardRoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	msg := f.mail.next(t)
+	if msg.To != "new@x.io" {
+		t.Errorf("mail sent to %q", msg.To)
+	}
+	token := linkToken(t, msg)
+
+	// baru mendaftar, email belum terverifikasi: token cukup sebagai bukti
+	user := seedUser(t, f.repos, "new@x.io", false)
+	inv, err := f.svc.AcceptToken(ctx, token, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.Status != models.InvitationAccepted {
+		t.Errorf("status = %s", inv.Status)
+	}
+	if got := f.role(t, user); got != models.BoardRoleEditor {
+		t.Errorf("role = %q, want editor", got)
+	}
+	if _, err := f.svc.AcceptToken(ctx, token, user.ID); !errors.Is(err, ErrInviteNotPending) {
+		t.Errorf("token reused: err = %v, want ErrInviteNotPending", err)
+	}
+	if _, err := f.svc.AcceptToken(ctx, "bogus", user.ID); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("unknown token: err = %v, want ErrInviteNotFound", err)
+	}
+}
+
+func TestInvitationInbox(t *testing.T) {
+	ctx := context.Background()
+	f := newInviteFixture(t, time.Hour)
+	invitee := seedUser(t, f.repos, "bob@x.io", false)
+	other := seedUser(t, f.repos, "eve@x.io", true)
+	inv, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, "bob@x.io", models.BoardRoleViewer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tanpa verifikasi email, undangan tidak terlihat / tidak bisa diterima lewat id
+	if _, err := f.svc.ListForUser(ctx, invitee.ID); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("unverified list: err = %v", err)
+	}
+	if _, err := f.svc.Accept(ctx, inv.ID, invitee.ID); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("unverified accept: err = %v", err)
+	}
+	if _, err := f.svc.Accept(ctx, inv.ID, other.ID); !errors.Is(err, ErrInviteNotYours) {
+		t.Errorf("other user accept: err = %v", err)
+	}
+
+	now := time.Now().UTC()
+	if err := f.repos.Users.Update(ctx, invitee.ID, map[string]interface{}{"emailVerifiedAt": now}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := f.svc.ListForUser(ctx, invitee.ID)
+	if err != nil || len(list) != 1 || list[0].ID != inv.ID {
+		t.Fatalf("inbox = %v, %v", list, err)
+	}
+	if err := f.svc.Decline(ctx, inv.ID, invitee.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.Accept(ctx, inv.ID, invitee.ID); !errors.Is(err, ErrInviteNotPending) {
+		t.Errorf("accept after decline: err = %v", err)
+	}
+	if got := f.role(t, invitee); got != "" {
+		t.Errorf("declined invitee has role %s", got)
+	}
+	if list, _ := f.svc.ListForUser(ctx, invitee.ID); len(list) != 0 {
+		t.Errorf("inbox still lists %d invitations", len(list))
+	}
+}
+
+func TestInvitationCreateRejects(t *testing.T) {
+	ctx := context.Background()
+	f := newInviteFixture(t, time.Hour)
+	member := seedUser(t, f.repos, "member@x.io", true)
+	if err := f.repos.Boards.Update(ctx, f.board.ID, map[string]interface{}{
+		"members":     []interface{}{member.ID},
+		"memberRoles": []models.BoardMember{{UserID: member.ID, Role: models.BoardRoleViewer}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, "dup@x.io", models.BoardRoleViewer); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		email string
+		role  models.BoardRole
+		want  error
+	}{
+		"not an email":     {"nope", models.BoardRoleViewer, ErrInvalidEmail},
+		"owner role":       {"a@x.io", models.BoardRoleOwner, ErrInvalidRole},
+		"already a member": {"MEMBER@x.io", models.BoardRoleEditor, ErrAlreadyMember},
+		"board owner":      {"owner@x.io", models.BoardRoleEditor, ErrAlreadyMember},
+		"already pending":  {"dup@x.io", models.BoardRoleEditor, ErrInvitePending},
+	}
+	for name, tc := range cases {
+		if _, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, tc.email, tc.role); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+// undangan kedaluwarsa tidak menghalangi undangan baru ke email yang sama
+func TestExpiredInvitationIsReplaced(t *testing.T) {
+	ctx := context.Background()
+	f := newInviteFixture(t, -time.Minute)
+	old, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, "late@x.io", models.BoardRoleViewer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := linkToken(t, f.mail.next(t))
+	user := seedUser(t, f.repos, "late@x.io", true)
+	if _, err := f.svc.AcceptToken(ctx, token, user.ID); !errors.Is(err, ErrInviteExpired) {
+		t.Errorf("expired token: err = %v, want ErrInviteExpired", err)
+	}
+
+	if _, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, "late@x.io", models.BoardRoleViewer); err != nil {
+		t.Fatalf("second invitation: %v", err)
+	}
+	list, err := f.svc.ListForBoard(ctx, f.board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, inv := range list {
+		if inv.ID == old.ID && inv.Status != models.InvitationExpired {
+			t.Errorf("old invitation status = %s", inv.Status)
+		}
+	}
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	ctx := context.Background()
+	f := newInviteFixture(t, time.Hour)
+	inv, err := f.svc.Create(ctx, f.board.ID, f.owner.ID, "r@x.io", models.BoardRoleViewer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := linkToken(t, f.mail.next(t))
+	other := seedBoard(t, f.repos, f.owner.ID)
+	if err := f.svc.Revoke(ctx, other.ID, inv.ID); !errors.Is(err, ErrInviteNotFound) {
+		t.Errorf("revoke via another board: err = %v, want ErrInviteNotFound", err)
+	}
+	if err := f.svc.Revoke(ctx, f.board.ID, inv.ID); err != nil {
+		t.Fatal(err)
+	}
+	user := seedUser(t, f.repos, "r@x.io", true)
+	if _, err := f.svc.AcceptToken(ctx, token, user.ID); !errors.Is(err, ErrInviteNotPending) {
+		t.Errorf("revoked token: err = %v, want ErrInviteNotPending", err)
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginGuard membatasi tebakan password: login gagal dihitung per akun
+// (email, termasuk yang tidak terdaftar) dan per IP. Akun mendapat jeda yang
+// naik eksponensial lalu dikunci sementara; IP hanya dikunci sementara.
+type LoginGuard interface {
+	// Check: *LoginLockedError bila akun atau IP sedang dikunci
+	Check(ctx context.Context, email, ip string) error
+	// Failure mencatat login gagal; userID nil untuk email tak dikenal
+	Failure(ctx context.Context, email, ip string, userID *primitive.ObjectID) error
+	// Success menghapus hitungan akun (hitungan IP dibiarkan meluruh)
+	Success(ctx context.Context, email string) error
+	// Unlock: admin membuka kunci akun user
+	Unlock(ctx context.Context, userID, adminID primitive.ObjectID) error
+}
+
+// LoginGuardOptions: isi dari config LOGIN_*
+type LoginGuardOptions struct {
+	MaxFailures   int           // kegagalan akun sampai dikunci penuh
+	IPMaxFailures int           // kegagalan dari satu IP sampai IP dikunci
+	Lockout       time.Duration // lama kunci
+}
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// LoginLockedError: ErrLoginLocked beserta sisa waktunya
+type LoginLockedError struct{ RetryAfter time.Duration }
+
+func (e *LoginLockedError) Error() string        { return ErrLoginLocked.Error() }
+func (e *LoginLockedError) Is(target error) bool { return target == ErrLoginLocked }
+
+const (
+	// freeAttempts: kegagalan akun tanpa jeda (salah ketik biasa)
+	freeAttempts = 3
+	// failureWindow: hitungan dilupakan setelah sekian lama tanpa kegagalan baru
+	failureWindow = time.Hour
+)
+
+type loginGuard struct {
+	throttles repository.LoginThrottleRepo
+	users     repository.UserRepo
+	audit     AuditService
+	opts      LoginGuardOptions
+}
+
+func NewLoginGuard(throttles repository.LoginThrottleRepo, users repository.UserRepo, audit AuditService, opts LoginGuardOptions) LoginGuard {
+	return &loginGuard{throttles: throttles, users: users, audit: audit, opts: opts}
+}
+
+func accountKey(email string) string { return "acct:" + strings.ToLower(strings.TrimSpace(email)) }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+func (g *loginGuard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{accountKey(email), ipKey(ip)} {
+		t, err := g.throttles.Get(ctx, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if d := t.LockedFor(now); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (g *loginGuard) Failure(ctx context.Context, email, ip string, userID *primitive.ObjectID) error {
+	now := time.Now().UTC()
+	acct, err := g.throttles.RecordFailure(ctx, accountKey(email), now, now.Add(failureWindow))
+	if err != nil {
+		return err
+	}
+	if d := g.accountDelay(acct.Failures); d > 0 {
+		if err := g.throttles.Lock(ctx, acct.Key, now.Add(d), now.Add(d+failureWindow)); err != nil {
+			return err
+		}
+		if acct.Failures >= g.opts.MaxFailures {
+			g.audit.Record(ctx, &models.AuditEvent{
+				Type:      models.AuditLoginLocked,
+				SubjectID: userID,
+				Email:     strings.ToLower(strings.TrimSpace(email)),
+				IP:        ip,
+				Details:   map[string]interface{}{"failures": acct.Failures, "lockedUntil": now.Add(d)},
+			})
+		}
+	}
+
+	byIP, err := g.throttles.RecordFailure(ctx, ipKey(ip), now, now.Add(failureWindow))
+	if err != nil {
+		return err
+	}
+	if byIP.Failures >= g.opts.IPMaxFailures {
+		until := now.Add(g.opts.Lockout)
+		if err := g.throttles.Lock(ctx, byIP.Key, until, until.Add(failureWindow)); err != nil {
+			return err
+		}
+		g.audit.Record(ctx, &models.AuditEvent{
+			Type:    models.AuditLoginIPLocked,
+			IP:      ip,
+			Details: map[string]interface{}{"failures": byIP.Failures, "lockedUntil": until},
+		})
+	}
+	return nil
+}
+
+// accountDelay: 0 untuk freeAttempts pertama, lalu 1s, 2s, 4s, ... (maks
+// Lockout), dan Lockout penuh mulai MaxFailures
+func (g *loginGuard) accountDelay(failures int) time.Duration {
+	switch {
+	case failures >= g.opts.MaxFailures:
+		return g.opts.Lockout
+	case failures <= freeAttempts:
+		return 0
+	}
+	shift := failures - freeAttempts - 1
+	if shift > 30 {
+		return g.opts.Lockout
+	}
+	d := time.Second << shift
+	if d > g.opts.Lockout {
+		return g.opts.Lockout
+	}
+	return d
+}
+
+func (g *loginGuard) Success(ctx context.Context, email string) error {
+	return g.throttles.Clear(ctx, accountKey(email))
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, userID, adminID primitive.ObjectID) error {
+	u, err := g.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := g.throttles.Clear(ctx, accountKey(u.Email)); err != nil {
+		return err
+	}
+	g.audit.Record(ctx, &models.AuditEvent{
+		Type:      models.AuditLoginUnlocked,
+		ActorID:   &adminID,
+		SubjectID: &u.ID,
+		Email:     u.Email,
+	})
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// auditLog: AuditService tiruan yang menyimpan event di memori
+type auditLog struct {
+	mu     sync.Mutex
+	events []models.AuditEvent
+}
+
+func (a *auditLog) Record(_ context.Context, e *models.AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, *e)
+}
+
+func (a *auditLog) ofType(typ models.AuditEventType) []models.AuditEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var out []models.AuditEvent
+	for _, e := range a.events {
+		if e.Type == typ {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestAccountDelay(t *testing.T) {
+	g := &loginGuard{opts: LoginGuardOptions{MaxFailures: 8, Lockout: 15 * time.Minute}}
+	short := &loginGuard{opts: LoginGuardOptions{MaxFailures: 8, Lockout: 3 * time.Second}}
+	cases := []struct {
+		guard    *loginGuard
+		failures int
+		want     time.Duration
+	}{
+		{g, 1, 0},
+		{g, 3, 0},
+		{g, 4, time.Second},
+		{g, 5, 2 * time.Second},
+		{g, 7, 8 * time.Second},
+		{g, 8, 15 * time.Minute},
+		{g, 50, 15 * time.Minute},
+		{short, 6, 3 * time.Second},
+	}
+	for _, tc := range cases {
+		if got := tc.guard.accountDelay(tc.failures); got != tc.want {
+			t.Errorf("lockout %s, %d failures: delay %s, want %s", tc.guard.opts.Lockout, tc.failures, got, tc.want)
+		}
+	}
+}
+
+func newTestGuard(opts LoginGuardOptions) (*repository.Repos, *auditLog, LoginGuard) {
+	repos := repository.NewMemory()
+	audit := &auditLog{}
+	return repos, audit, NewLoginGuard(repos.Throttles, repos.Users, audit, opts)
+}
+
+func TestAccountLockoutAndUnlock(t *testing.T) {
+	ctx := context.Background()
+	repos, audit, guard := newTestGuard(LoginGuardOptions{MaxFailures: 5, IPMaxFailures: 100, Lockout: 10 * time.Minute})
+	u := seedUser(t, repos, "locked@x.io", true)
+	for i := 0; i < 5; i++ {
+		if err := guard.Failure(ctx, "Locked@x.io ", "10.0.0.1", &u.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := guard.Check(ctx, "locked@x.io", "10.0.0.2")
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("err = %v, want LoginLockedError", err)
+	}
+	if locked.RetryAfter <= 9*time.Minute || locked.RetryAfter > 10*time.Minute {
+		t.Errorf("retry after %s, want about 10m", locked.RetryAfter)
+	}
+	if err := guard.Check(ctx, "other@x.io", "10.0.0.1"); err != nil {
+		t.Errorf("other account from the same IP: %v", err)
+	}
+	events := audit.ofType(models.AuditLoginLocked)
+	if len(events) != 1 || events[0].Email != "locked@x.io" || events[0].SubjectID == nil || *events[0].SubjectID != u.ID {
+		t.Fatalf("lockout audit events = %+v", events)
+	}
+
+	admin := primitive.NewObjectID()
+	if err := guard.Unlock(ctx, u.ID, admin); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Check(ctx, "locked@x.io", "10.0.0.1"); err != nil {
+		t.Errorf("after unlock: %v", err)
+	}
+	unlocked := audit.ofType(models.AuditLoginUnlocked)
+	if len(unlocked) != 1 || unlocked[0].ActorID == nil || *unlocked[0].ActorID != admin {
+		t.Errorf("unlock audit events = %+v", unlocked)
+	}
+}
+
+// satu IP menebak banyak akun: IP dikunci walau tiap akun di bawah batas
+func TestIPLockout(t *testing.T) {
+	ctx := context.Background()
+	_, audit, guard := newTestGuard(LoginGuardOptions{MaxFailures: 50, IPMaxFailures: 4, Lockout: time.Minute})
+	for _, email := range []string{"a@x.io", "b@x.io", "c@x.io", "d@x.io"} {
+		if err := guard.Failure(ctx, email, "10.9.9.9", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := guard.Check(ctx, "fresh@x.io", "10.9.9.9"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("locked IP: err = %v", err)
+	}
+	if err := guard.Check(ctx, "a@x.io", "10.1.1.1"); err != nil {
+		t.Errorf("same account from another IP: %v", err)
+	}
+	if events := audit.ofType(models.AuditLoginIPLocked); len(events) != 1 || events[0].IP != "10.9.9.9" {
+		t.Errorf("ip lock audit events = %+v", events)
+	}
+}
+
+func TestLoginCountsFailures(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	f.user(t, "sam@x.io", "right-password", true)
+	guard := NewLoginGuard(f.repos.Throttles, f.repos.Users, &auditLog{}, LoginGuardOptions{MaxFailures: 5, IPMaxFailures: 100, Lockout: time.Minute})
+	auth := NewAuthService(NewUserService(f.repos.Users, nil), f.sessions, guard, NewMFAService(f.repos.Users, f.repos.MFA, f.sessions, guard, "test"), false)
+	login := func(email, password string) error {
+		_, err := auth.Login(ctx, email, password, SessionMeta{IP: "10.0.0.1"})
+		return err
+	}
+
+	// login sukses menghapus hitungan kegagalan akun
+	for i := 0; i < 3; i++ {
+		if err := login("sam@x.io", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v", i, err)
+		}
+	}
+	if err := login("sam@x.io", "right-password"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := login("sam@x.io", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d after success: err = %v", i, err)
+		}
+	}
+
+	// email yang tidak terdaftar dihitung seperti password salah
+	for i := 0; i < 4; i++ {
+		if err := login("ghost@x.io", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("unknown email attempt %d: err = %v", i, err)
+		}
+	}
+	if err := login("ghost@x.io", "wrong"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("unknown email after 4 failures: err = %v, want ErrLoginLocked", err)
+	}
+}
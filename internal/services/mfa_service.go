@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/totp"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MFAService: 2FA TOTP (enrollment, kode pemulihan) dan langkah kedua login
+type MFAService interface {
+	Status(ctx context.Context, userID primitive.ObjectID) (*MFAStatus, error)
+	// Enroll membuat secret baru (belum aktif sampai Confirm)
+	Enroll(ctx context.Context, userID primitive.ObjectID) (*MFAEnrollment, error)
+	// Confirm mengaktifkan 2FA dengan kode dari authenticator; mengembalikan kode pemulihan
+	Confirm(ctx context.Context, userID primitive.ObjectID, code string) ([]string, error)
+	// Disable butuh password dan kode (TOTP atau pemulihan)
+	Disable(ctx context.Context, userID primitive.ObjectID, password, code string) error
+	// RegenerateRecoveryCodes mengganti semua kode pemulihan
+	RegenerateRecoveryCodes(ctx context.Context, userID primitive.ObjectID, code string) ([]string, error)
+	// Challenge dipanggil setelah password benar; token ditukar lewat Verify
+	Challenge(ctx context.Context, userID primitive.ObjectID) (*LoginResult, error)
+	Verify(ctx context.Context, token, code string, meta SessionMeta) (*TokenPair, error)
+}
+
+// LoginResult: token biasa, atau (bila user memakai 2FA) token "mfa pending"
+type LoginResult struct {
+	Tokens       *TokenPair
+	MFAToken     string
+	MFAExpiresIn int64 // detik
+}
+
+type MFAStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabledAt,omitempty"`
+	RecoveryCodesLeft int        `json:"recoveryCodesLeft"`
+}
+
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFANoEnrollment   = errors.New("start enrollment first")
+	ErrMFAInvalidCode    = errors.New("invalid two-factor code")
+	ErrMFAChallenge      = errors.New("invalid or expired mfa token")
+)
+
+const (
+	mfaChallengeTTL   = 5 * time.Minute
+	mfaMaxAttempts    = 5 // salah kode per challenge; kegagalan juga dihitung LoginGuard per akun
+	recoveryCodeCount = 10
+)
+
+type mfaService struct {
+	users      repository.UserRepo
+	challenges repository.MFAChallengeRepo
+	sessions   SessionService
+	guard      LoginGuard
+	issuer     string // nama di aplikasi authenticator
+}
+
+func NewMFAService(users repository.UserRepo, challenges repository.MFAChallengeRepo, sessions SessionService, guard LoginGuard, issuer string) MFAService {
+	return &mfaService{users: users, challenges: challenges, sessions: sessions, guard: guard, issuer: issuer}
+}
+
+func (s *mfaService) Status(ctx context.Context, userID primitive.ObjectID) (*MFAStatus, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.MFAEnabled() {
+		return &MFAStatus{}, nil
+	}
+	return &MFAStatus{Enabled: true, EnabledAt: u.MFA.EnabledAt, RecoveryCodesLeft: len(u.MFA.RecoveryHashes)}, nil
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID primitive.ObjectID) (*MFAEnrollment, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.MFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, userID, bson.M{"mfa": models.UserMFA{PendingSecret: secret}, "updatedAt": time.Now().UTC()}); err != nil {
+		return nil, err
+	}
+	return &MFAEnrollment{Secret: secret, OtpauthURI: totp.URI(s.issuer, u.Email, secret)}, nil
+}
+
+func (s *mfaService) Confirm(ctx context.Context, userID primitive.ObjectID, code string) ([]string, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.MFAEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if u.MFA == nil || u.MFA.PendingSecret == "" {
+		return nil, ErrMFANoEnrollment
+	}
+	now := time.Now().UTC()
+	step, ok := totp.Validate(u.MFA.PendingSecret, code, now)
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, userID, bson.M{"mfa": models.UserMFA{
+		Secret:         u.MFA.PendingSecret,
+		EnabledAt:      &now,
+		RecoveryHashes: hashes,
+		LastStep:       step,
+	}, "updatedAt": now}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID primitive.ObjectID, password, code string) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !u.MFAEnabled() {
+		return ErrMFANotEnabled
+	}
+	if !utils.CheckPassword(u.PasswordHash, password) {
+		return ErrInvalidCredentials
+	}
+	if err := s.checkCode(ctx, u, code); err != nil {
+		return err
+	}
+	return s.users.Update(ctx, userID, bson.M{"mfa": nil, "updatedAt": time.Now().UTC()})
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID primitive.ObjectID, code string) ([]string, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.MFAEnabled() {
+		return nil, ErrMFANotEnabled
+	}
+	if err := s.checkCode(ctx, u, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, userID, bson.M{"mfa.recoveryHashes": hashes, "updatedAt": time.Now().UTC()}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) Challenge(ctx context.Context, userID primitive.ObjectID) (*LoginResult, error) {
+	plain, hash, err := utils.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if err := s.challenges.Insert(ctx, &models.MFAChallenge{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(mfaChallengeTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+	return &LoginResult{MFAToken: plain, MFAExpiresIn: int64(mfaChallengeTTL.Seconds())}, nil
+}
+
+func (s *mfaService) Verify(ctx context.Context, token, code string, meta SessionMeta) (*TokenPair, error) {
+	if token == "" {
+		return nil, ErrMFAChallenge
+	}
+	ch, err := s.challenges.FindByTokenHash(ctx, utils.HashToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrMFAChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(ch.ExpiresAt) || ch.Attempts >= mfaMaxAttempts {
+		return nil, ErrMFAChallenge
+	}
+	u, err := s.users.FindByID(ctx, ch.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if !u.MFAEnabled() {
+		// 2FA dimatikan setelah challenge dibuat: login ulang
+		return nil, ErrMFAChallenge
+	}
+	// challenge baru tidak mereset hitungan: kunci akun tetap berlaku di langkah kedua
+	if err := s.guard.Check(ctx, u.Email, meta.IP); err != nil {
+		return nil, err
+	}
+	if err := s.checkCode(ctx, u, code); err != nil {
+		if errors.Is(err, ErrMFAInvalidCode) {
+			if err := s.challenges.IncAttempts(ctx, ch.ID); err != nil {
+				return nil, err
+			}
+			if err := s.guard.Failure(ctx, u.Email, meta.IP, &u.ID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+	// sekali pakai: request bersamaan dengan token yang sama hanya satu yang lolos
+	if _, err := s.challenges.Take(ctx, ch.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrMFAChallenge
+		}
+		return nil, err
+	}
+	// login baru dianggap berhasil setelah langkah kedua
+	if err := s.guard.Success(ctx, u.Email); err != nil {
+		return nil, err
+	}
+	return s.sessions.Start(ctx, u.ID, meta)
+}
+
+// checkCode menerima kode TOTP (6 digit, tidak boleh dipakai ulang) atau kode
+// pemulihan (dihapus setelah dipakai). Pemakaian dicatat dengan update
+// bersyarat: request bersamaan dengan kode yang sama hanya satu yang lolos.
+func (s *mfaService) checkCode(ctx context.Context, u *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	now := time.Now().UTC()
+	var err error
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(u.MFA.Secret, code, now)
+		if !ok {
+			return ErrMFAInvalidCode
+		}
+		err = s.users.UseMFAStep(ctx, u.ID, step)
+	} else {
+		err = s.users.UseRecoveryCode(ctx, u.ID, utils.HashToken(normalizeRecoveryCode(code)), now)
+	}
+	if errors.Is(err, repository.ErrConflict) || errors.Is(err, repository.ErrNotFound) {
+		return ErrMFAInvalidCode
+	}
+	return err
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes: kode "xxxxx-xxxxx" (50 bit acak) beserta hash-nya
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, utils.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
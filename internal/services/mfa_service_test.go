@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/totp"
+)
+
+type mfaFixture struct {
+	*accountFixture
+	mfa    MFAService
+	user   *models.User
+	secret string
+}
+
+// newMFAFixture: user verified dengan password "password-1" yang sudah
+// mengaktifkan 2FA; recovery = kode pemulihan dari Confirm
+func newMFAFixture(t *testing.T) (*mfaFixture, []string) {
+	t.Helper()
+	ctx := context.Background()
+	f := &mfaFixture{accountFixture: newAccountFixture(t)}
+	guard := NewLoginGuard(f.repos.Throttles, f.repos.Users, &auditLog{}, LoginGuardOptions{})
+	f.mfa = NewMFAService(f.repos.Users, f.repos.MFA, f.sessions, guard, "Be-Ambis")
+	f.user = f.accountFixture.user(t, "mfa@x.io", "password-1", true)
+
+	enrollment, err := f.mfa.Enroll(ctx, f.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enrollment.OtpauthURI, "otpauth://totp/Be-Ambis:mfa@x.io?") {
+		t.Errorf("otpauth uri = %s", enrollment.OtpauthURI)
+	}
+	f.secret = enrollment.Secret
+	if _, err := f.mfa.Confirm(ctx, f.user.ID, "000000"); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Fatalf("confirm with a wrong code: err = %v", err)
+	}
+	// langkah sebelumnya (masih dalam skew) supaya langkah sekarang & berikutnya
+	// tersisa untuk test
+	recovery, err := f.mfa.Confirm(ctx, f.user.ID, f.code(t, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, recovery
+}
+
+// code: kode TOTP untuk langkah sekarang + offset
+func (f *mfaFixture) code(t *testing.T, offset int64) string {
+	t.Helper()
+	c, err := totp.Code(f.secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// challenge: login password; hasilnya harus token mfa pending
+func (f *mfaFixture) challenge(t *testing.T) string {
+	t.Helper()
+	res, err := f.auth.Login(context.Background(), "mfa@x.io", "password-1", SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tokens != nil || res.MFAToken == "" {
+		t.Fatalf("login with 2FA returned %+v", res)
+	}
+	return res.MFAToken
+}
+
+func TestMFALogin(t *testing.T) {
+	ctx := context.Background()
+	f, recovery := newMFAFixture(t)
+	if st, err := f.mfa.Status(ctx, f.user.ID); err != nil || !st.Enabled || st.RecoveryCodesLeft != len(recovery) || len(recovery) != recoveryCodeCount {
+		t.Fatalf("status = %+v, %v (codes %d)", st, err, len(recovery))
+	}
+	if _, err := f.mfa.Enroll(ctx, f.user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("enroll again: err = %v", err)
+	}
+
+	token := f.challenge(t)
+	// kode yang sudah dipakai untuk Confirm tidak bisa dipakai ulang
+	if _, err := f.mfa.Verify(ctx, token, f.code(t, -1), SessionMeta{}); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Errorf("replayed code: err = %v, want ErrMFAInvalidCode", err)
+	}
+	pair, err := f.mfa.Verify(ctx, token, f.code(t, 0), SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pair.UserID != f.user.ID.Hex() || pair.AccessToken == "" {
+		t.Errorf("pair = %+v", pair)
+	}
+	if _, err := f.mfa.Verify(ctx, token, f.code(t, 1), SessionMeta{}); !errors.Is(err, ErrMFAChallenge) {
+		t.Errorf("token reused: err = %v, want ErrMFAChallenge", err)
+	}
+}
+
+func TestMFARecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	f, recovery := newMFAFixture(t)
+	// huruf besar & tanpa tanda hubung tetap diterima
+	typed := strings.ToUpper(strings.ReplaceAll(recovery[0], "-", ""))
+	if _, err := f.mfa.Verify(ctx, f.challenge(t), typed, SessionMeta{}); err != nil {
+		t.Fatalf("recovery code: %v", err)
+	}
+	if _, err := f.mfa.Verify(ctx, f.challenge(t), recovery[0], SessionMeta{}); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Errorf("used recovery code: err = %v", err)
+	}
+	if st, _ := f.mfa.Status(ctx, f.user.ID); st.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("codes left = %d", st.RecoveryCodesLeft)
+	}
+
+	fresh, err := f.mfa.RegenerateRecoveryCodes(ctx, f.user.ID, recovery[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.mfa.Verify(ctx, f.challenge(t), recovery[2], SessionMeta{}); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Errorf("code from before regenerate: err = %v", err)
+	}
+	if _, err := f.mfa.Verify(ctx, f.challenge(t), fresh[0], SessionMeta{}); err != nil {
+		t.Errorf("regenerated code: %v", err)
+	}
+}
+
+func TestMFAChallengeLimitsAttempts(t *testing.T) {
+	ctx := context.Background()
+	f, _ := newMFAFixture(t)
+	token := f.challenge(t)
+	for i := 0; i < mfaMaxAttempts; i++ {
+		if _, err := f.mfa.Verify(ctx, token, "000000", SessionMeta{}); !errors.Is(err, ErrMFAInvalidCode) {
+			t.Fatalf("attempt %d: err = %v", i, err)
+		}
+	}
+	if _, err := f.mfa.Verify(ctx, token, f.code(t, 0), SessionMeta{}); !errors.Is(err, ErrMFAChallenge) {
+		t.Errorf("right code after %d misses: err = %v, want ErrMFAChallenge", mfaMaxAttempts, err)
+	}
+	if _, err := f.mfa.Verify(ctx, "", f.code(t, 0), SessionMeta{}); !errors.Is(err, ErrMFAChallenge) {
+		t.Errorf("empty token: err = %v", err)
+	}
+}
+
+func TestMFADisable(t *testing.T) {
+	ctx := context.Background()
+	f, _ := newMFAFixture(t)
+	token := f.challenge(t)
+	if err := f.mfa.Disable(ctx, f.user.ID, "wrong-password", f.code(t, 0)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong password: err = %v", err)
+	}
+	if err := f.mfa.Disable(ctx, f.user.ID, "password-1", "000000"); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Errorf("wrong code: err = %v", err)
+	}
+	if err := f.mfa.Disable(ctx, f.user.ID, "password-1", f.code(t, 0)); err != nil {
+		t.Fatal(err)
+	}
+	// challenge yang dibuat sebelum 2FA dimatikan tidak berlaku lagi
+	if _, err := f.mfa.Verify(ctx, token, f.code(t, 1), SessionMeta{}); !errors.Is(err, ErrMFAChallenge) {
+		t.Errorf("old challenge: err = %v", err)
+	}
+	res, err := f.auth.Login(ctx, "mfa@x.io", "password-1", SessionMeta{})
+	if err != nil || res.Tokens == nil {
+		t.Errorf("login without 2FA = %+v, %v", res, err)
+	}
+}
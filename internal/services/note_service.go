@@ -3,29 +3,43 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"time"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type NoteService interface {
 	Create(ctx context.Context, authorID primitive.ObjectID, content string, boardID *primitive.ObjectID, taskID *primitive.ObjectID, onAt *time.Time, pinned bool) (*models.Note, error)
+	Get(ctx context.Context, id primitive.ObjectID) (*models.Note, error)
 	ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error)
 	ListByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Note, error)
-	Update(ctx context.Context, id primitive.ObjectID, patch bson.M) error
-	Delete(ctx context.Context, id primitive.ObjectID) error
+	ListTimeline(ctx context.Context, scope repository.TimelineScope, from, to time.Time) ([]models.Note, error)
+	// Update: expect (boleh nil) = version yang dilihat klien; ErrVersionConflict bila berbeda
+	Update(ctx context.Context, id, actor primitive.ObjectID, patch bson.M, expect *int64) error
+	// Delete memindahkan note ke trash
+	Delete(ctx context.Context, id, actor primitive.ObjectID) error
+	// Restore: ErrTaskInTrash / ErrBoardInTrash bila tempat note menempel masih di trash
+	Restore(ctx context.Context, id, actor primitive.ObjectID) (*models.Note, error)
 }
 
-type noteService struct{}
+type noteService struct {
+	notes    repository.NoteRepo
+	tasks    repository.TaskRepo
+	boards   repository.BoardRepo
+	activity ActivityService
+}
 
-func NewNoteService() NoteService { return &noteService{} }
+func NewNoteService(notes repository.NoteRepo, tasks repository.TaskRepo, boards repository.BoardRepo, activity ActivityService) NoteService {
+	return &noteService{notes: notes, tasks: tasks, boards: boards, activity: activity}
+}
 
 func (s *noteService) taskBoardID(ctx context.Context, taskID primitive.ObjectID) (*primitive.ObjectID, error) {
-	var t models.Task
-	if err := config.MongoDB.Collection("tasks").FindOne(ctx, bson.M{"_id": taskID}).Decode(&t); err != nil {
+	t, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
 		return nil, err
 	}
 	bid := t.BoardID
@@ -42,6 +56,9 @@ func (s *noteService) Create(ctx context.Context, authorID primitive.ObjectID, c
 			boardID = b
 		}
 	}
+	if err := s.checkWritable(ctx, boardID); err != nil {
+		return nil, err
+	}
 	now := time.Now().UTC()
 	n := &models.Note{
 		ID:           primitive.NewObjectID(),
@@ -51,50 +68,125 @@ func (s *noteService) Create(ctx context.Context, authorID primitive.ObjectID, c
 		Content:      content,
 		Pinned:       pinned,
 		OnTimelineAt: onAt,
+		Version:      1,
 		TimeMeta:     models.TimeMeta{CreatedAt: now, UpdatedAt: now},
 	}
-	if _, err := config.MongoDB.Collection("notes").InsertOne(ctx, n); err != nil {
+	if err := s.notes.Insert(ctx, n); err != nil {
 		return nil, err
 	}
+	s.record(ctx, models.ActivityCreated, authorID, nil, n)
 	return n, nil
 }
 
-func (s *noteService) ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error) {
-	cur, err := config.MongoDB.Collection("notes").Find(ctx, bson.M{"boardId": boardID})
-	if err != nil {
-		return nil, err
+// record mencatat activity note; before nil = baru dibuat, after nil = dihapus
+func (s *noteService) record(ctx context.Context, action models.ActivityAction, actor primitive.ObjectID, before, after *models.Note) {
+	n := after
+	if n == nil {
+		n = before
 	}
-	defer cur.Close(ctx)
-	var out []models.Note
-	if err := cur.All(ctx, &out); err != nil {
-		return nil, err
+	s.activity.Record(ctx, &models.Activity{
+		BoardID:  n.BoardID,
+		TaskID:   n.TaskID,
+		Entity:   models.ActivityNote,
+		EntityID: n.ID,
+		Action:   action,
+		ActorID:  actor,
+	}, before, after)
+}
+
+// checkWritable: note pribadi (boardID nil) selalu boleh
+func (s *noteService) checkWritable(ctx context.Context, boardID *primitive.ObjectID) error {
+	if boardID == nil {
+		return nil
 	}
-	return out, nil
+	_, err := writableBoard(ctx, s.boards, *boardID)
+	return err
+}
+
+func (s *noteService) Get(ctx context.Context, id primitive.ObjectID) (*models.Note, error) {
+	return s.notes.FindByID(ctx, id)
+}
+
+func (s *noteService) ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Note, error) {
+	return s.notes.ListByBoard(ctx, boardID)
 }
 
 func (s *noteService) ListByTask(ctx context.Context, taskID primitive.ObjectID) ([]models.Note, error) {
-	cur, err := config.MongoDB.Collection("notes").Find(ctx, bson.M{"taskId": taskID})
-	if err != nil {
-		return nil, err
-	}
-	defer cur.Close(ctx)
-	var out []models.Note
-	if err := cur.All(ctx, &out); err != nil {
-		return nil, err
-	}
-	return out, nil
+	return s.notes.ListByTask(ctx, taskID)
+}
+
+func (s *noteService) ListTimeline(ctx context.Context, scope repository.TimelineScope, from, to time.Time) ([]models.Note, error) {
+	return s.notes.ListInRange(ctx, scope, from, to)
 }
 
-func (s *noteService) Update(ctx context.Context, id primitive.ObjectID, patch bson.M) error {
+func (s *noteService) Update(ctx context.Context, id, actor primitive.ObjectID, patch bson.M, expect *int64) error {
 	if len(patch) == 0 {
 		return nil
 	}
+	before, err := s.notes.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkWritable(ctx, before.BoardID); err != nil {
+		return err
+	}
 	patch["updatedAt"] = time.Now().UTC()
-	_, err := config.MongoDB.Collection("notes").UpdateByID(ctx, id, bson.M{"$set": patch})
-	return err
+	if expect == nil {
+		err = s.notes.Update(ctx, id, patch)
+	} else {
+		err = versionErr(s.notes.UpdateIfVersion(ctx, id, *expect, patch))
+	}
+	if err != nil {
+		return err
+	}
+	after, err := s.notes.FindByID(ctx, id)
+	if err != nil {
+		log.Printf("[activity] reload note=%s: %v", id.Hex(), err)
+		return nil
+	}
+	s.record(ctx, models.ActivityUpdated, actor, before, after)
+	return nil
 }
 
-func (s *noteService) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := config.MongoDB.Collection("notes").DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (s *noteService) Delete(ctx context.Context, id, actor primitive.ObjectID) error {
+	n, err := s.notes.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkWritable(ctx, n.BoardID); err != nil {
+		return err
+	}
+	if err := s.notes.SoftDelete(ctx, id, actor, time.Now().UTC()); err != nil {
+		return err
+	}
+	s.record(ctx, models.ActivityDeleted, actor, n, nil)
+	return nil
+}
+
+func (s *noteService) Restore(ctx context.Context, id, actor primitive.ObjectID) (*models.Note, error) {
+	before, err := s.notes.FindDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable(ctx, before.BoardID); errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrBoardInTrash
+	} else if err != nil {
+		return nil, err
+	}
+	if before.TaskID != nil {
+		if _, err := s.tasks.FindByID(ctx, *before.TaskID); errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrTaskInTrash
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.notes.Restore(ctx, id, bson.M{"updatedAt": time.Now().UTC()}); err != nil {
+		return nil, err
+	}
+	after, err := s.notes.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.ActivityRestored, actor, before, after)
+	return after, nil
 }
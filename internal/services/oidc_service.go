@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/oidc"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCService: login SSO (authorization code + PKCE) yang berakhir dengan
+// session & token biasa milik API ini.
+type OIDCService interface {
+	Enabled() bool
+	// Begin menyimpan state lalu mengembalikan URL login provider
+	Begin(ctx context.Context, redirect string) (string, error)
+	// Complete memproses callback; redirect = URL frontend dari Begin (boleh kosong).
+	// User dengan 2FA tetap harus lewat /auth/mfa/verify.
+	Complete(ctx context.Context, code, state string, meta SessionMeta) (res *LoginResult, redirect string, err error)
+	// Abort membuang state saat provider mengembalikan error; mengembalikan redirect-nya
+	Abort(ctx context.Context, state string) string
+}
+
+// OIDCOptions: isi dari config OIDC_*
+type OIDCOptions struct {
+	Provider         oidc.Config
+	AutoCreate       bool
+	AllowedRedirects []string
+}
+
+var (
+	ErrOIDCDisabled         = errors.New("sso login is not configured")
+	ErrOIDCRedirect         = errors.New("redirect is not allowed")
+	ErrOIDCState            = errors.New("invalid or expired login state")
+	ErrOIDCEmailMissing     = errors.New("identity provider did not return a verified email")
+	ErrOIDCNoAccount        = errors.New("no account for this email")
+	ErrAccountDisabled      = errors.New("account is disabled")
+	ErrOIDCProviderUnusable = errors.New("identity provider unavailable")
+	// ErrOIDCUnverified: akun password dengan email ini belum terverifikasi;
+	// bisa saja dibuat orang lain, jadi tidak ditautkan ke login SSO
+	ErrOIDCUnverified = errors.New("an account with this email exists but its email is not verified; verify it or reset the password first")
+)
+
+// oidcStateTTL: waktu maksimal user di halaman login provider
+const oidcStateTTL = 10 * time.Minute
+
+type oidcService struct {
+	opts     OIDCOptions
+	states   repository.LoginStateRepo
+	users    UserService
+	sessions SessionService
+	mfa      MFAService
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(opts OIDCOptions, states repository.LoginStateRepo, users UserService, sessions SessionService, mfa MFAService) OIDCService {
+	return &oidcService{opts: opts, states: states, users: users, sessions: sessions, mfa: mfa}
+}
+
+func (s *oidcService) Enabled() bool {
+	return s.opts.Provider.Issuer != "" && s.opts.Provider.ClientID != "" && s.opts.Provider.RedirectURL != ""
+}
+
+// discover sekali saat pertama dipakai (startup tidak gagal bila IdP mati);
+// bila gagal, dicoba lagi di request berikutnya.
+func (s *oidcService) discover(ctx context.Context) (*oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider != nil {
+		return s.provider, nil
+	}
+	p, err := oidc.Discover(ctx, s.opts.Provider)
+	if err != nil {
+		return nil, errors.Join(ErrOIDCProviderUnusable, err)
+	}
+	s.provider = p
+	return p, nil
+}
+
+func (s *oidcService) Begin(ctx context.Context, redirect string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrOIDCDisabled
+	}
+	if redirect != "" && !s.redirectAllowed(redirect) {
+		return "", ErrOIDCRedirect
+	}
+	p, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	state, _, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, _, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, _, err := utils.NewToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	if err := s.states.Insert(ctx, &models.LoginState{
+		ID:        primitive.NewObjectID(),
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  verifier,
+		Redirect:  redirect,
+		ExpiresAt: now.Add(oidcStateTTL),
+		CreatedAt: now,
+	}); err != nil {
+		return "", err
+	}
+	return p.AuthURL(state, nonce, verifier), nil
+}
+
+func (s *oidcService) Complete(ctx context.Context, code, state string, meta SessionMeta) (*LoginResult, string, error) {
+	if !s.Enabled() {
+		return nil, "", ErrOIDCDisabled
+	}
+	st, err := s.takeState(ctx, state)
+	if err != nil {
+		return nil, "", err
+	}
+	p, err := s.discover(ctx)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+	raw, err := p.Exchange(ctx, code, st.Verifier)
+	if err != nil {
+		return nil, st.Redirect, errors.Join(ErrOIDCProviderUnusable, err)
+	}
+	claims, err := p.Verify(ctx, raw, st.Nonce)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+	if claims.Email == "" || !claims.Verified() {
+		return nil, st.Redirect, ErrOIDCEmailMissing
+	}
+	u, err := s.userFor(ctx, claims)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+	if u.MFAEnabled() {
+		res, err := s.mfa.Challenge(ctx, u.ID)
+		return res, st.Redirect, err
+	}
+	pair, err := s.sessions.Start(ctx, u.ID, meta)
+	if err != nil {
+		return nil, st.Redirect, err
+	}
+	return &LoginResult{Tokens: pair}, st.Redirect, nil
+}
+
+func (s *oidcService) Abort(ctx context.Context, state string) string {
+	st, err := s.takeState(ctx, state)
+	if err != nil {
+		return ""
+	}
+	return st.Redirect
+}
+
+func (s *oidcService) takeState(ctx context.Context, state string) (*models.LoginState, error) {
+	if state == "" {
+		return nil, ErrOIDCState
+	}
+	st, err := s.states.Take(ctx, state)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrOIDCState
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(st.ExpiresAt) {
+		return nil, ErrOIDCState
+	}
+	return st, nil
+}
+
+// userFor: user dengan email dari ID token; dibuat just-in-time bila diizinkan
+func (s *oidcService) userFor(ctx context.Context, claims *oidc.Claims) (*models.User, error) {
+	email := normalizeEmail(claims.Email)
+	u, err := s.users.FindByEmail(ctx, email)
+	if err == nil {
+		if !u.IsActive {
+			return nil, ErrAccountDisabled
+		}
+		// akun belum terverifikasi bisa milik penyerang yang tahu password-nya
+		if u.EmailVerifiedAt == nil {
+			return nil, ErrOIDCUnverified
+		}
+		return u, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if !s.opts.AutoCreate {
+		return nil, ErrOIDCNoAccount
+	}
+	name := claims.Name
+	if name == "" {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+	// password acak yang tidak pernah diberikan: akun SSO tidak bisa login pakai password
+	password, _, err := utils.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	u, err = s.users.Create(ctx, name, email, password)
+	if errors.Is(err, ErrEmailTaken) {
+		// login bersamaan untuk email yang sama
+		return s.users.FindByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, s.users.MarkEmailVerified(ctx, u.ID)
+}
+
+func (s *oidcService) redirectAllowed(redirect string) bool {
+	for _, allowed := range s.opts.AllowedRedirects {
+		if redirect == allowed {
+			return true
+		}
+	}
+	return false
+}
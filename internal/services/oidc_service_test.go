@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/oidc"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const fakeClientID = "be-ambis-test"
+
+// fakeIdP: identity provider tiruan (seperti cmd/oidc-stub) di httptest.
+// Tidak ada /authorize: test memanggil authorize langsung dengan URL dari Begin.
+type fakeIdP struct {
+	srv  *httptest.Server
+	priv ed25519.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]fakeGrant
+}
+
+type fakeGrant struct {
+	challenge, nonce, email string
+	verified                bool
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeIdP{priv: priv, grants: map[string]fakeGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		iss := p.srv.URL
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                iss,
+			"authorization_endpoint":                iss + "/authorize",
+			"token_endpoint":                        iss + "/token",
+			"jwks_uri":                              iss + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"EdDSA"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "OKP", "crv": "Ed25519", "use": "sig", "alg": "EdDSA", "kid": "test-1",
+			"x": base64.RawURLEncoding.EncodeToString(pub),
+		}}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+// authorize meniru persetujuan user di halaman provider; mengembalikan code & state
+func (p *fakeIdP) authorize(t *testing.T, authURL, email string, verified bool) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != fakeClientID || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected auth url %s", authURL)
+	}
+	code, _, err = utils.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	p.grants[code] = fakeGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), email: email, verified: verified}
+	p.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func (p *fakeIdP) token(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	now := time.Now()
+	tok := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss": p.srv.URL, "aud": fakeClientID, "sub": "fake|" + g.email,
+		"iat": now.Unix(), "exp": now.Add(5 * time.Minute).Unix(),
+		"nonce": g.nonce, "email": g.email, "email_verified": g.verified,
+	})
+	tok.Header["kid"] = "test-1"
+	raw, err := tok.SignedString(p.priv)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"token_type": "Bearer", "id_token": raw})
+}
+
+type oidcFixture struct {
+	idp   *fakeIdP
+	repos *repository.Repos
+	users UserService
+	svc   OIDCService
+}
+
+func newOIDCFixture(t *testing.T, autoCreate bool) *oidcFixture {
+	t.Helper()
+	loadTestJWTKeys(t)
+	idp := newFakeIdP(t)
+	repos := repository.NewMemory()
+	users := NewUserService(repos.Users, nil)
+	sessions := NewSessionService(repos.Sessions, time.Minute, time.Hour)
+	guard := NewLoginGuard(repos.Throttles, repos.Users, NewAuditService(repos.Audit), LoginGuardOptions{})
+	mfa := NewMFAService(repos.Users, repos.MFA, sessions, guard, "test")
+	svc := NewOIDCService(OIDCOptions{
+		Provider: oidc.Config{
+			Issuer:      idp.srv.URL,
+			ClientID:    fakeClientID,
+			RedirectURL: "http://api.test/api/auth/oidc/callback",
+		},
+		AutoCreate:       autoCreate,
+		AllowedRedirects: []string{"http://app.test/sso"},
+	}, repos.LoginStates, users, sessions, mfa)
+	return &oidcFixture{idp: idp, repos: repos, users: users, svc: svc}
+}
+
+// login: Begin → persetujuan di provider → Complete
+func (f *oidcFixture) login(t *testing.T, email string, verified bool) (*LoginResult, string, error) {
+	t.Helper()
+	ctx := context.Background()
+	authURL, err := f.svc.Begin(ctx, "http://app.test/sso")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := f.idp.authorize(t, authURL, email, verified)
+	return f.svc.Complete(ctx, code, state, SessionMeta{IP: "127.0.0.1"})
+}
+
+func TestOIDCLogin(t *testing.T) {
+	type account struct {
+		email    string
+		verified bool
+		disabled bool
+	}
+	cases := []struct {
+		name       string
+		autoCreate bool
+		existing   *account
+		email      string // email dari ID token
+		verified   bool   // email_verified dari ID token
+		want       error
+		wantEmail  string // email user yang login; kosong bila gagal
+	}{
+		{
+			name: "creates a verified account", autoCreate: true,
+			email: "New.User@Example.com", verified: true,
+			wantEmail: "new.user@example.com",
+		},
+		{
+			name:  "no account without auto create",
+			email: "nobody@example.com", verified: true,
+			want: ErrOIDCNoAccount,
+		},
+		{
+			name:     "matches a verified account case-insensitively",
+			existing: &account{email: "bob@example.com", verified: true},
+			email:    "Bob@EXAMPLE.com", verified: true,
+			wantEmail: "bob@example.com",
+		},
+		{
+			name: "refuses to link an unverified account", autoCreate: true,
+			existing: &account{email: "carol@example.com"},
+			email:    "carol@example.com", verified: true,
+			want: ErrOIDCUnverified,
+		},
+		{
+			name:     "refuses a disabled account",
+			existing: &account{email: "dave@example.com", verified: true, disabled: true},
+			email:    "dave@example.com", verified: true,
+			want: ErrAccountDisabled,
+		},
+		{
+			name: "rejects an unverified provider email", autoCreate: true,
+			email: "eve@example.com", verified: false,
+			want: ErrOIDCEmailMissing,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newOIDCFixture(t, tc.autoCreate)
+			if a := tc.existing; a != nil {
+				u, err := f.users.Create(ctx, "existing", a.email, "correct-horse-battery")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if a.verified {
+					if err := f.users.MarkEmailVerified(ctx, u.ID); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if a.disabled {
+					if err := f.repos.Users.Update(ctx, u.ID, bson.M{"isActive": false}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			res, redirect, err := f.login(t, tc.email, tc.verified)
+			if redirect != "http://app.test/sso" {
+				t.Errorf("redirect = %q", redirect)
+			}
+			if tc.want != nil {
+				if !errors.Is(err, tc.want) {
+					t.Fatalf("err = %v, want %v", err, tc.want)
+				}
+				if tc.existing != nil {
+					u, err := f.users.FindByEmail(ctx, tc.existing.email)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !tc.existing.verified && u.EmailVerifiedAt != nil {
+						t.Error("unverified account was marked verified by a failed sso login")
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Tokens == nil || res.Tokens.AccessToken == "" {
+				t.Fatalf("no tokens issued: %+v", res)
+			}
+			uid, err := primitive.ObjectIDFromHex(res.Tokens.UserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := f.repos.Users.FindByID(ctx, uid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Email != tc.wantEmail {
+				t.Errorf("logged in as %q, want %q", u.Email, tc.wantEmail)
+			}
+			if u.EmailVerifiedAt == nil {
+				t.Error("sso user is not verified")
+			}
+			if n, _ := f.repos.Users.Count(ctx, repository.UserFilter{}); n != 1 {
+				t.Errorf("%d users, want 1", n)
+			}
+		})
+	}
+}
+
+func TestOIDCState(t *testing.T) {
+	ctx := context.Background()
+	f := newOIDCFixture(t, true)
+
+	if _, err := f.svc.Begin(ctx, "http://evil.test/"); !errors.Is(err, ErrOIDCRedirect) {
+		t.Fatalf("Begin with foreign redirect: err = %v", err)
+	}
+
+	authURL, err := f.svc.Begin(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := f.idp.authorize(t, authURL, "frank@example.com", true)
+	cases := []struct {
+		name  string
+		state string
+		want  error
+	}{
+		{"missing state", "", ErrOIDCState},
+		{"unknown state", "not-a-state", ErrOIDCState},
+		{"first use", state, nil},
+		{"replayed state", state, ErrOIDCState},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := f.svc.Complete(ctx, code, tc.state, SessionMeta{})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PresenceTTL: koneksi dianggap offline bila tidak ada heartbeat selama ini
+const PresenceTTL = 45 * time.Second
+
+var ErrPresenceUnknown = errors.New("not present on this board")
+
+// PresenceState: yang sedang dilakukan user di board
+type PresenceState struct {
+	TaskID   *primitive.ObjectID
+	Dragging bool
+}
+
+type PresenceService interface {
+	// Set menyimpan state koneksi dan memperpanjang masa berlakunya. changed
+	// = false bila koneksi sudah online dengan state yang sama (heartbeat).
+	Set(ctx context.Context, boardID, userID primitive.ObjectID, connectionID string, st PresenceState) (p *models.Presence, changed bool, err error)
+	// Heartbeat hanya memperpanjang; ErrPresenceUnknown bila koneksi belum/tidak lagi online
+	Heartbeat(ctx context.Context, boardID primitive.ObjectID, connectionID string) error
+	// Leave: entri yang dihapus, nil bila koneksi memang tidak online
+	Leave(ctx context.Context, boardID primitive.ObjectID, connectionID string) (*models.Presence, error)
+	List(ctx context.Context, boardID primitive.ObjectID) ([]models.Presence, error)
+	// Expire menghapus koneksi yang heartbeat-nya berhenti
+	Expire(ctx context.Context) ([]models.Presence, error)
+	DeleteBoard(ctx context.Context, boardID primitive.ObjectID) error
+}
+
+type presenceService struct{ repo repository.PresenceRepo }
+
+func NewPresenceService(repo repository.PresenceRepo) PresenceService {
+	return &presenceService{repo: repo}
+}
+
+func (s *presenceService) Set(ctx context.Context, boardID, userID primitive.ObjectID, connectionID string, st PresenceState) (*models.Presence, bool, error) {
+	now := time.Now().UTC()
+	p := &models.Presence{
+		BoardID:      boardID,
+		UserID:       userID,
+		ConnectionID: connectionID,
+		TaskID:       st.TaskID,
+		Dragging:     st.Dragging,
+		LastSeen:     now,
+		ExpiresAt:    now.Add(PresenceTTL),
+	}
+	prev, err := s.repo.Upsert(ctx, p, now)
+	if err != nil {
+		return nil, false, err
+	}
+	changed := prev == nil || prev.Dragging != p.Dragging || !sameTask(prev.TaskID, p.TaskID)
+	return p, changed, nil
+}
+
+func sameTask(a, b *primitive.ObjectID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *presenceService) Heartbeat(ctx context.Context, boardID primitive.ObjectID, connectionID string) error {
+	now := time.Now().UTC()
+	err := s.repo.Touch(ctx, boardID, connectionID, now, now.Add(PresenceTTL))
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPresenceUnknown
+	}
+	return err
+}
+
+func (s *presenceService) Leave(ctx context.Context, boardID primitive.ObjectID, connectionID string) (*models.Presence, error) {
+	p, err := s.repo.Remove(ctx, boardID, connectionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (s *presenceService) List(ctx context.Context, boardID primitive.ObjectID) ([]models.Presence, error) {
+	out, err := s.repo.ListByBoard(ctx, boardID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []models.Presence{}
+	}
+	return out, nil
+}
+
+func (s *presenceService) Expire(ctx context.Context) ([]models.Presence, error) {
+	return s.repo.TakeExpired(ctx, time.Now().UTC())
+}
+
+func (s *presenceService) DeleteBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	return s.repo.DeleteByBoard(ctx, boardID)
+}
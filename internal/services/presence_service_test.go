@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPresenceSetReportsChanges(t *testing.T) {
+	ctx := context.Background()
+	svc := NewPresenceService(repository.NewMemory().Presence)
+	board, user := primitive.NewObjectID(), primitive.NewObjectID()
+	task, other := primitive.NewObjectID(), primitive.NewObjectID()
+
+	steps := []struct {
+		name    string
+		state   PresenceState
+		changed bool
+	}{
+		{"join", PresenceState{}, true},
+		{"same state (heartbeat)", PresenceState{}, false},
+		{"open a task", PresenceState{TaskID: &task}, true},
+		{"same task again", PresenceState{TaskID: &task}, false},
+		{"start dragging", PresenceState{TaskID: &task, Dragging: true}, true},
+		{"switch task", PresenceState{TaskID: &other, Dragging: true}, true},
+		{"close the task", PresenceState{}, true},
+	}
+	for _, s := range steps {
+		p, changed, err := svc.Set(ctx, board, user, "conn-1", s.state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != s.changed {
+			t.Errorf("%s: changed = %v, want %v", s.name, changed, s.changed)
+		}
+		if p.Dragging != s.state.Dragging || !sameTask(p.TaskID, s.state.TaskID) {
+			t.Errorf("%s: presence = %+v", s.name, p)
+		}
+	}
+
+	// tab kedua user yang sama adalah entri tersendiri
+	if _, changed, err := svc.Set(ctx, board, user, "conn-2", PresenceState{TaskID: &task}); err != nil || !changed {
+		t.Fatalf("second connection: changed %v, %v", changed, err)
+	}
+	list, err := svc.List(ctx, board)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("list = %+v, %v", list, err)
+	}
+	if list[1].ConnectionID != "conn-2" || list[1].TaskID == nil || *list[1].TaskID != task {
+		t.Errorf("second entry = %+v", list[1])
+	}
+	if empty, err := svc.List(ctx, primitive.NewObjectID()); err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("empty board = %#v, %v", empty, err)
+	}
+}
+
+func TestPresenceHeartbeatAndLeave(t *testing.T) {
+	ctx := context.Background()
+	svc := NewPresenceService(repository.NewMemory().Presence)
+	board, user := primitive.NewObjectID(), primitive.NewObjectID()
+
+	if err := svc.Heartbeat(ctx, board, "conn-1"); !errors.Is(err, ErrPresenceUnknown) {
+		t.Errorf("heartbeat before join: err = %v", err)
+	}
+	if _, _, err := svc.Set(ctx, board, user, "conn-1", PresenceState{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Heartbeat(ctx, board, "conn-1"); err != nil {
+		t.Errorf("heartbeat: %v", err)
+	}
+	left, err := svc.Leave(ctx, board, "conn-1")
+	if err != nil || left == nil || left.UserID != user {
+		t.Fatalf("leave = %+v, %v", left, err)
+	}
+	// leave kedua (mis. leave_board lalu disconnect) bukan error
+	if left, err := svc.Leave(ctx, board, "conn-1"); err != nil || left != nil {
+		t.Errorf("leave twice = %+v, %v", left, err)
+	}
+	if err := svc.Heartbeat(ctx, board, "conn-1"); !errors.Is(err, ErrPresenceUnknown) {
+		t.Errorf("heartbeat after leave: err = %v", err)
+	}
+}
+
+func TestPresenceExpire(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	svc := NewPresenceService(repos.Presence)
+	board := primitive.NewObjectID()
+	if _, _, err := svc.Set(ctx, board, primitive.NewObjectID(), "alive", PresenceState{}); err != nil {
+		t.Fatal(err)
+	}
+	// koneksi yang heartbeat-nya berhenti sejak lebih dari PresenceTTL
+	stale := time.Now().UTC().Add(-PresenceTTL - time.Second)
+	gone := &models.Presence{BoardID: board, UserID: primitive.NewObjectID(), ConnectionID: "gone", LastSeen: stale, ExpiresAt: stale.Add(PresenceTTL)}
+	if _, err := repos.Presence.Upsert(ctx, gone, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if list, _ := svc.List(ctx, board); len(list) != 1 || list[0].ConnectionID != "alive" {
+		t.Errorf("list shows expired entries: %+v", list)
+	}
+	expired, err := svc.Expire(ctx)
+	if err != nil || len(expired) != 1 || expired[0].ConnectionID != "gone" {
+		t.Fatalf("expire = %+v, %v", expired, err)
+	}
+	// tiap entri hanya diumumkan sekali
+	if again, _ := svc.Expire(ctx); len(again) != 0 {
+		t.Errorf("expired twice: %+v", again)
+	}
+	if err := svc.Heartbeat(ctx, board, "gone"); !errors.Is(err, ErrPresenceUnknown) {
+		t.Errorf("heartbeat after expiry: err = %v", err)
+	}
+
+	if err := svc.DeleteBoard(ctx, board); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := svc.List(ctx, board); len(list) != 0 {
+		t.Errorf("list after DeleteBoard = %+v", list)
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenPair: hasil login / refresh
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"` // detik, masa berlaku access token
+	UserID       string `json:"userId"`
+	SessionID    string `json:"sessionId"`
+}
+
+// SessionMeta: info perangkat untuk daftar session
+type SessionMeta struct {
+	UserAgent string
+	IP        string
+}
+
+type SessionService interface {
+	Start(ctx context.Context, userID primitive.ObjectID, meta SessionMeta) (*TokenPair, error)
+	// Refresh merotasi refresh token. Refresh token lama yang dipakai ulang
+	// dianggap bocor: session langsung dicabut.
+	Refresh(ctx context.Context, refreshToken string, meta SessionMeta) (*TokenPair, error)
+	ListActive(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error)
+	// Revoke mencabut session milik userID (ErrSessionNotFound bila bukan miliknya)
+	Revoke(ctx context.Context, userID, sessionID primitive.ObjectID) error
+	RevokeAll(ctx context.Context, userID primitive.ObjectID) error
+	// RevokeOthers mencabut semua session user kecuali keep (mis. setelah ganti password)
+	RevokeOthers(ctx context.Context, userID, keep primitive.ObjectID) error
+	// IsActive dipakai JWTProtected; hasilnya di-cache sebentar per proses
+	IsActive(ctx context.Context, sessionID primitive.ObjectID) (bool, error)
+	// OnRevoke: fn dipanggil dengan id session yang baru dicabut di instance
+	// ini, untuk diteruskan ke instance lain
+	OnRevoke(fn func(ids []primitive.ObjectID))
+	// Forget: session dicabut di instance lain; status cache-nya tidak dipakai lagi
+	Forget(ids []primitive.ObjectID)
+}
+
+var (
+	ErrInvalidRefresh  = errors.New("invalid or expired refresh token")
+	ErrRefreshReused   = errors.New("refresh token reused; session revoked")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// sessionCacheTTL: batas basi status session di instance lain bila kabar
+// pencabutan (OnRevoke/Forget) tidak sampai; di instance ini langsung berlaku
+const sessionCacheTTL = 30 * time.Second
+
+type sessionService struct {
+	sessions   repository.SessionRepo
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+
+	mu       sync.Mutex
+	cache    map[primitive.ObjectID]sessionCacheEntry
+	onRevoke func(ids []primitive.ObjectID)
+}
+
+type sessionCacheEntry struct {
+	active bool
+	until  time.Time
+}
+
+func NewSessionService(sessions repository.SessionRepo, accessTTL, refreshTTL time.Duration) SessionService {
+	return &sessionService{
+		sessions:   sessions,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		cache:      map[primitive.ObjectID]sessionCacheEntry{},
+	}
+}
+
+func (s *sessionService) Start(ctx context.Context, userID primitive.ObjectID, meta SessionMeta) (*TokenPair, error) {
+	refresh, hash, err := utils.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	sess := &models.Session{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		RefreshHash: hash,
+		UserAgent:   meta.UserAgent,
+		IP:          meta.IP,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(s.refreshTTL),
+		TimeMeta:    models.TimeMeta{CreatedAt: now, UpdatedAt: now},
+	}
+	if err := s.sessions.Insert(ctx, sess); err != nil {
+		return nil, err
+	}
+	return s.pair(sess, refresh)
+}
+
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string, meta SessionMeta) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefresh
+	}
+	hash := utils.HashToken(refreshToken)
+	sess, err := s.sessions.FindByRefreshHash(ctx, hash)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidRefresh
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if !sess.Active(now) {
+		return nil, ErrInvalidRefresh
+	}
+	if sess.RefreshHash != hash {
+		// token generasi sebelumnya dipakai lagi → cabut session
+		if err := s.revoke(ctx, sess.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshReused
+	}
+
+	refresh, newHash, err := utils.NewToken()
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{
+		"refreshHash":     newHash,
+		"prevRefreshHash": hash,
+		"lastUsedAt":      now,
+		"expiresAt":       now.Add(s.refreshTTL),
+		"updatedAt":       now,
+	}
+	if meta.UserAgent != "" {
+		set["userAgent"] = meta.UserAgent
+	}
+	if meta.IP != "" {
+		set["ip"] = meta.IP
+	}
+	// refresh bersamaan dengan token yang sama: hanya satu yang menang
+	if err := s.sessions.Rotate(ctx, sess.ID, hash, set); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrInvalidRefresh
+		}
+		return nil, err
+	}
+	return s.pair(sess, refresh)
+}
+
+func (s *sessionService) ListActive(ctx context.Context, userID primitive.ObjectID) ([]models.Session, error) {
+	return s.sessions.ListActiveByUser(ctx, userID, time.Now().UTC())
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID primitive.ObjectID) error {
+	sess, err := s.sessions.FindByID(ctx, sessionID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && sess.UserID != userID) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.revoke(ctx, sessionID, time.Now().UTC())
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID primitive.ObjectID) error {
+	list, err := s.sessions.ListActiveByUser(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := s.sessions.RevokeAllForUser(ctx, userID, time.Now().UTC()); err != nil {
+		return err
+	}
+	ids := make([]primitive.ObjectID, 0, len(list))
+	for _, sess := range list {
+		s.remember(sess.ID, false)
+		ids = append(ids, sess.ID)
+	}
+	s.notify(ids)
+	return nil
+}
+
+func (s *sessionService) RevokeOthers(ctx context.Context, userID, keep primitive.ObjectID) error {
+	now := time.Now().UTC()
+	list, err := s.sessions.ListActiveByUser(ctx, userID, now)
+	if err != nil {
+		return err
+	}
+	for _, sess := range list {
+		if sess.ID == keep {
+			continue
+		}
+		if err := s.revoke(ctx, sess.ID, now); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sessionService) IsActive(ctx context.Context, sessionID primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	e, ok := s.cache[sessionID]
+	s.mu.Unlock()
+	if ok && now.Before(e.until) {
+		return e.active, nil
+	}
+	sess, err := s.sessions.FindByID(ctx, sessionID)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.remember(sessionID, false)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	active := sess.Active(now)
+	s.remember(sessionID, active)
+	return active, nil
+}
+
+func (s *sessionService) revoke(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	if err := s.sessions.Revoke(ctx, id, at); err != nil {
+		return err
+	}
+	s.remember(id, false)
+	s.notify([]primitive.ObjectID{id})
+	return nil
+}
+
+func (s *sessionService) OnRevoke(fn func(ids []primitive.ObjectID)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRevoke = fn
+}
+
+func (s *sessionService) notify(ids []primitive.ObjectID) {
+	s.mu.Lock()
+	fn := s.onRevoke
+	s.mu.Unlock()
+	if fn != nil && len(ids) > 0 {
+		fn(ids)
+	}
+}
+
+func (s *sessionService) Forget(ids []primitive.ObjectID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.cache, id)
+	}
+}
+
+func (s *sessionService) remember(id primitive.ObjectID, active bool) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// buang entri basi supaya map tidak tumbuh terus
+	if len(s.cache) > 10000 {
+		for k, e := range s.cache {
+			if now.After(e.until) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	s.cache[id] = sessionCacheEntry{active: active, until: now.Add(sessionCacheTTL)}
+}
+
+func (s *sessionService) pair(sess *models.Session, refresh string) (*TokenPair, error) {
+	access, err := utils.GenerateJWT(sess.UserID.Hex(), sess.ID.Hex(), s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.accessTTL / time.Second),
+		UserID:       sess.UserID.Hex(),
+		SessionID:    sess.ID.Hex(),
+	}, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestSessions(t *testing.T) (*repository.Repos, SessionService) {
+	t.Helper()
+	loadTestJWTKeys(t)
+	repos := repository.NewMemory()
+	return repos, NewSessionService(repos.Sessions, time.Minute, time.Hour)
+}
+
+func TestSessionAccessTokenCarriesSession(t *testing.T) {
+	_, svc := newTestSessions(t)
+	user := primitive.NewObjectID()
+	pair, err := svc.Start(context.Background(), user, SessionMeta{UserAgent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := utils.ParseJWT(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != user.Hex() || claims.ID != pair.SessionID {
+		t.Errorf("claims sub=%s jti=%s, want %s / %s", claims.Subject, claims.ID, user.Hex(), pair.SessionID)
+	}
+	if left := time.Until(claims.ExpiresAt.Time); left > time.Minute || pair.ExpiresIn != 60 {
+		t.Errorf("access token lives %s (expiresIn %d), want the one minute access TTL", left, pair.ExpiresIn)
+	}
+}
+
+func TestRefreshRotates(t *testing.T) {
+	ctx := context.Background()
+	_, svc := newTestSessions(t)
+	first, err := svc.Start(ctx, primitive.NewObjectID(), SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.Refresh(ctx, first.RefreshToken, SessionMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.SessionID != first.SessionID {
+		t.Fatalf("refresh returned token %q for session %s", second.RefreshToken, second.SessionID)
+	}
+	third, err := svc.Refresh(ctx, second.RefreshToken, SessionMeta{})
+	if err != nil {
+		t.Fatalf("rotated token rejected: %v", err)
+	}
+
+	// token yang sudah dirotasi muncul lagi: dianggap dicuri, session dicabut
+	if _, err := svc.Refresh(ctx, second.RefreshToken, SessionMeta{}); !errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("reused token: err = %v, want ErrRefreshReused", err)
+	}
+	if _, err := svc.Refresh(ctx, third.RefreshToken, SessionMeta{}); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("latest token after reuse: err = %v, want ErrInvalidRefresh", err)
+	}
+	sid, _ := primitive.ObjectIDFromHex(first.SessionID)
+	if ok, err := svc.IsActive(ctx, sid); err != nil || ok {
+		t.Errorf("session active=%v err=%v after reuse", ok, err)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	ctx := context.Background()
+	repos, svc := newTestSessions(t)
+	for _, token := range []string{"", "not-a-token"} {
+		if _, err := svc.Refresh(ctx, token, SessionMeta{}); !errors.Is(err, ErrInvalidRefresh) {
+			t.Errorf("Refresh(%q): err = %v", token, err)
+		}
+	}
+
+	refresh, hash, err := utils.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := &models.Session{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), RefreshHash: hash, ExpiresAt: time.Now().Add(-time.Second)}
+	if err := repos.Sessions.Insert(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Refresh(ctx, refresh, SessionMeta{}); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("expired session: err = %v, want ErrInvalidRefresh", err)
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	ctx := context.Background()
+	_, svc := newTestSessions(t)
+	user, stranger := primitive.NewObjectID(), primitive.NewObjectID()
+	var ids []primitive.ObjectID
+	for i := 0; i < 3; i++ {
+		pair, err := svc.Start(ctx, user, SessionMeta{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := primitive.ObjectIDFromHex(pair.SessionID)
+		ids = append(ids, id)
+		// status aktif sudah di-cache sebelum dicabut
+		if ok, _ := svc.IsActive(ctx, id); !ok {
+			t.Fatalf("new session %d not active", i)
+		}
+	}
+
+	if err := svc.Revoke(ctx, stranger, ids[0]); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("revoke someone else's session: err = %v", err)
+	}
+	if err := svc.Revoke(ctx, user, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RevokeOthers(ctx, user, ids[2]); err != nil {
+		t.Fatal(err)
+	}
+	want := []bool{false, false, true}
+	for i, id := range ids {
+		if ok, _ := svc.IsActive(ctx, id); ok != want[i] {
+			t.Errorf("session %d active = %v, want %v", i, ok, want[i])
+		}
+	}
+	list, err := svc.ListActive(ctx, user)
+	if err != nil || len(list) != 1 || list[0].ID != ids[2] {
+		t.Errorf("ListActive = %d sessions, %v", len(list), err)
+	}
+
+	if err := svc.RevokeAll(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := svc.IsActive(ctx, ids[2]); ok {
+		t.Error("session still active after RevokeAll")
+	}
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/ot"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// TaskDocLogSize: jumlah op terakhir per dokumen; klien yang tertinggal
+	// lebih jauh harus join ulang
+	TaskDocLogSize    = 200
+	taskDocPruneEvery = 50
+	// TaskDocMaxLen: panjang maksimum deskripsi (unit UTF-16)
+	TaskDocMaxLen = 100000
+	// TaskDocIdleTTL: dokumen yang sudah tersimpan dan tidak diedit selama
+	// ini dibuang; dibuka lagi → dibuat ulang dari Task.Description
+	TaskDocIdleTTL   = time.Hour
+	taskDocSaveBatch = 100
+	// berapa kali Apply diulang saat kalah balapan dengan op lain
+	maxTaskDocRetries = 5
+)
+
+var (
+	ErrDocSession  = errors.New("editing session changed, rejoin the task")
+	ErrDocRevision = errors.New("revision is unknown or too old, rejoin the task")
+	ErrDocTooLarge = errors.New("description is too long")
+	ErrDocBusy     = errors.New("description changed concurrently, please retry")
+)
+
+// DocEdit: op yang sudah diterapkan (sudah di-transform), untuk disiarkan
+type DocEdit struct {
+	TaskID  primitive.ObjectID
+	BoardID primitive.ObjectID
+	Rev     int64 // rev dokumen setelah op
+	Op      ot.Op
+	UserID  primitive.ObjectID
+}
+
+// DocSaved: dokumen yang baru disalin ke Task.Description
+type DocSaved struct {
+	TaskID  primitive.ObjectID
+	BoardID primitive.ObjectID
+	UserID  primitive.ObjectID // editor terakhir
+}
+
+// TaskDocService: edit deskripsi task bersama dengan operational transform.
+// Server menjadi acuan urutan: op klien dibuat di atas rev tertentu,
+// di-transform terhadap op yang masuk sesudahnya, lalu diberi rev berikutnya.
+type TaskDocService interface {
+	// Open: dokumen task; dibuat dari Task.Description bila belum ada
+	Open(ctx context.Context, taskID primitive.ObjectID) (*models.TaskDoc, error)
+	// Apply menerapkan op yang dibuat klien di atas rev dalam session
+	Apply(ctx context.Context, taskID, session, userID primitive.ObjectID, rev int64, op ot.Op) (*DocEdit, error)
+	// Replace: deskripsi diganti utuh (PATCH task) saat ada sesi edit;
+	// nil bila tidak ada sesi atau teks sama
+	Replace(ctx context.Context, taskID, userID primitive.ObjectID, text string) (*DocEdit, error)
+	// Save menyalin dokumen yang berubah ke task dan membuang dokumen idle
+	Save(ctx context.Context) ([]DocSaved, error)
+}
+
+type taskDocService struct {
+	docs     repository.TaskDocRepo
+	tasks    repository.TaskRepo
+	tx       repository.Transactor
+	activity ActivityService
+}
+
+func NewTaskDocService(docs repository.TaskDocRepo, tasks repository.TaskRepo, tx repository.Transactor, activity ActivityService) TaskDocService {
+	return &taskDocService{docs: docs, tasks: tasks, tx: tx, activity: activity}
+}
+
+func (s *taskDocService) Open(ctx context.Context, taskID primitive.ObjectID) (*models.TaskDoc, error) {
+	d, err := s.docs.Get(ctx, taskID)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return d, err
+	}
+	t, err := s.tasks.FindByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	d = &models.TaskDoc{
+		ID:        taskID,
+		BoardID:   t.BoardID,
+		Session:   primitive.NewObjectID(),
+		UpdatedBy: t.UpdatedBy,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if t.Description != nil {
+		d.Text = *t.Description
+	}
+	err = s.docs.Create(ctx, d)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return s.docs.Get(ctx, taskID) // dibuat pemanggil lain lebih dulu
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *taskDocService) Apply(ctx context.Context, taskID, session, userID primitive.ObjectID, rev int64, op ot.Op) (*DocEdit, error) {
+	for i := 0; i < maxTaskDocRetries; i++ {
+		var edit *DocEdit
+		err := s.withTx(ctx, func(ctx context.Context) error {
+			var err error
+			edit, _, err = s.apply(ctx, taskID, session, userID, rev, op)
+			return err
+		})
+		if errors.Is(err, repository.ErrConflict) {
+			continue
+		}
+		return edit, err
+	}
+	return nil, ErrDocBusy
+}
+
+// withTx: transaksi bila backend mendukung; tanpa transaksi, Advance yang
+// kondisional tetap menjaga urutan rev
+func (s *taskDocService) withTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := s.tx.WithTx(ctx, fn)
+	if errors.Is(err, repository.ErrTxUnsupported) {
+		return fn(ctx)
+	}
+	return err
+}
+
+// apply mengembalikan juga teks hasil; ErrConflict = ulangi
+func (s *taskDocService) apply(ctx context.Context, taskID, session, userID primitive.ObjectID, rev int64, op ot.Op) (*DocEdit, string, error) {
+	d, err := s.docs.Get(ctx, taskID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "", ErrDocSession // dokumen sudah dibuang (idle)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if d.Session != session {
+		return nil, "", ErrDocSession
+	}
+	if rev < 0 || rev > d.Rev {
+		return nil, "", ErrDocRevision
+	}
+	if rev < d.Rev {
+		past, err := s.docs.ListOps(ctx, taskID, rev)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(past) == 0 || past[0].Rev != rev+1 {
+			return nil, "", ErrDocRevision // log sudah dipangkas
+		}
+		if int64(len(past)) != d.Rev-rev {
+			// op terakhir belum tercatat (tulis bersamaan tanpa transaksi)
+			return nil, "", repository.ErrConflict
+		}
+		for _, p := range past {
+			var prev ot.Op
+			if err := json.Unmarshal([]byte(p.Op), &prev); err != nil {
+				return nil, "", err
+			}
+			if op, _, err = ot.Transform(op, prev); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if op.TargetLen() > TaskDocMaxLen {
+		return nil, "", ErrDocTooLarge
+	}
+	text, err := op.Apply(d.Text)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return nil, "", err
+	}
+	rec := &models.TaskDocOp{
+		ID:        primitive.NewObjectID(),
+		TaskID:    taskID,
+		Rev:       d.Rev + 1,
+		Op:        string(data),
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.docs.Advance(ctx, taskID, d.Rev, text, rec); err != nil {
+		return nil, "", err
+	}
+	if rec.Rev%taskDocPruneEvery == 0 && rec.Rev > TaskDocLogSize {
+		if err := s.docs.PruneOps(ctx, taskID, rec.Rev-TaskDocLogSize); err != nil {
+			log.Printf("[taskdoc] prune task=%s: %v", taskID.Hex(), err)
+		}
+	}
+	return &DocEdit{TaskID: taskID, BoardID: d.BoardID, Rev: rec.Rev, Op: op, UserID: userID}, text, nil
+}
+
+func (s *taskDocService) Replace(ctx context.Context, taskID, userID primitive.ObjectID, text string) (*DocEdit, error) {
+	d, err := s.docs.Get(ctx, taskID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	op := ot.Replace(d.Text, text)
+	if op.IsNoop() {
+		return nil, nil
+	}
+	// dibuat di atas d.Rev; op yang masuk sesudahnya ikut digabung lewat transform
+	var edit *DocEdit
+	var result string
+	for i := 0; ; i++ {
+		err = s.withTx(ctx, func(ctx context.Context) error {
+			var err error
+			edit, result, err = s.apply(ctx, taskID, d.Session, userID, d.Rev, op)
+			return err
+		})
+		if !errors.Is(err, repository.ErrConflict) {
+			break
+		}
+		if i == maxTaskDocRetries-1 {
+			return nil, ErrDocBusy
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// task sudah berisi teks ini (PATCH); snapshot tidak perlu menulis ulang
+	if result == text {
+		if err := s.docs.MarkSaved(ctx, taskID, d.SavedRev, edit.Rev); err != nil && !errors.Is(err, repository.ErrConflict) {
+			log.Printf("[taskdoc] mark saved task=%s: %v", taskID.Hex(), err)
+		}
+	}
+	return edit, nil
+}
+
+func (s *taskDocService) Save(ctx context.Context) ([]DocSaved, error) {
+	docs, err := s.docs.ListUnsaved(ctx, taskDocSaveBatch)
+	if err != nil {
+		return nil, err
+	}
+	var saved []DocSaved
+	for _, d := range docs {
+		// klaim dulu supaya instance lain tidak menyimpan rev yang sama
+		if err := s.docs.MarkSaved(ctx, d.ID, d.SavedRev, d.Rev); err != nil {
+			if !errors.Is(err, repository.ErrConflict) {
+				log.Printf("[taskdoc] mark saved task=%s: %v", d.ID.Hex(), err)
+			}
+			continue
+		}
+		before, err := s.tasks.FindByID(ctx, d.ID)
+		if err == nil {
+			text := d.Text
+			err = s.tasks.Update(ctx, d.ID, bson.M{"description": &text, "updatedAt": time.Now().UTC(), "updatedBy": d.UpdatedBy})
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			// task sudah dihapus
+			if err := s.docs.Delete(ctx, d.ID); err != nil {
+				log.Printf("[taskdoc] delete task=%s: %v", d.ID.Hex(), err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("[taskdoc] save task=%s: %v", d.ID.Hex(), err)
+			_ = s.docs.MarkSaved(ctx, d.ID, d.Rev, d.SavedRev) // coba lagi putaran berikutnya
+			continue
+		}
+		s.recordSave(ctx, before, d.UpdatedBy)
+		saved = append(saved, DocSaved{TaskID: d.ID, BoardID: d.BoardID, UserID: d.UpdatedBy})
+	}
+	if err := s.docs.DeleteIdle(ctx, time.Now().UTC().Add(-TaskDocIdleTTL)); err != nil {
+		log.Printf("[taskdoc] delete idle: %v", err)
+	}
+	return saved, nil
+}
+
+// recordSave: snapshot dicatat sebagai update task oleh editor terakhir
+// (satu activity per snapshot, bukan per op)
+func (s *taskDocService) recordSave(ctx context.Context, before *models.Task, actor primitive.ObjectID) {
+	after, err := s.tasks.FindByID(ctx, before.ID)
+	if err != nil {
+		log.Printf("[activity] reload task=%s: %v", before.ID.Hex(), err)
+		return
+	}
+	s.activity.Record(ctx, &models.Activity{
+		BoardID:  &after.BoardID,
+		TaskID:   &after.ID,
+		Entity:   models.ActivityTask,
+		EntityID: after.ID,
+		Action:   models.ActivityUpdated,
+		ActorID:  actor,
+	}, before, after)
+}
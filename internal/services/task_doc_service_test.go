@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/ot"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type docFixture struct {
+	repos *repository.Repos
+	tasks TaskService
+	docs  TaskDocService
+	owner primitive.ObjectID
+	task  primitive.ObjectID
+}
+
+// newDocFixture: satu task berdeskripsi "hello"; tx nil = transaksi memory
+func newDocFixture(t *testing.T, tx repository.Transactor) *docFixture {
+	t.Helper()
+	f := &docFixture{repos: repository.NewMemory(), owner: primitive.NewObjectID()}
+	if tx == nil {
+		tx = f.repos.Tx
+	}
+	f.tasks = newTestTaskService(f.repos, tx, OrderingIndex)
+	f.docs = NewTaskDocService(f.repos.TaskDocs, f.repos.Tasks, tx, NewActivityService(f.repos.Activities))
+	b := seedBoard(t, f.repos, f.owner, "todo")
+	desc := "hello"
+	task, err := f.tasks.Create(context.Background(), b.ID, f.owner, "spec", &desc, "todo", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.task = task.ID
+	return f
+}
+
+func (f *docFixture) description(t *testing.T) string {
+	t.Helper()
+	task, err := f.tasks.Get(context.Background(), f.task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Description == nil {
+		return ""
+	}
+	return *task.Description
+}
+
+// Dua editor mengetik di atas rev yang sama; op kedua di-transform
+func TestTaskDocMergesConcurrentOps(t *testing.T) {
+	for name, tx := range map[string]repository.Transactor{"transactions": nil, "no transactions": noTx{}} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newDocFixture(t, tx)
+			d, err := f.docs.Open(ctx, f.task)
+			if err != nil || d.Text != "hello" || d.Rev != 0 {
+				t.Fatalf("open = %+v, %v", d, err)
+			}
+			alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+			e1, err := f.docs.Apply(ctx, f.task, d.Session, alice, 0, *(&ot.Op{}).Retain(5).Insert(" world"))
+			if err != nil || e1.Rev != 1 {
+				t.Fatalf("alice = %+v, %v", e1, err)
+			}
+			e2, err := f.docs.Apply(ctx, f.task, d.Session, bob, 0, *(&ot.Op{}).Insert("oh, ").Retain(5))
+			if err != nil || e2.Rev != 2 || e2.UserID != bob {
+				t.Fatalf("bob = %+v, %v", e2, err)
+			}
+			// op yang disiarkan sudah berlaku di atas rev 1
+			if got, err := e2.Op.Apply("hello world"); err != nil || got != "oh, hello world" {
+				t.Errorf("broadcast op gives %q, %v", got, err)
+			}
+			again, err := f.docs.Open(ctx, f.task)
+			if err != nil || again.Text != "oh, hello world" || again.Rev != 2 || again.Session != d.Session {
+				t.Errorf("reopen = %+v, %v", again, err)
+			}
+			// belum disalin ke task sampai Save
+			if got := f.description(t); got != "hello" {
+				t.Errorf("description before save = %q", got)
+			}
+		})
+	}
+}
+
+func TestTaskDocRejects(t *testing.T) {
+	ctx := context.Background()
+	f := newDocFixture(t, nil)
+	d, err := f.docs.Open(ctx, f.task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.docs.Apply(ctx, f.task, d.Session, f.owner, 0, *(&ot.Op{}).Retain(5).Insert("!")); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name    string
+		session primitive.ObjectID
+		rev     int64
+		op      ot.Op
+		want    error
+	}{
+		{"other session", primitive.NewObjectID(), 1, *(&ot.Op{}).Retain(6), ErrDocSession},
+		{"future rev", d.Session, 2, *(&ot.Op{}).Retain(6), ErrDocRevision},
+		{"negative rev", d.Session, -1, *(&ot.Op{}).Retain(6), ErrDocRevision},
+		{"too long", d.Session, 1, *(&ot.Op{}).Retain(6).Insert(strings.Repeat("x", TaskDocMaxLen)), ErrDocTooLarge},
+	}
+	for _, tc := range cases {
+		if _, err := f.docs.Apply(ctx, f.task, tc.session, f.owner, tc.rev, tc.op); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	// op yang panjang dasarnya tidak cocok dengan dokumen
+	if _, err := f.docs.Apply(ctx, f.task, d.Session, f.owner, 1, *(&ot.Op{}).Retain(3)); err == nil {
+		t.Error("op with the wrong base length accepted")
+	}
+	if d, _ := f.docs.Open(ctx, f.task); d.Text != "hello!" || d.Rev != 1 {
+		t.Errorf("doc after rejected ops = %+v", d)
+	}
+}
+
+func TestTaskDocSave(t *testing.T) {
+	ctx := context.Background()
+	f := newDocFixture(t, nil)
+	editor := primitive.NewObjectID()
+	d, err := f.docs.Open(ctx, f.task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.docs.Apply(ctx, f.task, d.Session, editor, 0, *(&ot.Op{}).Retain(5).Insert(" world")); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := f.docs.Save(ctx)
+	if err != nil || len(saved) != 1 || saved[0].TaskID != f.task || saved[0].UserID != editor {
+		t.Fatalf("save = %+v, %v", saved, err)
+	}
+	if got := f.description(t); got != "hello world" {
+		t.Errorf("description = %q", got)
+	}
+	if again, _ := f.docs.Save(ctx); len(again) != 0 {
+		t.Errorf("unchanged doc saved again: %+v", again)
+	}
+	acts, err := f.repos.Activities.List(ctx, repository.ActivityFilter{TaskID: &f.task}, nil, 10)
+	if err != nil || len(acts) == 0 || acts[0].Action != models.ActivityUpdated || acts[0].ActorID != editor {
+		t.Errorf("snapshot activity = %+v, %v", acts, err)
+	}
+}
+
+// PATCH deskripsi saat ada sesi edit menjadi op biasa untuk editor lain
+func TestTaskDocReplace(t *testing.T) {
+	ctx := context.Background()
+	f := newDocFixture(t, nil)
+	if edit, err := f.docs.Replace(ctx, f.task, f.owner, "ignored"); edit != nil || err != nil {
+		t.Fatalf("replace without a session = %+v, %v", edit, err)
+	}
+	d, err := f.docs.Open(ctx, f.task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit, err := f.docs.Replace(ctx, f.task, f.owner, "hello"); edit != nil || err != nil {
+		t.Errorf("replace with the same text = %+v, %v", edit, err)
+	}
+	edit, err := f.docs.Replace(ctx, f.task, f.owner, "goodbye")
+	if err != nil || edit == nil || edit.Rev != 1 {
+		t.Fatalf("replace = %+v, %v", edit, err)
+	}
+	if got, err := edit.Op.Apply("hello"); err != nil || got != "goodbye" {
+		t.Errorf("replace op gives %q, %v", got, err)
+	}
+	// editor yang masih di rev 0 tetap bisa mengirim op-nya
+	if _, err := f.docs.Apply(ctx, f.task, d.Session, primitive.NewObjectID(), 0, *(&ot.Op{}).Retain(5).Insert("!")); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := f.docs.Open(ctx, f.task); d.Text != "goodbye!" {
+		t.Errorf("text = %q", d.Text)
+	}
+}
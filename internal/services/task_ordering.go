@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sort"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// berapa kali jalur optimistic diulang sebelum menyerah
+const maxOrderingRetries = 5
+
+var ErrOrderConflict = errors.New("column changed concurrently, please retry")
+
+// withOrdering menjalankan fn (yang mengubah urutan task) dalam transaksi,
+// lalu kolom yang disentuh (nilai balik fn) dicek & dirapikan supaya order
+// tetap 1..N. Pengecekan ini perlu juga di jalur transaksi: dua Create
+// bersamaan hanya menyisipkan dokumen baru, jadi snapshot transaction tidak
+// bentrok dan keduanya bisa mendapat order yang sama.
+//
+// Bila backend tidak mendukung transaksi (Mongo standalone), fn dijalankan
+// optimistic dan diulang saat SetOrders konflik. SetOrders di Mongo tidak
+// all-or-nothing: perubahan sebelum yang konflik tetap tersimpan. Percobaan
+// berikutnya membaca ulang keadaan itu, dan ensureContiguous merapikan
+// sisanya, juga saat retry habis.
+func (s *taskService) withOrdering(ctx context.Context, boardID primitive.ObjectID, fn func(ctx context.Context) ([]string, error)) error {
+	var touched []string
+	err := s.tx.WithTx(ctx, func(tc context.Context) error {
+		var err error
+		touched, err = fn(tc)
+		return err
+	})
+	if !errors.Is(err, repository.ErrTxUnsupported) {
+		if err != nil {
+			return err // transaksi dibatalkan, tidak ada yang perlu dirapikan
+		}
+		return s.ensureContiguous(ctx, boardID, touched)
+	}
+
+	touched = nil
+	for i := 0; i < maxOrderingRetries; i++ {
+		cols, err := fn(ctx)
+		touched = appendMissing(touched, cols...)
+		if errors.Is(err, repository.ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return s.ensureContiguous(ctx, boardID, touched)
+	}
+	if err := s.ensureContiguous(ctx, boardID, touched); err != nil {
+		log.Printf("[ordering] repair board=%s after conflicts: %v", boardID.Hex(), err)
+	}
+	return ErrOrderConflict
+}
+
+func appendMissing(list []string, items ...string) []string {
+	for _, it := range items {
+		if !slices.Contains(list, it) {
+			list = append(list, it)
+		}
+	}
+	return list
+}
+
+// ensureContiguous: jalur non-transaksi bisa kalah balapan dengan insert/move
+// lain yang tidak ikut dicek; baca ulang dan rapikan bila ada celah/duplikat.
+func (s *taskService) ensureContiguous(ctx context.Context, boardID primitive.ObjectID, columns []string) error {
+	for i := 0; i < maxOrderingRetries; i++ {
+		var changes []repository.OrderChange
+		for _, col := range columns {
+			items, err := s.tasks.ListByColumn(ctx, boardID, col)
+			if err != nil {
+				return err
+			}
+			s.sortColumn(items)
+			changes = append(changes, renumber(items, col)...)
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		err := s.tasks.SetOrders(ctx, changes)
+		if !errors.Is(err, repository.ErrConflict) {
+			return err
+		}
+	}
+	return ErrOrderConflict
+}
+
+// moveByIndex: kolom sumber & tujuan dinomori ulang 1..N
+func (s *taskService) moveByIndex(ctx context.Context, boardID, id primitive.ObjectID, toColumn string, toPos int, expect *int64) ([]string, int, error) {
+	// baca ulang di dalam transaksi
+	task, err := s.tasks.FindByID(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := checkVersion(task.Version, expect); err != nil {
+		return nil, 0, err
+	}
+	// Validasi kolom tujuan (sekalian nama kolom untuk status default)
+	toName, err := s.verifyColumn(ctx, boardID, toColumn)
+	if err != nil {
+		return nil, 0, err
+	}
+	srcCol := task.ColumnID
+
+	src, err := s.tasks.ListByColumn(ctx, boardID, srcCol)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.sortColumn(src)
+	src = withoutTask(src, id)
+
+	dst := src
+	if srcCol != toColumn {
+		if dst, err = s.tasks.ListByColumn(ctx, boardID, toColumn); err != nil {
+			return nil, 0, err
+		}
+		s.sortColumn(dst)
+	}
+	pos := clampPosition(toPos, len(dst)+1)
+	dst = append(dst[:pos-1], append([]models.Task{*task}, dst[pos-1:]...)...)
+
+	changes := renumber(dst, toColumn)
+	touched := []string{toColumn}
+	if srcCol != toColumn {
+		changes = append(changes, renumber(src, srcCol)...)
+		touched = append(touched, srcCol)
+	}
+	if err := s.tasks.SetOrders(ctx, changes); err != nil {
+		return touched, 0, err // bisa sudah tertulis sebagian, lihat withOrdering
+	}
+	return touched, pos, s.afterMove(ctx, id, srcCol, toColumn, toName)
+}
+
+// sortColumn mengurutkan task satu kolom sesuai mode
+func (s *taskService) sortColumn(items []models.Task) {
+	sort.SliceStable(items, func(i, j int) bool { return s.less(&items[i], &items[j]) })
+}
+
+// sortBoard: per columnId, lalu urutan kolom
+func (s *taskService) sortBoard(items []models.Task) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].ColumnID != items[j].ColumnID {
+			return items[i].ColumnID < items[j].ColumnID
+		}
+		return s.less(&items[i], &items[j])
+	})
+}
+
+// less: mode rank → rank dulu (kosong di belakang); lalu order naik (kosong
+// di belakang), createdAt & _id supaya hasil rapikan deterministik.
+func (s *taskService) less(a, b *models.Task) bool {
+	if s.mode == OrderingRank && a.Rank != b.Rank {
+		if a.Rank == "" || b.Rank == "" {
+			return b.Rank == ""
+		}
+		return a.Rank < b.Rank
+	}
+	if (a.Order == nil) != (b.Order == nil) {
+		return b.Order == nil
+	}
+	if a.Order != nil && *a.Order != *b.Order {
+		return *a.Order < *b.Order
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.Hex() < b.ID.Hex()
+}
+
+// renumber memberi items (sudah terurut) posisi 1..N di kolom col dan
+// mengembalikan perubahan untuk task yang posisinya berbeda saja.
+func renumber(items []models.Task, col string) []repository.OrderChange {
+	var out []repository.OrderChange
+	for i, t := range items {
+		pos := i + 1
+		if t.ColumnID == col && t.Order != nil && *t.Order == pos {
+			continue
+		}
+		out = append(out, repository.OrderChange{
+			ID:         t.ID,
+			FromColumn: t.ColumnID,
+			FromOrder:  t.Order,
+			ToColumn:   col,
+			ToOrder:    pos,
+		})
+	}
+	return out
+}
+
+// columnsOf: task board per kolom, sudah terurut
+func (s *taskService) columnsOf(ctx context.Context, boardID primitive.ObjectID) (map[string][]models.Task, error) {
+	all, err := s.tasks.ListByBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	byCol := map[string][]models.Task{}
+	for _, t := range all {
+		byCol[t.ColumnID] = append(byCol[t.ColumnID], t)
+	}
+	for _, items := range byCol {
+		s.sortColumn(items)
+	}
+	return byCol, nil
+}
+
+func withoutTask(items []models.Task, id primitive.ObjectID) []models.Task {
+	out := items[:0]
+	for _, t := range items {
+		if t.ID != id {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// clampPosition: posisi 1-based dibatasi ke [1, n]
+func clampPosition(pos, n int) int {
+	if pos < 1 {
+		return 1
+	}
+	if pos > n {
+		return n
+	}
+	return pos
+}
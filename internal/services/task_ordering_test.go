@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMoveKeepsColumnsContiguous(t *testing.T) {
+	type move struct {
+		task, column string
+		pos          int
+	}
+	cases := []struct {
+		name    string
+		moves   []move
+		wantPos int // posisi yang dikembalikan move terakhir
+		want    map[string][]string
+	}{
+		{"down within column", []move{{"a", "todo", 3}}, 3, map[string][]string{"todo": {"b", "c", "a"}}},
+		{"up within column", []move{{"c", "todo", 1}}, 1, map[string][]string{"todo": {"c", "a", "b"}}},
+		{"to another column", []move{{"b", "doing", 1}}, 1, map[string][]string{"todo": {"a", "c"}, "doing": {"b"}}},
+		{"past the end is clamped", []move{{"a", "doing", 1}, {"c", "doing", 99}}, 2, map[string][]string{"todo": {"b"}, "doing": {"a", "c"}}},
+		{"below one is clamped", []move{{"c", "todo", 0}}, 1, map[string][]string{"todo": {"c", "a", "b"}}},
+		{"back and forth", []move{{"a", "doing", 1}, {"a", "todo", 2}}, 2, map[string][]string{"todo": {"b", "a", "c"}}},
+	}
+	for _, tx := range []struct {
+		name string
+		tx   repository.Transactor
+	}{{"tx", nil}, {"no tx", noTx{}}} {
+		for _, tc := range cases {
+			t.Run(tx.name+"/"+tc.name, func(t *testing.T) {
+				repos := repository.NewMemory()
+				owner := primitive.NewObjectID()
+				b := seedBoard(t, repos, owner, "todo", "doing")
+				svc := newTestTaskService(repos, tx.tx, OrderingIndex)
+				ids := seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c")
+
+				pos := 0
+				for _, mv := range tc.moves {
+					var err error
+					if pos, err = svc.Move(context.Background(), ids[mv.task], mv.column, mv.pos, owner, nil); err != nil {
+						t.Fatalf("move %s: %v", mv.task, err)
+					}
+				}
+				if pos != tc.wantPos {
+					t.Errorf("position = %d, want %d", pos, tc.wantPos)
+				}
+				if got := columnTitles(t, svc, b.ID); !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("columns = %v, want %v", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+// racingTx: setelah transaksi commit, "transaksi lain" menyisipkan task
+// dengan order yang sama, seperti dua Create bersamaan dari snapshot yang sama
+type racingTx struct {
+	inner repository.Transactor
+	tasks repository.TaskRepo
+	race  *models.Task
+}
+
+func (r *racingTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := r.inner.WithTx(ctx, fn); err != nil {
+		return err
+	}
+	if t := r.race; t != nil {
+		r.race = nil
+		max, err := r.tasks.MaxOrder(ctx, t.BoardID, t.ColumnID)
+		if err != nil {
+			return err
+		}
+		t.Order = &max
+		return r.tasks.Insert(ctx, t)
+	}
+	return nil
+}
+
+func TestCreateRepairsDuplicateOrderFromConcurrentTx(t *testing.T) {
+	repos := repository.NewMemory()
+	owner := primitive.NewObjectID()
+	b := seedBoard(t, repos, owner, "todo")
+	tx := &racingTx{inner: repos.Tx, tasks: repos.Tasks}
+	svc := newTestTaskService(repos, tx, OrderingIndex)
+	seedTasks(t, svc, b.ID, owner, "todo", "a")
+
+	tx.race = &models.Task{
+		ID: primitive.NewObjectID(), BoardID: b.ID, ColumnID: "todo", Title: "other", Version: 1,
+		TimeMeta: models.TimeMeta{CreatedAt: time.Now().UTC().Add(time.Second)},
+	}
+	seedTasks(t, svc, b.ID, owner, "todo", "b")
+
+	want := map[string][]string{"todo": {"a", "b", "other"}}
+	if got := columnTitles(t, svc, b.ID); !reflect.DeepEqual(got, want) {
+		t.Fatalf("columns = %v, want %v", got, want)
+	}
+}
+
+// partialTasks meniru BulkWrite ordered di Mongo standalone: SetOrders
+// dengan lebih dari satu perubahan hanya menulis yang pertama lalu konflik,
+// sebanyak failures kali
+type partialTasks struct {
+	repository.TaskRepo
+	failures int
+}
+
+func (p *partialTasks) SetOrders(ctx context.Context, changes []repository.OrderChange) error {
+	if p.failures > 0 && len(changes) > 1 {
+		p.failures--
+		if err := p.TaskRepo.SetOrders(ctx, changes[:1]); err != nil {
+			return err
+		}
+		return repository.ErrConflict
+	}
+	return p.TaskRepo.SetOrders(ctx, changes)
+}
+
+func TestPartialSetOrdersIsRepaired(t *testing.T) {
+	for _, failures := range []int{1, 3, maxOrderingRetries - 1} {
+		t.Run("", func(t *testing.T) {
+			ctx := context.Background()
+			repos := repository.NewMemory()
+			tasks := &partialTasks{TaskRepo: repos.Tasks}
+			repos.Tasks = tasks
+			owner := primitive.NewObjectID()
+			b := seedBoard(t, repos, owner, "todo", "doing")
+			svc := newTestTaskService(repos, noTx{}, OrderingIndex)
+			ids := seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c", "d")
+
+			tasks.failures = failures
+			if _, err := svc.Move(ctx, ids["d"], "todo", 1, owner, nil); err != nil {
+				t.Fatal(err)
+			}
+			want := map[string][]string{"todo": {"d", "a", "b", "c"}}
+			if got := columnTitles(t, svc, b.ID); !reflect.DeepEqual(got, want) {
+				t.Fatalf("after move: %v, want %v", got, want)
+			}
+
+			tasks.failures = failures
+			if err := svc.Delete(ctx, ids["a"], owner); err != nil {
+				t.Fatal(err)
+			}
+			want = map[string][]string{"todo": {"d", "b", "c"}}
+			if got := columnTitles(t, svc, b.ID); !reflect.DeepEqual(got, want) {
+				t.Fatalf("after delete: %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeleteTrashesNotesAndClosesGap(t *testing.T) {
+	for _, tx := range []struct {
+		name string
+		tx   repository.Transactor
+	}{{"tx", nil}, {"no tx", noTx{}}} {
+		t.Run(tx.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := repository.NewMemory()
+			owner := primitive.NewObjectID()
+			b := seedBoard(t, repos, owner, "todo")
+			svc := newTestTaskService(repos, tx.tx, OrderingIndex)
+			ids := seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c")
+			taskID := ids["b"]
+			note := &models.Note{ID: primitive.NewObjectID(), BoardID: &b.ID, TaskID: &taskID, AuthorID: owner, Content: "n", Version: 1}
+			if err := repos.Notes.Insert(ctx, note); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := svc.Delete(ctx, taskID, owner); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := columnTitles(t, svc, b.ID), map[string][]string{"todo": {"a", "c"}}; !reflect.DeepEqual(got, want) {
+				t.Errorf("columns = %v, want %v", got, want)
+			}
+			trashed, err := repos.Tasks.FindDeleted(ctx, taskID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repos.Notes.FindByID(ctx, note.ID); err == nil {
+				t.Error("note of the deleted task is still live")
+			}
+			n, err := repos.Notes.FindDeleted(ctx, note.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !n.DeletedAt.Equal(*trashed.DeletedAt) {
+				t.Errorf("note deletedAt %v, task deletedAt %v", n.DeletedAt, trashed.DeletedAt)
+			}
+		})
+	}
+}
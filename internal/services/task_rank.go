@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/rank"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mode rank (TASK_ORDERING=rank): urutan disimpan di Task.Rank sehingga move
+// hanya menulis task yang dipindah. Field order tidak dirawat; di list diisi
+// posisi 1..N supaya frontend lama tetap jalan. Kolom yang masih memakai
+// order (data lama) dimigrasi otomatis saat pertama kali disentuh.
+
+// listByRank: migrasi kolom yang belum punya rank, lalu isi order tampilan
+func (s *taskService) listByRank(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error) {
+	out, err := s.tasks.ListByBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range out {
+		if t.Rank == "" {
+			if err := s.migrateBoard(ctx, boardID); err != nil {
+				return nil, err
+			}
+			if out, err = s.tasks.ListByBoard(ctx, boardID); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	s.sortBoard(out)
+	pos := 0
+	for i := range out {
+		if i == 0 || out[i].ColumnID != out[i-1].ColumnID {
+			pos = 0
+		}
+		pos++
+		p := pos
+		out[i].Order = &p
+	}
+	return out, nil
+}
+
+func (s *taskService) migrateBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	return s.withOrdering(ctx, boardID, func(ctx context.Context) ([]string, error) {
+		byCol, err := s.columnsOf(ctx, boardID)
+		if err != nil {
+			return nil, err
+		}
+		for col, items := range byCol {
+			if _, err := s.ensureRanks(ctx, col, items); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+}
+
+// insertByRank: task baru di akhir kolom
+func (s *taskService) insertByRank(ctx context.Context, t *models.Task) error {
+	items, err := s.tasks.ListByColumn(ctx, t.BoardID, t.ColumnID)
+	if err != nil {
+		return err
+	}
+	s.sortColumn(items)
+	if items, err = s.ensureRanks(ctx, t.ColumnID, items); err != nil {
+		return err
+	}
+	last := ""
+	if len(items) > 0 {
+		last = items[len(items)-1].Rank
+	}
+	if t.Rank, err = rank.Between(last, ""); err != nil {
+		return err
+	}
+	t.Order = nil
+	if err := s.tasks.Insert(ctx, t); err != nil {
+		return err
+	}
+	pos := len(items) + 1 // hanya untuk response/broadcast
+	t.Order = &pos
+	return nil
+}
+
+// moveByRank: hitung rank di antara tetangga posisi tujuan; hanya task yang
+// dipindah yang ditulis, kecuali kolom perlu dirapikan (rank terlalu panjang).
+func (s *taskService) moveByRank(ctx context.Context, boardID, id primitive.ObjectID, toColumn string, toPos int, expect *int64) (int, error) {
+	task, err := s.tasks.FindByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkVersion(task.Version, expect); err != nil {
+		return 0, err
+	}
+	toName, err := s.verifyColumn(ctx, boardID, toColumn)
+	if err != nil {
+		return 0, err
+	}
+	srcCol := task.ColumnID
+
+	dst, err := s.tasks.ListByColumn(ctx, boardID, toColumn)
+	if err != nil {
+		return 0, err
+	}
+	s.sortColumn(dst)
+	if dst, err = s.ensureRanks(ctx, toColumn, dst); err != nil {
+		return 0, err
+	}
+	for _, t := range dst {
+		if t.ID == id {
+			task.Rank = t.Rank // bisa baru saja dimigrasi
+		}
+	}
+	dst = withoutTask(dst, id)
+	pos := clampPosition(toPos, len(dst)+1)
+
+	r, err := rankAt(dst, pos)
+	if err != nil {
+		// rank tetangga kembar (tulis bersamaan) → ratakan dulu lalu hitung ulang
+		if dst, err = s.rebalanceColumn(ctx, toColumn, dst); err != nil {
+			return 0, err
+		}
+		if r, err = rankAt(dst, pos); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.tasks.SetRanks(ctx, []repository.RankChange{{
+		ID: id, FromColumn: srcCol, FromRank: task.Rank, ToColumn: toColumn, ToRank: r,
+	}}); err != nil {
+		return 0, err
+	}
+
+	if len(r) > rank.MaxLen {
+		task.ColumnID, task.Rank = toColumn, r
+		dst = append(dst[:pos-1], append([]models.Task{*task}, dst[pos-1:]...)...)
+		if _, err := s.rebalanceColumn(ctx, toColumn, dst); err != nil {
+			return 0, err
+		}
+	}
+	return pos, s.afterMove(ctx, id, srcCol, toColumn, toName)
+}
+
+// rankAt: rank untuk disisipkan di posisi pos (1-based) pada items terurut
+func rankAt(items []models.Task, pos int) (string, error) {
+	prev, next := "", ""
+	if pos > 1 {
+		prev = items[pos-2].Rank
+	}
+	if pos <= len(items) {
+		next = items[pos-1].Rank
+	}
+	return rank.Between(prev, next)
+}
+
+// ensureRanks: kolom dengan task tanpa rank → beri rank berjarak rata
+// mengikuti urutan sekarang (items harus sudah terurut).
+func (s *taskService) ensureRanks(ctx context.Context, col string, items []models.Task) ([]models.Task, error) {
+	for _, t := range items {
+		if t.Rank == "" {
+			return s.rebalanceColumn(ctx, col, items)
+		}
+	}
+	return items, nil
+}
+
+// rebalanceColumn menulis ulang rank items (terurut) secara rata
+func (s *taskService) rebalanceColumn(ctx context.Context, col string, items []models.Task) ([]models.Task, error) {
+	ranks := rank.Spread(len(items))
+	var changes []repository.RankChange
+	for i := range items {
+		if items[i].Rank == ranks[i] && items[i].ColumnID == col {
+			continue
+		}
+		changes = append(changes, repository.RankChange{
+			ID: items[i].ID, FromColumn: items[i].ColumnID, FromRank: items[i].Rank,
+			ToColumn: col, ToRank: ranks[i],
+		})
+		items[i].Rank = ranks[i]
+		items[i].ColumnID = col
+	}
+	return items, s.tasks.SetRanks(ctx, changes)
+}
+
+// rebalanceBoard: ratakan rank semua kolom dan tulis order 1..N yang sesuai,
+// sehingga board tetap rapi bila TASK_ORDERING dikembalikan ke index.
+func (s *taskService) rebalanceBoard(ctx context.Context, boardID primitive.ObjectID) error {
+	byCol, err := s.columnsOf(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	for col, items := range byCol {
+		items, err := s.rebalanceColumn(ctx, col, items)
+		if err != nil {
+			return err
+		}
+		if err := s.tasks.SetOrders(ctx, renumber(items, col)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
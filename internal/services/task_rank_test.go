@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/rank"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func titles(items []models.Task) []string {
+	var out []string
+	for _, t := range items {
+		out = append(out, t.Title)
+	}
+	return out
+}
+
+// storedRanks: rank tersimpan per judul (mode rank tidak merawat order)
+func storedRanks(t *testing.T, repos *repository.Repos, boardID primitive.ObjectID) map[string]string {
+	t.Helper()
+	items, err := repos.Tasks.ListByBoard(context.Background(), boardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]string{}
+	for _, it := range items {
+		out[it.Title] = it.Rank
+	}
+	return out
+}
+
+func TestRankMoveWritesOnlyTheMovedTask(t *testing.T) {
+	cases := []struct {
+		name         string
+		task, column string
+		pos          int
+		want         map[string][]string
+	}{
+		{"to the top", "c", "todo", 1, map[string][]string{"todo": {"c", "a", "b"}}},
+		{"between two", "c", "todo", 2, map[string][]string{"todo": {"a", "c", "b"}}},
+		{"to the bottom", "a", "todo", 99, map[string][]string{"todo": {"b", "c", "a"}}},
+		{"to another column", "b", "doing", 1, map[string][]string{"todo": {"a", "c"}, "doing": {"b"}}},
+	}
+	for _, tx := range []struct {
+		name string
+		tx   repository.Transactor
+	}{{"tx", nil}, {"no tx", noTx{}}} {
+		for _, tc := range cases {
+			t.Run(tx.name+"/"+tc.name, func(t *testing.T) {
+				repos := repository.NewMemory()
+				owner := primitive.NewObjectID()
+				b := seedBoard(t, repos, owner, "todo", "doing")
+				svc := newTestTaskService(repos, tx.tx, OrderingRank)
+				ids := seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c")
+				before := storedRanks(t, repos, b.ID)
+
+				if _, err := svc.Move(context.Background(), ids[tc.task], tc.column, tc.pos, owner, nil); err != nil {
+					t.Fatal(err)
+				}
+				if got := columnTitles(t, svc, b.ID); !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("columns = %v, want %v", got, tc.want)
+				}
+				for title, r := range storedRanks(t, repos, b.ID) {
+					if title != tc.task && r != before[title] {
+						t.Errorf("rank of %s changed from %q to %q", title, before[title], r)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRankMigratesLegacyOrder(t *testing.T) {
+	cases := []struct {
+		name   string
+		orders []int // order tersimpan task t0, t1, ...; 0 = tanpa order
+		want   []string
+	}{
+		{"contiguous", []int{1, 2, 3}, []string{"t0", "t1", "t2"}},
+		{"reversed", []int{3, 2, 1}, []string{"t2", "t1", "t0"}},
+		{"gaps and duplicates", []int{5, 2, 2}, []string{"t1", "t2", "t0"}},
+		{"missing order goes last", []int{0, 2, 1}, []string{"t2", "t1", "t0"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			repos := repository.NewMemory()
+			b := seedBoard(t, repos, primitive.NewObjectID(), "todo")
+			for i, o := range tc.orders {
+				task := &models.Task{ID: primitive.NewObjectID(), BoardID: b.ID, ColumnID: "todo", Title: fmt.Sprintf("t%d", i), Version: 1}
+				if o > 0 {
+					o := o
+					task.Order = &o
+				}
+				if err := repos.Tasks.Insert(ctx, task); err != nil {
+					t.Fatal(err)
+				}
+			}
+			items, err := newTestTaskService(repos, nil, OrderingRank).ListByBoard(ctx, b.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(items); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("order = %v, want %v", got, tc.want)
+			}
+			for title, r := range storedRanks(t, repos, b.ID) {
+				if r == "" {
+					t.Errorf("task %s was not given a rank", title)
+				}
+			}
+		})
+	}
+}
+
+// task terakhir berulang kali dipindah ke posisi yang sama: rank makin
+// panjang sampai kolom diratakan, urutan tetap benar
+func TestRankRebalancesLongRanks(t *testing.T) {
+	for _, pos := range []int{1, 2} {
+		t.Run(fmt.Sprint("position ", pos), func(t *testing.T) {
+			ctx := context.Background()
+			repos := repository.NewMemory()
+			owner := primitive.NewObjectID()
+			b := seedBoard(t, repos, owner, "todo")
+			svc := newTestTaskService(repos, nil, OrderingRank)
+			seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c", "d")
+
+			for i := 0; i < 40; i++ {
+				items, err := svc.ListByBoard(ctx, b.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				last := items[len(items)-1]
+				if _, err := svc.Move(ctx, last.ID, "todo", pos, owner, nil); err != nil {
+					t.Fatalf("move %d: %v", i, err)
+				}
+				stored, err := svc.Get(ctx, last.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(stored.Rank) > rank.MaxLen {
+					t.Fatalf("move %d: rank %q longer than %d", i, stored.Rank, rank.MaxLen)
+				}
+				if items, err = svc.ListByBoard(ctx, b.ID); err != nil {
+					t.Fatal(err)
+				}
+				if items[pos-1].ID != last.ID {
+					t.Fatalf("move %d: %s not at position %d: %v", i, last.Title, pos, titles(items))
+				}
+			}
+		})
+	}
+}
+
+// Reorder di mode rank menulis order juga, sehingga kembali ke mode index
+// tidak mengacak kolom
+func TestRankReorderKeepsIndexModeInSync(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	owner := primitive.NewObjectID()
+	b := seedBoard(t, repos, owner, "todo")
+	svc := newTestTaskService(repos, nil, OrderingRank)
+	ids := seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c")
+
+	if _, err := svc.Move(ctx, ids["c"], "todo", 1, owner, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Reorder(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	got := columnTitles(t, newTestTaskService(repos, nil, OrderingIndex), b.ID)
+	if want := map[string][]string{"todo": {"c", "a", "b"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("index mode = %v, want %v", got, want)
+	}
+}
@@ -3,54 +3,79 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
+	"math"
 	"time"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TaskService interface {
 	ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error)
 	Create(ctx context.Context, boardID, userID primitive.ObjectID, title string, desc *string, columnId string, status *models.TaskStatus, due *time.Time, assignees []primitive.ObjectID) (*models.Task, error)
 	Get(ctx context.Context, id primitive.ObjectID) (*models.Task, error)
-	Update(ctx context.Context, id primitive.ObjectID, patch bson.M, updater primitive.ObjectID) error
-	Delete(ctx context.Context, id primitive.ObjectID) error
-	Move(ctx context.Context, id primitive.ObjectID, toColumn string, toPos int) error
+	// Update: expect (boleh nil) = version yang dilihat klien; ErrVersionConflict bila berbeda
+	Update(ctx context.Context, id primitive.ObjectID, patch bson.M, updater primitive.ObjectID, expect *int64) error
+	// Delete memindahkan task ke trash; posisi terakhirnya disimpan untuk Restore
+	Delete(ctx context.Context, id, actor primitive.ObjectID) error
+	// Restore mengembalikan task dari trash ke posisi semula di kolomnya
+	// (kolom sudah dihapus → akhir kolom pertama); ErrBoardInTrash bila board-nya juga
+	Restore(ctx context.Context, id, actor primitive.ObjectID) (*models.Task, error)
+	// Move mengembalikan posisi akhir (1-based) task di kolom tujuan; expect seperti Update
+	Move(ctx context.Context, id primitive.ObjectID, toColumn string, toPos int, actor primitive.ObjectID, expect *int64) (int, error)
+	// Reorder merapikan order semua kolom board menjadi 1..N (mode rank: rank juga diratakan)
+	Reorder(ctx context.Context, boardID primitive.ObjectID) error
+	ListTimeline(ctx context.Context, scope repository.TimelineScope, from, to time.Time) ([]models.Task, error)
 }
 
-type taskService struct{}
+// OrderingMode menentukan cara urutan task dalam kolom disimpan
+type OrderingMode string
 
-func NewTaskService() TaskService { return &taskService{} }
+const (
+	// OrderingIndex: field order 1..N, move menomori ulang kolom
+	OrderingIndex OrderingMode = "index"
+	// OrderingRank: field rank leksikografis, move hanya mengubah task yang dipindah
+	OrderingRank OrderingMode = "rank"
+)
+
+type taskService struct {
+	tasks    repository.TaskRepo
+	notes    repository.NoteRepo // ikut ke trash/keluar bersama task-nya
+	boards   repository.BoardRepo
+	tx       repository.Transactor
+	activity ActivityService
+	mode     OrderingMode
+}
+
+func NewTaskService(tasks repository.TaskRepo, notes repository.NoteRepo, boards repository.BoardRepo, tx repository.Transactor, activity ActivityService, mode OrderingMode) TaskService {
+	if mode != OrderingRank {
+		mode = OrderingIndex
+	}
+	return &taskService{tasks: tasks, notes: notes, boards: boards, tx: tx, activity: activity, mode: mode}
+}
 
 func (s *taskService) ListByBoard(ctx context.Context, boardID primitive.ObjectID) ([]models.Task, error) {
-	cur, err := config.MongoDB.Collection("tasks").Find(ctx, bson.M{"boardId": boardID},
-		options.Find().SetSort(bson.D{{Key: "columnId", Value: 1}, {Key: "order", Value: 1}}))
-	if err != nil {
-		return nil, err
+	if s.mode == OrderingRank {
+		return s.listByRank(ctx, boardID)
 	}
-	defer cur.Close(ctx)
-	var out []models.Task
-	if err := cur.All(ctx, &out); err != nil {
+	out, err := s.tasks.ListByBoard(ctx, boardID)
+	if err != nil {
 		return nil, err
 	}
+	// sisa rank dari mode rank diabaikan di mode index
+	s.sortBoard(out)
 	return out, nil
 }
 
+func (s *taskService) ListTimeline(ctx context.Context, scope repository.TimelineScope, from, to time.Time) ([]models.Task, error) {
+	return s.tasks.ListInRange(ctx, scope, from, to)
+}
+
 func (s *taskService) maxOrder(ctx context.Context, boardID primitive.ObjectID, columnId string) (int, error) {
-	opts := options.FindOne().SetSort(bson.D{{Key: "order", Value: -1}})
-	var t models.Task
-	err := config.MongoDB.Collection("tasks").FindOne(ctx, bson.M{"boardId": boardID, "columnId": columnId}, opts).Decode(&t)
-	if err != nil {
-		return 0, nil // tidak apa-apa, berarti kosong
-	}
-	if t.Order == nil {
-		v := 0
-		return v, nil
-	}
-	return *t.Order, nil
+	return s.tasks.MaxOrder(ctx, boardID, columnId)
 }
 
 // map status default berdasarkan nama kolom
@@ -65,28 +90,32 @@ func defaultStatusForColumn(name string) models.TaskStatus {
 	}
 }
 
+// ErrColumnNotFound: columnId tidak ada di board
+var ErrColumnNotFound = errors.New("column not found in board")
+
 func (s *taskService) verifyColumn(ctx context.Context, boardID primitive.ObjectID, columnId string) (string, error) {
 	// return column name untuk bantu status default
-	var b models.Board
-	if err := config.MongoDB.Collection("boards").FindOne(ctx, bson.M{"_id": boardID, "columns.id": columnId}).Decode(&b); err != nil {
-		return "", errors.New("column not found in board")
+	b, err := s.boards.FindByID(ctx, boardID)
+	if err != nil {
+		return "", ErrColumnNotFound
 	}
 	for _, c := range b.Columns {
 		if c.ID == columnId {
 			return c.Name, nil
 		}
 	}
-	return "", errors.New("column not found")
+	return "", ErrColumnNotFound
 }
 
 func (s *taskService) Create(ctx context.Context, boardID, userID primitive.ObjectID, title string, desc *string, columnId string, status *models.TaskStatus, due *time.Time, assignees []primitive.ObjectID) (*models.Task, error) {
+	if _, err := writableBoard(ctx, s.boards, boardID); err != nil {
+		return nil, err
+	}
 	colName, err := s.verifyColumn(ctx, boardID, columnId)
 	if err != nil {
 		return nil, err
 	}
 
-	max, _ := s.maxOrder(ctx, boardID, columnId)
-	next := max + 1
 	st := models.StatusPlanned
 	if status != nil {
 		st = *status
@@ -104,110 +133,277 @@ func (s *taskService) Create(ctx context.Context, boardID, userID primitive.Obje
 		Priority:    models.PriorityMedium,
 		Assignees:   assignees,
 		DueDate:     due,
-		Order:       &next,
 		CreatedBy:   userID,
 		UpdatedBy:   userID,
+		Version:     1,
 		TimeMeta:    models.TimeMeta{CreatedAt: now, UpdatedAt: now},
 	}
-	_, err = config.MongoDB.Collection("tasks").InsertOne(ctx, t)
+	err = s.withOrdering(ctx, boardID, func(ctx context.Context) ([]string, error) {
+		if s.mode == OrderingRank {
+			return nil, s.insertByRank(ctx, t)
+		}
+		max, err := s.maxOrder(ctx, boardID, columnId)
+		if err != nil {
+			return nil, err
+		}
+		next := max + 1
+		t.Order = &next
+		return []string{columnId}, s.tasks.Insert(ctx, t)
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.record(ctx, models.ActivityCreated, userID, nil, t)
 	return t, nil
 }
 
-func (s *taskService) Get(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
-	var t models.Task
-	if err := config.MongoDB.Collection("tasks").FindOne(ctx, bson.M{"_id": id}).Decode(&t); err != nil {
-		return nil, err
+// record mencatat activity task; before nil = baru dibuat, after nil = dihapus
+func (s *taskService) record(ctx context.Context, action models.ActivityAction, actor primitive.ObjectID, before, after *models.Task) {
+	t := after
+	if t == nil {
+		t = before
+	}
+	s.activity.Record(ctx, &models.Activity{
+		BoardID:  &t.BoardID,
+		TaskID:   &t.ID,
+		Entity:   models.ActivityTask,
+		EntityID: t.ID,
+		Action:   action,
+		ActorID:  actor,
+	}, before, after)
+}
+
+// recordChange: seperti record, keadaan sesudahnya dibaca ulang dari store
+func (s *taskService) recordChange(ctx context.Context, action models.ActivityAction, actor primitive.ObjectID, before *models.Task) {
+	after, err := s.tasks.FindByID(ctx, before.ID)
+	if err != nil {
+		log.Printf("[activity] reload task=%s: %v", before.ID.Hex(), err)
+		return
 	}
-	return &t, nil
+	s.record(ctx, action, actor, before, after)
 }
 
-func (s *taskService) Update(ctx context.Context, id primitive.ObjectID, patch bson.M, updater primitive.ObjectID) error {
+func (s *taskService) Get(ctx context.Context, id primitive.ObjectID) (*models.Task, error) {
+	return s.tasks.FindByID(ctx, id)
+}
+
+func (s *taskService) Update(ctx context.Context, id primitive.ObjectID, patch bson.M, updater primitive.ObjectID, expect *int64) error {
+	before, err := s.tasks.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion(before.Version, expect); err != nil {
+		return err
+	}
+	if _, err := writableBoard(ctx, s.boards, before.BoardID); err != nil {
+		return err
+	}
+	// pindah kolom lewat PATCH → taruh di akhir kolom tujuan lewat Move supaya
+	// order tetap rapi; status di patch (kalau ada) tetap menang.
+	if col, moving := patch["columnId"].(string); moving {
+		delete(patch, "columnId")
+		if before.ColumnID != col {
+			if _, err := s.Move(ctx, id, col, math.MaxInt, updater, expect); err != nil {
+				return err
+			}
+			if expect != nil {
+				next := *expect + 1 // afterMove menaikkan version tepat sekali
+				expect = &next
+			}
+			// pindahnya sudah tercatat sebagai "moved"
+			if before, err = s.tasks.FindByID(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
 	patch["updatedAt"] = time.Now().UTC()
 	patch["updatedBy"] = updater
-	_, err := config.MongoDB.Collection("tasks").UpdateByID(ctx, id, bson.M{"$set": patch})
-	return err
+	if expect == nil {
+		err = s.tasks.Update(ctx, id, patch)
+	} else {
+		err = versionErr(s.tasks.UpdateIfVersion(ctx, id, *expect, patch))
+	}
+	if err != nil {
+		return err
+	}
+	s.recordChange(ctx, models.ActivityUpdated, updater, before)
+	return nil
 }
 
-func (s *taskService) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := config.MongoDB.Collection("tasks").DeleteOne(ctx, bson.M{"_id": id})
-	return err
+func (s *taskService) Delete(ctx context.Context, id, actor primitive.ObjectID) error {
+	task, err := s.tasks.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := writableBoard(ctx, s.boards, task.BoardID); err != nil {
+		return err
+	}
+	// order/rank task di trash tidak diubah → posisi semula untuk Restore.
+	// Presisi Mongo milidetik: now dipakai lagi untuk mengenali percobaan ulang.
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	// trash, note & celah kolom dalam satu withOrdering supaya gagal di
+	// tengah tidak meninggalkan celah atau note yatim
+	err = s.withOrdering(ctx, task.BoardID, func(ctx context.Context) ([]string, error) {
+		if err := s.trashWithNotes(ctx, id, actor, now); err != nil {
+			return nil, err
+		}
+		if s.mode == OrderingRank { // rank tidak perlu ditutup celahnya
+			return nil, nil
+		}
+		rest, err := s.tasks.ListByColumn(ctx, task.BoardID, task.ColumnID)
+		if err != nil {
+			return nil, err
+		}
+		s.sortColumn(rest)
+		return []string{task.ColumnID}, s.tasks.SetOrders(ctx, renumber(rest, task.ColumnID))
+	})
+	if err != nil {
+		return err
+	}
+	s.record(ctx, models.ActivityDeleted, actor, task, nil)
+	return nil
 }
 
-// Move: geser order di kolom sumber/tujuan (best-effort, tanpa transaksi)
-func (s *taskService) Move(ctx context.Context, id primitive.ObjectID, toColumn string, toPos int) error {
-	// Ambil task lama
-	task, err := s.Get(ctx, id)
+// trashWithNotes: task & note-nya ke trash dengan waktu yang sama (Restore
+// memakainya untuk tahu note mana yang dibawa). Aman diulang oleh
+// withOrdering: task yang sudah di trash oleh percobaan ini dilewati.
+func (s *taskService) trashWithNotes(ctx context.Context, id, actor primitive.ObjectID, at time.Time) error {
+	err := s.tasks.SoftDelete(ctx, id, actor, at)
+	if errors.Is(err, repository.ErrNotFound) {
+		if t, ferr := s.tasks.FindDeleted(ctx, id); ferr == nil && t.DeletedAt != nil && t.DeletedAt.Equal(at) {
+			err = nil
+		}
+	}
 	if err != nil {
 		return err
 	}
+	return s.notes.SoftDeleteByTask(ctx, id, actor, at)
+}
 
-	// Validasi kolom tujuan
-	if _, err := s.verifyColumn(ctx, task.BoardID, toColumn); err != nil {
-		return err
+// Move: pindahkan task ke posisi toPos (1-based, di-clamp ke ukuran kolom)
+// di toColumn, dalam satu transaksi (atau optimistic bila Mongo standalone,
+// lihat withOrdering). Version dicek ulang di dalam transaksi supaya drag
+// dari tampilan board yang basi ketahuan.
+func (s *taskService) Move(ctx context.Context, id primitive.ObjectID, toColumn string, toPos int, actor primitive.ObjectID, expect *int64) (int, error) {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := writableBoard(ctx, s.boards, task.BoardID); err != nil {
+		return 0, err
 	}
+	pos, err := s.place(ctx, task.BoardID, id, toColumn, toPos, expect)
+	if err != nil {
+		return 0, err
+	}
+	s.recordChange(ctx, models.ActivityMoved, actor, task)
+	return pos, nil
+}
+
+// place: inti Move tanpa pencatatan activity
+func (s *taskService) place(ctx context.Context, boardID, id primitive.ObjectID, toColumn string, toPos int, expect *int64) (int, error) {
+	var pos int
+	err := s.withOrdering(ctx, boardID, func(ctx context.Context) ([]string, error) {
+		if s.mode == OrderingRank {
+			var err error
+			pos, err = s.moveByRank(ctx, boardID, id, toColumn, toPos, expect)
+			return nil, err
+		}
+		touched, p, err := s.moveByIndex(ctx, boardID, id, toColumn, toPos, expect)
+		pos = p
+		return touched, err
+	})
+	return pos, err
+}
 
-	srcCol := task.ColumnID
-	var srcOrder int
-	if task.Order != nil {
-		srcOrder = *task.Order
+func (s *taskService) Restore(ctx context.Context, id, actor primitive.ObjectID) (*models.Task, error) {
+	task, err := s.tasks.FindDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := writableBoard(ctx, s.boards, task.BoardID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrBoardInTrash
+	}
+	if err != nil {
+		return nil, err
 	}
+	toColumn := restoreColumn(b, task.ColumnID)
+	if toColumn == "" {
+		return nil, errors.New("board has no columns")
+	}
+	now := time.Now().UTC()
+	if err := s.tasks.Restore(ctx, id, bson.M{"updatedAt": now, "updatedBy": actor}); err != nil {
+		return nil, err
+	}
+	if err := s.notes.RestoreByTask(ctx, id, *task.DeletedAt, bson.M{"updatedAt": now}); err != nil {
+		return nil, err
+	}
+	// mode index: sisipkan kembali di nomor lamanya (kolom sudah dinomori
+	// ulang saat dihapus); mode rank: rank lama masih berada di antara
+	// tetangganya, cukup dipindah bila kolomnya sudah tidak ada
+	if s.mode == OrderingIndex || toColumn != task.ColumnID {
+		pos := math.MaxInt
+		if toColumn == task.ColumnID && task.Order != nil {
+			pos = *task.Order
+		}
+		if _, err := s.place(ctx, task.BoardID, id, toColumn, pos, nil); err != nil {
+			return nil, err
+		}
+	}
+	after, err := s.tasks.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, models.ActivityRestored, actor, task, after)
+	return after, nil
+}
 
-	// 1) Jika pindah kolom: longgar-kan kolom tujuan untuk toPos
-	if srcCol != toColumn {
-		_, _ = config.MongoDB.Collection("tasks").UpdateMany(ctx,
-			bson.M{"boardId": task.BoardID, "columnId": toColumn, "order": bson.M{"$gte": toPos}},
-			bson.M{"$inc": bson.M{"order": 1}},
-		)
-		// rapikan kolom sumber (tutup gap)
-		_, _ = config.MongoDB.Collection("tasks").UpdateMany(ctx,
-			bson.M{"boardId": task.BoardID, "columnId": srcCol, "order": bson.M{"$gt": srcOrder}},
-			bson.M{"$inc": bson.M{"order": -1}},
-		)
-	} else {
-		// tetap di kolom sama: geser ruang untuk toPos lalu tutup gap dari posisi lama
-		if toPos < srcOrder {
-			// geser turun semua item yang >= toPos dan < srcOrder
-			_, _ = config.MongoDB.Collection("tasks").UpdateMany(ctx,
-				bson.M{"boardId": task.BoardID, "columnId": srcCol, "order": bson.M{"$gte": toPos, "$lt": srcOrder}},
-				bson.M{"$inc": bson.M{"order": 1}},
-			)
-		} else if toPos > srcOrder {
-			// geser naik semua item yang <= toPos dan > srcOrder
-			_, _ = config.MongoDB.Collection("tasks").UpdateMany(ctx,
-				bson.M{"boardId": task.BoardID, "columnId": srcCol, "order": bson.M{"$lte": toPos, "$gt": srcOrder}},
-				bson.M{"$inc": bson.M{"order": -1}},
-			)
+// restoreColumn: kolom asal bila masih ada, selain itu kolom pertama board
+func restoreColumn(b *models.Board, columnID string) string {
+	first := ""
+	firstOrder := 0
+	for _, c := range b.Columns {
+		if c.ID == columnID {
+			return c.ID
+		}
+		if first == "" || c.Order < firstOrder {
+			first, firstOrder = c.ID, c.Order
 		}
 	}
+	return first
+}
 
-	// 2) Update task tujuan
-	st := task.Status
+// afterMove: pindah kolom → status default sesuai kolom tujuan
+// (opsional: frontend juga bisa kirim status)
+func (s *taskService) afterMove(ctx context.Context, id primitive.ObjectID, srcCol, toColumn, toName string) error {
+	set := bson.M{"updatedAt": time.Now().UTC()}
 	if srcCol != toColumn {
-		// set status default sesuai kolom tujuan
-		// (opsional: frontend juga bisa kirim status)
-		// ambil nama kolom tujuan dulu:
-		var b models.Board
-		if err := config.MongoDB.Collection("boards").
-			FindOne(ctx, bson.M{"_id": task.BoardID, "columns.id": toColumn}).Decode(&b); err == nil {
-			for _, c := range b.Columns {
-				if c.ID == toColumn {
-					st = defaultStatusForColumn(c.Name)
-					break
-				}
-			}
-		}
+		set["status"] = defaultStatusForColumn(toName)
 	}
+	return s.tasks.Update(ctx, id, set)
+}
 
-	_, err = config.MongoDB.Collection("tasks").UpdateByID(ctx, id, bson.M{
-		"$set": bson.M{
-			"columnId":  toColumn,
-			"order":     toPos,
-			"status":    st,
-			"updatedAt": time.Now().UTC(),
-		},
+func (s *taskService) Reorder(ctx context.Context, boardID primitive.ObjectID) error {
+	if _, err := writableBoard(ctx, s.boards, boardID); err != nil {
+		return err
+	}
+	if s.mode == OrderingRank {
+		return s.withOrdering(ctx, boardID, func(ctx context.Context) ([]string, error) {
+			return nil, s.rebalanceBoard(ctx, boardID)
+		})
+	}
+	return s.withOrdering(ctx, boardID, func(ctx context.Context) ([]string, error) {
+		byCol, err := s.columnsOf(ctx, boardID)
+		if err != nil {
+			return nil, err
+		}
+		var cols []string
+		var changes []repository.OrderChange
+		for col, items := range byCol {
+			cols = append(cols, col)
+			changes = append(changes, renumber(items, col)...)
+		}
+		return cols, s.tasks.SetOrders(ctx, changes)
 	})
-	return err
 }
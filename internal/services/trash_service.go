@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrBoardInTrash = errors.New("board is in trash, restore the board first")
+	ErrTaskInTrash  = errors.New("task is in trash, restore the task first")
+)
+
+const (
+	trashPurgeInterval = time.Hour
+	trashPurgeBatch    = 100
+)
+
+// BoardTrash: isi trash satu board, terbaru dihapus dulu
+type BoardTrash struct {
+	Tasks []models.Task `json:"tasks"`
+	Notes []models.Note `json:"notes"`
+}
+
+// TrashService: daftar isi trash & hapus permanen setelah masa retensi.
+// Hapus/restore sendiri ada di service masing-masing (Task/Note/BoardService).
+type TrashService interface {
+	ListBoard(ctx context.Context, boardID primitive.ObjectID) (*BoardTrash, error)
+	// ListBoards: board milik user yang ada di trash
+	ListBoards(ctx context.Context, ownerID primitive.ObjectID) ([]models.Board, error)
+	// Purge menghapus permanen yang masuk trash sebelum before; board ikut
+	// membawa semua task & note-nya, task membawa note-nya (activity tetap disimpan)
+	Purge(ctx context.Context, before time.Time) error
+	// Run: Purge berkala dengan masa retensi sampai ctx selesai
+	Run(ctx context.Context)
+}
+
+type trashService struct {
+	boards    repository.BoardRepo
+	tasks     repository.TaskRepo
+	notes     repository.NoteRepo
+	retention time.Duration
+}
+
+func NewTrashService(boards repository.BoardRepo, tasks repository.TaskRepo, notes repository.NoteRepo, retention time.Duration) TrashService {
+	return &trashService{boards: boards, tasks: tasks, notes: notes, retention: retention}
+}
+
+func (s *trashService) ListBoard(ctx context.Context, boardID primitive.ObjectID) (*BoardTrash, error) {
+	tasks, err := s.tasks.ListDeleted(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.notes.ListDeletedByBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	out := &BoardTrash{Tasks: []models.Task{}, Notes: []models.Note{}}
+	out.Tasks = append(out.Tasks, tasks...)
+	out.Notes = append(out.Notes, notes...)
+	return out, nil
+}
+
+func (s *trashService) ListBoards(ctx context.Context, ownerID primitive.ObjectID) ([]models.Board, error) {
+	out, err := s.boards.ListDeletedByOwner(ctx, ownerID)
+	if out == nil && err == nil {
+		out = []models.Board{}
+	}
+	return out, err
+}
+
+func (s *trashService) Purge(ctx context.Context, before time.Time) error {
+	boards := 0
+	for {
+		batch, err := s.boards.ListDeletedBefore(ctx, before, trashPurgeBatch)
+		if err != nil {
+			return err
+		}
+		for _, b := range batch {
+			// isi dulu, board terakhir: bila gagal di tengah, putaran berikutnya mengulang
+			if err := s.tasks.DeleteByBoard(ctx, b.ID); err != nil {
+				return err
+			}
+			if err := s.notes.DeleteByBoard(ctx, b.ID); err != nil {
+				return err
+			}
+			if err := s.boards.Delete(ctx, b.ID); err != nil {
+				return err
+			}
+		}
+		boards += len(batch)
+		if len(batch) < trashPurgeBatch {
+			break
+		}
+	}
+	tasks := 0
+	for {
+		batch, err := s.tasks.ListDeletedBefore(ctx, before, trashPurgeBatch)
+		if err != nil {
+			return err
+		}
+		for _, t := range batch {
+			if err := s.notes.DeleteByTask(ctx, t.ID); err != nil {
+				return err
+			}
+			if err := s.tasks.Delete(ctx, t.ID); err != nil {
+				return err
+			}
+		}
+		tasks += len(batch)
+		if len(batch) < trashPurgeBatch {
+			break
+		}
+	}
+	notes, err := s.notes.PurgeDeleted(ctx, before)
+	if err != nil {
+		return err
+	}
+	if boards+tasks+int(notes) > 0 {
+		log.Printf("[trash] purged boards=%d tasks=%d notes=%d", boards, tasks, notes)
+	}
+	return nil
+}
+
+func (s *trashService) Run(ctx context.Context) {
+	t := time.NewTicker(trashPurgeInterval)
+	defer t.Stop()
+	for {
+		if err := s.Purge(ctx, time.Now().UTC().Add(-s.retention)); err != nil {
+			log.Printf("[trash] purge: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type trashFixture struct {
+	repos *repository.Repos
+	tasks TaskService
+	notes NoteService
+	board BoardService
+	trash TrashService
+	owner primitive.ObjectID
+	b     *models.Board
+	ids   map[string]primitive.ObjectID
+}
+
+// newTrashFixture: board todo/done dengan a, b, c di todo
+func newTrashFixture(t *testing.T, mode OrderingMode) *trashFixture {
+	t.Helper()
+	repos := repository.NewMemory()
+	activity := NewActivityService(repos.Activities)
+	f := &trashFixture{
+		repos: repos,
+		tasks: NewTaskService(repos.Tasks, repos.Notes, repos.Boards, repos.Tx, activity, mode),
+		notes: NewNoteService(repos.Notes, repos.Tasks, repos.Boards, activity),
+		board: NewBoardService(repos.Boards, activity),
+		trash: NewTrashService(repos.Boards, repos.Tasks, repos.Notes, 30*24*time.Hour),
+		owner: primitive.NewObjectID(),
+	}
+	f.b = seedBoard(t, repos, f.owner, "todo", "done")
+	f.ids = seedTasks(t, f.tasks, f.b.ID, f.owner, "todo", "a", "b", "c")
+	return f
+}
+
+func (f *trashFixture) note(t *testing.T, task string) *models.Note {
+	t.Helper()
+	id := f.ids[task]
+	n, err := f.notes.Create(context.Background(), f.owner, "on "+task, &f.b.ID, &id, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func (f *trashFixture) titles(t *testing.T) map[string][]string {
+	t.Helper()
+	items, err := f.tasks.ListByBoard(context.Background(), f.b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string][]string{}
+	for _, it := range items {
+		out[it.ColumnID] = append(out[it.ColumnID], it.Title)
+	}
+	return out
+}
+
+func TestTaskRestoreKeepsPosition(t *testing.T) {
+	for _, mode := range []OrderingMode{OrderingIndex, OrderingRank} {
+		t.Run(string(mode), func(t *testing.T) {
+			ctx := context.Background()
+			f := newTrashFixture(t, mode)
+			if err := f.tasks.Delete(ctx, f.ids["b"], f.owner); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.tasks.Get(ctx, f.ids["b"]); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("Get of a trashed task: err = %v", err)
+			}
+			seedTasks(t, f.tasks, f.b.ID, f.owner, "todo", "d")
+
+			task, err := f.tasks.Restore(ctx, f.ids["b"], f.owner)
+			if err != nil || task.InTrash() {
+				t.Fatalf("restore = %+v, %v", task, err)
+			}
+			want := map[string][]string{"todo": {"a", "b", "c", "d"}}
+			if got := f.titles(t); !reflect.DeepEqual(got, want) {
+				t.Errorf("columns = %v, want %v", got, want)
+			}
+			if _, err := f.tasks.Restore(ctx, f.ids["b"], f.owner); !errors.Is(err, repository.ErrNotFound) {
+				t.Errorf("restore twice: err = %v", err)
+			}
+		})
+	}
+}
+
+// kolom asal sudah dihapus → akhir kolom pertama
+func TestTaskRestoreIntoRemovedColumn(t *testing.T) {
+	ctx := context.Background()
+	f := newTrashFixture(t, OrderingIndex)
+	seedTasks(t, f.tasks, f.b.ID, f.owner, "done", "x")
+	if _, err := f.tasks.Move(ctx, f.ids["a"], "done", 1, f.owner, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.tasks.Delete(ctx, f.ids["a"], f.owner); err != nil {
+		t.Fatal(err)
+	}
+	cols := []models.BoardColumn{{ID: "todo", Name: "todo", Order: 1}}
+	if err := f.repos.Boards.Update(ctx, f.b.ID, bson.M{"columns": cols}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := f.tasks.Restore(ctx, f.ids["a"], f.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ColumnID != "todo" || task.Order == nil || *task.Order != 3 {
+		t.Errorf("restored into %s at %v, want the end of todo", task.ColumnID, task.Order)
+	}
+}
+
+func TestTaskTrashCarriesItsNotes(t *testing.T) {
+	ctx := context.Background()
+	f := newTrashFixture(t, OrderingIndex)
+	kept, earlier := f.note(t, "b"), f.note(t, "b")
+	// note yang sudah dihapus sendiri tidak ikut kembali bersama task (waktu
+	// dimundurkan: deletedAt dibandingkan per milidetik)
+	if err := f.repos.Notes.SoftDelete(ctx, earlier.ID, f.owner, time.Now().UTC().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.tasks.Delete(ctx, f.ids["b"], f.owner); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := f.trash.ListBoard(ctx, f.b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tasks) != 1 || got.Tasks[0].ID != f.ids["b"] || len(got.Notes) != 2 {
+		t.Fatalf("trash = %d tasks, %d notes", len(got.Tasks), len(got.Notes))
+	}
+	if _, err := f.notes.Restore(ctx, kept.ID, f.owner); !errors.Is(err, ErrTaskInTrash) {
+		t.Errorf("restore a note of a trashed task: err = %v", err)
+	}
+
+	if _, err := f.tasks.Restore(ctx, f.ids["b"], f.owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.repos.Notes.FindByID(ctx, kept.ID); err != nil {
+		t.Errorf("note trashed with the task: %v", err)
+	}
+	if _, err := f.repos.Notes.FindDeleted(ctx, earlier.ID); err != nil {
+		t.Errorf("note trashed before the task came back: %v", err)
+	}
+	if _, err := f.notes.Restore(ctx, earlier.ID, f.owner); err != nil {
+		t.Errorf("restore the earlier note: %v", err)
+	}
+	if got, _ := f.trash.ListBoard(ctx, f.b.ID); len(got.Tasks)+len(got.Notes) != 0 {
+		t.Errorf("trash not empty: %+v", got)
+	}
+}
+
+func TestBoardTrash(t *testing.T) {
+	ctx := context.Background()
+	f := newTrashFixture(t, OrderingIndex)
+	if err := f.tasks.Delete(ctx, f.ids["a"], f.owner); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.board.Delete(ctx, f.b.ID, f.owner); err != nil {
+		t.Fatal(err)
+	}
+	boards, err := f.trash.ListBoards(ctx, f.owner)
+	if err != nil || len(boards) != 1 || boards[0].ID != f.b.ID {
+		t.Fatalf("trashed boards = %+v, %v", boards, err)
+	}
+	if other, _ := f.trash.ListBoards(ctx, primitive.NewObjectID()); other == nil || len(other) != 0 {
+		t.Errorf("someone else's trash = %#v", other)
+	}
+	if _, err := f.tasks.Restore(ctx, f.ids["a"], f.owner); !errors.Is(err, ErrBoardInTrash) {
+		t.Errorf("restore a task of a trashed board: err = %v", err)
+	}
+
+	b, err := f.board.Restore(ctx, f.b.ID, f.owner)
+	if err != nil || b.InTrash() {
+		t.Fatalf("board restore = %+v, %v", b, err)
+	}
+	// task yang dihapus sebelum board tetap di trash
+	if got := f.titles(t); !reflect.DeepEqual(got, map[string][]string{"todo": {"b", "c"}}) {
+		t.Errorf("columns = %v", got)
+	}
+	if _, err := f.tasks.Restore(ctx, f.ids["a"], f.owner); err != nil {
+		t.Errorf("restore after the board came back: %v", err)
+	}
+}
+
+func TestTrashPurge(t *testing.T) {
+	ctx := context.Background()
+	f := newTrashFixture(t, OrderingIndex)
+	old := time.Now().UTC().Add(-31 * 24 * time.Hour)
+	n := f.note(t, "a")
+	if err := f.repos.Tasks.SoftDelete(ctx, f.ids["a"], f.owner, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.repos.Notes.SoftDeleteByTask(ctx, f.ids["a"], f.owner, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.tasks.Delete(ctx, f.ids["b"], f.owner); err != nil {
+		t.Fatal(err)
+	}
+	gone := seedBoard(t, f.repos, f.owner, "todo")
+	goneTasks := seedTasks(t, f.tasks, gone.ID, f.owner, "todo", "x")
+	if err := f.repos.Boards.SoftDelete(ctx, gone.ID, f.owner, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.trash.Purge(ctx, time.Now().UTC().Add(-30*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.repos.Tasks.FindDeleted(ctx, f.ids["a"]); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("old task still in trash: %v", err)
+	}
+	if _, err := f.repos.Notes.FindDeleted(ctx, n.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("note of the purged task: %v", err)
+	}
+	if _, err := f.repos.Boards.FindDeleted(ctx, gone.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("old board still in trash: %v", err)
+	}
+	if _, err := f.repos.Tasks.FindByID(ctx, goneTasks["x"]); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("task of the purged board: %v", err)
+	}
+	// masih dalam masa retensi
+	if _, err := f.tasks.Restore(ctx, f.ids["b"], f.owner); err != nil {
+		t.Errorf("recent task purged: %v", err)
+	}
+}
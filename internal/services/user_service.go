@@ -3,34 +3,135 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
 	"github.com/PPLGPride/Be-Ambis-Solving/internal/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserService interface {
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	// FindByIDs: profil publik, id yang tidak ada dilewati
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]PublicUser, error)
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	Create(ctx context.Context, name, email, password string) (*models.User, error)
+	MarkEmailVerified(ctx context.Context, id primitive.ObjectID) error
+	// UpdateProfile: nil = tidak diubah; avatarURL "" menghapus avatar
+	UpdateProfile(ctx context.Context, id primitive.ObjectID, name, avatarURL *string) (*models.User, error)
+	// EnsureAdmins menjadikan admin user yang emailnya ada di daftar bootstrap
+	// (ADMIN_EMAILS) dan sudah terverifikasi; dipanggil saat startup
+	EnsureAdmins(ctx context.Context) error
 }
 
-type userService struct{}
+// PublicUser: data user yang boleh dilihat user lain (tanpa email)
+type PublicUser struct {
+	ID        primitive.ObjectID `json:"id"`
+	Name      string             `json:"name"`
+	AvatarURL *string            `json:"avatarUrl,omitempty"`
+	IsActive  bool               `json:"isActive"`
+}
 
-func NewUserService() UserService { return &userService{} }
+func ToPublicUser(u *models.User) PublicUser {
+	return PublicUser{ID: u.ID, Name: u.Name, AvatarURL: u.AvatarURL, IsActive: u.IsActive}
+}
 
-func (s *userService) FindByEmail(ctx context.Context, email string) (*models.User, error) {
-	var u models.User
-	err := config.MongoDB.Collection("users").FindOne(ctx, bson.M{"email": email}).Decode(&u)
+type userService struct {
+	users repository.UserRepo
+	// adminEmails: email (lowercase) → email seperti ditulis di config
+	adminEmails map[string]string
+}
+
+func NewUserService(users repository.UserRepo, adminEmails []string) UserService {
+	set := make(map[string]string, len(adminEmails))
+	for _, e := range adminEmails {
+		set[normalizeEmail(e)] = strings.TrimSpace(e)
+	}
+	return &userService{users: users, adminEmails: set}
+}
+
+func (s *userService) EnsureAdmins(ctx context.Context) error {
+	for key, email := range s.adminEmails {
+		u, err := s.users.FindByEmail(ctx, key)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if u.Role == models.RoleAdmin {
+			continue
+		}
+		// siapa pun bisa register dengan email itu; tunggu sampai terbukti pemiliknya
+		if u.EmailVerifiedAt == nil {
+			log.Printf("[admin] %s not promoted: email not verified (ADMIN_EMAILS)", email)
+			continue
+		}
+		if err := s.users.Update(ctx, u.ID, bson.M{"role": models.RoleAdmin, "updatedAt": time.Now().UTC()}); err != nil {
+			return err
+		}
+		log.Printf("[admin] %s promoted to admin (ADMIN_EMAILS)", email)
+	}
+	return nil
+}
+
+func (s *userService) FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	return s.users.FindByID(ctx, id)
+}
+
+func (s *userService) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]PublicUser, error) {
+	list, err := s.users.FindByIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
-	return &u, nil
+	out := make([]PublicUser, 0, len(list))
+	for i := range list {
+		out = append(out, ToPublicUser(&list[i]))
+	}
+	return out, nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, id primitive.ObjectID, name, avatarURL *string) (*models.User, error) {
+	set := bson.M{"updatedAt": time.Now().UTC()}
+	if name != nil {
+		n := strings.TrimSpace(*name)
+		if n == "" || utf8.RuneCountInString(n) > maxNameLength {
+			return nil, ErrInvalidName
+		}
+		set["name"] = n
+	}
+	if avatarURL != nil {
+		if *avatarURL == "" {
+			set["avatarUrl"] = nil
+		} else {
+			u, err := url.Parse(*avatarURL)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || len(*avatarURL) > maxAvatarURLLength {
+				return nil, ErrInvalidAvatarURL
+			}
+			set["avatarUrl"] = *avatarURL
+		}
+	}
+	if err := s.users.Update(ctx, id, set); err != nil {
+		return nil, err
+	}
+	return s.users.FindByID(ctx, id)
+}
+
+func (s *userService) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.users.FindByEmail(ctx, normalizeEmail(email))
 }
 
 func (s *userService) Create(ctx context.Context, name, email, password string) (*models.User, error) {
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
 	hash, err := utils.HashPassword(password)
 	if err != nil {
 		return nil, err
@@ -38,18 +139,46 @@ func (s *userService) Create(ctx context.Context, name, email, password string)
 	now := time.Now().UTC()
 	u := &models.User{
 		ID:           primitive.NewObjectID(),
-		Email:        email,
+		Email:        normalizeEmail(email), // lookup & uniq_email exact: simpan lowercase
 		PasswordHash: hash,
 		Name:         name,
 		Role:         models.RoleUser,
 		IsActive:     true,
 		TimeMeta:     models.TimeMeta{CreatedAt: now, UpdatedAt: now},
 	}
-	_, err = config.MongoDB.Collection("users").InsertOne(ctx, u)
-	if err != nil {
+	if err := s.users.Insert(ctx, u); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrEmailTaken
+		}
 		return nil, err
 	}
 	return u, nil
 }
 
-var ErrInvalidCredentials = errors.New("invalid credentials")
+func (s *userService) MarkEmailVerified(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now().UTC()
+	return s.users.Update(ctx, id, bson.M{"emailVerifiedAt": now, "updatedAt": now})
+}
+
+const (
+	maxNameLength      = 100
+	maxAvatarURLLength = 2048
+)
+
+// minPasswordLength: berlaku untuk register & reset password
+const minPasswordLength = 8
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+var (
+	ErrWeakPassword       = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrEmailTaken         = errors.New("email already registered")
+	ErrInvalidName        = fmt.Errorf("name must be 1-%d characters", maxNameLength)
+	ErrInvalidAvatarURL   = errors.New("avatarUrl must be an http(s) URL")
+)
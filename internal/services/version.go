@@ -0,0 +1,27 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+)
+
+// ErrVersionConflict: version yang dikirim klien (If-Match) sudah tidak sama
+// dengan yang tersimpan; klien harus memuat ulang dulu
+var ErrVersionConflict = errors.New("resource was modified by someone else, reload and retry")
+
+// checkVersion: expect nil = klien tidak meminta pengecekan
+func checkVersion(current int64, expect *int64) error {
+	if expect != nil && current != *expect {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// versionErr menerjemahkan ErrConflict dari UpdateIfVersion
+func versionErr(err error) error {
+	if errors.Is(err, repository.ErrConflict) {
+		return ErrVersionConflict
+	}
+	return err
+}
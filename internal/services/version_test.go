@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/models"
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// PATCH berisi name dan requireMfa: satu tulis, satu version, satu activity
+func TestBoardUpdateWritesRequireMFAWithOtherFields(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	owner := primitive.NewObjectID()
+	b := seedBoard(t, repos, owner, "todo")
+	activity := NewActivityService(repos.Activities)
+	svc := NewBoardService(repos.Boards, activity)
+
+	patch := BoardPatch{Name: ptr("renamed"), RequireMFA: ptr(true)}
+	if _, err := svc.Update(ctx, b.ID, owner, patch, ptr(b.Version)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "renamed" || !got.RequireMFA || got.Version != b.Version+1 {
+		t.Fatalf("board = %q requireMfa=%v version=%d, want renamed/true/%d", got.Name, got.RequireMFA, got.Version, b.Version+1)
+	}
+	page, err := activity.ListByBoard(ctx, b.ID, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("%d activities, want 1", len(page.Items))
+	}
+	var fields []string
+	for _, c := range page.Items[0].Changes {
+		fields = append(fields, c.Field)
+	}
+	if want := []string{"name", "requireMfa"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("changed fields = %v, want %v", fields, want)
+	}
+}
+
+// version basi: tidak ada field yang tertulis, termasuk requireMfa
+func TestBoardUpdateStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	owner := primitive.NewObjectID()
+	b := seedBoard(t, repos, owner, "todo")
+	svc := NewBoardService(repos.Boards, NewActivityService(repos.Activities))
+
+	if _, err := svc.Update(ctx, b.ID, owner, BoardPatch{Name: ptr("first")}, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err := svc.Update(ctx, b.ID, owner, BoardPatch{Name: ptr("second"), RequireMFA: ptr(true)}, ptr(b.Version))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+	got, err := svc.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "first" || got.RequireMFA {
+		t.Errorf("board = %q requireMfa=%v after a rejected update", got.Name, got.RequireMFA)
+	}
+}
+
+func TestTaskStaleVersionChangesNothing(t *testing.T) {
+	for _, mode := range []OrderingMode{OrderingIndex, OrderingRank} {
+		t.Run(string(mode), func(t *testing.T) {
+			ctx := context.Background()
+			repos := repository.NewMemory()
+			owner := primitive.NewObjectID()
+			b := seedBoard(t, repos, owner, "todo", "doing")
+			svc := newTestTaskService(repos, nil, mode)
+			ids := seedTasks(t, svc, b.ID, owner, "todo", "a", "b", "c")
+			stale := int64(1)
+			if err := svc.Update(ctx, ids["b"], bson.M{"title": "b"}, owner, &stale); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := svc.Move(ctx, ids["b"], "doing", 1, owner, &stale); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("Move: err = %v, want ErrVersionConflict", err)
+			}
+			if err := svc.Update(ctx, ids["b"], bson.M{"title": "x"}, owner, &stale); !errors.Is(err, ErrVersionConflict) {
+				t.Errorf("Update: err = %v, want ErrVersionConflict", err)
+			}
+			want := map[string][]string{"todo": {"a", "b", "c"}}
+			if mode == OrderingRank {
+				items, err := svc.ListByBoard(ctx, b.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := titles(items); !reflect.DeepEqual(got, want["todo"]) {
+					t.Errorf("tasks = %v, want %v", got, want["todo"])
+				}
+				return
+			}
+			if got := columnTitles(t, svc, b.ID); !reflect.DeepEqual(got, want) {
+				t.Errorf("columns = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// expect yang benar: Move lalu Update berikutnya memakai version baru
+func TestTaskVersionFollowsWrites(t *testing.T) {
+	ctx := context.Background()
+	repos := repository.NewMemory()
+	owner := primitive.NewObjectID()
+	b := seedBoard(t, repos, owner, "todo", "doing")
+	svc := newTestTaskService(repos, nil, OrderingIndex)
+	id := seedTasks(t, svc, b.ID, owner, "todo", "a")["a"]
+
+	var task *models.Task
+	reload := func() {
+		t.Helper()
+		var err error
+		if task, err = svc.Get(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reload()
+	if _, err := svc.Move(ctx, id, "doing", 1, owner, ptr(task.Version)); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	before := task.Version
+	reload()
+	if task.Version <= before {
+		t.Fatalf("version %d after Move, want > %d", task.Version, before)
+	}
+	if err := svc.Update(ctx, id, bson.M{"title": "renamed"}, owner, ptr(task.Version)); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	reload()
+	if task.Title != "renamed" || task.ColumnID != "doing" {
+		t.Errorf("task = %q in %s", task.Title, task.ColumnID)
+	}
+}
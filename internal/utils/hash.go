@@ -10,3 +10,13 @@ func HashPassword(plain string) (string, error) {
 func CheckPassword(hash, plain string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
 }
+
+// dummyHash: hash dengan cost yang sama untuk BurnPasswordCheck; dibuat saat
+// start supaya request pertama tidak lebih lambat
+var dummyHash, _ = HashPassword("not-a-real-password")
+
+// BurnPasswordCheck menjalankan bcrypt walau user tidak ada, supaya waktu
+// respons login tidak membocorkan email mana yang terdaftar
+func BurnPasswordCheck(plain string) {
+	CheckPassword(dummyHash, plain)
+}
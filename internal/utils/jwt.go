@@ -1,27 +1,55 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
-	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateJWT(sub string) (string, error) {
+const jwtIssuer = "be-ambis-solving"
+
+// GenerateJWT: access token untuk user sub; sid (claim jti) = ID session.
+// Ditandatangani dengan signing key aktif (header kid untuk RS256/EdDSA).
+func GenerateJWT(sub, sid string, ttl time.Duration) (string, error) {
+	ks, err := activeKeys()
+	if err != nil {
+		return "", err
+	}
 	claims := jwt.RegisteredClaims{
 		Subject:   sub,
-		Issuer:    "be-ambis-solving",
+		ID:        sid,
+		Issuer:    jwtIssuer,
 		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString([]byte(config.Cfg.JWTSecret))
+	k := ks.signing
+	t := jwt.NewWithClaims(k.method, claims)
+	if k.kid != "" {
+		t.Header["kid"] = k.kid
+	}
+	return t.SignedString(k.sign)
 }
 
+// ParseJWT hanya menerima algoritma dari key set, dan alg token harus sama
+// dengan alg key yang ditunjuk kid (tidak bisa "alg confusion").
 func ParseJWT(tokenStr string) (*jwt.RegisteredClaims, error) {
+	ks, err := activeKeys()
+	if err != nil {
+		return nil, err
+	}
 	tkn, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(config.Cfg.JWTSecret), nil
-	})
+		kid, _ := t.Header["kid"].(string)
+		k, ok := ks.byKID[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		if t.Method.Alg() != k.method.Alg() {
+			return nil, errors.New("unexpected signing algorithm")
+		}
+		return k.verify, nil
+	}, jwt.WithValidMethods(ks.methods), jwt.WithIssuer(jwtIssuer), jwt.WithExpirationRequired())
 	if err != nil {
 		return nil, err
 	}
@@ -30,3 +58,20 @@ func ParseJWT(tokenStr string) (*jwt.RegisteredClaims, error) {
 	}
 	return nil, jwt.ErrTokenInvalidClaims
 }
+
+// activeKeys: key set dari LoadJWTKeys (dimuat saat pertama dipakai bila
+// startup belum memanggilnya)
+func activeKeys() (*keySet, error) {
+	keysMu.RLock()
+	ks := keys
+	keysMu.RUnlock()
+	if ks != nil {
+		return ks, nil
+	}
+	if err := LoadJWTKeys(); err != nil {
+		return nil, err
+	}
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys, nil
+}
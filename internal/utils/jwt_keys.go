@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Default secret lama; hanya boleh dipakai dengan DEV_MODE=true
+const devJWTSecret = "devsecret"
+
+type jwtKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   interface{} // private key / secret; nil = verify-only
+	verify interface{} // public key / secret
+	public crypto.PublicKey
+}
+
+type keySet struct {
+	signing *jwtKey
+	byKID   map[string]*jwtKey
+	methods []string
+}
+
+var (
+	keysMu sync.RWMutex
+	keys   *keySet
+)
+
+// LoadJWTKeys membaca key dari config:
+//   - JWT_ALG=HS256 (default): JWT_SECRET, token tanpa kid
+//   - JWT_ALG=RS256 / EdDSA: JWT_SIGNING_KEY_FILE (PEM private key)
+//
+// JWT_VERIFY_KEY_FILES (PEM public key, pisah koma) menambah key lama yang
+// masih diterima saat rotasi. kid = thumbprint SHA-256 dari public key.
+func LoadJWTKeys() error {
+	cfg := config.Cfg
+	ks := &keySet{byKID: map[string]*jwtKey{}}
+
+	switch cfg.JWTAlg {
+	case "HS256":
+		if cfg.JWTSecret == "" || (cfg.JWTSecret == devJWTSecret && !cfg.DevMode) {
+			return errors.New("JWT_SECRET must be set (the default secret is only allowed with DEV_MODE=true)")
+		}
+		ks.signing = &jwtKey{method: jwt.SigningMethodHS256, sign: []byte(cfg.JWTSecret), verify: []byte(cfg.JWTSecret)}
+	case "RS256", "EdDSA":
+		if cfg.JWTSigningKeyFile == "" {
+			return fmt.Errorf("JWT_SIGNING_KEY_FILE is required for JWT_ALG=%s", cfg.JWTAlg)
+		}
+		k, err := loadSigningKey(cfg.JWTSigningKeyFile)
+		if err != nil {
+			return err
+		}
+		if k.method.Alg() != cfg.JWTAlg {
+			return fmt.Errorf("JWT_SIGNING_KEY_FILE holds a %s key but JWT_ALG=%s", k.method.Alg(), cfg.JWTAlg)
+		}
+		ks.signing = k
+	default:
+		return fmt.Errorf("unsupported JWT_ALG %q (use HS256, RS256 or EdDSA)", cfg.JWTAlg)
+	}
+	ks.add(ks.signing)
+
+	for _, path := range strings.Split(cfg.JWTVerifyKeyFiles, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		k, err := loadVerifyKey(path)
+		if err != nil {
+			return err
+		}
+		ks.add(k)
+	}
+
+	keysMu.Lock()
+	keys = ks
+	keysMu.Unlock()
+	return nil
+}
+
+func (ks *keySet) add(k *jwtKey) {
+	if _, dup := ks.byKID[k.kid]; dup {
+		return
+	}
+	ks.byKID[k.kid] = k
+	for _, m := range ks.methods {
+		if m == k.method.Alg() {
+			return
+		}
+	}
+	ks.methods = append(ks.methods, k.method.Alg())
+}
+
+func loadSigningKey(path string) (*jwtKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	var priv interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: expected a private key, got %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported private key", path)
+	}
+	k, err := newAsymKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	k.sign = priv
+	return k, nil
+}
+
+func loadVerifyKey(path string) (*jwtKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	var pub interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: expected a public key, got %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	k, err := newAsymKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	return block, nil
+}
+
+// newAsymKey: method & kid dari tipe public key
+func newAsymKey(pub crypto.PublicKey) (*jwtKey, error) {
+	k := &jwtKey{verify: pub, public: pub}
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", pub)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	k.kid = base64.RawURLEncoding.EncodeToString(sum[:])[:16]
+	return k, nil
+}
+
+// JWKS: public key untuk verifikasi token di service lain (RFC 7517).
+// HS256 tidak punya public key → daftar kosong.
+func JWKS() (map[string]interface{}, error) {
+	ks, err := activeKeys()
+	if err != nil {
+		return nil, err
+	}
+	out := []map[string]string{}
+	// signing key dulu, lalu key rotasi
+	list := []*jwtKey{ks.signing}
+	for _, k := range ks.byKID {
+		if k != ks.signing {
+			list = append(list, k)
+		}
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+	for _, k := range list {
+		switch p := k.public.(type) {
+		case *rsa.PublicKey:
+			out = append(out, map[string]string{
+				"kty": "RSA", "use": "sig", "alg": k.method.Alg(), "kid": k.kid,
+				"n": b64(p.N.Bytes()), "e": b64(big.NewInt(int64(p.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			out = append(out, map[string]string{
+				"kty": "OKP", "crv": "Ed25519", "use": "sig", "alg": k.method.Alg(), "kid": k.kid,
+				"x": b64(p),
+			})
+		}
+	}
+	return map[string]interface{}{"keys": out}, nil
+}
@@ -0,0 +1,212 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PPLGPride/Be-Ambis-Solving/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useJWTConfig memuat key dari cfg; config dan key set dikembalikan setelah test
+func useJWTConfig(t *testing.T, cfg config.AppConfig) error {
+	t.Helper()
+	saved := config.Cfg
+	keysMu.RLock()
+	savedKeys := keys
+	keysMu.RUnlock()
+	t.Cleanup(func() {
+		config.Cfg = saved
+		keysMu.Lock()
+		keys = savedKeys
+		keysMu.Unlock()
+	})
+	config.Cfg = cfg
+	return LoadJWTKeys()
+}
+
+// writeKeyPair menulis private key (PKCS#8) dan public key (PKIX) ke dir
+func writeKeyPair(t *testing.T, dir, name string, priv crypto.Signer) (privPath, pubPath string) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPath = filepath.Join(dir, name+".pem")
+	pubPath = filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privPath, pubPath
+}
+
+func newEd25519(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func TestLoadJWTKeysRefusesUnsafeConfig(t *testing.T) {
+	dir := t.TempDir()
+	edKey, _ := writeKeyPair(t, dir, "ed", newEd25519(t))
+	cases := map[string]config.AppConfig{
+		"default secret":        {JWTAlg: "HS256", JWTSecret: devJWTSecret},
+		"empty secret":          {JWTAlg: "HS256", DevMode: true},
+		"missing key file":      {JWTAlg: "EdDSA"},
+		"key does not match":    {JWTAlg: "RS256", JWTSigningKeyFile: edKey},
+		"public key to sign":    {JWTAlg: "EdDSA", JWTSigningKeyFile: strings.TrimSuffix(edKey, ".pem") + ".pub.pem"},
+		"unsupported algorithm": {JWTAlg: "HS512", JWTSecret: "long-enough-secret"},
+	}
+	for name, cfg := range cases {
+		if err := useJWTConfig(t, cfg); err == nil {
+			t.Errorf("%s: LoadJWTKeys accepted %+v", name, cfg)
+		}
+	}
+	if err := useJWTConfig(t, config.AppConfig{JWTAlg: "HS256", JWTSecret: devJWTSecret, DevMode: true}); err != nil {
+		t.Errorf("default secret in dev mode: %v", err)
+	}
+}
+
+// key lama tetap memverifikasi token yang sudah terbit setelah signing key diganti
+func TestJWTKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPriv, oldPub := writeKeyPair(t, dir, "old", newEd25519(t))
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPriv, _ := writeKeyPair(t, dir, "new", rsaKey)
+
+	if err := useJWTConfig(t, config.AppConfig{JWTAlg: "EdDSA", JWTSigningKeyFile: oldPriv}); err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := GenerateJWT("user", "session", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := useJWTConfig(t, config.AppConfig{JWTAlg: "RS256", JWTSigningKeyFile: newPriv, JWTVerifyKeyFiles: " , " + oldPub}); err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := GenerateJWT("user", "session", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tok := range map[string]string{"old": oldToken, "new": newToken} {
+		if claims, err := ParseJWT(tok); err != nil || claims.Subject != "user" || claims.ID != "session" {
+			t.Errorf("%s token: %+v, %v", name, claims, err)
+		}
+	}
+
+	set, err := JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := set["keys"].([]map[string]string)
+	if len(jwks) != 2 || jwks[0]["alg"] != "RS256" || jwks[1]["alg"] != "EdDSA" || jwks[1]["crv"] != "Ed25519" {
+		t.Fatalf("jwks = %v", jwks)
+	}
+	header := func(tok string) string {
+		parsed, _, err := jwt.NewParser().ParseUnverified(tok, &jwt.RegisteredClaims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		kid, _ := parsed.Header["kid"].(string)
+		return kid
+	}
+	if header(newToken) != jwks[0]["kid"] || header(oldToken) != jwks[1]["kid"] {
+		t.Errorf("token kids %q/%q do not match jwks %q/%q", header(newToken), header(oldToken), jwks[0]["kid"], jwks[1]["kid"])
+	}
+
+	// key lama dilepas dari rotasi: token lama ditolak
+	if err := useJWTConfig(t, config.AppConfig{JWTAlg: "RS256", JWTSigningKeyFile: newPriv}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseJWT(oldToken); err == nil {
+		t.Error("token signed by a retired key was accepted")
+	}
+}
+
+func TestParseJWTPinsAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	priv := newEd25519(t)
+	privPath, _ := writeKeyPair(t, dir, "ed", priv)
+	if err := useJWTConfig(t, config.AppConfig{JWTAlg: "EdDSA", JWTSigningKeyFile: privPath}); err != nil {
+		t.Fatal(err)
+	}
+	valid, err := GenerateJWT("user", "session", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(valid, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid := parsed.Header["kid"].(string)
+	claims := func(issuer string) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{Subject: "user", ID: "session", Issuer: issuer, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+	}
+	sign := func(method jwt.SigningMethod, c jwt.Claims, kid string, key interface{}) string {
+		tok := jwt.NewWithClaims(method, c)
+		if kid != "" {
+			tok.Header["kid"] = kid
+		}
+		s, err := tok.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	pub := []byte(priv.Public().(ed25519.PublicKey))
+
+	forged := map[string]string{
+		// public key dipakai sebagai secret HMAC
+		"HS256 with the public key": sign(jwt.SigningMethodHS256, claims(jwtIssuer), kid, pub),
+		"alg none":                  sign(jwt.SigningMethodNone, claims(jwtIssuer), kid, jwt.UnsafeAllowNoneSignatureType),
+		"unknown kid":               sign(jwt.SigningMethodEdDSA, claims(jwtIssuer), "other", priv),
+		"no kid":                    sign(jwt.SigningMethodEdDSA, claims(jwtIssuer), "", priv),
+		"other issuer":              sign(jwt.SigningMethodEdDSA, claims("someone-else"), kid, priv),
+		"no expiry":                 sign(jwt.SigningMethodEdDSA, jwt.RegisteredClaims{Subject: "user", Issuer: jwtIssuer}, kid, priv),
+	}
+	for name, tok := range forged {
+		if _, err := ParseJWT(tok); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+	if _, err := ParseJWT(valid); err != nil {
+		t.Errorf("valid token: %v", err)
+	}
+}
+
+// HS256 tidak punya public key untuk dibagikan
+func TestJWKSIsEmptyForHS256(t *testing.T) {
+	if err := useJWTConfig(t, config.AppConfig{JWTAlg: "HS256", JWTSecret: "utils-test-secret-0123456789abcdef"}); err != nil {
+		t.Fatal(err)
+	}
+	set, err := JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := set["keys"].([]map[string]string); len(keys) != 0 {
+		t.Errorf("jwks = %v", keys)
+	}
+}
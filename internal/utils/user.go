@@ -15,3 +15,10 @@ func UserIDFromCtx(c *fiber.Ctx) (primitive.ObjectID, error) {
 	}
 	return MustObjectID(s)
 }
+
+// SessionIDFromCtx: ID session dari access token (NilObjectID bila tidak ada)
+func SessionIDFromCtx(c *fiber.Ctx) primitive.ObjectID {
+	s, _ := c.Locals("sessionId").(string)
+	oid, _ := primitive.ObjectIDFromHex(s)
+	return oid
+}